@@ -0,0 +1,49 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package boundedcache
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Cache keeps the values computed for at most limit keys. Once full, the values of new keys are
+// computed on every use, so keys built from untrusted input cannot grow it without bounds.
+type Cache[K comparable, V any] struct {
+	entries sync.Map
+	size    atomic.Int64
+	limit   int64
+}
+
+// New returns an empty Cache holding at most limit entries.
+func New[K comparable, V any](limit int) *Cache[K, V] {
+	return &Cache[K, V]{limit: int64(limit)}
+}
+
+// Get returns the value cached for key, computing it with compute on first use. Errors returned
+// by compute are never cached.
+func (c *Cache[K, V]) Get(key K, compute func(K) (V, error)) (V, error) {
+	if cached, found := c.entries.Load(key); found {
+		if value, ok := cached.(V); ok {
+			return value, nil
+		}
+	}
+
+	value, err := compute(key)
+	if err != nil {
+		return value, err
+	}
+
+	if c.size.Load() < c.limit {
+		if _, loaded := c.entries.LoadOrStore(key, value); !loaded {
+			c.size.Add(1)
+		}
+	}
+	return value, nil
+}
+
+// Len returns the number of cached entries.
+func (c *Cache[K, V]) Len() int {
+	return int(c.size.Load())
+}
@@ -0,0 +1,50 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package boundedcache
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	compute := func(key string) (*string, error) {
+		calls++
+		if key == "invalid" {
+			return nil, errors.New("invalid key")
+		}
+		return &key, nil
+	}
+
+	cache := New[string, *string](2)
+	first, err := cache.Get("first", compute)
+	require.NoError(t, err)
+	cached, err := cache.Get("first", compute)
+	require.NoError(t, err)
+	assert.Same(t, first, cached, "the value is computed only on first use")
+	assert.Equal(t, 1, calls)
+
+	_, err = cache.Get("invalid", compute)
+	require.Error(t, err)
+	assert.Equal(t, 1, cache.Len(), "errors are not cached")
+
+	for index := range 5 {
+		_, err := cache.Get(strconv.Itoa(index), compute)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, cache.Len())
+
+	first, err = cache.Get("not-cached", compute)
+	require.NoError(t, err)
+	second, err := cache.Get("not-cached", compute)
+	require.NoError(t, err)
+	assert.NotSame(t, first, second, "once full, new values are computed on every use")
+}
@@ -0,0 +1,6 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+// Package boundedcache provides a concurrent cache of values computed from their key, like
+// compiled expressions or patterns, that stops growing once it holds a maximum number of entries.
+package boundedcache
@@ -25,7 +25,8 @@ const (
 	- azure: Microsoft Azure integration
 	- console: Mia Platform Console integration
 	- azure-devops: Microsoft Azure DevOps integration
-	- gcp: Google Cloud Platform integration`
+	- gcp: Google Cloud Platform integration
+	- replay: replay of the source data recorded with the record or dead-letter flags`
 
 	runCmdExample = `# Run the Google Cloud Platform integration
 	ibdm run gcp --mapping-path mapping.yaml`
@@ -40,10 +41,15 @@ const (
 	The available integrations are:
 	- azure: Microsoft Azure integration
 	- azure-devops: Microsoft Azure DevOps integration
-	- gcp: Google Cloud Platform integration`
+	- gcp: Google Cloud Platform integration
+	- replay: replay of the source data recorded with the record or dead-letter flags`
 
 	syncCmdExample = `# Run the Google Cloud Platform synchronization
-	ibdm sync gcp --mapping-path mapping.yaml`
+	ibdm sync gcp --mapping-path mapping.yaml
+
+	# Record the data received from GitLab, then map it again offline
+	ibdm sync gitlab -f mappings/ --record recorded/
+	ibdm sync replay --replay-dir recorded/ -f mappings/ --local-output`
 )
 
 // RunCmd returns the Cobra command that starts an event-stream integration.
@@ -6,14 +6,18 @@ package cmd
 import (
 	"bytes"
 	"fmt"
+	"os"
 	"path/filepath"
+	"strings"
 	"syscall"
 	"testing"
 
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/mia-platform/ibdm/internal/source/gcp"
+	"github.com/mia-platform/ibdm/internal/source/recording"
 )
 
 func TestCmds(t *testing.T) {
@@ -108,3 +112,149 @@ func TestCmds(t *testing.T) {
 		})
 	}
 }
+
+func TestOutputFlag(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		args                 func(t *testing.T) []string
+		expectedError        error
+		expectedErrorMessage string
+		expectedFile         string
+	}{
+		"unsupported output return error": {
+			args: func(*testing.T) []string {
+				return []string{"gcp", "--" + outputFlagName, "stdout"}
+			},
+			expectedError:        errInvalidOutput,
+			expectedErrorMessage: "invalid output: \"stdout\", only file://<path> is supported\n",
+		},
+		"empty file path return error": {
+			args: func(*testing.T) []string {
+				return []string{"gcp", "--" + outputFlagName, "file://"}
+			},
+			expectedError:        errInvalidOutput,
+			expectedErrorMessage: "invalid output: \"file://\", only file://<path> is supported\n",
+		},
+		"file output is created before running the source": {
+			args: func(t *testing.T) []string {
+				t.Helper()
+				return []string{"gcp", "--" + outputFlagName, "file://" + filepath.Join(t.TempDir(), "output.ndjson")}
+			},
+			expectedError:        gcp.ErrGCPSource,
+			expectedErrorMessage: "gcp source: missing environment variable: GOOGLE_CLOUD_SYNC_PARENT\n",
+			expectedFile:         "output.ndjson",
+		},
+	}
+
+	for testName, test := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			errBuffer := new(bytes.Buffer)
+			cmd := SyncCmd()
+			cmd.SetOut(new(bytes.Buffer))
+			cmd.SetErr(errBuffer)
+			args := test.args(t)
+			cmd.SetArgs(args)
+
+			err := cmd.ExecuteContext(t.Context())
+			assert.ErrorIs(t, err, test.expectedError)
+			assert.Equal(t, test.expectedErrorMessage, errBuffer.String())
+
+			if test.expectedFile != "" {
+				outputPath := strings.TrimPrefix(args[len(args)-1], fileOutputPrefix)
+				assert.Equal(t, test.expectedFile, filepath.Base(outputPath))
+				assert.FileExists(t, outputPath)
+			}
+		})
+	}
+
+	t.Run("output and local output cannot be used together", func(t *testing.T) {
+		t.Parallel()
+
+		cmd := SyncCmd()
+		cmd.SetOut(new(bytes.Buffer))
+		cmd.SetErr(new(bytes.Buffer))
+		cmd.SetArgs([]string{"gcp", "--" + localOutputFlagName, "--" + outputFlagName, "file://output.ndjson"})
+
+		err := cmd.ExecuteContext(t.Context())
+		assert.ErrorContains(t, err, "none of the others can be")
+	})
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	t.Parallel()
+
+	recordDir := t.TempDir()
+	outBuffer := new(bytes.Buffer)
+	errBuffer := new(bytes.Buffer)
+	cmd := SyncCmd()
+	cmd.SetOut(outBuffer)
+	cmd.SetErr(errBuffer)
+	cmd.SetArgs([]string{
+		replaySource,
+		"--" + replayDirFlagName, filepath.Join("testdata", "replay", "recorded"),
+		"--" + mappingPathFlagName, filepath.Join("testdata", "mappers.yaml"),
+		"--" + recordFlagName, recordDir,
+		"--" + identifierCacheFlagName, filepath.Join(t.TempDir(), "identifiers.jsonl"),
+		"--" + localOutputFlagName,
+	})
+
+	require.NoError(t, cmd.ExecuteContext(t.Context()))
+	assert.Empty(t, errBuffer)
+	assert.Equal(t, "Send data:\n\tAPIVersion: v1\n\tItemFamily: family\n\tItem Name: 1\n\tTimestamp: 2024-06-01T12:00:00Z\n"+
+		"\tMetadata: {}\n\n\tSpec: {\n\t\t\"field1\": \"value\"\n\t}\n\n"+
+		"Delete data:\n\tAPIVersion: v1\n\tItemFamily: family\n\tItem Name: 1\n\tTimestamp: 2024-06-01T12:00:01Z\n\n", outBuffer.String())
+
+	recordedFiles, err := filepath.Glob(filepath.Join(recordDir, replaySource+"-*.ndjson"))
+	require.NoError(t, err)
+	require.Len(t, recordedFiles, 1)
+	recorded, err := os.ReadFile(recordedFiles[0])
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"mapper-type","operation":"upsert","values":{"field1":"VALUE","id":"1","nativeID":"native-1"},"time":"2024-06-01T12:00:00Z"}
+{"type":"mapper-type","operation":"delete","values":{"id":"1","nativeID":"native-1"},"time":"2024-06-01T12:00:01Z"}
+`, string(recorded), "only the types requested by the mappings are replayed")
+}
+
+func TestDeadLetter(t *testing.T) {
+	t.Parallel()
+
+	deadLetterDir := t.TempDir()
+	cmd := SyncCmd()
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{
+		replaySource,
+		"--" + replayDirFlagName, filepath.Join("testdata", "mapping-test", "data"),
+		"--" + mappingPathFlagName, filepath.Join("testdata", "mapping-test", "mappings.yaml"),
+		"--" + deadLetterFlagName, deadLetterDir,
+		"--" + localOutputFlagName,
+	})
+
+	require.NoError(t, cmd.ExecuteContext(t.Context()))
+
+	deadLetterFiles, err := filepath.Glob(filepath.Join(deadLetterDir, replaySource+"-*.ndjson"))
+	require.NoError(t, err)
+	require.Len(t, deadLetterFiles, 1)
+	deadLetter, err := os.ReadFile(deadLetterFiles[0])
+	require.NoError(t, err)
+	assert.Equal(t, `{"error":{"message":"spec.visibility: template: spec:3:15: executing \"spec\" at \u003c.visibility\u003e: map has no entry for key \"visibility\"","file":"`+
+		filepath.Join("testdata", "mapping-test", "mappings.yaml")+`","document":0,"field":"spec.visibility","line":8,"column":5},`+
+		`"operation":"upsert","time":"2024-06-01T12:00:01Z","type":"repository","values":{"id":"2","name":"second"}}
+`, string(deadLetter), "only the data that cannot be mapped is recorded with the position of the failing field")
+}
+
+func TestReplaySourceRequiresReplayDir(t *testing.T) {
+	t.Parallel()
+
+	errBuffer := new(bytes.Buffer)
+	cmd := RunCmd()
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(errBuffer)
+	cmd.SetArgs([]string{replaySource, "--" + localOutputFlagName})
+
+	err := cmd.ExecuteContext(t.Context())
+	assert.ErrorIs(t, err, recording.ErrReplaySource)
+	assert.Equal(t, "replay source: the directory of the recorded data is required\n", errBuffer.String())
+}
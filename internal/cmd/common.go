@@ -14,20 +14,27 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/mia-platform/ibdm/internal/config"
-	"github.com/mia-platform/ibdm/internal/mapper"
+	mapperpkg "github.com/mia-platform/ibdm/internal/mapper"
 	"github.com/mia-platform/ibdm/internal/pipeline"
+	"github.com/mia-platform/ibdm/internal/source/aws"
 	"github.com/mia-platform/ibdm/internal/source/azure"
 	azuredevops "github.com/mia-platform/ibdm/internal/source/azure-devops"
 	"github.com/mia-platform/ibdm/internal/source/bitbucket"
 	"github.com/mia-platform/ibdm/internal/source/console"
+	filesource "github.com/mia-platform/ibdm/internal/source/file"
 	"github.com/mia-platform/ibdm/internal/source/gcp"
 	"github.com/mia-platform/ibdm/internal/source/github"
 	"github.com/mia-platform/ibdm/internal/source/gitlab"
+	"github.com/mia-platform/ibdm/internal/source/httpsource"
+	"github.com/mia-platform/ibdm/internal/source/kubernetes"
 	"github.com/mia-platform/ibdm/internal/source/nexus"
 	"github.com/mia-platform/ibdm/internal/source/sysdig"
+	webhooksource "github.com/mia-platform/ibdm/internal/source/webhook"
 )
 
 const (
+	awsSource              = "aws"
+	awsDescription         = "Amazon Web Services integration"
 	azureDevOpsSource      = "azure-devops"
 	azureDevOpsDescription = "Microsoft Azure DevOps integration"
 	azureSource            = "azure"
@@ -36,44 +43,66 @@ const (
 	bitbucketDescription   = "Bitbucket integration"
 	consoleSource          = "console"
 	consoleDescription     = "Mia Platform Console integration"
+	fileSource             = "file"
+	fileDescription        = "JSON, YAML and CSV files integration"
 	gcpSource              = "gcp"
 	gcpDescription         = "Google Cloud Platform integration"
 	githubSource           = "github"
 	githubDescription      = "GitHub integration"
 	gitlabSource           = "gitlab"
 	gitlabDescription      = "GitLab integration"
+	httpSource             = "http"
+	httpDescription        = "Generic REST API integration"
+	kubernetesSource       = "kubernetes"
+	kubernetesDescription  = "Kubernetes integration"
 	nexusSource            = "nexus"
 	nexusDescription       = "Sonatype Nexus Repository Manager integration"
+	replaySource           = "replay"
+	replayDescription      = "Replay of the source data recorded with the record or dead-letter flags"
 	sysdigSource           = "sysdig"
 	sysdigDescription      = "Sysdig Secure integration"
+	webhookSource          = "webhook"
+	webhookDescription     = "Generic signed webhook integration"
 )
 
 var (
 	errNoArguments        = errors.New("no integration name provided")
 	errInvalidIntegration = errors.New("invalid integration name provided")
+	errTombstoneNoCache   = errors.New("the tombstone delete mode requires the " + identifierCacheFlagName + " flag")
 
 	// availableEventSources covers event-stream integration sources used for completion and help text.
 	availableEventSources = map[string]string{
+		awsSource:         awsDescription,
 		azureDevOpsSource: azureDevOpsDescription,
 		azureSource:       azureDescription,
 		bitbucketSource:   bitbucketDescription,
 		consoleSource:     consoleDescription,
+		fileSource:        fileDescription,
 		gcpSource:         gcpDescription,
 		githubSource:      githubDescription,
 		gitlabSource:      gitlabDescription,
+		httpSource:        httpDescription,
+		kubernetesSource:  kubernetesDescription,
 		nexusSource:       nexusDescription,
+		replaySource:      replayDescription,
 		sysdigSource:      sysdigDescription,
+		webhookSource:     webhookDescription,
 	}
 	// availableSyncSources covers synchronization sources used for completion and help text.
 	availableSyncSources = map[string]string{
+		awsSource:         awsDescription,
 		azureDevOpsSource: azureDevOpsDescription,
 		azureSource:       azureDescription,
 		bitbucketSource:   bitbucketDescription,
 		consoleSource:     consoleDescription,
+		fileSource:        fileDescription,
 		gcpSource:         gcpDescription,
 		githubSource:      githubDescription,
 		gitlabSource:      gitlabDescription,
+		httpSource:        httpDescription,
+		kubernetesSource:  kubernetesDescription,
 		nexusSource:       nexusDescription,
+		replaySource:      replayDescription,
 		sysdigSource:      sysdigDescription,
 	}
 )
@@ -115,6 +144,8 @@ func validArgsFunc(sources map[string]string) cobra.CompletionFunc {
 // sourceFromIntegrationName returns the pipeline source matching integrationName.
 func sourceFromIntegrationName(integrationName string) (any, error) {
 	switch integrationName {
+	case awsSource:
+		return aws.NewSource()
 	case azureSource:
 		return azure.NewSource()
 	case azureDevOpsSource:
@@ -127,12 +158,20 @@ func sourceFromIntegrationName(integrationName string) (any, error) {
 		return github.NewSource()
 	case consoleSource:
 		return console.NewSource()
+	case fileSource:
+		return filesource.NewSource()
 	case gitlabSource:
 		return gitlab.NewSource()
+	case httpSource:
+		return httpsource.NewSource()
+	case kubernetesSource:
+		return kubernetes.NewSource()
 	case nexusSource:
 		return nexus.NewSource()
 	case sysdigSource:
 		return sysdig.NewSource()
+	case webhookSource:
+		return webhooksource.NewSource()
 	}
 	return nil, nil
 }
@@ -204,22 +243,59 @@ func loadMappers(paths []string, syncOnly bool) (map[string]pipeline.DataMapper,
 		}
 
 		mappings := mapping.Mappings
-		mapper, err := mapper.New(mappings.Identifier, mappings.Metadata, mappings.Spec, mappings.Extra)
+		mapper, err := mapperpkg.New(mappings.Identifier, mappings.Metadata, mappings.Spec, mappings.Extra)
 		if err != nil {
 			return nil, err
 		}
 
-		typedMappers[mapping.Type] = pipeline.DataMapper{
+		dataMapper := pipeline.DataMapper{
 			APIVersion: mapping.APIVersion,
 			ItemFamily: mapping.ItemFamily,
+			Version:    mapping.Version,
 			Mapper:     mapper,
 			Extra:      mapping.Extra,
+			Source:     mapping.Source,
 		}
+
+		if mappings.SourceKey != "" {
+			sourceKeyMapper, err := mapperpkg.NewSourceKeyMapper(mappings.SourceKey)
+			if err != nil {
+				return nil, err
+			}
+			dataMapper.SourceKey = sourceKeyMapper
+		}
+
+		if mapping.DeleteMode == config.DeleteModeTombstone {
+			tombstoneMapper, err := mapperpkg.NewTombstoneMapper(mappings.Tombstone)
+			if err != nil {
+				return nil, err
+			}
+			dataMapper.Tombstone = tombstoneMapper
+		}
+
+		typedMappers[mapping.Type] = dataMapper
 	}
 
 	return typedMappers, nil
 }
 
+// checkDeleteModes reports an error when a mapper uses the tombstone delete mode without a
+// persistent identifier cache: tombstones are built from the last payload cached for each item,
+// which an in-memory cache loses at every restart.
+func checkDeleteModes(mappers map[string]pipeline.DataMapper, persistentCache bool) error {
+	if persistentCache {
+		return nil
+	}
+
+	for dataType, mapper := range mappers {
+		if mapper.Tombstone != nil {
+			return fmt.Errorf("%w: mapping of type %q", errTombstoneNoCache, dataType)
+		}
+	}
+
+	return nil
+}
+
 // loadMappingConfigs reads every mapping configuration from the provided paths.
 func loadMappingConfigs(paths []string) ([]*config.MappingConfig, error) {
 	mappings := make([]*config.MappingConfig, 0)
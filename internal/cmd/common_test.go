@@ -7,6 +7,7 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/spf13/cobra"
@@ -31,15 +32,21 @@ func TestCompletion(t *testing.T) {
 		"no args, complete root commands": {
 			args: []string{},
 			expectedCompletion: []string{
+				awsSource + "\t" + awsDescription,
 				azureDevOpsSource + "\t" + azureDevOpsDescription,
 				azureSource + "\t" + azureDescription,
 				bitbucketSource + "\t" + bitbucketDescription,
 				consoleSource + "\t" + consoleDescription,
+				fileSource + "\t" + fileDescription,
 				gcpSource + "\t" + gcpDescription,
 				githubSource + "\t" + githubDescription,
 				gitlabSource + "\t" + gitlabDescription,
+				httpSource + "\t" + httpDescription,
+				kubernetesSource + "\t" + kubernetesDescription,
 				nexusSource + "\t" + nexusDescription,
+				replaySource + "\t" + replayDescription,
 				sysdigSource + "\t" + sysdigDescription,
+				webhookSource + "\t" + webhookDescription,
 			},
 		},
 		"some args, no completions": {
@@ -196,10 +203,12 @@ func TestLoadMappers(t *testing.T) {
 	t.Parallel()
 
 	testCases := map[string]struct {
-		paths           []string
-		syncOnly        bool
-		expectedMappers map[string]pipeline.DataMapper
-		expectedError   error
+		paths              []string
+		syncOnly           bool
+		expectedMappers    map[string]pipeline.DataMapper
+		expectedSourceKeys []string
+		expectedTombstones []string
+		expectedError      error
 	}{
 		"valid mapping config": {
 			paths: []string{
@@ -215,6 +224,8 @@ func TestLoadMappers(t *testing.T) {
 					ItemFamily: "family",
 				},
 			},
+			expectedSourceKeys: []string{"mapper-type"},
+			expectedTombstones: []string{"valid"},
 		},
 		"valid mapping config filtered by sync": {
 			paths: []string{
@@ -227,6 +238,7 @@ func TestLoadMappers(t *testing.T) {
 					ItemFamily: "family",
 				},
 			},
+			expectedSourceKeys: []string{"mapper-type"},
 		},
 		"error reading config": {
 			paths: []string{
@@ -260,6 +272,8 @@ func TestLoadMappers(t *testing.T) {
 				assert.Equal(t, expectedMapper.APIVersion, mapper.APIVersion)
 				assert.Equal(t, expectedMapper.ItemFamily, mapper.ItemFamily)
 				assert.NotNil(t, mapper.Mapper)
+				assert.Equal(t, slices.Contains(test.expectedSourceKeys, name), mapper.SourceKey != nil)
+				assert.Equal(t, slices.Contains(test.expectedTombstones, name), mapper.Tombstone != nil)
 			}
 		})
 	}
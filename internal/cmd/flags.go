@@ -4,13 +4,17 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/mia-platform/ibdm/internal/destination"
 	"github.com/mia-platform/ibdm/internal/destination/catalog"
+	"github.com/mia-platform/ibdm/internal/destination/file"
 	"github.com/mia-platform/ibdm/internal/destination/writer"
+	"github.com/mia-platform/ibdm/internal/source/recording"
 )
 
 const (
@@ -21,12 +25,37 @@ const (
 	localOutputFlagName  = "local-output"
 	localOutputFlagUsage = "If set, writes the output to stdout instead of sending it to the remote"
 	defaultLocalOutput   = false
+
+	outputFlagName   = "output"
+	outputFlagUsage  = "If set, writes the output to the given destination instead of sending it to the remote, only file://<path> is supported"
+	fileOutputPrefix = "file://"
+
+	recordFlagName  = "record"
+	recordFlagUsage = "Path to a directory where the raw data received from the source is recorded, to replay it later with the replay integration"
+
+	deadLetterFlagName  = "dead-letter"
+	deadLetterFlagUsage = "Path to a directory where the source data that cannot be mapped is recorded with the mapping error, to replay it later with the replay integration"
+
+	replayDirFlagName  = "replay-dir"
+	replayDirFlagUsage = "Path to the directory of the data recorded with the record or dead-letter flags, used by the replay integration"
+
+	identifierCacheFlagName  = "identifier-cache"
+	identifierCacheFlagUsage = "Path to a file where the generated identifiers are persisted between runs. If not set, they are kept in memory"
+)
+
+var (
+	errInvalidOutput = errors.New("invalid output")
 )
 
 // flags collects the CLI options shared by the run and sync commands.
 type flags struct {
-	mappingPaths []string
-	localOutput  bool
+	mappingPaths        []string
+	localOutput         bool
+	output              string
+	recordDir           string
+	deadLetterDir       string
+	replayDir           string
+	identifierCachePath string
 }
 
 // addFlags registers the CLI flags on cmd.
@@ -39,6 +68,12 @@ func (f *flags) addFlags(cmd *cobra.Command) {
 		mappingPathFlagUsage)
 
 	cmd.Flags().BoolVar(&f.localOutput, localOutputFlagName, defaultLocalOutput, localOutputFlagUsage)
+	cmd.Flags().StringVar(&f.output, outputFlagName, "", outputFlagUsage)
+	cmd.MarkFlagsMutuallyExclusive(localOutputFlagName, outputFlagName)
+	cmd.Flags().StringVar(&f.identifierCachePath, identifierCacheFlagName, "", identifierCacheFlagUsage)
+	cmd.Flags().StringVar(&f.recordDir, recordFlagName, "", recordFlagUsage)
+	cmd.Flags().StringVar(&f.deadLetterDir, deadLetterFlagName, "", deadLetterFlagUsage)
+	cmd.Flags().StringVar(&f.replayDir, replayDirFlagName, "", replayDirFlagUsage)
 }
 
 // toOptions builds an options instance from the parsed flags and CLI arguments.
@@ -53,21 +88,41 @@ func (f *flags) toOptions(cmd *cobra.Command, args []string) (*options, error) {
 		return nil, err
 	}
 
-	var destination destination.Sender
-	if f.localOutput {
-		destination = writer.NewDestination(cmd.OutOrStdout())
-	} else {
-		var err error
-		destination, err = catalog.NewDestination()
-		if err != nil {
-			return nil, err
-		}
+	destination, err := outputDestination(cmd, f.localOutput, f.output)
+	if err != nil {
+		return nil, err
 	}
 
 	return &options{
-		integrationName: strings.ToLower(integrationName),
-		mappingPaths:    mappingPaths,
-		destination:     destination,
-		sourceGetter:    sourceFromIntegrationName,
+		integrationName:     strings.ToLower(integrationName),
+		mappingPaths:        mappingPaths,
+		watchedMappingPaths: f.mappingPaths,
+		identifierCachePath: f.identifierCachePath,
+		recordDir:           f.recordDir,
+		deadLetterDir:       f.deadLetterDir,
+		destination:         destination,
+		sourceGetter: func(integrationName string) (any, error) {
+			if integrationName == replaySource {
+				return recording.NewSource(f.replayDir)
+			}
+			return sourceFromIntegrationName(integrationName)
+		},
 	}, nil
 }
+
+// outputDestination returns the destination selected by the local output and output flags,
+// falling back to the catalog when none of them is set.
+func outputDestination(cmd *cobra.Command, localOutput bool, output string) (destination.Sender, error) {
+	switch {
+	case localOutput:
+		return writer.NewDestination(cmd.OutOrStdout()), nil
+	case output != "":
+		path, found := strings.CutPrefix(output, fileOutputPrefix)
+		if !found || path == "" {
+			return nil, fmt.Errorf("%w: %q, only %s<path> is supported", errInvalidOutput, output, fileOutputPrefix)
+		}
+		return file.NewDestination(path)
+	default:
+		return catalog.NewDestination()
+	}
+}
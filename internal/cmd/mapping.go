@@ -0,0 +1,399 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/mia-platform/ibdm/internal/destination"
+	"github.com/mia-platform/ibdm/internal/destination/catalog"
+	"github.com/mia-platform/ibdm/internal/destination/writer"
+	"github.com/mia-platform/ibdm/internal/identifiercache"
+	"github.com/mia-platform/ibdm/internal/mapper"
+	"github.com/mia-platform/ibdm/internal/migration"
+	"github.com/mia-platform/ibdm/internal/pipeline"
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+const (
+	mappingCmdUse   = "mapping"
+	mappingCmdShort = "manage the mapping configurations"
+
+	migrateCmdUse   = "migrate"
+	migrateCmdShort = "migrate the items generated by a previous version of the mappings"
+	migrateCmdLong  = `Migrate the items generated by a previous version of the mappings.
+	Changing the identifier template of a mapping generates a new set of items and
+	leaves the old ones orphaned in the catalog. The command applies the old and the
+	new mappings to a sync's worth of source data, computes the old and new identifiers
+	of every item, and then upserts the new items and deletes the old ones.
+
+	Only the types found in both mapping sets with a different 'version' are migrated.
+	When an identifier cache is provided, the old identifiers are resolved from it and
+	it is updated with the new ones.`
+
+	migrateCmdExample = `# Show the changes without applying them
+	ibdm mapping migrate --from old/ --to new/ --data data.jsonl --dry-run
+
+	# Migrate the items and update the identifier cache
+	ibdm mapping migrate --from old/ --to new/ --data data.jsonl --identifier-cache identifiers.jsonl`
+
+	fromFlagName  = "from"
+	fromFlagUsage = "Path to a file or directory containing the old mapping rules. Can be specified multiple times."
+	toFlagName    = "to"
+	toFlagUsage   = "Path to a file or directory containing the new mapping rules. Can be specified multiple times."
+	dataFlagName  = "data"
+	dataFlagUsage = "Path to a file containing the source data, one JSON object per line with its type and values"
+
+	dryRunFlagName  = "dry-run"
+	dryRunFlagUsage = "If set, prints the changes without applying them"
+
+	migrateCacheFlagUsage = "Path to the file where the generated identifiers are persisted"
+
+	testCmdUse   = "test"
+	testCmdShort = "apply the mappings to sample source data and print the result"
+	testCmdLong  = `Apply the mappings to sample source data and print the result.
+	Every record of the data file is mapped as the run and sync commands would do, and the
+	generated items are printed without sending them anywhere. When a template cannot be
+	rendered, the error is printed with the mapping file, document, line, and column of the
+	failing field, and the command fails once every record has been processed.
+
+	The data file uses the format written by the record and dead-letter flags, so the data
+	that failed during a sync can be used to test a fixed mapping.`
+
+	testCmdExample = `# Test the mappings against the data that could not be mapped during a sync
+	ibdm mapping test -f mappings/ --data dead-letter/gitlab-20240601T120000.000Z.ndjson`
+)
+
+var (
+	errMissingMigrateFlags = errors.New("the from, to, and data flags are required")
+	errMissingTestFlags    = errors.New("the mapping-file and data flags are required")
+	errMappingTestFailed   = errors.New("mapping test failed")
+)
+
+// migrateFlags collects the CLI options of the mapping migrate command.
+type migrateFlags struct {
+	fromPaths           []string
+	toPaths             []string
+	dataPath            string
+	identifierCachePath string
+	dryRun              bool
+	localOutput         bool
+}
+
+// migrateOptions configures a mapping migration.
+type migrateOptions struct {
+	fromPaths           []string
+	toPaths             []string
+	dataPath            string
+	identifierCachePath string
+	dryRun              bool
+	out                 io.Writer
+	destination         func() (destination.Sender, error)
+}
+
+// testFlags collects the CLI options of the mapping test command.
+type testFlags struct {
+	mappingPaths []string
+	dataPath     string
+}
+
+// testOptions configures a mapping test.
+type testOptions struct {
+	mappingPaths []string
+	dataPath     string
+	out          io.Writer
+}
+
+// MappingCmd returns the Cobra command grouping the mapping management commands.
+func MappingCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   mappingCmdUse,
+		Short: heredoc.Doc(mappingCmdShort),
+
+		SilenceErrors: true,
+		SilenceUsage:  true,
+
+		ValidArgsFunction: cobra.NoFileCompletions,
+	}
+
+	cmd.AddCommand(migrateCmd())
+	cmd.AddCommand(testCmd())
+	return cmd
+}
+
+// migrateCmd returns the Cobra command that migrates items between two mapping versions.
+func migrateCmd() *cobra.Command {
+	flags := &migrateFlags{}
+	cmd := &cobra.Command{
+		Use:     migrateCmdUse,
+		Short:   heredoc.Doc(migrateCmdShort),
+		Long:    heredoc.Doc(migrateCmdLong),
+		Example: heredoc.Doc(migrateCmdExample),
+
+		SilenceErrors: true,
+		SilenceUsage:  true,
+
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			opts, err := flags.toOptions(cmd)
+			if err != nil {
+				return handleError(cmd, err)
+			}
+
+			if err := opts.execute(cmd.Context()); err != nil {
+				return handleError(cmd, err)
+			}
+
+			return nil
+		},
+	}
+
+	flags.addFlags(cmd)
+	return cmd
+}
+
+// addFlags registers the CLI flags on cmd.
+func (f *migrateFlags) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&f.fromPaths, fromFlagName, nil, fromFlagUsage)
+	cmd.Flags().StringArrayVar(&f.toPaths, toFlagName, nil, toFlagUsage)
+	cmd.Flags().StringVar(&f.dataPath, dataFlagName, "", dataFlagUsage)
+	cmd.Flags().StringVar(&f.identifierCachePath, identifierCacheFlagName, "", migrateCacheFlagUsage)
+	cmd.Flags().BoolVar(&f.dryRun, dryRunFlagName, false, dryRunFlagUsage)
+	cmd.Flags().BoolVar(&f.localOutput, localOutputFlagName, defaultLocalOutput, localOutputFlagUsage)
+}
+
+// toOptions builds a migrateOptions instance from the parsed flags.
+func (f *migrateFlags) toOptions(cmd *cobra.Command) (*migrateOptions, error) {
+	if len(f.fromPaths) == 0 || len(f.toPaths) == 0 || f.dataPath == "" {
+		return nil, errMissingMigrateFlags
+	}
+
+	fromPaths, err := collectPaths(f.fromPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	toPaths, err := collectPaths(f.toPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	out := cmd.OutOrStdout()
+	return &migrateOptions{
+		fromPaths:           fromPaths,
+		toPaths:             toPaths,
+		dataPath:            f.dataPath,
+		identifierCachePath: f.identifierCachePath,
+		dryRun:              f.dryRun,
+		out:                 out,
+		destination: func() (destination.Sender, error) {
+			if f.localOutput {
+				return writer.NewDestination(out), nil
+			}
+			return catalog.NewDestination()
+		},
+	}, nil
+}
+
+// execute computes the migration and applies it, or prints it when running in dry-run mode.
+func (o *migrateOptions) execute(ctx context.Context) error {
+	fromMappers, err := loadMappers(o.fromPaths, false)
+	if err != nil {
+		return err
+	}
+
+	toMappers, err := loadMappers(o.toPaths, false)
+	if err != nil {
+		return err
+	}
+
+	dataFile, err := os.Open(o.dataPath)
+	if err != nil {
+		return fmt.Errorf("data file %q: %w", o.dataPath, unwrappedError(err))
+	}
+	defer dataFile.Close()
+
+	records, err := migration.ReadRecords(dataFile)
+	if err != nil {
+		return err
+	}
+
+	var cache identifiercache.Cache
+	if o.identifierCachePath != "" {
+		if cache, err = identifiercache.NewFileCache(o.identifierCachePath); err != nil {
+			return err
+		}
+		defer cache.Close()
+	}
+
+	migrator := migration.New(fromMappers, toMappers, cache)
+	changes := migrator.Plan(ctx, records)
+	if o.dryRun {
+		return migration.WriteDiff(o.out, changes)
+	}
+
+	destination, err := o.destination()
+	if err != nil {
+		return err
+	}
+
+	return migrator.Apply(ctx, destination, changes)
+}
+
+// testCmd returns the Cobra command that applies the mappings to sample source data.
+func testCmd() *cobra.Command {
+	flags := &testFlags{}
+	cmd := &cobra.Command{
+		Use:     testCmdUse,
+		Short:   heredoc.Doc(testCmdShort),
+		Long:    heredoc.Doc(testCmdLong),
+		Example: heredoc.Doc(testCmdExample),
+
+		SilenceErrors: true,
+		SilenceUsage:  true,
+
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			opts, err := flags.toOptions(cmd)
+			if err != nil {
+				return handleError(cmd, err)
+			}
+
+			if err := opts.execute(cmd.Context()); err != nil {
+				return handleError(cmd, err)
+			}
+
+			return nil
+		},
+	}
+
+	flags.addFlags(cmd)
+	return cmd
+}
+
+// addFlags registers the CLI flags on cmd.
+func (f *testFlags) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVarP(&f.mappingPaths, mappingPathFlagName, mappingPathFlagShort, nil, mappingPathFlagUsage)
+	cmd.Flags().StringVar(&f.dataPath, dataFlagName, "", dataFlagUsage)
+}
+
+// toOptions builds a testOptions instance from the parsed flags.
+func (f *testFlags) toOptions(cmd *cobra.Command) (*testOptions, error) {
+	if len(f.mappingPaths) == 0 || f.dataPath == "" {
+		return nil, errMissingTestFlags
+	}
+
+	mappingPaths, err := collectPaths(f.mappingPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	return &testOptions{
+		mappingPaths: mappingPaths,
+		dataPath:     f.dataPath,
+		out:          cmd.OutOrStdout(),
+	}, nil
+}
+
+// execute maps every record of the data file and prints the generated items or the mapping errors.
+func (o *testOptions) execute(ctx context.Context) error {
+	mappers, err := loadMappers(o.mappingPaths, false)
+	if err != nil {
+		return err
+	}
+
+	dataFile, err := os.Open(o.dataPath)
+	if err != nil {
+		return fmt.Errorf("data file %q: %w", o.dataPath, unwrappedError(err))
+	}
+	defer dataFile.Close()
+
+	records, err := migration.ReadRecords(dataFile)
+	if err != nil {
+		return err
+	}
+
+	output := writer.NewDestination(o.out)
+	failed := 0
+	for index, data := range records {
+		dataMapper, found := mappers[data.Type]
+		if !found {
+			fmt.Fprintf(o.out, "record %d: type %q is not mapped, skipped\n\n", index+1, data.Type)
+			continue
+		}
+
+		if err := testRecord(ctx, output, data, dataMapper); err != nil {
+			var mappingErr *mapper.MappingError
+			if !errors.As(err, &mappingErr) {
+				return err
+			}
+
+			failed++
+			fmt.Fprintf(o.out, "record %d: %s\n\n", index+1, mappingErr)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%w: %d of %d records cannot be mapped", errMappingTestFailed, failed, len(records))
+	}
+	return nil
+}
+
+// testRecord maps data with dataMapper and sends the generated items to output. The identifiers of
+// a delete are always rendered from its data, because no identifier cache is available.
+func testRecord(ctx context.Context, output destination.Sender, data source.Data, dataMapper pipeline.DataMapper) error {
+	dataToSend := &destination.Data{
+		APIVersion:    dataMapper.APIVersion,
+		ItemFamily:    dataMapper.ItemFamily,
+		OperationTime: data.Timestamp(),
+	}
+
+	if data.Operation == source.DataOperationDelete {
+		identifier, _, err := dataMapper.Mapper.ApplyIdentifierTemplate(data.Values)
+		if err != nil {
+			return mapper.NewMappingError(err, dataMapper.Source, data.Type, data.Values)
+		}
+
+		dataToSend.Name = identifier
+		return output.DeleteData(ctx, dataToSend)
+	}
+
+	mapped, extra, err := dataMapper.Mapper.ApplyTemplates(data.Values, mapper.ParentItemInfo{
+		APIVersion: dataMapper.APIVersion,
+		ItemFamily: dataMapper.ItemFamily,
+	})
+	if err != nil {
+		return mapper.NewMappingError(err, dataMapper.Source, data.Type, data.Values)
+	}
+
+	dataToSend.Name = mapped.Identifier
+	dataToSend.Metadata = mapped.Metadata
+	dataToSend.Data = mapped.Spec
+	if err := output.SendData(ctx, dataToSend); err != nil {
+		return err
+	}
+
+	for _, extraItem := range extra {
+		if err := output.SendData(ctx, &destination.Data{
+			APIVersion:    extraItem.APIVersion,
+			ItemFamily:    extraItem.ItemFamily,
+			Name:          extraItem.Identifier,
+			Data:          extraItem.Spec,
+			OperationTime: data.Timestamp(),
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
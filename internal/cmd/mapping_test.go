@@ -0,0 +1,125 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrateCmd(t *testing.T) {
+	t.Parallel()
+
+	migrateTestdata := filepath.Join("testdata", "migrate")
+	migrateArgs := []string{
+		"--" + fromFlagName, filepath.Join(migrateTestdata, "old.yaml"),
+		"--" + toFlagName, filepath.Join(migrateTestdata, "new.yaml"),
+		"--" + dataFlagName, filepath.Join(migrateTestdata, "data.jsonl"),
+	}
+
+	testCases := map[string]struct {
+		args                 []string
+		expectedError        error
+		expectedErrorMessage string
+		expectedOutput       string
+	}{
+		"missing flags return error": {
+			args:                 []string{"--" + fromFlagName, filepath.Join(migrateTestdata, "old.yaml")},
+			expectedError:        errMissingMigrateFlags,
+			expectedErrorMessage: errMissingMigrateFlags.Error() + "\n",
+		},
+		"dry run prints the changes": {
+			args: append([]string{"--" + dryRunFlagName}, migrateArgs...),
+			expectedOutput: "item:\n  - v1 family 1\n  + v1 family first\n" +
+				"item:\n  - v1 family 2\n  + v1 family second\n" +
+				"2 items to migrate\n",
+		},
+		"local output prints the operations": {
+			args: append([]string{"--" + localOutputFlagName}, migrateArgs...),
+			expectedOutput: "Send data:\n\tAPIVersion: v1\n\tItemFamily: family\n\tItem Name: first\n\tTimestamp: 2024-06-01T12:00:00Z\n" +
+				"\tMetadata: {}\n\n\tSpec: {\n\t\t\"name\": \"first\"\n\t}\n\n" +
+				"Delete data:\n\tAPIVersion: v1\n\tItemFamily: family\n\tItem Name: 1\n\tTimestamp: 2024-06-01T12:00:00Z\n\n" +
+				"Send data:\n\tAPIVersion: v1\n\tItemFamily: family\n\tItem Name: second\n\tTimestamp: 2024-06-01T12:00:00Z\n" +
+				"\tMetadata: {}\n\n\tSpec: {\n\t\t\"name\": \"second\"\n\t}\n\n" +
+				"Delete data:\n\tAPIVersion: v1\n\tItemFamily: family\n\tItem Name: 2\n\tTimestamp: 2024-06-01T12:00:00Z\n\n",
+		},
+	}
+
+	for testName, test := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			errBuffer := new(bytes.Buffer)
+			outBuffer := new(bytes.Buffer)
+			cmd := MappingCmd()
+			cmd.SetOut(outBuffer)
+			cmd.SetErr(errBuffer)
+			cmd.SetArgs(append([]string{migrateCmdUse}, test.args...))
+
+			err := cmd.ExecuteContext(t.Context())
+			if test.expectedError != nil {
+				assert.ErrorIs(t, err, test.expectedError)
+				assert.Equal(t, test.expectedErrorMessage, errBuffer.String())
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Empty(t, errBuffer)
+			assert.Equal(t, test.expectedOutput, outBuffer.String())
+		})
+	}
+}
+
+func TestMappingTestCmd(t *testing.T) {
+	t.Parallel()
+
+	mappingTestdata := filepath.Join("testdata", "mapping-test")
+	mappingsPath := filepath.Join(mappingTestdata, "mappings.yaml")
+	dataPath := filepath.Join(mappingTestdata, "data", "gitlab-20240601T120000.000Z.ndjson")
+
+	testCases := map[string]struct {
+		args                 []string
+		expectedError        error
+		expectedErrorMessage string
+		expectedOutput       string
+	}{
+		"missing flags return error": {
+			args:                 []string{"--" + mappingPathFlagName, mappingsPath},
+			expectedError:        errMissingTestFlags,
+			expectedErrorMessage: errMissingTestFlags.Error() + "\n",
+		},
+		"prints the items and the position of the mapping errors": {
+			args: []string{"--" + mappingPathFlagName, mappingsPath, "--" + dataFlagName, dataPath},
+			expectedOutput: "Send data:\n\tAPIVersion: v1\n\tItemFamily: repositories\n\tItem Name: 1\n\tTimestamp: 2024-06-01T12:00:00Z\n" +
+				"\tMetadata: {}\n\n\tSpec: {\n\t\t\"name\": \"first\",\n\t\t\"visibility\": \"private\"\n\t}\n\n" +
+				"record 2: mapping \"" + mappingsPath + "\" document 0 line 8 column 5 (type \"repository\"): " +
+				"spec.visibility: template: spec:3:15: executing \"spec\" at <.visibility>: map has no entry for key \"visibility\"\n\n" +
+				"record 3: type \"unmapped\" is not mapped, skipped\n\n" +
+				"Delete data:\n\tAPIVersion: v1\n\tItemFamily: repositories\n\tItem Name: 1\n\tTimestamp: 2024-06-01T12:00:03Z\n\n",
+			expectedError:        errMappingTestFailed,
+			expectedErrorMessage: "mapping test failed: 1 of 4 records cannot be mapped\n",
+		},
+	}
+
+	for testName, test := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			errBuffer := new(bytes.Buffer)
+			outBuffer := new(bytes.Buffer)
+			cmd := MappingCmd()
+			cmd.SetOut(outBuffer)
+			cmd.SetErr(errBuffer)
+			cmd.SetArgs(append([]string{testCmdUse}, test.args...))
+
+			err := cmd.ExecuteContext(t.Context())
+			assert.ErrorIs(t, err, test.expectedError)
+			assert.Equal(t, test.expectedErrorMessage, errBuffer.String())
+			assert.Equal(t, test.expectedOutput, outBuffer.String())
+		})
+	}
+}
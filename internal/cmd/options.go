@@ -6,18 +6,26 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
 	"sync"
 
 	"github.com/mia-platform/ibdm/internal/destination"
+	"github.com/mia-platform/ibdm/internal/identifiercache"
+	"github.com/mia-platform/ibdm/internal/logger"
 	"github.com/mia-platform/ibdm/internal/pipeline"
+	"github.com/mia-platform/ibdm/internal/source/recording"
 )
 
 // options configures pipelines for event streams and sync runs.
 type options struct {
-	integrationName string
-	mappingPaths    []string
-	destination     destination.Sender
-	sourceGetter    func(string) (any, error)
+	integrationName     string
+	mappingPaths        []string
+	watchedMappingPaths []string
+	identifierCachePath string
+	recordDir           string
+	deadLetterDir       string
+	destination         destination.Sender
+	sourceGetter        func(string) (any, error)
 
 	lock sync.Mutex
 }
@@ -42,10 +50,30 @@ func (o *options) executeEventStream(ctx context.Context) error {
 	}
 	defer o.lock.Unlock()
 
-	pipeline, err := o.pipeline(ctx)
+	defer closeDestination(ctx, o.destination)
+
+	pipeline, release, err := o.pipeline(ctx)
 	if err != nil {
 		return err
 	}
+	defer release()
+
+	if len(o.watchedMappingPaths) > 0 {
+		watchCtx, stopWatching := context.WithCancel(ctx)
+		defer stopWatching()
+
+		reloader := &mappingsReloader{
+			paths:           o.watchedMappingPaths,
+			updater:         pipeline,
+			delay:           mappingsReloadDelay,
+			persistentCache: o.identifierCachePath != "",
+		}
+		go func() {
+			if err := reloader.watch(watchCtx); err != nil {
+				logger.FromContext(ctx).WithName(reloadLoggerName).Error("mapping files will not be reloaded", "error", err)
+			}
+		}()
+	}
 
 	return pipeline.Start(ctx)
 }
@@ -57,25 +85,104 @@ func (o *options) executeSync(ctx context.Context) error {
 	}
 	defer o.lock.Unlock()
 
-	pipeline, err := o.pipeline(ctx)
+	defer closeDestination(ctx, o.destination)
+
+	pipeline, release, err := o.pipeline(ctx)
 	if err != nil {
 		return err
 	}
+	defer release()
 
 	return pipeline.Sync(ctx)
 }
 
+// closeDestination closes dest when it holds resources that must be released, like the files
+// written by the file destination.
+func closeDestination(ctx context.Context, dest destination.Sender) {
+	closer, ok := dest.(io.Closer)
+	if !ok {
+		return
+	}
+
+	if err := closer.Close(); err != nil {
+		logger.FromContext(ctx).Error("error closing destination", "error", err)
+	}
+}
+
 // pipeline assembles a pipeline from the configured source, mappers, and destination.
-func (o *options) pipeline(ctx context.Context) (*pipeline.Pipeline, error) {
+// The returned release function closes the identifier cache, the recorder, and the dead letter,
+// and must be called once the pipeline has finished.
+func (o *options) pipeline(ctx context.Context) (*pipeline.Pipeline, func(), error) {
 	mappers, err := loadMappers(o.mappingPaths, false)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	if err := checkDeleteModes(mappers, o.identifierCachePath != ""); err != nil {
+		return nil, nil, err
 	}
 
 	source, err := o.sourceGetter(o.integrationName)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	identifierCache, err := o.identifierCache()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pipelineOptions := []pipeline.Option{pipeline.WithIdentifierCache(identifierCache)}
+	closers := []func(){func() { identifierCache.Close() }}
+	release := func() {
+		for _, closer := range closers {
+			closer()
+		}
+	}
+	if o.recordDir != "" {
+		recorder, err := recording.NewRecorder(o.recordDir, o.integrationName)
+		if err != nil {
+			release()
+			return nil, nil, err
+		}
+
+		pipelineOptions = append(pipelineOptions, pipeline.WithRecorder(recorder))
+		closers = append(closers, func() { closeRecorder(ctx, recorder, "recorded source data") })
+	}
+
+	if o.deadLetterDir != "" {
+		deadLetter, err := recording.NewRecorder(o.deadLetterDir, o.integrationName)
+		if err != nil {
+			release()
+			return nil, nil, err
+		}
+
+		pipelineOptions = append(pipelineOptions, pipeline.WithDeadLetter(deadLetter))
+		closers = append(closers, func() { closeRecorder(ctx, deadLetter, "dead letter") })
+	}
+
+	p, err := pipeline.New(ctx, source, mappers, o.destination, pipelineOptions...)
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+
+	return p, release, nil
+}
+
+// closeRecorder closes recorder, logging the error of the recorded files described by name.
+func closeRecorder(ctx context.Context, recorder *recording.Recorder, name string) {
+	if err := recorder.Close(); err != nil {
+		logger.FromContext(ctx).Error("error closing "+name, "error", err)
+	}
+}
+
+// identifierCache opens the persisted identifier cache when a path is configured,
+// falling back to an in-memory one.
+func (o *options) identifierCache() (identifiercache.Cache, error) {
+	if o.identifierCachePath == "" {
+		return identifiercache.NewMemoryCache(), nil
 	}
 
-	return pipeline.New(ctx, source, mappers, o.destination)
+	return identifiercache.NewFileCache(o.identifierCachePath)
 }
@@ -4,6 +4,7 @@
 package cmd
 
 import (
+	"path/filepath"
 	"syscall"
 	"testing"
 
@@ -44,6 +45,16 @@ func TestExecuteEventStream(t *testing.T) {
 			},
 			expectedError: syscall.ENOENT,
 		},
+		"tombstone mappings without identifier cache": {
+			options: &options{
+				integrationName: "fake",
+				mappingPaths: []string{
+					filepath.Join("testdata", "mappers.yaml"),
+				},
+				sourceGetter: testSourceGetter(t),
+			},
+			expectedError: errTombstoneNoCache,
+		},
 	}
 
 	for name, tc := range testCases {
@@ -88,6 +99,16 @@ func TestExecuteSync(t *testing.T) {
 			},
 			expectedError: syscall.ENOENT,
 		},
+		"tombstone mappings without identifier cache": {
+			options: &options{
+				integrationName: "fake",
+				mappingPaths: []string{
+					filepath.Join("testdata", "mappers.yaml"),
+				},
+				sourceGetter: testSourceGetter(t),
+			},
+			expectedError: errTombstoneNoCache,
+		},
 	}
 
 	for name, tc := range testCases {
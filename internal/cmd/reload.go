@@ -0,0 +1,176 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/mia-platform/ibdm/internal/logger"
+	"github.com/mia-platform/ibdm/internal/pipeline"
+)
+
+const (
+	reloadLoggerName = "ibdm:mappings"
+
+	// mappingsReloadDelay groups the burst of file events produced by a single save or by a
+	// Kubernetes ConfigMap update into one reload.
+	mappingsReloadDelay = 500 * time.Millisecond
+
+	// kubernetesDataDir is the symlink swapped by Kubernetes to update the files of a mounted
+	// ConfigMap or Secret at once, the files themselves are symlinks through it.
+	kubernetesDataDir = "..data"
+)
+
+// mappersUpdater replaces the mappers of a running pipeline.
+type mappersUpdater interface {
+	UpdateMappers(ctx context.Context, mappers map[string]pipeline.DataMapper) error
+}
+
+// mappingsReloader reloads the mapping files of a running pipeline.
+type mappingsReloader struct {
+	// paths holds the mapping paths as received from the flags, directories are walked again
+	// on every reload to pick up new files.
+	paths   []string
+	updater mappersUpdater
+	delay   time.Duration
+	// persistentCache reports whether the pipeline uses a persistent identifier cache, which the
+	// mappings with the tombstone delete mode require.
+	persistentCache bool
+}
+
+// watch reloads the mappings every time the files under the watched paths change or the process
+// receives SIGHUP, until ctx is cancelled.
+func (r *mappingsReloader) watch(ctx context.Context) error {
+	log := logger.FromContext(ctx).WithName(reloadLoggerName)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	for _, dir := range watchedDirs(r.paths) {
+		if err := watcher.Add(dir); err != nil {
+			return err
+		}
+		log.Debug("watching mappings", "path", dir)
+	}
+
+	isMapping := mappingEventFilter(r.paths)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	timer := time.NewTimer(r.delay)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-signals:
+			log.Info("received SIGHUP, reloading mappings")
+			r.reload(ctx)
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op == fsnotify.Chmod || !isMapping(event.Name) {
+				continue
+			}
+			log.Trace("mappings changed", "path", event.Name, "operation", event.Op.String())
+			timer.Reset(r.delay)
+		case <-timer.C:
+			log.Info("mapping files changed, reloading mappings")
+			r.reload(ctx)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Error("error watching mapping files", "error", err)
+		}
+	}
+}
+
+// reload validates the mappings found in the configured paths and hands them to the pipeline.
+// Invalid mappings are reported and discarded, so the pipeline keeps using the current ones.
+func (r *mappingsReloader) reload(ctx context.Context) {
+	log := logger.FromContext(ctx).WithName(reloadLoggerName)
+
+	paths, err := collectPaths(r.paths)
+	if err != nil {
+		log.Error("mappings not reloaded, keeping the current ones", "error", err)
+		return
+	}
+
+	mappers, err := loadMappers(paths, false)
+	if err == nil {
+		err = checkDeleteModes(mappers, r.persistentCache)
+	}
+	if err != nil {
+		log.Error("mappings not reloaded, keeping the current ones", "error", err)
+		return
+	}
+
+	if err := r.updater.UpdateMappers(ctx, mappers); err != nil {
+		log.Error("error updating the mappings", "error", err)
+	}
+}
+
+// watchedDirs returns the directories to watch for paths. Files are watched through their parent
+// directory, because editors and Kubernetes replace them instead of writing them in place.
+func watchedDirs(paths []string) []string {
+	dirs := make([]string, 0, len(paths))
+	seen := make(map[string]struct{}, len(paths))
+	for _, path := range paths {
+		dir := filepath.Clean(path)
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			dir = filepath.Dir(dir)
+		}
+
+		if _, found := seen[dir]; found {
+			continue
+		}
+		seen[dir] = struct{}{}
+		dirs = append(dirs, dir)
+	}
+
+	return dirs
+}
+
+// mappingEventFilter returns a function reporting whether an event on the file name, inside one of
+// the watched directories, can change the mappings found in paths. Every file of a directory path
+// is a mapping file, while the other files next to a file path are ignored.
+func mappingEventFilter(paths []string) func(name string) bool {
+	dirs := make(map[string]struct{}, len(paths))
+	files := make(map[string]struct{}, len(paths))
+	for _, path := range paths {
+		path = filepath.Clean(path)
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			dirs[path] = struct{}{}
+			continue
+		}
+
+		files[path] = struct{}{}
+		files[filepath.Join(filepath.Dir(path), kubernetesDataDir)] = struct{}{}
+	}
+
+	return func(name string) bool {
+		name = filepath.Clean(name)
+		if _, found := files[name]; found {
+			return true
+		}
+
+		_, found := dirs[filepath.Dir(name)]
+		return found
+	}
+}
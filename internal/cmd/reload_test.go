@@ -0,0 +1,213 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package cmd
+
+import (
+	"context"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mia-platform/ibdm/internal/pipeline"
+)
+
+const (
+	reloadFirstMapping = `type: first
+apiVersion: v1
+itemFamily: family
+mappings:
+  identifier: "{{ .id }}"
+  spec:
+    name: "{{ .name }}"
+`
+	reloadSecondMapping = `type: second
+apiVersion: v1
+itemFamily: family
+mappings:
+  identifier: "{{ .id }}"
+  spec:
+    name: "{{ .name | upper }}"
+`
+	reloadInvalidMapping = `type: second
+apiVersion: v1
+itemFamily: family
+mappings:
+  identifier: "{{ .id "
+`
+)
+
+// fakeMappersUpdater records the types of every set of mappers it receives.
+type fakeMappersUpdater struct {
+	updates chan []string
+}
+
+func (u *fakeMappersUpdater) UpdateMappers(_ context.Context, mappers map[string]pipeline.DataMapper) error {
+	u.updates <- slices.Sorted(maps.Keys(mappers))
+	return nil
+}
+
+func TestMappingsReloaderWatch(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "first.yaml"), []byte(reloadFirstMapping), 0o600))
+
+	updater := &fakeMappersUpdater{updates: make(chan []string, 10)}
+	reloader := &mappingsReloader{paths: []string{dir}, updater: updater, delay: 50 * time.Millisecond}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		assert.NoError(t, reloader.watch(ctx))
+	})
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	waitForUpdate := func(t *testing.T) []string {
+		t.Helper()
+		select {
+		case types := <-updater.updates:
+			return types
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "timeout waiting for mappings reload")
+			return nil
+		}
+	}
+
+	// wait for the watcher to be registered before changing the files
+	require.Eventually(t, func() bool {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "first.yaml"), []byte(reloadFirstMapping), 0o600))
+		select {
+		case types := <-updater.updates:
+			assert.Equal(t, []string{"first"}, types)
+			return true
+		case <-time.After(200 * time.Millisecond):
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// new files added to a watched directory are loaded
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "second.yaml"), []byte(reloadSecondMapping), 0o600))
+	assert.Equal(t, []string{"first", "second"}, waitForUpdate(t))
+
+	// invalid mappings are discarded
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "second.yaml"), []byte(reloadInvalidMapping), 0o600))
+	select {
+	case types := <-updater.updates:
+		assert.Fail(t, "invalid mappings must not be applied", "received types %v", types)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	// removed files are dropped from the mappings
+	require.NoError(t, os.Remove(filepath.Join(dir, "second.yaml")))
+	assert.Equal(t, []string{"first"}, waitForUpdate(t))
+}
+
+func TestMappingsReloaderReload(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		files         map[string]string
+		expectedTypes []string
+	}{
+		"valid mappings are applied": {
+			files: map[string]string{
+				"first.yaml":  reloadFirstMapping,
+				"second.yaml": reloadSecondMapping,
+			},
+			expectedTypes: []string{"first", "second"},
+		},
+		"invalid mappings are discarded": {
+			files: map[string]string{
+				"first.yaml":  reloadFirstMapping,
+				"second.yaml": reloadInvalidMapping,
+			},
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			for fileName, content := range test.files {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, fileName), []byte(content), 0o600))
+			}
+
+			updater := &fakeMappersUpdater{updates: make(chan []string, 1)}
+			reloader := &mappingsReloader{paths: []string{dir}, updater: updater}
+			reloader.reload(t.Context())
+
+			if test.expectedTypes == nil {
+				assert.Empty(t, updater.updates)
+				return
+			}
+
+			require.Len(t, updater.updates, 1)
+			assert.Equal(t, test.expectedTypes, <-updater.updates)
+		})
+	}
+}
+
+func TestWatchedDirs(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "mapping.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(reloadFirstMapping), 0o600))
+
+	assert.Equal(t, []string{dir}, watchedDirs([]string{dir, file, dir + "/"}))
+	assert.Equal(t, []string{"testdata"}, watchedDirs([]string{filepath.Join("testdata", "missing.yaml")}))
+}
+
+func TestMappingEventFilter(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	mappingsDir := filepath.Join(dir, "mappings")
+	require.NoError(t, os.Mkdir(mappingsDir, 0o700))
+	file := filepath.Join(dir, "mapping.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(reloadFirstMapping), 0o600))
+
+	isMapping := mappingEventFilter([]string{mappingsDir + "/", file})
+	testCases := map[string]struct {
+		name     string
+		expected bool
+	}{
+		"file of a watched directory": {
+			name:     filepath.Join(mappingsDir, "new.yaml"),
+			expected: true,
+		},
+		"watched file": {
+			name:     file,
+			expected: true,
+		},
+		"kubernetes data symlink next to a watched file": {
+			name:     filepath.Join(dir, kubernetesDataDir),
+			expected: true,
+		},
+		"sibling of a watched file": {
+			name: filepath.Join(dir, "unrelated.log"),
+		},
+		"file of a nested directory": {
+			name: filepath.Join(mappingsDir, "nested", "mapping.yaml"),
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, test.expected, isMapping(test.name))
+		})
+	}
+}
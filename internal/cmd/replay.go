@@ -0,0 +1,206 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package cmd
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/mia-platform/ibdm/internal/destination"
+	"github.com/mia-platform/ibdm/internal/replay"
+)
+
+const (
+	replayCmdUse   = "replay file..."
+	replayCmdShort = "send recorded outputs to the configured destination"
+	replayCmdLong  = `Send recorded outputs to the configured destination.
+	The items are sent to the catalog, unless the local-output or output flag selects
+	another destination like for the run and sync commands.
+	The recorded files contain one item per line in the NDJSON format written by the
+	file output, and are read in the given order; files ending in .gz are decompressed.
+	Lines that cannot be sent are reported at the end without stopping the replay.
+
+	The report ends with the offset of the last line read, that can be passed to the
+	offset flag to resume an interrupted replay from the following line.`
+
+	replayCmdExample = `# Backfill the catalog with the recorded items
+	ibdm replay output-20240601T120000.000Z.ndjson.gz output.ndjson
+
+	# Resume a replay, sending only the repositories at most 10 per second
+	ibdm replay output.ndjson --offset 1500 --item-family repositories --rate 10`
+
+	offsetFlagName      = "offset"
+	offsetFlagUsage     = "Number of lines to skip, counted across all the files, to resume a previous replay"
+	rateFlagName        = "rate"
+	rateFlagUsage       = "Maximum number of items sent per second, 0 to disable the limit"
+	itemFamilyFlagName  = "item-family"
+	itemFamilyFlagUsage = "If set, replays only the items of the given item family. Can be specified multiple times."
+	operationFlagName   = "operation"
+	operationFlagUsage  = "If set, replays only the items with the given operation, upsert or delete. Can be specified multiple times."
+
+	gzipExtension = ".gz"
+)
+
+var (
+	errMissingReplayFiles = errors.New("at least one recorded file is required")
+	errReplayFailures     = errors.New("some items were not replayed")
+)
+
+// replayFlags collects the CLI options of the replay command.
+type replayFlags struct {
+	offset       int
+	rate         float64
+	itemFamilies []string
+	operations   []string
+	localOutput  bool
+	output       string
+}
+
+// replayOptions configures a replay of recorded outputs.
+type replayOptions struct {
+	paths       []string
+	out         io.Writer
+	destination destination.Sender
+	options     []replay.Option
+}
+
+// ReplayCmd returns the Cobra command that sends recorded outputs to a destination.
+func ReplayCmd() *cobra.Command {
+	flags := &replayFlags{}
+	cmd := &cobra.Command{
+		Use:     replayCmdUse,
+		Short:   heredoc.Doc(replayCmdShort),
+		Long:    heredoc.Doc(replayCmdLong),
+		Example: heredoc.Doc(replayCmdExample),
+
+		SilenceErrors: true,
+		SilenceUsage:  true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := flags.toOptions(cmd, args)
+			if err != nil {
+				return handleError(cmd, err)
+			}
+
+			if err := opts.execute(cmd.Context()); err != nil {
+				return handleError(cmd, err)
+			}
+
+			return nil
+		},
+	}
+
+	flags.addFlags(cmd)
+	return cmd
+}
+
+// addFlags registers the CLI flags on cmd.
+func (f *replayFlags) addFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&f.offset, offsetFlagName, 0, offsetFlagUsage)
+	cmd.Flags().Float64Var(&f.rate, rateFlagName, 0, rateFlagUsage)
+	cmd.Flags().StringArrayVar(&f.itemFamilies, itemFamilyFlagName, nil, itemFamilyFlagUsage)
+	cmd.Flags().StringArrayVar(&f.operations, operationFlagName, nil, operationFlagUsage)
+	cmd.Flags().BoolVar(&f.localOutput, localOutputFlagName, defaultLocalOutput, localOutputFlagUsage)
+	cmd.Flags().StringVar(&f.output, outputFlagName, "", outputFlagUsage)
+	cmd.MarkFlagsMutuallyExclusive(localOutputFlagName, outputFlagName)
+}
+
+// toOptions builds a replayOptions instance from the parsed flags and the recorded files.
+func (f *replayFlags) toOptions(cmd *cobra.Command, args []string) (*replayOptions, error) {
+	if len(args) == 0 {
+		return nil, errMissingReplayFiles
+	}
+
+	destination, err := outputDestination(cmd, f.localOutput, f.output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &replayOptions{
+		paths:       args,
+		out:         cmd.OutOrStdout(),
+		destination: destination,
+		options: []replay.Option{
+			replay.WithOffset(f.offset),
+			replay.WithRateLimit(f.rate),
+			replay.WithItemFamilies(f.itemFamilies...),
+			replay.WithOperations(f.operations...),
+		},
+	}, nil
+}
+
+// execute replays the recorded files and prints the report, even when the replay is interrupted.
+func (o *replayOptions) execute(ctx context.Context) error {
+	defer closeDestination(ctx, o.destination)
+
+	replayer, err := replay.New(o.destination, o.options...)
+	if err != nil {
+		return err
+	}
+
+	inputs := make([]io.Reader, 0, len(o.paths))
+	for _, path := range o.paths {
+		input, err := openRecordedFile(path)
+		if err != nil {
+			return err
+		}
+		defer input.Close()
+		inputs = append(inputs, input)
+	}
+
+	report, replayErr := replayer.Replay(ctx, inputs...)
+	if err := report.Write(o.out); err != nil {
+		return err
+	}
+
+	if replayErr != nil {
+		return replayErr
+	}
+
+	if len(report.Failures) > 0 {
+		return fmt.Errorf("%w: %d failed", errReplayFailures, len(report.Failures))
+	}
+
+	return nil
+}
+
+// openRecordedFile opens the recorded file at path, decompressing it when it ends in .gz.
+func openRecordedFile(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("recorded file %q: %w", path, unwrappedError(err))
+	}
+
+	if filepath.Ext(path) != gzipExtension {
+		return file, nil
+	}
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("recorded file %q: %w", path, err)
+	}
+
+	return &gzipFile{Reader: reader, file: file}, nil
+}
+
+// gzipFile closes both the decompressing reader and the underlying file.
+type gzipFile struct {
+	*gzip.Reader
+
+	file *os.File
+}
+
+// Close implements io.Closer.
+func (f *gzipFile) Close() error {
+	return errors.Join(f.Reader.Close(), f.file.Close())
+}
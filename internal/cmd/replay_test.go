@@ -0,0 +1,114 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplayCmd(t *testing.T) {
+	t.Parallel()
+
+	recordedFile := filepath.Join("testdata", "replay", "output.ndjson")
+	recorded, err := os.ReadFile(recordedFile)
+	require.NoError(t, err)
+
+	compressedFile := filepath.Join(t.TempDir(), "output.ndjson.gz")
+	compressed := new(bytes.Buffer)
+	gzipWriter := gzip.NewWriter(compressed)
+	_, err = gzipWriter.Write(recorded)
+	require.NoError(t, err)
+	require.NoError(t, gzipWriter.Close())
+	require.NoError(t, os.WriteFile(compressedFile, compressed.Bytes(), 0o600))
+
+	invalidFile := filepath.Join(t.TempDir(), "invalid.ndjson")
+	require.NoError(t, os.WriteFile(invalidFile, []byte("{\n"), 0o600))
+
+	testCases := map[string]struct {
+		args                 []string
+		expectedError        error
+		expectedErrorMessage string
+		expectedOutput       string
+	}{
+		"missing files return error": {
+			args:                 []string{"--" + localOutputFlagName},
+			expectedError:        errMissingReplayFiles,
+			expectedErrorMessage: errMissingReplayFiles.Error() + "\n",
+		},
+		"missing file return error": {
+			args:                 []string{"--" + localOutputFlagName, filepath.Join("testdata", "replay", "missing.ndjson")},
+			expectedError:        os.ErrNotExist,
+			expectedErrorMessage: "recorded file \"testdata/replay/missing.ndjson\": no such file or directory\n",
+		},
+		"invalid operation filter return error": {
+			args:                 []string{"--" + localOutputFlagName, "--" + operationFlagName, "patch", recordedFile},
+			expectedErrorMessage: "replay: invalid operation: \"patch\"\n",
+		},
+		"filtered items are replayed from the offset": {
+			args: []string{"--" + localOutputFlagName, "--" + offsetFlagName, "3", "--" + itemFamilyFlagName, "repositories", recordedFile, compressedFile},
+			expectedOutput: "Send data:\n\tAPIVersion: v1\n\tItemFamily: repositories\n\tItem Name: repo-1\n\tTimestamp: 2024-06-01T12:00:00Z\n" +
+				"\tMetadata: null\n\n\tSpec: {\n\t\t\"name\": \"repo-1\"\n\t}\n\n" +
+				"Delete data:\n\tAPIVersion: v1\n\tItemFamily: repositories\n\tItem Name: repo-2\n\tTimestamp: 2024-06-01T12:00:02Z\n\n" +
+				"1 upserted, 1 deleted, 1 skipped, 0 failed, offset 6\n",
+		},
+		"failed lines are reported": {
+			args:                 []string{"--" + localOutputFlagName, "--" + operationFlagName, "delete", invalidFile, recordedFile},
+			expectedError:        errReplayFailures,
+			expectedErrorMessage: "some items were not replayed: 1 failed\n",
+			expectedOutput: "Delete data:\n\tAPIVersion: v1\n\tItemFamily: repositories\n\tItem Name: repo-2\n\tTimestamp: 2024-06-01T12:00:02Z\n\n" +
+				"line 1: unexpected end of JSON input\n" +
+				"0 upserted, 1 deleted, 2 skipped, 1 failed, offset 4\n",
+		},
+	}
+
+	for testName, test := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			errBuffer := new(bytes.Buffer)
+			outBuffer := new(bytes.Buffer)
+			cmd := ReplayCmd()
+			cmd.SetOut(outBuffer)
+			cmd.SetErr(errBuffer)
+			cmd.SetArgs(test.args)
+
+			err := cmd.ExecuteContext(t.Context())
+			if test.expectedErrorMessage != "" {
+				assert.Error(t, err)
+				if test.expectedError != nil {
+					assert.ErrorIs(t, err, test.expectedError)
+				}
+				assert.Equal(t, test.expectedErrorMessage, errBuffer.String())
+				assert.Equal(t, test.expectedOutput, outBuffer.String())
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Empty(t, errBuffer)
+			assert.Equal(t, test.expectedOutput, outBuffer.String())
+		})
+	}
+
+	t.Run("replay to a file output", func(t *testing.T) {
+		t.Parallel()
+
+		outputFile := filepath.Join(t.TempDir(), "replayed.ndjson")
+		cmd := ReplayCmd()
+		cmd.SetOut(new(bytes.Buffer))
+		cmd.SetErr(new(bytes.Buffer))
+		cmd.SetArgs([]string{"--" + outputFlagName, fileOutputPrefix + outputFile, compressedFile})
+
+		require.NoError(t, cmd.ExecuteContext(t.Context()))
+		replayed, err := os.ReadFile(outputFile)
+		require.NoError(t, err)
+		assert.Equal(t, string(recorded), string(replayed))
+	})
+}
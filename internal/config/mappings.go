@@ -4,6 +4,7 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -20,10 +21,16 @@ const (
 	// DeletePolicyNone indicates that related items should not be deleted when the source item is deleted.
 	DeletePolicyNone = "none"
 
+	// DeleteModeDelete indicates that items are deleted from the destination when the source item is deleted.
+	DeleteModeDelete = "delete"
+	// DeleteModeTombstone indicates that items are marked as deleted instead of being removed from the destination.
+	DeleteModeTombstone = "tombstone"
+
 	// ExtraRelationshipFamily indicates that the mapping is for relationships between items.
 	ExtraRelationshipFamily = "relationships"
 
 	APIVersionField   = "apiVersion"
+	DeleteModeField   = "deleteMode"
 	DeletePolicyField = "deletePolicy"
 	IdentifierField   = "identifier"
 	ItemFamilyField   = "itemFamily"
@@ -47,15 +54,26 @@ type MappingConfig struct {
 	APIVersion string         `json:"apiVersion" yaml:"apiVersion"`
 	ItemFamily string         `json:"itemFamily" yaml:"itemFamily"`
 	Syncable   bool           `json:"syncable" yaml:"syncable"`
-	Mappings   Mappings       `json:"mappings" yaml:"mappings"`
+	// Version of the mapping, it must be changed every time the identifier templates are
+	// changed to allow the migration of the already generated items.
+	Version int `json:"version,omitempty" yaml:"version,omitempty"`
+	// DeleteMode sets how delete operations are propagated, it defaults to DeleteModeDelete.
+	DeleteMode string   `json:"deleteMode,omitempty" yaml:"deleteMode,omitempty"`
+	Mappings   Mappings `json:"mappings" yaml:"mappings"`
+
+	// Source is the location of the configuration, used to report mapping errors.
+	Source Source `json:"-" yaml:"-"`
 }
 
 // Mappings holds the identifier and specification templates for mapping rules.
 type Mappings struct {
 	Identifier string            `json:"identifier" yaml:"identifier"`
+	SourceKey  string            `json:"sourceKey,omitempty" yaml:"sourceKey,omitempty"`
 	Metadata   MetadataMapping   `json:"metadata,omitempty" yaml:"metadata,omitempty"`
 	Spec       map[string]string `json:"spec" yaml:"spec"`
 	Extra      []Extra           `json:"extra,omitempty" yaml:"extra,omitempty"`
+	// Tombstone holds the templates of the fields set on deleted items when DeleteMode is DeleteModeTombstone.
+	Tombstone map[string]string `json:"tombstone,omitempty" yaml:"tombstone,omitempty"`
 }
 
 // MetadataMapping holds a flattened representation of metadata templates.
@@ -180,20 +198,20 @@ func (e *Extra) UnmarshalYAML(value *yaml.Node) error {
 // NewMappingConfigsFromPath parses the file or directory at path and returns any mapping
 // configurations it contains. It reports failures encountered while reading or decoding the data.
 func NewMappingConfigsFromPath(path string) ([]*MappingConfig, error) {
-	file, err := os.Open(path)
+	content, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
 
-	// Create a YAML decoder for the file.
-	decoder := yaml.NewDecoder(file)
+	// Create a YAML decoder for the file, and a second one to read the nodes positions.
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
 	decoder.KnownFields(true)
+	nodesDecoder := yaml.NewDecoder(bytes.NewReader(content))
 
 	configs := make([]*MappingConfig, 0)
 
 	// Continue parsing until the end of the file.
-	for {
+	for document := 0; ; document++ {
 		config := new(MappingConfig)
 		err := decoder.Decode(&config)
 		if err != nil {
@@ -206,11 +224,22 @@ func NewMappingConfigsFromPath(path string) ([]*MappingConfig, error) {
 			return nil, fmt.Errorf("%w %q: %w", ErrParsing, path, err)
 		}
 
+		var node yaml.Node
+		if err := nodesDecoder.Decode(&node); err != nil {
+			return nil, fmt.Errorf("%w %q: %w", ErrParsing, path, err)
+		}
+
 		// Skip empty configs.
 		if config == nil {
 			continue
 		}
 
+		config.Source = Source{
+			Path:     path,
+			Document: document,
+			fields:   mappingsFieldPositions(&node),
+		}
+
 		missingFields := []string{}
 		if config.Type == "" {
 			missingFields = append(missingFields, TypeField)
@@ -226,6 +255,10 @@ func NewMappingConfigsFromPath(path string) ([]*MappingConfig, error) {
 			return nil, fmt.Errorf("%w %q: missing required fields: %v", ErrParsing, path, strings.Join(missingFields, ", "))
 		}
 
+		if config.DeleteMode != "" && config.DeleteMode != DeleteModeDelete && config.DeleteMode != DeleteModeTombstone {
+			return nil, fmt.Errorf("%w %q: unknown value '%s' in field %s", ErrParsing, path, config.DeleteMode, DeleteModeField)
+		}
+
 		configs = append(configs, config)
 	}
 
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewMappingsFromPath(t *testing.T) {
@@ -38,6 +39,44 @@ func TestNewMappingsFromPath(t *testing.T) {
 				},
 			},
 		},
+		"valid yaml file with versioned mapping": {
+			path: filepath.Join("testdata", "versioned.yaml"),
+			expectedMappingConfigs: []*MappingConfig{
+				{
+					Type:       "versioned",
+					APIVersion: "group/v1",
+					ItemFamily: "configs",
+					Version:    2,
+					Mappings: Mappings{
+						Identifier: "{{ .path | sha256sum }}",
+						SourceKey:  "{{ .id }}",
+						Spec: map[string]string{
+							"key": "{{ .value }}",
+						},
+					},
+				},
+			},
+		},
+		"valid yaml file with tombstone delete mode": {
+			path: filepath.Join("testdata", "tombstone.yaml"),
+			expectedMappingConfigs: []*MappingConfig{
+				{
+					Type:       "tombstone",
+					APIVersion: "group/v1",
+					ItemFamily: "configs",
+					DeleteMode: DeleteModeTombstone,
+					Mappings: Mappings{
+						Identifier: "{{ .name }}",
+						Spec: map[string]string{
+							"key": "{{ .value }}",
+						},
+						Tombstone: map[string]string{
+							"deletedBy": "{{ .user }}",
+						},
+					},
+				},
+			},
+		},
 		"valid json file with one mapping": {
 			path: filepath.Join("testdata", "one.json"),
 			expectedMappingConfigs: []*MappingConfig{
@@ -106,6 +145,10 @@ func TestNewMappingsFromPath(t *testing.T) {
 			path:          filepath.Join("testdata", "missingdata.yaml"),
 			expectedError: ErrParsing,
 		},
+		"invalid delete mode return error": {
+			path:          filepath.Join("testdata", "invaliddeletemode.yaml"),
+			expectedError: ErrParsing,
+		},
 		"missing file return error": {
 			path:          filepath.Join(tempDir, "missing"),
 			expectedError: syscall.ENOENT,
@@ -263,7 +306,57 @@ func TestNewMappingsFromPath(t *testing.T) {
 			}
 
 			assert.NoError(t, err)
+			for _, mappingConfig := range mappingConfigs {
+				assert.Equal(t, test.path, mappingConfig.Source.Path)
+				mappingConfig.Source = Source{}
+			}
 			assert.Equal(t, test.expectedMappingConfigs, mappingConfigs)
 		})
 	}
 }
+
+func TestMappingConfigSource(t *testing.T) {
+	t.Parallel()
+
+	mappingConfigs, err := NewMappingConfigsFromPath(filepath.Join("testdata", "twoextra.yaml"))
+	require.NoError(t, err)
+	require.NotEmpty(t, mappingConfigs)
+
+	source := mappingConfigs[0].Source
+	assert.Equal(t, 0, source.Document)
+
+	testCases := map[string]struct {
+		field            string
+		expectedPosition Position
+		expectedFound    bool
+	}{
+		"identifier": {
+			field:            "identifier",
+			expectedPosition: Position{Line: 6, Column: 3},
+			expectedFound:    true,
+		},
+		"spec key": {
+			field:            "spec.key",
+			expectedPosition: Position{Line: 8, Column: 5},
+			expectedFound:    true,
+		},
+		"extra key": {
+			field:            "extra[1].identifier",
+			expectedPosition: Position{Line: 21, Column: 7},
+			expectedFound:    true,
+		},
+		"unknown field": {
+			field: "spec.missing",
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			position, found := source.Position(test.field)
+			assert.Equal(t, test.expectedFound, found)
+			assert.Equal(t, test.expectedPosition, position)
+		})
+	}
+}
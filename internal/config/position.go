@@ -0,0 +1,96 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package config
+
+import (
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Position is a line and column inside a mapping file, both starting from 1.
+type Position struct {
+	Line   int
+	Column int
+}
+
+// Source records where a MappingConfig has been read from.
+type Source struct {
+	// Path of the mapping file.
+	Path string
+	// Document is the index of the YAML document inside the file, starting from 0.
+	Document int
+
+	fields map[string]Position
+}
+
+// Position returns the position of the key of field inside the mapping file. Fields are named
+// after their path inside the mappings section, like "identifier", "spec.name" or
+// "extra[0].identifier".
+func (s Source) Position(field string) (Position, bool) {
+	position, found := s.fields[field]
+	return position, found
+}
+
+// mappingsFieldPositions collects the positions of the keys found in the mappings section of document.
+func mappingsFieldPositions(document *yaml.Node) map[string]Position {
+	positions := make(map[string]Position)
+	root := document
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+
+	mappings := mappingValue(root, "mappings")
+	if mappings == nil || mappings.Kind != yaml.MappingNode {
+		return positions
+	}
+
+	for index := 0; index+1 < len(mappings.Content); index += 2 {
+		key, value := mappings.Content[index], mappings.Content[index+1]
+		positions[key.Value] = nodePosition(key)
+		switch {
+		case value.Kind == yaml.MappingNode:
+			addKeyPositions(positions, key.Value, value)
+		case value.Kind == yaml.SequenceNode:
+			for itemIndex, item := range value.Content {
+				itemField := key.Value + "[" + strconv.Itoa(itemIndex) + "]"
+				positions[itemField] = nodePosition(item)
+				addKeyPositions(positions, itemField, item)
+			}
+		}
+	}
+
+	return positions
+}
+
+// addKeyPositions records the positions of the keys of node under prefix.
+func addKeyPositions(positions map[string]Position, prefix string, node *yaml.Node) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+
+	for index := 0; index+1 < len(node.Content); index += 2 {
+		key := node.Content[index]
+		positions[prefix+"."+key.Value] = nodePosition(key)
+	}
+}
+
+// mappingValue returns the value of key in the mapping node, or nil when not found.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for index := 0; index+1 < len(node.Content); index += 2 {
+		if node.Content[index].Value == key {
+			return node.Content[index+1]
+		}
+	}
+
+	return nil
+}
+
+func nodePosition(node *yaml.Node) Position {
+	return Position{Line: node.Line, Column: node.Column}
+}
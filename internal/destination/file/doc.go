@@ -0,0 +1,7 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+// Package file implements a destination that appends sent and deleted data to a file as
+// newline-delimited JSON, rotating and optionally compressing it.
+// The produced files can be diffed, archived or fed to other tools.
+package file
@@ -0,0 +1,290 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package file
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/caarlos0/env/v11"
+
+	"github.com/mia-platform/ibdm/internal/destination"
+	"github.com/mia-platform/ibdm/internal/logger"
+)
+
+const (
+	loggerName = "ibdm:destination:file"
+
+	rotatedTimeFormat = "20060102T150405.000Z"
+	gzipExtension     = ".gz"
+	bytesInMegabyte   = 1024 * 1024
+)
+
+var (
+	// ErrFileDestination wraps errors emitted by the file destination implementation.
+	ErrFileDestination = errors.New("file destination")
+
+	errClosed = errors.New("destination is closed")
+)
+
+var _ destination.Sender = &fileDestination{}
+var _ io.Closer = &fileDestination{}
+
+// config holds the environment-driven rotation settings.
+type config struct {
+	// MaxSizeMB rotates the file before it grows past the given size in megabytes, 0 disables it.
+	MaxSizeMB int64 `env:"FILE_OUTPUT_MAX_SIZE_MB"`
+	// RotationInterval rotates the file once it has been written for the given duration, 0
+	// disables it.
+	RotationInterval time.Duration `env:"FILE_OUTPUT_ROTATION_INTERVAL"`
+	// Gzip compresses every rotated file, and the last one when the destination is closed.
+	Gzip bool `env:"FILE_OUTPUT_GZIP"`
+}
+
+// fileDestination implements destination.Sender writing one JSON line for each operation.
+type fileDestination struct {
+	path             string
+	maxSize          int64
+	rotationInterval time.Duration
+	gzip             bool
+	now              func() time.Time
+
+	file     *os.File
+	size     int64
+	openedAt time.Time
+
+	lock sync.Mutex
+}
+
+// NewDestination loads the rotation settings from environment variables and returns a destination
+// appending to the file at path. The returned destination implements io.Closer and must be closed
+// to flush the last file.
+func NewDestination(path string) (destination.Sender, error) {
+	cfg, err := env.ParseAs[config]()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFileDestination, err)
+	}
+
+	if cfg.MaxSizeMB < 0 || cfg.RotationInterval < 0 {
+		return nil, fmt.Errorf("%w: FILE_OUTPUT_MAX_SIZE_MB and FILE_OUTPUT_ROTATION_INTERVAL cannot be negative", ErrFileDestination)
+	}
+
+	return newFileDestination(path, cfg.MaxSizeMB*bytesInMegabyte, cfg.RotationInterval, cfg.Gzip)
+}
+
+// newFileDestination opens the file at path and returns a destination writing to it.
+func newFileDestination(path string, maxSize int64, rotationInterval time.Duration, gzip bool) (*fileDestination, error) {
+	d := &fileDestination{
+		path:             filepath.Clean(path),
+		maxSize:          maxSize,
+		rotationInterval: rotationInterval,
+		gzip:             gzip,
+		now:              time.Now,
+	}
+
+	if err := d.open(); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// SendData implements destination.Sender.
+func (d *fileDestination) SendData(ctx context.Context, data *destination.Data) error {
+	return d.write(ctx, data)
+}
+
+// DeleteData implements destination.Sender.
+func (d *fileDestination) DeleteData(ctx context.Context, data *destination.Data) error {
+	return d.write(ctx, data)
+}
+
+// Close closes the current file, compressing it when gzip is enabled and it is not empty.
+func (d *fileDestination) Close() error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.file == nil {
+		return nil
+	}
+
+	err := d.file.Close()
+	d.file = nil
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFileDestination, err)
+	}
+
+	if d.gzip && d.size > 0 {
+		return d.archive(d.now())
+	}
+
+	return nil
+}
+
+// write appends data to the current file as a single JSON line, rotating the file first when needed.
+// A failed rotation is logged and data is still written, to the file reopened at the destination
+// path: the rotation is then retried on the next write.
+func (d *fileDestination) write(ctx context.Context, data *destination.Data) error {
+	line, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFileDestination, err)
+	}
+	line = append(line, '\n')
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.file == nil {
+		return fmt.Errorf("%w: %w", ErrFileDestination, errClosed)
+	}
+
+	if d.shouldRotate(int64(len(line))) {
+		archiveErr, err := d.rotate()
+		if err != nil {
+			return err
+		}
+		if archiveErr != nil {
+			logger.FromContext(ctx).WithName(loggerName).Error("error archiving rotated file", "path", d.path, "error", archiveErr)
+		}
+	}
+
+	written, err := d.file.Write(line)
+	d.size += int64(written)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFileDestination, err)
+	}
+
+	return nil
+}
+
+// shouldRotate reports whether the current file must be rotated before writing length bytes.
+// Empty files are never rotated, so a line bigger than the maximum size is still written.
+func (d *fileDestination) shouldRotate(length int64) bool {
+	if d.size == 0 {
+		return false
+	}
+
+	if d.maxSize > 0 && d.size+length > d.maxSize {
+		return true
+	}
+
+	return d.rotationInterval > 0 && d.now().Sub(d.openedAt) >= d.rotationInterval
+}
+
+// open opens the file at the destination path in append mode.
+func (d *fileDestination) open() error {
+	if err := os.MkdirAll(filepath.Dir(d.path), 0o755); err != nil {
+		return fmt.Errorf("%w: %w", ErrFileDestination, err)
+	}
+
+	file, err := os.OpenFile(d.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFileDestination, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("%w: %w", ErrFileDestination, err)
+	}
+
+	d.file = file
+	d.size = info.Size()
+	d.openedAt = d.now()
+	// when appending to a file written by a previous run, the rotation interval is counted from
+	// its last modification, so restarting the process does not postpone the rotation
+	if d.size > 0 && info.ModTime().Before(d.openedAt) {
+		d.openedAt = info.ModTime()
+	}
+	return nil
+}
+
+// rotate closes the current file, moves it aside and opens a new one at the destination path.
+// The file at the destination path is reopened even when it cannot be moved aside, so a transient
+// filesystem error does not stop the destination: in that case archiveErr is returned, while err
+// reports the errors that leave the destination without a file to write to.
+func (d *fileDestination) rotate() (archiveErr, err error) {
+	closeErr := d.file.Close()
+	d.file = nil
+	if closeErr != nil {
+		archiveErr = fmt.Errorf("%w: %w", ErrFileDestination, closeErr)
+	} else {
+		archiveErr = d.archive(d.now())
+	}
+
+	return archiveErr, d.open()
+}
+
+// archive renames the closed file at the destination path adding now to its name, and compresses
+// it when gzip is enabled. When a file rotated at the same time already exists, now is moved
+// forward by the smallest step of rotatedTimeFormat, so no archive is ever overwritten and the
+// names keep sorting in rotation order.
+func (d *fileDestination) archive(now time.Time) error {
+	extension := filepath.Ext(d.path)
+	rotatedPath := ""
+	for rotatedTime := now.UTC(); ; rotatedTime = rotatedTime.Add(time.Millisecond) {
+		rotatedPath = strings.TrimSuffix(d.path, extension) + "-" + rotatedTime.Format(rotatedTimeFormat) + extension
+		if !exists(rotatedPath) && !exists(rotatedPath+gzipExtension) {
+			break
+		}
+	}
+
+	if err := os.Rename(d.path, rotatedPath); err != nil {
+		return fmt.Errorf("%w: %w", ErrFileDestination, err)
+	}
+
+	if d.gzip {
+		if err := compress(rotatedPath); err != nil {
+			return fmt.Errorf("%w: %w", ErrFileDestination, err)
+		}
+	}
+
+	return nil
+}
+
+// exists reports whether a file is found at path.
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// compress replaces the file at path with its gzip compressed version.
+func compress(path string) error {
+	source, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	target, err := os.OpenFile(path+gzipExtension, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	writer := gzip.NewWriter(target)
+	if _, err := io.Copy(writer, source); err != nil {
+		target.Close()
+		return err
+	}
+
+	if err := writer.Close(); err != nil {
+		target.Close()
+		return err
+	}
+
+	if err := target.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(path)
+}
@@ -0,0 +1,313 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package file
+
+import (
+	"compress/gzip"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mia-platform/ibdm/internal/destination"
+)
+
+var (
+	testUpsert = &destination.Data{
+		APIVersion:    "v1",
+		ItemFamily:    "family",
+		Name:          "id-1",
+		Data:          map[string]any{"key": "value"},
+		OperationTime: "2020-01-01T00:00:00Z",
+	}
+	testDelete = &destination.Data{
+		APIVersion:    "v1",
+		ItemFamily:    "family",
+		Name:          "id-1",
+		OperationTime: "2020-01-01T00:00:00Z",
+	}
+)
+
+const (
+	testUpsertLine = `{"apiVersion":"v1","itemFamily":"family","name":"id-1","data":{"key":"value"},"operationTime":"2020-01-01T00:00:00Z","operation":"upsert"}` + "\n"
+	testDeleteLine = `{"apiVersion":"v1","itemFamily":"family","name":"id-1","operationTime":"2020-01-01T00:00:00Z","operation":"delete"}` + "\n"
+)
+
+// testClock returns a clock that advances by step every time it is read.
+func testClock(step time.Duration) func() time.Time {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	return func() time.Time {
+		now = now.Add(step)
+		return now
+	}
+}
+
+// newTestDestination returns a destination whose clock advances by step every time it is read.
+func newTestDestination(t *testing.T, path string, maxSize int64, rotationInterval time.Duration, gzip bool, step time.Duration) *fileDestination {
+	t.Helper()
+
+	fileDestination, err := newFileDestination(path, maxSize, rotationInterval, gzip)
+	require.NoError(t, err)
+	fileDestination.now = testClock(step)
+	fileDestination.openedAt = fileDestination.now()
+	return fileDestination
+}
+
+// readDir returns the content of every file in dir keyed by name, decompressing gzip files.
+func readDir(t *testing.T, dir string) map[string]string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	files := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		file, err := os.Open(filepath.Join(dir, entry.Name()))
+		require.NoError(t, err)
+		defer file.Close()
+
+		var reader io.Reader = file
+		if filepath.Ext(entry.Name()) == gzipExtension {
+			gzipReader, err := gzip.NewReader(file)
+			require.NoError(t, err)
+			reader = gzipReader
+		}
+
+		content, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		files[entry.Name()] = string(content)
+	}
+
+	return files
+}
+
+func TestFileDestination(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		existingContent  string
+		maxSize          int64
+		rotationInterval time.Duration
+		gzip             bool
+		operations       []*destination.Data
+		expectedFiles    map[string]string
+	}{
+		"write operations as json lines": {
+			operations: []*destination.Data{testUpsert, testDelete},
+			expectedFiles: map[string]string{
+				"output.ndjson": testUpsertLine + testDeleteLine,
+			},
+		},
+		"append to existing file": {
+			existingContent: testDeleteLine,
+			operations:      []*destination.Data{testUpsert},
+			expectedFiles: map[string]string{
+				"output.ndjson": testDeleteLine + testUpsertLine,
+			},
+		},
+		"rotate by size": {
+			maxSize:    int64(len(testUpsertLine) + len(testDeleteLine)),
+			operations: []*destination.Data{testUpsert, testDelete, testUpsert},
+			expectedFiles: map[string]string{
+				"output-20240601T120002.000Z.ndjson": testUpsertLine + testDeleteLine,
+				"output.ndjson":                      testUpsertLine,
+			},
+		},
+		"lines bigger than the max size are written to a new file": {
+			maxSize:    1,
+			operations: []*destination.Data{testUpsert, testDelete},
+			expectedFiles: map[string]string{
+				"output-20240601T120002.000Z.ndjson": testUpsertLine,
+				"output.ndjson":                      testDeleteLine,
+			},
+		},
+		"rotate by time": {
+			rotationInterval: 2 * time.Second,
+			operations:       []*destination.Data{testUpsert, testDelete, testUpsert},
+			expectedFiles: map[string]string{
+				"output-20240601T120004.000Z.ndjson": testUpsertLine + testDeleteLine,
+				"output.ndjson":                      testUpsertLine,
+			},
+		},
+		"compress rotated and closed files": {
+			maxSize:    int64(len(testUpsertLine)),
+			gzip:       true,
+			operations: []*destination.Data{testUpsert, testDelete},
+			expectedFiles: map[string]string{
+				"output-20240601T120002.000Z.ndjson.gz": testUpsertLine,
+				"output-20240601T120004.000Z.ndjson.gz": testDeleteLine,
+			},
+		},
+		"empty files are not compressed": {
+			gzip: true,
+			expectedFiles: map[string]string{
+				"output.ndjson": "",
+			},
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			path := filepath.Join(dir, "output.ndjson")
+			if test.existingContent != "" {
+				require.NoError(t, os.WriteFile(path, []byte(test.existingContent), 0o600))
+			}
+
+			fileDestination := newTestDestination(t, path, test.maxSize, test.rotationInterval, test.gzip, time.Second)
+
+			for _, data := range test.operations {
+				if data.Data == nil {
+					require.NoError(t, fileDestination.DeleteData(t.Context(), data))
+					continue
+				}
+				require.NoError(t, fileDestination.SendData(t.Context(), data))
+			}
+
+			require.NoError(t, fileDestination.Close())
+			assert.Equal(t, test.expectedFiles, readDir(t, dir))
+
+			err := fileDestination.SendData(t.Context(), testUpsert)
+			require.ErrorIs(t, err, ErrFileDestination)
+			require.ErrorIs(t, err, errClosed)
+			assert.NoError(t, fileDestination.Close())
+		})
+	}
+}
+
+func TestNewDestination(t *testing.T) {
+	t.Run("rotation settings are read from the environment", func(t *testing.T) {
+		t.Setenv("FILE_OUTPUT_MAX_SIZE_MB", "10")
+		t.Setenv("FILE_OUTPUT_ROTATION_INTERVAL", "1h")
+		t.Setenv("FILE_OUTPUT_GZIP", "true")
+
+		path := filepath.Join(t.TempDir(), "nested", "output.ndjson")
+		sender, err := NewDestination(path)
+		require.NoError(t, err)
+
+		fileDestination, ok := sender.(*fileDestination)
+		require.True(t, ok)
+		defer fileDestination.Close()
+
+		assert.Equal(t, int64(10*bytesInMegabyte), fileDestination.maxSize)
+		assert.Equal(t, time.Hour, fileDestination.rotationInterval)
+		assert.True(t, fileDestination.gzip)
+		assert.FileExists(t, path)
+	})
+
+	t.Run("invalid settings return an error", func(t *testing.T) {
+		t.Setenv("FILE_OUTPUT_ROTATION_INTERVAL", "-1h")
+
+		_, err := NewDestination(filepath.Join(t.TempDir(), "output.ndjson"))
+		assert.ErrorIs(t, err, ErrFileDestination)
+	})
+
+	t.Run("unparsable settings return an error", func(t *testing.T) {
+		t.Setenv("FILE_OUTPUT_GZIP", "maybe")
+
+		_, err := NewDestination(filepath.Join(t.TempDir(), "output.ndjson"))
+		assert.ErrorIs(t, err, ErrFileDestination)
+	})
+
+	t.Run("unwritable path return an error", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "file"), nil, 0o600))
+
+		_, err := NewDestination(filepath.Join(dir, "file", "output.ndjson"))
+		assert.ErrorIs(t, err, ErrFileDestination)
+	})
+}
+
+func TestRotatedFilesAreSortable(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	fileDestination := newTestDestination(t, filepath.Join(dir, "output.ndjson"), 1, 0, false, time.Millisecond)
+
+	for range 4 {
+		require.NoError(t, fileDestination.SendData(t.Context(), testUpsert))
+	}
+	require.NoError(t, fileDestination.Close())
+
+	names := make([]string, 0)
+	for name := range readDir(t, dir) {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	assert.Equal(t, []string{
+		"output-20240601T120000.002Z.ndjson",
+		"output-20240601T120000.004Z.ndjson",
+		"output-20240601T120000.006Z.ndjson",
+		"output.ndjson",
+	}, names)
+}
+
+func TestRotationsInTheSameTickKeepEveryArchive(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	fileDestination := newTestDestination(t, filepath.Join(dir, "output.ndjson"), 1, 0, true, 0)
+
+	require.NoError(t, fileDestination.SendData(t.Context(), testUpsert))
+	require.NoError(t, fileDestination.DeleteData(t.Context(), testDelete))
+	require.NoError(t, fileDestination.SendData(t.Context(), testUpsert))
+	require.NoError(t, fileDestination.Close())
+
+	assert.Equal(t, map[string]string{
+		"output-20240601T120000.000Z.ndjson.gz": testUpsertLine,
+		"output-20240601T120000.001Z.ndjson.gz": testDeleteLine,
+		"output-20240601T120000.002Z.ndjson.gz": testUpsertLine,
+	}, readDir(t, dir))
+}
+
+func TestFailedRotationKeepsWriting(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "output.ndjson")
+	fileDestination := newTestDestination(t, path, 1, 0, false, time.Second)
+
+	require.NoError(t, fileDestination.SendData(t.Context(), testUpsert))
+	// the file is removed from under the destination, so it cannot be moved aside on rotation
+	require.NoError(t, os.Remove(path))
+
+	require.NoError(t, fileDestination.DeleteData(t.Context(), testDelete))
+	require.NoError(t, fileDestination.SendData(t.Context(), testUpsert))
+	require.NoError(t, fileDestination.Close())
+
+	assert.Equal(t, map[string]string{
+		"output-20240601T120004.000Z.ndjson": testDeleteLine,
+		"output.ndjson":                      testUpsertLine,
+	}, readDir(t, dir))
+}
+
+func TestAppendedFileRotatesOnItsModificationTime(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "output.ndjson")
+	require.NoError(t, os.WriteFile(path, []byte(testDeleteLine), 0o600))
+	modTime := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+
+	fileDestination, err := newFileDestination(path, 0, time.Hour, false)
+	require.NoError(t, err)
+	assert.True(t, fileDestination.openedAt.Equal(modTime))
+
+	require.NoError(t, fileDestination.SendData(t.Context(), testUpsert))
+	require.NoError(t, fileDestination.Close())
+
+	files := readDir(t, dir)
+	assert.Equal(t, testUpsertLine, files["output.ndjson"])
+	delete(files, "output.ndjson")
+	assert.Equal(t, []string{testDeleteLine}, slices.Collect(maps.Values(files)), "the appended file is rotated before writing")
+}
@@ -0,0 +1,124 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package identifiercache
+
+import (
+	"maps"
+	"slices"
+	"sync"
+)
+
+// Cache stores the identifiers emitted for a source item under its source-native key.
+type Cache interface {
+	// Get returns the entry stored for key, reporting whether it was found.
+	Get(key Key) (Entry, bool)
+	// Set stores entry for key, replacing any previous value.
+	Set(key Key, entry Entry) error
+	// Delete removes the entry stored for key, if any.
+	Delete(key Key) error
+	// Close releases any resource held by the cache.
+	Close() error
+}
+
+// Key identifies a source item by its data type and its source-native key.
+type Key struct {
+	Type      string `json:"type"`
+	SourceKey string `json:"sourceKey"`
+}
+
+// Entry holds the identifiers emitted for a source item during its last upsert.
+type Entry struct {
+	APIVersion string `json:"apiVersion"`
+	ItemFamily string `json:"itemFamily"`
+	Identifier string `json:"identifier"`
+	// Metadata and Spec hold the last payload sent for the item, they are only kept for the
+	// mappings that need it to retire deleted items.
+	Metadata map[string]any `json:"metadata,omitempty"`
+	Spec     map[string]any `json:"spec,omitempty"`
+	Extras   []Extra        `json:"extras,omitempty"`
+}
+
+// Extra holds the identifier of an extra item emitted alongside its parent.
+type Extra struct {
+	APIVersion   string `json:"apiVersion"`
+	ItemFamily   string `json:"itemFamily"`
+	Identifier   string `json:"identifier"`
+	DeletePolicy string `json:"deletePolicy"`
+	// Spec holds the last payload sent for the extra item, see Entry.Spec.
+	Spec map[string]any `json:"spec,omitempty"`
+}
+
+var _ Cache = &memoryCache{}
+
+// memoryCache is a Cache implementation that only lives in memory.
+type memoryCache struct {
+	entries map[Key]Entry
+
+	lock sync.RWMutex
+}
+
+// NewMemoryCache returns a Cache that keeps its entries in memory for the process lifetime.
+func NewMemoryCache() Cache {
+	return newMemoryCache()
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{
+		entries: make(map[Key]Entry),
+	}
+}
+
+// Get implements Cache.
+func (c *memoryCache) Get(key Key) (Entry, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	entry, found := c.entries[key]
+	if !found {
+		return Entry{}, false
+	}
+
+	entry.Extras = slices.Clone(entry.Extras)
+	return entry, true
+}
+
+// Set implements Cache.
+func (c *memoryCache) Set(key Key, entry Entry) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry.Extras = slices.Clone(entry.Extras)
+	c.entries[key] = entry
+	return nil
+}
+
+// Delete implements Cache.
+func (c *memoryCache) Delete(key Key) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.entries, key)
+	return nil
+}
+
+// Close implements Cache.
+func (c *memoryCache) Close() error {
+	return nil
+}
+
+// len returns the number of stored entries.
+func (c *memoryCache) len() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return len(c.entries)
+}
+
+// snapshot returns a copy of all the stored entries.
+func (c *memoryCache) snapshot() map[Key]Entry {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return maps.Clone(c.entries)
+}
@@ -0,0 +1,46 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package identifiercache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCache(t *testing.T) {
+	t.Parallel()
+
+	cache := NewMemoryCache()
+	key := Key{Type: "type", SourceKey: "/subscriptions/id/resource"}
+	entry := Entry{
+		APIVersion: "v1",
+		ItemFamily: "family",
+		Identifier: "identifier",
+		Extras: []Extra{
+			{APIVersion: "v1", ItemFamily: "relationships", Identifier: "relationship", DeletePolicy: "cascade"},
+		},
+	}
+
+	_, found := cache.Get(key)
+	assert.False(t, found)
+
+	require.NoError(t, cache.Set(key, entry))
+	cachedEntry, found := cache.Get(key)
+	assert.True(t, found)
+	assert.Equal(t, entry, cachedEntry)
+
+	cachedEntry.Extras[0].Identifier = "changed"
+	cachedEntry, _ = cache.Get(key)
+	assert.Equal(t, "relationship", cachedEntry.Extras[0].Identifier)
+
+	_, found = cache.Get(Key{Type: "other", SourceKey: key.SourceKey})
+	assert.False(t, found)
+
+	require.NoError(t, cache.Delete(key))
+	_, found = cache.Get(key)
+	assert.False(t, found)
+	assert.NoError(t, cache.Close())
+}
@@ -0,0 +1,7 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+// Package identifiercache keeps track of the catalog identifiers generated for every source item.
+// Entries are keyed by the data type and a source-native key, so that delete events carrying only
+// a minimal payload can still be resolved to the identifiers emitted during the last upsert.
+package identifiercache
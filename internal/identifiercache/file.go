@@ -0,0 +1,259 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package identifiercache
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+const (
+	recordOperationSet    = "set"
+	recordOperationDelete = "delete"
+
+	// maxRecordSize bounds the length of a single journal line.
+	maxRecordSize = 1024 * 1024
+
+	// compactMinRecords is the journal length below which the journal is never compacted while
+	// the cache is open.
+	compactMinRecords = 1024
+	// compactRatio is the ratio between the journal records and the stored entries above which
+	// the journal is compacted while the cache is open.
+	compactRatio = 2
+)
+
+var (
+	// ErrFileCache wraps errors returned by the file backed cache.
+	ErrFileCache = errors.New("identifier cache")
+)
+
+var _ Cache = &fileCache{}
+
+// record is a single line of the cache journal.
+type record struct {
+	Operation string `json:"op"`
+	Key
+	Entry *Entry `json:"entry,omitempty"`
+}
+
+// fileCache is a Cache implementation persisted on disk as a journal of JSON lines.
+// Every mutation is appended to the journal, which is compacted each time the cache is opened and
+// whenever it grows past compactRatio times the stored entries.
+type fileCache struct {
+	*memoryCache
+
+	path string
+	file *os.File
+	// records counts the lines of the journal.
+	records int
+
+	lock sync.Mutex
+}
+
+// NewFileCache returns a Cache persisted at path. Existing entries are loaded from the file, that is
+// created if missing, and the journal is compacted before new mutations are appended to it.
+func NewFileCache(path string) (Cache, error) {
+	memoryCache := newMemoryCache()
+	if err := loadJournal(path, memoryCache); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFileCache, err)
+	}
+
+	entries := memoryCache.snapshot()
+	if err := compactJournal(path, entries); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFileCache, err)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFileCache, err)
+	}
+
+	return &fileCache{
+		memoryCache: memoryCache,
+		path:        path,
+		file:        file,
+		records:     len(entries),
+	}, nil
+}
+
+// Set implements Cache.
+func (c *fileCache) Set(key Key, entry Entry) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err := c.append(record{Operation: recordOperationSet, Key: key, Entry: &entry}); err != nil {
+		return err
+	}
+
+	_ = c.memoryCache.Set(key, entry)
+	return c.compactIfNeeded()
+}
+
+// Delete implements Cache.
+func (c *fileCache) Delete(key Key) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if _, found := c.memoryCache.Get(key); !found {
+		return nil
+	}
+
+	if err := c.append(record{Operation: recordOperationDelete, Key: key}); err != nil {
+		return err
+	}
+
+	_ = c.memoryCache.Delete(key)
+	return c.compactIfNeeded()
+}
+
+// Close implements Cache.
+func (c *fileCache) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.file == nil {
+		return nil
+	}
+
+	err := c.file.Close()
+	c.file = nil
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFileCache, err)
+	}
+	return nil
+}
+
+// append writes rec at the end of the journal. The caller must hold the cache lock.
+func (c *fileCache) append(rec record) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFileCache, err)
+	}
+
+	if c.file == nil {
+		return fmt.Errorf("%w: %w", ErrFileCache, fs.ErrClosed)
+	}
+
+	if _, err := c.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("%w: %w", ErrFileCache, err)
+	}
+	c.records++
+	return nil
+}
+
+// compactIfNeeded rewrites the journal with only the stored entries once it has grown past
+// compactRatio times their number, so that a long running cache does not grow its file without
+// bound. If the compaction fails the mutations keep being appended to the current journal.
+// The caller must hold the cache lock.
+func (c *fileCache) compactIfNeeded() error {
+	if c.records < compactMinRecords || c.records < compactRatio*c.memoryCache.len() {
+		return nil
+	}
+
+	entries := c.memoryCache.snapshot()
+	if err := compactJournal(c.path, entries); err != nil {
+		return fmt.Errorf("%w: %w", ErrFileCache, err)
+	}
+
+	// the current file descriptor still points to the replaced journal
+	c.file.Close()
+	file, err := os.OpenFile(c.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		c.file = nil
+		return fmt.Errorf("%w: %w", ErrFileCache, err)
+	}
+
+	c.file = file
+
+	c.records = len(entries)
+	return nil
+}
+
+// loadJournal replays the journal found at path into cache. A missing file is not an error.
+func loadJournal(path string, cache *memoryCache) error {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRecordSize)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var rec record
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return fmt.Errorf("%q line %d: %w", path, lineNumber, err)
+		}
+
+		switch rec.Operation {
+		case recordOperationSet:
+			if rec.Entry != nil {
+				_ = cache.Set(rec.Key, *rec.Entry)
+			}
+		case recordOperationDelete:
+			_ = cache.Delete(rec.Key)
+		default:
+			return fmt.Errorf("%q line %d: unknown operation %q", path, lineNumber, rec.Operation)
+		}
+	}
+
+	return scanner.Err()
+}
+
+// compactJournal atomically replaces the journal at path with one set record per entry.
+func compactJournal(path string, entries map[Key]Entry) error {
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	writer := bufio.NewWriter(tmpFile)
+	keys := slices.SortedFunc(maps.Keys(entries), compareKeys)
+
+	for _, key := range keys {
+		entry := entries[key]
+		line, err := json.Marshal(record{Operation: recordOperationSet, Key: key, Entry: &entry})
+		if err != nil {
+			tmpFile.Close()
+			return err
+		}
+		_, _ = writer.Write(append(line, '\n'))
+	}
+
+	if err := writer.Flush(); err != nil {
+		tmpFile.Close()
+		return err
+	}
+
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile.Name(), path)
+}
+
+// compareKeys orders keys by type first and source key after.
+func compareKeys(a, b Key) int {
+	return cmp.Or(cmp.Compare(a.Type, b.Type), cmp.Compare(a.SourceKey, b.SourceKey))
+}
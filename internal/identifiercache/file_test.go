@@ -0,0 +1,134 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package identifiercache
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileCache(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "cache.jsonl")
+	firstKey := Key{Type: "type", SourceKey: "first"}
+	secondKey := Key{Type: "type", SourceKey: "second"}
+	firstEntry := Entry{APIVersion: "v1", ItemFamily: "family", Identifier: "first-identifier"}
+	secondEntry := Entry{
+		APIVersion: "v1",
+		ItemFamily: "family",
+		Identifier: "second-identifier",
+		Extras: []Extra{
+			{APIVersion: "v1", ItemFamily: "relationships", Identifier: "relationship", DeletePolicy: "none"},
+		},
+	}
+
+	cache, err := NewFileCache(path)
+	require.NoError(t, err)
+	require.NoError(t, cache.Set(firstKey, Entry{Identifier: "old"}))
+	require.NoError(t, cache.Set(firstKey, firstEntry))
+	require.NoError(t, cache.Set(secondKey, secondEntry))
+	require.NoError(t, cache.Delete(secondKey))
+	require.NoError(t, cache.Delete(Key{Type: "missing"}))
+	require.NoError(t, cache.Set(secondKey, secondEntry))
+	require.NoError(t, cache.Close())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(string(content)), "\n"), 5)
+
+	reopened, err := NewFileCache(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { reopened.Close() })
+
+	entry, found := reopened.Get(firstKey)
+	assert.True(t, found)
+	assert.Equal(t, firstEntry, entry)
+	entry, found = reopened.Get(secondKey)
+	assert.True(t, found)
+	assert.Equal(t, secondEntry, entry)
+
+	content, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(string(content)), "\n"), 2, "journal must be compacted on open")
+}
+
+func TestFileCacheErrors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("invalid journal line", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "cache.jsonl")
+		require.NoError(t, os.WriteFile(path, []byte("not json\n"), 0o600))
+
+		cache, err := NewFileCache(path)
+		assert.Nil(t, cache)
+		assert.ErrorIs(t, err, ErrFileCache)
+	})
+
+	t.Run("unknown journal operation", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "cache.jsonl")
+		require.NoError(t, os.WriteFile(path, []byte(`{"op":"unknown","type":"a","sourceKey":"b"}`+"\n"), 0o600))
+
+		cache, err := NewFileCache(path)
+		assert.Nil(t, cache)
+		assert.ErrorContains(t, err, `unknown operation "unknown"`)
+	})
+
+	t.Run("missing parent directory", func(t *testing.T) {
+		t.Parallel()
+		cache, err := NewFileCache(filepath.Join(t.TempDir(), "missing", "cache.jsonl"))
+		assert.Nil(t, cache)
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+	})
+
+	t.Run("writing after close", func(t *testing.T) {
+		t.Parallel()
+		cache, err := NewFileCache(filepath.Join(t.TempDir(), "cache.jsonl"))
+		require.NoError(t, err)
+		require.NoError(t, cache.Close())
+		assert.ErrorIs(t, cache.Set(Key{}, Entry{}), fs.ErrClosed)
+		assert.NoError(t, cache.Close())
+	})
+}
+
+func TestFileCacheCompactionWhileOpen(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "cache.jsonl")
+	cache, err := NewFileCache(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { cache.Close() })
+
+	liveKey := Key{Type: "type", SourceKey: "live"}
+	liveEntry := Entry{APIVersion: "v1", ItemFamily: "family", Identifier: "live"}
+	require.NoError(t, cache.Set(liveKey, liveEntry))
+	for range compactMinRecords {
+		key := Key{Type: "type", SourceKey: "deleted"}
+		require.NoError(t, cache.Set(key, Entry{Identifier: "deleted"}))
+		require.NoError(t, cache.Delete(key))
+	}
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Less(t, len(strings.Split(strings.TrimSpace(string(content)), "\n")), compactMinRecords, "journal must be compacted while open")
+
+	require.NoError(t, cache.Close())
+	reopened, err := NewFileCache(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { reopened.Close() })
+
+	entry, found := reopened.Get(liveKey)
+	assert.True(t, found)
+	assert.Equal(t, liveEntry, entry)
+	_, found = reopened.Get(Key{Type: "type", SourceKey: "deleted"})
+	assert.False(t, found)
+}
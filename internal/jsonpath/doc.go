@@ -0,0 +1,7 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+// Package jsonpath evaluates JSONPath expressions, extended with the full gval expression language,
+// against decoded JSON values. Compiled expressions are cached, so the same expression evaluated
+// for many items is parsed only once.
+package jsonpath
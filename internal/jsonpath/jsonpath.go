@@ -0,0 +1,44 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package jsonpath
+
+import (
+	"context"
+
+	"github.com/PaesslerAG/gval"
+	"github.com/PaesslerAG/jsonpath"
+
+	"github.com/mia-platform/ibdm/internal/boundedcache"
+)
+
+// maxCachedExpressions bounds the number of compiled expressions kept in expressionCache, since
+// templates can build the expressions from the input data.
+const maxCachedExpressions = 256
+
+// language extends JSONPath with the full gval expression language, so filters can use
+// comparisons, arithmetic and logical operators.
+var language = gval.NewLanguage(gval.Full(), jsonpath.Language())
+
+// expressionCache keeps the compiled expressions, so each one is parsed only once for all the
+// values it is evaluated against. Once full, new expressions are parsed on every use.
+var expressionCache = boundedcache.New[string, gval.Evaluable](maxCachedExpressions)
+
+// Get evaluates the JSONPath expression against object.
+// Expressions selecting a single element return its value, while expressions using wildcards,
+// filters or recursive descent return the list of matched values.
+func Get(expression string, object any) (any, error) {
+	evaluable, err := compile(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	return evaluable(context.Background(), object)
+}
+
+// compile returns the compiled expression from the cache, parsing it on first use.
+func compile(expression string) (gval.Evaluable, error) {
+	return expressionCache.Get(expression, func(expression string) (gval.Evaluable, error) {
+		return language.NewEvaluable(expression)
+	})
+}
@@ -0,0 +1,82 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package jsonpath
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGet(t *testing.T) {
+	t.Parallel()
+
+	object := map[string]any{
+		"repository": map[string]any{
+			"name": "ibdm",
+			"topics": []any{
+				map[string]any{"name": "go", "stars": float64(10)},
+				map[string]any{"name": "catalog", "stars": float64(2)},
+			},
+		},
+	}
+
+	testCases := map[string]struct {
+		expression    string
+		expected      any
+		expectedError bool
+	}{
+		"single element": {
+			expression: "$.repository.name",
+			expected:   "ibdm",
+		},
+		"wildcard": {
+			expression: "$.repository.topics[*].name",
+			expected:   []any{"go", "catalog"},
+		},
+		"filter": {
+			expression: "$.repository.topics[?(@.stars > 5)].name",
+			expected:   []any{"go"},
+		},
+		"missing key": {
+			expression:    "$.repository.missing",
+			expectedError: true,
+		},
+		"invalid expression": {
+			expression:    "$.repository[",
+			expectedError: true,
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			value, err := Get(test.expression, object)
+			if test.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, value)
+		})
+	}
+}
+
+func TestCacheIsBounded(t *testing.T) {
+	t.Parallel()
+
+	object := map[string]any{"name": "ibdm"}
+	for index := range maxCachedExpressions + 10 {
+		_, err := Get(fmt.Sprintf("$.missing%d", index), object)
+		require.Error(t, err)
+	}
+	assert.LessOrEqual(t, expressionCache.Len(), maxCachedExpressions)
+
+	name, err := Get("$.name", object)
+	require.NoError(t, err)
+	assert.Equal(t, "ibdm", name)
+}
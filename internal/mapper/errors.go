@@ -48,3 +48,18 @@ func (e *ParsingError) Is(target error) bool {
 
 	return false
 }
+
+// TemplateError reports a failure while rendering a mapping template, pointing to the mapping
+// field that caused it, like "identifier", "spec.name" or "extra[0].identifier".
+type TemplateError struct {
+	Field string
+	Err   error
+}
+
+func (e *TemplateError) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
@@ -5,9 +5,15 @@ package mapper
 
 import (
 	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mia-platform/ibdm/internal/config"
 )
 
 func TestParsingError(t *testing.T) {
@@ -28,3 +34,54 @@ func TestParsingError(t *testing.T) {
 
 	assert.False(t, parsingErr.Is(nil))
 }
+
+func TestMappingError(t *testing.T) {
+	t.Parallel()
+
+	mappingConfigs, err := config.NewMappingConfigsFromPath(filepath.Join("testdata", "mapping.yaml"))
+	require.NoError(t, err)
+	require.Len(t, mappingConfigs, 2)
+
+	mappingConfig := mappingConfigs[1]
+	mapper, err := New(mappingConfig.Mappings.Identifier, nil, mappingConfig.Mappings.Spec, nil)
+	require.NoError(t, err)
+
+	input := map[string]any{
+		"id":          "item",
+		"name":        "example",
+		"description": strings.Repeat("a", 100),
+		"credentials": map[string]any{
+			"apiToken": "very-secret",
+		},
+		"nested": map[string]any{
+			"password": "very-secret",
+			"list":     []any{"value", 1},
+		},
+	}
+
+	_, _, err = mapper.ApplyTemplates(input, ParentItemInfo{})
+	require.Error(t, err)
+
+	mappingErr := NewMappingError(err, mappingConfig.Source, mappingConfig.Type, input)
+	assert.ErrorIs(t, mappingErr, err)
+	assert.Equal(t, filepath.Join("testdata", "mapping.yaml"), mappingErr.Path)
+	assert.Equal(t, 1, mappingErr.Document)
+	assert.Equal(t, "spec.owner", mappingErr.Field)
+	assert.Equal(t, 16, mappingErr.Line)
+	assert.Equal(t, 5, mappingErr.Column)
+	assert.Equal(t, "second", mappingErr.Type)
+	assert.NotContains(t, mappingErr.Excerpt, "very-secret")
+	assert.Contains(t, mappingErr.Excerpt, `"credentials":"[REDACTED]"`)
+	assert.Contains(t, mappingErr.Excerpt, `"password":"[REDACTED]"`)
+	assert.Contains(t, mappingErr.Excerpt, `"description":"`+strings.Repeat("a", 64)+`..."`)
+	assert.Contains(t, mappingErr.Error(), fmt.Sprintf(`mapping %q document 1 line 16 column 5 (type "second"): spec.owner: `, filepath.Join("testdata", "mapping.yaml")))
+
+	t.Run("error without source", func(t *testing.T) {
+		t.Parallel()
+
+		mappingErr := NewMappingError(errors.New("underlying error"), config.Source{}, "type", nil)
+		assert.Equal(t, `type "type": underlying error`, mappingErr.Error())
+		assert.Empty(t, mappingErr.Field)
+		assert.Equal(t, "{}", mappingErr.Excerpt)
+	})
+}
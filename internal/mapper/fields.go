@@ -0,0 +1,108 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package mapper
+
+import (
+	"errors"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+var (
+	// execErrorLine extracts the line of the failing action from a template.ExecError message.
+	execErrorLine = regexp.MustCompile(`^template: [^:]+:(\d+):`)
+)
+
+// templateFields maps the lines of a template that combines many mapping fields into a single
+// YAML document back to the fields that generated them.
+type templateFields struct {
+	// name is used when the failing line cannot be attributed to a field.
+	name       string
+	firstLines []int
+	fields     []string
+}
+
+// newTemplateFields returns an empty templateFields for the combined template name.
+func newTemplateFields(name string) templateFields {
+	return templateFields{name: name}
+}
+
+// add records that field starts at line of the combined template.
+func (f *templateFields) add(field string, line int) {
+	f.firstLines = append(f.firstLines, line)
+	f.fields = append(f.fields, f.name+"."+field)
+}
+
+// field returns the name of the field rendered at line, falling back to the template name.
+func (f templateFields) field(line int) string {
+	index, found := slices.BinarySearch(f.firstLines, line)
+	if !found {
+		index--
+	}
+
+	if index < 0 || index >= len(f.fields) {
+		return f.name
+	}
+
+	return f.fields[index]
+}
+
+// wrap returns err as a TemplateError pointing to the field that caused it.
+func (f templateFields) wrap(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	field := f.name
+	var execErr template.ExecError
+	if errors.As(err, &execErr) {
+		if match := execErrorLine.FindStringSubmatch(execErr.Error()); match != nil {
+			line, _ := strconv.Atoi(match[1])
+			field = f.field(line)
+		}
+	}
+
+	return &TemplateError{Field: field, Err: err}
+}
+
+// compileFieldTemplates combines the key/value templates into a single YAML document template
+// named name, with the keys in lexical order to keep the rendered document stable.
+func compileFieldTemplates(name string, templates map[string]string, tmpl *template.Template, parsingErrs *error) (*template.Template, templateFields) {
+	fields := newTemplateFields(name)
+	templateString := new(strings.Builder)
+	templateString.WriteString("---\n")
+	line := 2
+	for _, key := range slices.Sorted(maps.Keys(templates)) {
+		value := templates[key]
+		fields.add(key, line)
+		templateString.WriteString(key + ": " + value + "\n")
+		line += strings.Count(value, "\n") + 1
+	}
+
+	compiledTemplate, err := tmpl.New(name).Parse(templateString.String())
+	if err != nil {
+		*parsingErrs = errors.Join(*parsingErrs, err)
+	}
+	return compiledTemplate, fields
+}
+
+// yamlDocumentFields maps the top level keys of a marshaled YAML document to its lines.
+func yamlDocumentFields(name string, document string) templateFields {
+	fields := newTemplateFields(name)
+	for index, line := range strings.Split(document, "\n") {
+		if line == "" || strings.HasPrefix(line, " ") || strings.HasPrefix(line, "-") {
+			continue
+		}
+
+		if key, _, found := strings.Cut(line, ":"); found {
+			fields.add(strings.Trim(key, `"'`), index+1)
+		}
+	}
+
+	return fields
+}
@@ -0,0 +1,53 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package functions
+
+import "reflect"
+
+// Default returns value, or defaultValue when value is empty.
+func Default(defaultValue any, value any) any {
+	if isEmpty(value) {
+		return defaultValue
+	}
+
+	return value
+}
+
+// Coalesce returns the first value that is not empty, or nil when all of them are empty.
+func Coalesce(values ...any) any {
+	for _, value := range values {
+		if !isEmpty(value) {
+			return value
+		}
+	}
+
+	return nil
+}
+
+// Ternary returns trueValue when condition is true and falseValue otherwise.
+func Ternary(trueValue, falseValue any, condition bool) any {
+	if condition {
+		return trueValue
+	}
+
+	return falseValue
+}
+
+// isEmpty reports whether v is nil, an empty string, or an empty list or object.
+// Zero numbers and false are valid values and are not considered empty.
+func isEmpty(v any) bool {
+	if v == nil {
+		return true
+	}
+
+	reflected := reflect.ValueOf(v)
+	switch reflected.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return reflected.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return reflected.IsNil()
+	default:
+		return false
+	}
+}
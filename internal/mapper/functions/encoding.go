@@ -0,0 +1,41 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package functions
+
+import (
+	"encoding/json"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FromJSON decodes the JSON document contained in s.
+func FromJSON(s string) (any, error) {
+	var value any
+	if err := json.Unmarshal([]byte(s), &value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// FromYAML decodes the YAML document contained in s.
+func FromYAML(s string) (any, error) {
+	var value any
+	if err := yaml.Unmarshal([]byte(s), &value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// ToYAML converts a value to its YAML string representation without the trailing newline.
+func ToYAML(v any) string {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSuffix(string(data), "\n")
+}
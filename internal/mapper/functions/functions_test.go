@@ -5,6 +5,7 @@ package functions
 
 import (
 	"bytes"
+	"fmt"
 	"testing"
 	"time"
 
@@ -147,6 +148,108 @@ func TestListFunctions(t *testing.T) {
 		require.Error(t, err)
 		require.Nil(t, value)
 	})
+
+	members := []any{
+		map[string]any{"login": "alice", "role": "owner", "commits": float64(12)},
+		map[string]any{"login": "bob", "role": "member", "commits": float64(3)},
+		map[string]any{"login": "carol", "role": "owner"},
+		map[string]any{"login": "dave", "role": "member", "commits": float64(40)},
+	}
+
+	t.Run("map function", func(t *testing.T) {
+		t.Parallel()
+
+		logins, err := Map("login", members)
+		require.NoError(t, err)
+		assert.Equal(t, []any{"alice", "bob", "carol", "dave"}, logins)
+
+		commits, err := Map("commits", members)
+		require.NoError(t, err)
+		assert.Equal(t, []any{float64(12), float64(3), float64(40)}, commits)
+
+		_, err = Map("login", "not a list")
+		require.Error(t, err)
+	})
+
+	t.Run("filter function", func(t *testing.T) {
+		t.Parallel()
+
+		owners, err := Filter("role", "owner", members)
+		require.NoError(t, err)
+		assert.Equal(t, []any{members[0], members[2]}, owners)
+
+		// template integer literals match JSON float64 numbers
+		matching, err := Filter("commits", 3, members)
+		require.NoError(t, err)
+		assert.Equal(t, []any{members[1]}, matching)
+
+		none, err := Filter("role", "admin", members)
+		require.NoError(t, err)
+		assert.Empty(t, none)
+
+		_, err = Filter("role", "owner", nil)
+		require.Error(t, err)
+	})
+
+	t.Run("sortBy function", func(t *testing.T) {
+		t.Parallel()
+
+		sorted, err := SortBy("commits", members)
+		require.NoError(t, err)
+		assert.Equal(t, []any{members[2], members[1], members[0], members[3]}, sorted)
+
+		sortedStrings, err := SortBy("", []string{"go", "catalog", "api"})
+		require.NoError(t, err)
+		assert.Equal(t, []any{"api", "catalog", "go"}, sortedStrings)
+
+		_, err = SortBy("", 123)
+		require.Error(t, err)
+	})
+
+	t.Run("uniq function", func(t *testing.T) {
+		t.Parallel()
+
+		unique, err := Uniq([]any{"go", "api", "go", float64(1), 1, map[string]any{"a": 1}, map[string]any{"a": 1}})
+		require.NoError(t, err)
+		assert.Equal(t, []any{"go", "api", float64(1), map[string]any{"a": 1}}, unique)
+
+		_, err = Uniq(map[string]any{})
+		require.Error(t, err)
+	})
+
+	t.Run("join function", func(t *testing.T) {
+		t.Parallel()
+
+		joined, err := Join(", ", []any{"go", float64(1), nil, true})
+		require.NoError(t, err)
+		assert.Equal(t, "go, 1, true", joined)
+
+		empty, err := Join(",", []any{})
+		require.NoError(t, err)
+		assert.Empty(t, empty)
+
+		_, err = Join(",", "go")
+		require.Error(t, err)
+	})
+
+	t.Run("groupBy function", func(t *testing.T) {
+		t.Parallel()
+
+		groups, err := GroupBy("role", members)
+		require.NoError(t, err)
+		expected := map[string]any{
+			"owner":  []any{members[0], members[2]},
+			"member": []any{members[1], members[3]},
+		}
+		assert.Equal(t, expected, groups)
+
+		byCommits, err := GroupBy("commits", members)
+		require.NoError(t, err)
+		assert.Len(t, byCommits, 3)
+
+		_, err = GroupBy("role", 1)
+		require.Error(t, err)
+	})
 }
 
 func TestObjectsFunctions(t *testing.T) {
@@ -218,6 +321,96 @@ func TestObjectsFunctions(t *testing.T) {
 		}
 		assert.Equal(t, expected, updatedObject)
 	})
+
+	t.Run("dig function", func(t *testing.T) {
+		t.Parallel()
+
+		object := map[string]any{
+			"properties": map[string]any{
+				"tags": map[string]any{"env": "prod"},
+				"containers": []any{
+					map[string]any{"name": "app"},
+				},
+				"empty": nil,
+			},
+		}
+		assert.Equal(t, "prod", Dig("properties.tags.env", "none", object))
+		assert.Equal(t, "app", Dig("properties.containers.0.name", "none", object))
+		assert.Equal(t, map[string]any{"env": "prod"}, Dig("properties.tags", nil, object))
+		assert.Equal(t, "none", Dig("properties.tags.team", "none", object))
+		assert.Equal(t, "none", Dig("properties.containers.1.name", "none", object))
+		assert.Equal(t, "none", Dig("properties.containers.first", "none", object))
+		assert.Equal(t, "none", Dig("properties.tags.env.value", "none", object))
+		assert.Equal(t, "none", Dig("properties.empty", "none", object))
+		assert.Nil(t, Dig("missing", nil, nil))
+	})
+
+	t.Run("merge function", func(t *testing.T) {
+		t.Parallel()
+
+		defaults := map[string]any{
+			"env":    "dev",
+			"labels": map[string]any{"team": "platform", "tier": "backend"},
+		}
+		overrides := map[string]any{
+			"env":    "prod",
+			"labels": map[string]any{"tier": "frontend"},
+			"owner":  "alice",
+		}
+		expected := map[string]any{
+			"env":    "prod",
+			"labels": map[string]any{"team": "platform", "tier": "frontend"},
+			"owner":  "alice",
+		}
+		assert.Equal(t, expected, Merge(defaults, overrides))
+		assert.Equal(t, map[string]any{"team": "platform", "tier": "backend"}, defaults["labels"])
+		assert.Equal(t, map[string]any{}, Merge())
+	})
+
+	t.Run("omit function", func(t *testing.T) {
+		t.Parallel()
+
+		object := map[string]any{
+			"name":    "Alice",
+			"age":     30,
+			"country": "Wonderland",
+		}
+		assert.Equal(t, map[string]any{"age": 30}, Omit(object, "name", "country", "missing"))
+		assert.Len(t, object, 3)
+		assert.Equal(t, map[string]any{}, Omit(nil, "name"))
+	})
+
+	t.Run("jsonpath function", func(t *testing.T) {
+		t.Parallel()
+
+		object := map[string]any{
+			"repository": map[string]any{
+				"name": "ibdm",
+				"topics": []any{
+					map[string]any{"name": "go", "stars": float64(10)},
+					map[string]any{"name": "catalog", "stars": float64(2)},
+				},
+			},
+		}
+
+		name, err := JSONPath("$.repository.name", object)
+		require.NoError(t, err)
+		assert.Equal(t, "ibdm", name)
+
+		topics, err := JSONPath("$.repository.topics[*].name", object)
+		require.NoError(t, err)
+		assert.Equal(t, []any{"go", "catalog"}, topics)
+
+		popular, err := JSONPath("$.repository.topics[?(@.stars > 5)].name", object)
+		require.NoError(t, err)
+		assert.Equal(t, []any{"go"}, popular)
+
+		_, err = JSONPath("$.repository.missing", object)
+		require.Error(t, err)
+
+		_, err = JSONPath("$.repository[", object)
+		require.Error(t, err)
+	})
 }
 
 func TestStringsFunctions(t *testing.T) {
@@ -329,6 +522,237 @@ func TestStringsFunctions(t *testing.T) {
 	})
 }
 
+func TestRegexFunctions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("regexMatch function", func(t *testing.T) {
+		t.Parallel()
+		matched, err := RegexMatch(`^v\d+`, "v1.2.3")
+		require.NoError(t, err)
+		assert.True(t, matched)
+
+		matched, err = RegexMatch(`^v\d+`, "latest")
+		require.NoError(t, err)
+		assert.False(t, matched)
+
+		_, err = RegexMatch(`(`, "latest")
+		require.Error(t, err)
+	})
+
+	t.Run("regexFind function", func(t *testing.T) {
+		t.Parallel()
+		found, err := RegexFind(`\d+\.\d+\.\d+`, "release-1.2.3-alpine")
+		require.NoError(t, err)
+		assert.Equal(t, "1.2.3", found)
+
+		found, err = RegexFind(`^v?(\d+)\.`, "v12.0.1")
+		require.NoError(t, err)
+		assert.Equal(t, "12", found)
+
+		found, err = RegexFind(`\d+`, "latest")
+		require.NoError(t, err)
+		assert.Empty(t, found)
+
+		_, err = RegexFind(`[`, "latest")
+		require.Error(t, err)
+	})
+
+	t.Run("regexReplace function", func(t *testing.T) {
+		t.Parallel()
+		replaced, err := RegexReplace(`^(\w+)/(\w+)$`, "${2}-${1}", "group/project")
+		require.NoError(t, err)
+		assert.Equal(t, "project-group", replaced)
+
+		replaced, err = RegexReplace(`[^a-z0-9]+`, "-", "my project_name")
+		require.NoError(t, err)
+		assert.Equal(t, "my-project-name", replaced)
+
+		_, err = RegexReplace(`(`, "", "latest")
+		require.Error(t, err)
+	})
+
+	t.Run("compiled patterns are cached up to a limit", func(t *testing.T) {
+		t.Parallel()
+		first, err := compileRegex(`^cached-\d+$`)
+		require.NoError(t, err)
+		second, err := compileRegex(`^cached-\d+$`)
+		require.NoError(t, err)
+		assert.Same(t, first, second)
+
+		for index := range maxCachedRegexes + 10 {
+			_, err := compileRegex(fmt.Sprintf(`^generated-%d$`, index))
+			require.NoError(t, err)
+		}
+		assert.LessOrEqual(t, regexCache.Len(), maxCachedRegexes)
+
+		first, err = compileRegex(`^not-cached$`)
+		require.NoError(t, err)
+		second, err = compileRegex(`^not-cached$`)
+		require.NoError(t, err)
+		assert.NotSame(t, first, second)
+	})
+}
+
+func TestVersionFunctions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("semver function", func(t *testing.T) {
+		t.Parallel()
+		version, err := Semver("v1.2.3-rc.1+build.5")
+		require.NoError(t, err)
+		expected := map[string]any{
+			"version":    "1.2.3-rc.1+build.5",
+			"major":      int64(1),
+			"minor":      int64(2),
+			"patch":      int64(3),
+			"prerelease": "rc.1",
+			"metadata":   "build.5",
+		}
+		assert.Equal(t, expected, version)
+
+		version, err = Semver("2.4")
+		require.NoError(t, err)
+		assert.Equal(t, "2.4.0", version["version"])
+
+		_, err = Semver("latest")
+		require.Error(t, err)
+	})
+
+	t.Run("semverCompare function", func(t *testing.T) {
+		t.Parallel()
+		testCases := map[string]struct {
+			constraint string
+			version    string
+			expected   bool
+		}{
+			"greater or equal": {constraint: ">= 1.2.0", version: "1.4.1", expected: true},
+			"lower":            {constraint: "< 1.2.0", version: "1.4.1", expected: false},
+			"caret range":      {constraint: "^1.4", version: "v1.9.0", expected: true},
+			"tilde range":      {constraint: "~1.4", version: "1.5.0", expected: false},
+			"multiple ranges":  {constraint: ">= 1.0, < 2.0 || >= 3.0", version: "3.1.0", expected: true},
+		}
+
+		for name, tc := range testCases {
+			satisfied, err := SemverCompare(tc.constraint, tc.version)
+			require.NoError(t, err, name)
+			assert.Equal(t, tc.expected, satisfied, name)
+		}
+
+		_, err := SemverCompare("~> what", "1.0.0")
+		require.Error(t, err)
+
+		_, err = SemverCompare(">= 1.0.0", "latest")
+		require.Error(t, err)
+	})
+
+	t.Run("parseImageRef function", func(t *testing.T) {
+		t.Parallel()
+		testCases := map[string]struct {
+			ref      string
+			expected map[string]any
+		}{
+			"official image": {
+				ref: "nginx",
+				expected: map[string]any{
+					"registry":   "docker.io",
+					"repository": "library/nginx",
+					"name":       "docker.io/library/nginx",
+					"tag":        "latest",
+					"digest":     "",
+				},
+			},
+			"docker hub image with tag": {
+				ref: "miaplatform/console:1.2.3",
+				expected: map[string]any{
+					"registry":   "docker.io",
+					"repository": "miaplatform/console",
+					"name":       "docker.io/miaplatform/console",
+					"tag":        "1.2.3",
+					"digest":     "",
+				},
+			},
+			"private registry with port": {
+				ref: "registry.example.com:5000/team/app:v2",
+				expected: map[string]any{
+					"registry":   "registry.example.com:5000",
+					"repository": "team/app",
+					"name":       "registry.example.com:5000/team/app",
+					"tag":        "v2",
+					"digest":     "",
+				},
+			},
+			"tag and digest": {
+				ref: "ghcr.io/org/app:1.0@sha256:abcdef",
+				expected: map[string]any{
+					"registry":   "ghcr.io",
+					"repository": "org/app",
+					"name":       "ghcr.io/org/app",
+					"tag":        "1.0",
+					"digest":     "sha256:abcdef",
+				},
+			},
+			"localhost digest only": {
+				ref: "localhost/app@sha256:abcdef",
+				expected: map[string]any{
+					"registry":   "localhost",
+					"repository": "app",
+					"name":       "localhost/app",
+					"tag":        "",
+					"digest":     "sha256:abcdef",
+				},
+			},
+		}
+
+		for name, tc := range testCases {
+			parsed, err := ParseImageRef(tc.ref)
+			require.NoError(t, err, name)
+			assert.Equal(t, tc.expected, parsed, name)
+		}
+
+		for _, ref := range []string{"", "nginx:", "nginx@", "ghcr.io/", "my image"} {
+			_, err := ParseImageRef(ref)
+			require.ErrorIs(t, err, errInvalidImageRef, ref)
+		}
+	})
+}
+
+func TestURLFunctions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("urlParse function", func(t *testing.T) {
+		t.Parallel()
+		parsed, err := URLParse("https://user@gitlab.example.com:8443/group/project?ref=main&ref=dev#readme")
+		require.NoError(t, err)
+		expected := map[string]any{
+			"scheme":   "https",
+			"host":     "gitlab.example.com:8443",
+			"hostname": "gitlab.example.com",
+			"port":     "8443",
+			"path":     "/group/project",
+			"query":    map[string]any{"ref": "main"},
+			"fragment": "readme",
+			"user":     "user",
+		}
+		assert.Equal(t, expected, parsed)
+
+		_, err = URLParse("group/project")
+		require.ErrorIs(t, err, errNotAbsoluteURL)
+
+		_, err = URLParse("https://exa mple.com")
+		require.Error(t, err)
+	})
+
+	t.Run("urlJoin function", func(t *testing.T) {
+		t.Parallel()
+		joined, err := URLJoin("https://gitlab.example.com/group/", "project", "../other", "-/issues")
+		require.NoError(t, err)
+		assert.Equal(t, "https://gitlab.example.com/group/other/-/issues", joined)
+
+		_, err = URLJoin("http://[::1", "path")
+		require.Error(t, err)
+	})
+}
+
 func TestNumbersFunctions(t *testing.T) {
 	t.Parallel()
 
@@ -343,11 +767,139 @@ func TestNumbersFunctions(t *testing.T) {
 		assert.False(t, IsNumber(nil))
 		assert.False(t, IsNumber([]float64{1, 2}))
 	})
+
+	t.Run("add function", func(t *testing.T) {
+		t.Parallel()
+		sum, err := Add(float64(1.5), 2, int64(3), uint8(4))
+		require.NoError(t, err)
+		assert.InDelta(t, 10.5, sum, 0)
+
+		_, err = Add(1, "2")
+		require.Error(t, err)
+	})
+
+	t.Run("sub function", func(t *testing.T) {
+		t.Parallel()
+		result, err := Sub(float64(10), 4)
+		require.NoError(t, err)
+		assert.InDelta(t, 6.0, result, 0)
+
+		_, err = Sub(nil, 4)
+		require.Error(t, err)
+	})
+
+	t.Run("div function", func(t *testing.T) {
+		t.Parallel()
+		result, err := Div(float64(7), 2)
+		require.NoError(t, err)
+		assert.InDelta(t, 3.5, result, 0)
+
+		_, err = Div(float64(7), 0)
+		require.Error(t, err)
+
+		_, err = Div("7", 2)
+		require.Error(t, err)
+	})
+
+	t.Run("round function", func(t *testing.T) {
+		t.Parallel()
+		result, err := Round(2, float64(3.14159))
+		require.NoError(t, err)
+		assert.InDelta(t, 3.14, result, 0)
+
+		result, err = Round(0, float64(2.5))
+		require.NoError(t, err)
+		assert.InDelta(t, 3.0, result, 0)
+
+		result, err = Round(-2, 1234)
+		require.NoError(t, err)
+		assert.InDelta(t, 1200.0, result, 0)
+
+		_, err = Round(2, true)
+		require.Error(t, err)
+	})
+}
+
+func TestConditionalFunctions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("default function", func(t *testing.T) {
+		t.Parallel()
+		assert.Equal(t, "fallback", Default("fallback", nil))
+		assert.Equal(t, "fallback", Default("fallback", ""))
+		assert.Equal(t, "fallback", Default("fallback", []any{}))
+		assert.Equal(t, "fallback", Default("fallback", map[string]any{}))
+		assert.Equal(t, "value", Default("fallback", "value"))
+		assert.Equal(t, float64(0), Default("fallback", float64(0)))
+		assert.Equal(t, false, Default("fallback", false))
+	})
+
+	t.Run("coalesce function", func(t *testing.T) {
+		t.Parallel()
+		assert.Equal(t, "first", Coalesce(nil, "", "first", "second"))
+		assert.Equal(t, float64(0), Coalesce(nil, float64(0)))
+		assert.Nil(t, Coalesce(nil, "", []any{}))
+		assert.Nil(t, Coalesce())
+	})
+
+	t.Run("ternary function", func(t *testing.T) {
+		t.Parallel()
+		assert.Equal(t, "yes", Ternary("yes", "no", true))
+		assert.Equal(t, "no", Ternary("yes", "no", false))
+	})
+}
+
+func TestEncodingFunctions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("fromJSON function", func(t *testing.T) {
+		t.Parallel()
+		value, err := FromJSON(`{"name":"Alice","tags":["a","b"],"age":30}`)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"name": "Alice", "tags": []any{"a", "b"}, "age": float64(30)}, value)
+
+		_, err = FromJSON(`{"name":`)
+		require.Error(t, err)
+	})
+
+	t.Run("fromYAML function", func(t *testing.T) {
+		t.Parallel()
+		value, err := FromYAML("name: Alice\ntags:\n  - a\n  - b\n")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"name": "Alice", "tags": []any{"a", "b"}}, value)
+
+		_, err = FromYAML("name: [")
+		require.Error(t, err)
+	})
+
+	t.Run("toYAML function", func(t *testing.T) {
+		t.Parallel()
+		assert.Equal(t, "age: 30\nname: Alice", ToYAML(map[string]any{"name": "Alice", "age": 30}))
+		assert.Equal(t, "- a\n- b", ToYAML([]any{"a", "b"}))
+	})
 }
 
 func TestDateFunctions(t *testing.T) {
 	t.Parallel()
 
+	// ago replaces nowFn, so it runs before the parallel subtests are started
+	t.Run("ago function", func(t *testing.T) {
+		nowFn = func() time.Time {
+			return time.Date(2024, 6, 10, 14, 4, 5, 0, time.UTC)
+		}
+
+		result, err := Ago("2024-06-10T12:03:05Z")
+		require.NoError(t, err)
+		assert.Equal(t, "2h1m0s", result)
+
+		result, err = Ago(float64(1718028240.6))
+		require.NoError(t, err)
+		assert.Equal(t, "4s", result)
+
+		_, err = Ago("yesterday")
+		require.Error(t, err)
+	})
+
 	t.Run("now function", func(t *testing.T) {
 		t.Parallel()
 		loc := time.FixedZone("Fixed+01", int((1 * time.Hour).Seconds()))
@@ -393,6 +945,47 @@ func TestDateFunctions(t *testing.T) {
 		_, err := ConvertFromTimestamp(nil)
 		require.Error(t, err)
 	})
+
+	t.Run("dateFormat function", func(t *testing.T) {
+		t.Parallel()
+		result, err := DateFormat("2006-01-02", "2024-06-10T23:30:00+02:00")
+		require.NoError(t, err)
+		assert.Equal(t, "2024-06-10", result)
+
+		result, err = DateFormat("02 Jan 2006 15:04", float64(1717977845))
+		require.NoError(t, err)
+		assert.Equal(t, "10 Jun 2024 00:04", result)
+
+		_, err = DateFormat("2006-01-02", "10/06/2024")
+		require.Error(t, err)
+
+		_, err = DateFormat("2006-01-02", true)
+		require.Error(t, err)
+	})
+
+	t.Run("dateParse function", func(t *testing.T) {
+		t.Parallel()
+		result, err := DateParse("2006-01-02 15:04:05 -0700", "2024-06-10 16:04:05 +0200")
+		require.NoError(t, err)
+		assert.Equal(t, "2024-06-10T14:04:05Z", result)
+
+		_, err = DateParse("2006-01-02", "10/06/2024")
+		require.Error(t, err)
+	})
+
+	t.Run("duration function", func(t *testing.T) {
+		t.Parallel()
+		result, err := Duration(float64(5400))
+		require.NoError(t, err)
+		assert.Equal(t, "1h30m0s", result)
+
+		result, err = Duration(1.5)
+		require.NoError(t, err)
+		assert.Equal(t, "1.5s", result)
+
+		_, err = Duration("5400")
+		require.Error(t, err)
+	})
 }
 
 func TestUUIDFunctions(t *testing.T) {
@@ -0,0 +1,15 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package functions
+
+import (
+	"github.com/mia-platform/ibdm/internal/jsonpath"
+)
+
+// JSONPath evaluates the JSONPath expression against object.
+// Expressions selecting a single element return its value, while expressions using wildcards,
+// filters or recursive descent return the list of matched values.
+func JSONPath(expression string, object any) (any, error) {
+	return jsonpath.Get(expression, object)
+}
@@ -4,8 +4,11 @@
 package functions
 
 import (
+	"cmp"
 	"fmt"
 	"reflect"
+	"slices"
+	"strings"
 )
 
 // List returns the provided elements as a slice of any.
@@ -92,3 +95,172 @@ func Last(list any) (any, error) {
 		return nil, fmt.Errorf("cannot find last element of type %s", listType.String())
 	}
 }
+
+// Map returns the values found at path in every object of list, skipping the objects where the
+// path is missing.
+func Map(path string, list any) ([]any, error) {
+	elements, err := toList(list, "map")
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]any, 0, len(elements))
+	for _, element := range elements {
+		if value := Dig(path, nil, element); value != nil {
+			result = append(result, value)
+		}
+	}
+
+	return result, nil
+}
+
+// Filter returns the objects of list whose value at path is equal to value.
+func Filter(path string, value any, list any) ([]any, error) {
+	elements, err := toList(list, "filter")
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]any, 0, len(elements))
+	for _, element := range elements {
+		if valuesEqual(Dig(path, nil, element), value) {
+			result = append(result, element)
+		}
+	}
+
+	return result, nil
+}
+
+// SortBy returns a copy of list sorted by the value found at path in each object; an empty path
+// sorts the elements themselves. Numbers are compared numerically, every other value by its string
+// representation and missing values come first. The sort is stable.
+func SortBy(path string, list any) ([]any, error) {
+	elements, err := toList(list, "sort")
+	if err != nil {
+		return nil, err
+	}
+
+	valueOf := func(element any) any {
+		if path == "" {
+			return element
+		}
+		return Dig(path, nil, element)
+	}
+
+	result := slices.Clone(elements)
+	slices.SortStableFunc(result, func(a, b any) int {
+		return compareValues(valueOf(a), valueOf(b))
+	})
+
+	return result, nil
+}
+
+// Uniq returns the elements of list without duplicates, keeping the first occurrence of each.
+func Uniq(list any) ([]any, error) {
+	elements, err := toList(list, "deduplicate")
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]any, 0, len(elements))
+	for _, element := range elements {
+		if !slices.ContainsFunc(result, func(other any) bool { return valuesEqual(element, other) }) {
+			result = append(result, element)
+		}
+	}
+
+	return result, nil
+}
+
+// Join concatenates the string representation of the elements of list using sep as separator,
+// nil elements are skipped.
+func Join(sep string, list any) (string, error) {
+	elements, err := toList(list, "join")
+	if err != nil {
+		return "", err
+	}
+
+	values := make([]string, 0, len(elements))
+	for _, element := range elements {
+		if element != nil {
+			values = append(values, castToString(element))
+		}
+	}
+
+	return strings.Join(values, sep), nil
+}
+
+// GroupBy groups the objects of list by the string representation of their value at path,
+// skipping the objects where the path is missing.
+func GroupBy(path string, list any) (map[string]any, error) {
+	elements, err := toList(list, "group")
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]any)
+	for _, element := range elements {
+		value := Dig(path, nil, element)
+		if value == nil {
+			continue
+		}
+
+		key := castToString(value)
+		group, _ := result[key].([]any)
+		result[key] = append(group, element)
+	}
+
+	return result, nil
+}
+
+// toList converts list to a slice of any when it is a slice or array.
+func toList(list any, action string) ([]any, error) {
+	if list == nil {
+		return nil, fmt.Errorf("cannot %s type nil", action)
+	}
+
+	listType := reflect.TypeOf(list).Kind()
+	switch listType {
+	case reflect.Slice, reflect.Array:
+		reflectedList := reflect.ValueOf(list)
+		result := make([]any, reflectedList.Len())
+		for i := range reflectedList.Len() {
+			result[i] = reflectedList.Index(i).Interface()
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("cannot %s type %s", action, listType.String())
+	}
+}
+
+// valuesEqual reports whether a and b are equal, comparing numbers by value regardless of their type.
+func valuesEqual(a, b any) bool {
+	aNumber, aIsNumber := toFloat(a)
+	bNumber, bIsNumber := toFloat(b)
+	if aIsNumber && bIsNumber {
+		return aNumber == bNumber
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+// compareValues orders a and b numerically when both are numbers and by their string
+// representation otherwise; nil is ordered before any other value.
+func compareValues(a, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	aNumber, aIsNumber := toFloat(a)
+	bNumber, bIsNumber := toFloat(b)
+	if aIsNumber && bIsNumber {
+		return cmp.Compare(aNumber, bNumber)
+	}
+
+	return strings.Compare(castToString(a), castToString(b))
+}
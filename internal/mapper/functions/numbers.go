@@ -3,6 +3,13 @@
 
 package functions
 
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+)
+
 // IsNumber reports whether v is a numeric value.
 // Data decoded from JSON always produces float64; int64 is accepted as a
 // convenience for values set explicitly in source code.
@@ -14,3 +21,73 @@ func IsNumber(v any) bool {
 		return false
 	}
 }
+
+// Add returns the sum of values.
+func Add(values ...any) (float64, error) {
+	var sum float64
+	for _, value := range values {
+		number, ok := toFloat(value)
+		if !ok {
+			return 0, fmt.Errorf("cannot add type %T", value)
+		}
+		sum += number
+	}
+
+	return sum, nil
+}
+
+// Sub returns the result of subtracting b from a.
+func Sub(a, b any) (float64, error) {
+	aNumber, aOk := toFloat(a)
+	bNumber, bOk := toFloat(b)
+	if !aOk || !bOk {
+		return 0, fmt.Errorf("cannot subtract type %T from type %T", b, a)
+	}
+
+	return aNumber - bNumber, nil
+}
+
+// Div returns the result of dividing a by b.
+func Div(a, b any) (float64, error) {
+	aNumber, aOk := toFloat(a)
+	bNumber, bOk := toFloat(b)
+	if !aOk || !bOk {
+		return 0, fmt.Errorf("cannot divide type %T by type %T", a, b)
+	}
+	if bNumber == 0 {
+		return 0, errors.New("cannot divide by zero")
+	}
+
+	return aNumber / bNumber, nil
+}
+
+// Round rounds value half away from zero keeping the given number of decimal places.
+func Round(precision int, value any) (float64, error) {
+	number, ok := toFloat(value)
+	if !ok {
+		return 0, fmt.Errorf("cannot round type %T", value)
+	}
+
+	scale := math.Pow10(precision)
+	return math.Round(number*scale) / scale, nil
+}
+
+// toFloat converts any integer, unsigned-integer, or floating-point value to float64, reporting
+// whether the conversion was possible.
+func toFloat(v any) (float64, bool) {
+	if v == nil {
+		return 0, false
+	}
+
+	reflected := reflect.ValueOf(v)
+	switch reflected.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(reflected.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(reflected.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return reflected.Float(), true
+	default:
+		return 0, false
+	}
+}
@@ -3,7 +3,12 @@
 
 package functions
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"maps"
+	"strconv"
+	"strings"
+)
 
 // Object builds a map from alternating key and value arguments.
 func Object(keyAndValues ...any) map[string]any {
@@ -67,3 +72,68 @@ func Keys(object map[string]any) []any {
 	}
 	return keys
 }
+
+// Dig walks object following the dot separated path and returns the value found at its end, or
+// defaultValue when any step of the path is missing. Numeric path segments index into lists.
+func Dig(path string, defaultValue any, object any) any {
+	current := object
+	for segment := range strings.SplitSeq(path, ".") {
+		switch value := current.(type) {
+		case map[string]any:
+			next, exists := value[segment]
+			if !exists {
+				return defaultValue
+			}
+			current = next
+		case []any:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(value) {
+				return defaultValue
+			}
+			current = value[index]
+		default:
+			return defaultValue
+		}
+	}
+
+	if current == nil {
+		return defaultValue
+	}
+
+	return current
+}
+
+// Merge returns a new map combining all objects; values of later objects take precedence and
+// nested maps are merged recursively. The input objects are never modified.
+func Merge(objects ...map[string]any) map[string]any {
+	result := make(map[string]any)
+	for _, object := range objects {
+		for key, value := range object {
+			existing, existingIsMap := result[key].(map[string]any)
+			valueMap, valueIsMap := value.(map[string]any)
+			if existingIsMap && valueIsMap {
+				result[key] = Merge(existing, valueMap)
+				continue
+			}
+			if valueIsMap {
+				value = Merge(valueMap)
+			}
+			result[key] = value
+		}
+	}
+
+	return result
+}
+
+// Omit creates a map containing all the keys of object except the specified ones.
+func Omit(object map[string]any, keys ...string) map[string]any {
+	result := maps.Clone(object)
+	if result == nil {
+		result = make(map[string]any)
+	}
+	for _, key := range keys {
+		delete(result, key)
+	}
+
+	return result
+}
@@ -0,0 +1,64 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package functions
+
+import (
+	"regexp"
+
+	"github.com/mia-platform/ibdm/internal/boundedcache"
+)
+
+// maxCachedRegexes bounds the number of compiled patterns kept in regexCache, since templates
+// can build the patterns from the input data.
+const maxCachedRegexes = 256
+
+// regexCache keeps the compiled patterns, so each one is compiled only once for all the items
+// rendered by a mapping. Once full, new patterns are compiled on every use.
+var regexCache = boundedcache.New[string, *regexp.Regexp](maxCachedRegexes)
+
+// RegexMatch reports whether s contains any match of pattern.
+func RegexMatch(pattern, s string) (bool, error) {
+	re, err := compileRegex(pattern)
+	if err != nil {
+		return false, err
+	}
+
+	return re.MatchString(s), nil
+}
+
+// RegexFind returns the first match of pattern in s, or an empty string when there is none.
+// When pattern contains capturing groups the value of the first group is returned instead of the
+// whole match.
+func RegexFind(pattern, s string) (string, error) {
+	re, err := compileRegex(pattern)
+	if err != nil {
+		return "", err
+	}
+
+	matches := re.FindStringSubmatch(s)
+	switch len(matches) {
+	case 0:
+		return "", nil
+	case 1:
+		return matches[0], nil
+	default:
+		return matches[1], nil
+	}
+}
+
+// RegexReplace replaces every match of pattern in s with replacement, expanding the $1 style
+// references to the capturing groups.
+func RegexReplace(pattern, replacement, s string) (string, error) {
+	re, err := compileRegex(pattern)
+	if err != nil {
+		return "", err
+	}
+
+	return re.ReplaceAllString(s, replacement), nil
+}
+
+// compileRegex returns the compiled pattern from the cache, compiling it on first use.
+func compileRegex(pattern string) (*regexp.Regexp, error) {
+	return regexCache.Get(pattern, regexp.Compile)
+}
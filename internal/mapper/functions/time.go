@@ -34,3 +34,59 @@ func ConvertFromTimestamp(v any) (string, error) {
 
 	return time.Unix(sec, 0).UTC().Format(time.RFC3339), nil
 }
+
+// DateFormat formats a date using the Go reference layout, converting it to UTC first.
+// The date can be an RFC3339 string or a Unix timestamp in seconds.
+func DateFormat(layout string, v any) (string, error) {
+	date, err := toTime(v)
+	if err != nil {
+		return "", err
+	}
+
+	return date.UTC().Format(layout), nil
+}
+
+// DateParse parses s using the Go reference layout and returns the UTC date formatted as RFC3339.
+func DateParse(layout, s string) (string, error) {
+	date, err := time.Parse(layout, s)
+	if err != nil {
+		return "", err
+	}
+
+	return date.UTC().Format(time.RFC3339), nil
+}
+
+// Duration converts an amount of seconds to a duration string like 1h30m0s.
+func Duration(v any) (string, error) {
+	seconds, ok := toFloat(v)
+	if !ok {
+		return "", fmt.Errorf("cannot convert type %T to duration", v)
+	}
+
+	return time.Duration(seconds * float64(time.Second)).String(), nil
+}
+
+// Ago returns the time elapsed since a date as a duration string rounded to the second.
+// The date can be an RFC3339 string or a Unix timestamp in seconds.
+func Ago(v any) (string, error) {
+	date, err := toTime(v)
+	if err != nil {
+		return "", err
+	}
+
+	return nowFn().Sub(date).Round(time.Second).String(), nil
+}
+
+// toTime converts an RFC3339 string or a Unix timestamp in seconds to a time.Time.
+func toTime(v any) (time.Time, error) {
+	if date, ok := v.(string); ok {
+		return time.Parse(time.RFC3339, date)
+	}
+
+	seconds, ok := toFloat(v)
+	if !ok {
+		return time.Time{}, fmt.Errorf("cannot convert type %T to date", v)
+	}
+
+	return time.Unix(0, int64(seconds*float64(time.Second))).UTC(), nil
+}
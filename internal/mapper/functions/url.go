@@ -0,0 +1,47 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package functions
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+var (
+	errNotAbsoluteURL = errors.New("url is not absolute")
+)
+
+// URLParse splits an absolute URL into its components. Query parameters are returned as an object
+// holding the first value of each parameter.
+func URLParse(rawURL string) (map[string]any, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if !parsed.IsAbs() {
+		return nil, fmt.Errorf("%w: %s", errNotAbsoluteURL, rawURL)
+	}
+
+	query := make(map[string]any)
+	for key, values := range parsed.Query() {
+		query[key] = values[0]
+	}
+
+	return map[string]any{
+		"scheme":   parsed.Scheme,
+		"host":     parsed.Host,
+		"hostname": parsed.Hostname(),
+		"port":     parsed.Port(),
+		"path":     parsed.Path,
+		"query":    query,
+		"fragment": parsed.Fragment,
+		"user":     parsed.User.Username(),
+	}, nil
+}
+
+// URLJoin appends the elements to the path of base, cleaning any ./ or ../ element.
+func URLJoin(base string, elements ...string) (string, error) {
+	return url.JoinPath(base, elements...)
+}
@@ -0,0 +1,113 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package functions
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+const (
+	defaultRegistry   = "docker.io"
+	defaultRepository = "library"
+	defaultTag        = "latest"
+)
+
+var (
+	errInvalidImageRef = errors.New("invalid image reference")
+)
+
+// Semver parses version as a semantic version and returns its components.
+// Partial versions like 1.2 and the v prefix are accepted.
+func Semver(version string) (map[string]any, error) {
+	parsed, err := semver.NewVersion(version)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, version)
+	}
+
+	return map[string]any{
+		"version":    parsed.String(),
+		"major":      int64(parsed.Major()), //nolint:gosec // version components never overflow an int64
+		"minor":      int64(parsed.Minor()), //nolint:gosec // version components never overflow an int64
+		"patch":      int64(parsed.Patch()), //nolint:gosec // version components never overflow an int64
+		"prerelease": parsed.Prerelease(),
+		"metadata":   parsed.Metadata(),
+	}, nil
+}
+
+// SemverCompare reports whether version satisfies constraint, like >= 1.2.0, < 2 or ^1.4.
+func SemverCompare(constraint, version string) (bool, error) {
+	constraints, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return false, fmt.Errorf("%w: %s", err, constraint)
+	}
+
+	parsed, err := semver.NewVersion(version)
+	if err != nil {
+		return false, fmt.Errorf("%w: %s", err, version)
+	}
+
+	return constraints.Check(parsed), nil
+}
+
+// ParseImageRef splits a container image reference into its registry, repository, tag and digest.
+// References without a registry are resolved against Docker Hub like the Docker CLI does, and the
+// latest tag is assumed when neither a tag nor a digest is present.
+func ParseImageRef(ref string) (map[string]any, error) {
+	name := strings.TrimSpace(ref)
+	if name == "" || strings.ContainsAny(name, " \t\n") {
+		return nil, fmt.Errorf("%w: %q", errInvalidImageRef, ref)
+	}
+
+	var digest string
+	if index := strings.LastIndex(name, "@"); index != -1 {
+		name, digest = name[:index], name[index+1:]
+		if digest == "" {
+			return nil, fmt.Errorf("%w: %q", errInvalidImageRef, ref)
+		}
+	}
+
+	var tag string
+	if index := strings.LastIndex(name, ":"); index > strings.LastIndex(name, "/") {
+		name, tag = name[:index], name[index+1:]
+		if tag == "" {
+			return nil, fmt.Errorf("%w: %q", errInvalidImageRef, ref)
+		}
+	}
+
+	registry, repository := splitRegistry(name)
+	if repository == "" || strings.HasPrefix(repository, "/") || strings.HasSuffix(repository, "/") {
+		return nil, fmt.Errorf("%w: %q", errInvalidImageRef, ref)
+	}
+
+	if tag == "" && digest == "" {
+		tag = defaultTag
+	}
+
+	return map[string]any{
+		"registry":   registry,
+		"repository": repository,
+		"name":       registry + "/" + repository,
+		"tag":        tag,
+		"digest":     digest,
+	}, nil
+}
+
+// splitRegistry splits an image name in its registry and repository, applying the Docker Hub
+// defaults when the name has no registry.
+func splitRegistry(name string) (string, string) {
+	registry := defaultRegistry
+	repository := name
+	if first, rest, found := strings.Cut(name, "/"); found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		registry, repository = first, rest
+	}
+
+	if registry == defaultRegistry && repository != "" && !strings.Contains(repository, "/") {
+		repository = defaultRepository + "/" + repository
+	}
+	return registry, repository
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"regexp"
 	"slices"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -49,13 +50,19 @@ type ExtraMapping struct {
 	CreateIfTemplate *template.Template
 	IDTemplate       *template.Template
 	BodyTemplate     *template.Template
+
+	// field is the name of the extra in the mapping, used to report template errors.
+	field      string
+	bodyFields templateFields
 }
 
 // internalMapper is the default Mapper implementation backed by text/template.
 type internalMapper struct {
 	idTemplate       *template.Template
 	metadataTemplate *template.Template
+	metadataFields   templateFields
 	specTemplate     *template.Template
+	specFields       templateFields
 	extraMappings    []ExtraMapping
 }
 
@@ -68,10 +75,11 @@ type MappedData struct {
 
 // ExtraMappedData wraps the identifier and rendered spec produced by a Mapper.
 type ExtraMappedData struct {
-	APIVersion string
-	ItemFamily string
-	Identifier string
-	Spec       map[string]any
+	APIVersion   string
+	ItemFamily   string
+	DeletePolicy string
+	Identifier   string
+	Spec         map[string]any
 }
 
 // ParentItemInfo holds metadata about the parent item for relationship extra mappings.
@@ -90,9 +98,9 @@ func New(identifierTemplate string, metadataTemplates, specTemplates map[string]
 		parsingErrs = err
 	}
 
-	metadataTemplate := compileMetadataTemplates(metadataTemplates, tmpl, &parsingErrs)
+	metadataTemplate, metadataFields := compileMetadataTemplates(metadataTemplates, tmpl, &parsingErrs)
 
-	specTemplate := compileSpecTemplates(specTemplates, tmpl, &parsingErrs)
+	specTemplate, specFields := compileSpecTemplates(specTemplates, tmpl, &parsingErrs)
 
 	var extraMappings []ExtraMapping
 	if len(extraTemplates) > 0 {
@@ -106,48 +114,31 @@ func New(identifierTemplate string, metadataTemplates, specTemplates map[string]
 	return &internalMapper{
 		idTemplate:       idTemplate,
 		metadataTemplate: metadataTemplate,
+		metadataFields:   metadataFields,
 		specTemplate:     specTemplate,
+		specFields:       specFields,
 		extraMappings:    extraMappings,
 	}, nil
 }
 
 // compileMetadataTemplates combines the key/value metadata templates into a
 // single YAML document template.
-func compileMetadataTemplates(metadataTemplates map[string]string, tmpl *template.Template, parsingErrs *error) *template.Template {
-	metadataTemplateString := new(strings.Builder)
-	metadataTemplateString.WriteString("---\n")
-	for key, value := range metadataTemplates {
-		metadataTemplateString.WriteString(key + ": " + value + "\n")
-	}
-
-	metadataTemplate, err := tmpl.New("metadata").Parse(metadataTemplateString.String())
-	if err != nil {
-		*parsingErrs = errors.Join(*parsingErrs, err)
-	}
-	return metadataTemplate
+func compileMetadataTemplates(metadataTemplates map[string]string, tmpl *template.Template, parsingErrs *error) (*template.Template, templateFields) {
+	return compileFieldTemplates("metadata", metadataTemplates, tmpl, parsingErrs)
 }
 
 // compileSpecTemplates combines the key/value spec templates into a single YAML
 // document template.
-func compileSpecTemplates(specTemplates map[string]string, tmpl *template.Template, parsingErrs *error) *template.Template {
-	specTemplateString := new(strings.Builder)
-	specTemplateString.WriteString("---\n")
-	for key, value := range specTemplates {
-		specTemplateString.WriteString(key + ": " + value + "\n")
-	}
-
-	specTemplate, err := tmpl.New("spec").Parse(specTemplateString.String())
-	if err != nil {
-		*parsingErrs = errors.Join(*parsingErrs, err)
-	}
-	return specTemplate
+func compileSpecTemplates(specTemplates map[string]string, tmpl *template.Template, parsingErrs *error) (*template.Template, templateFields) {
+	return compileFieldTemplates("spec", specTemplates, tmpl, parsingErrs)
 }
 
 // compileExtraMappings pre-compiles extra templates (identifier, createIf and body)
 // to speed up mapping execution and to catch template errors early.
 func compileExtraMappings(extraTemplates []config.Extra, tmpl *template.Template, parsingErrs *error) []ExtraMapping {
 	extraMappings := make([]ExtraMapping, 0, len(extraTemplates))
-	for _, extra := range extraTemplates {
+	for index, extra := range extraTemplates {
+		field := "extra[" + strconv.Itoa(index) + "]"
 		// Clone the base template set to avoid name collisions across extras.
 		extraTmpl, err := tmpl.Clone()
 		if err != nil {
@@ -203,6 +194,8 @@ func compileExtraMappings(extraTemplates []config.Extra, tmpl *template.Template
 			CreateIfTemplate: createIfTmpl,
 			IDTemplate:       idTmpl,
 			BodyTemplate:     bodyTmpl,
+			field:            field,
+			bodyFields:       yamlDocumentFields(field, string(bodyBytes)),
 		})
 	}
 	return extraMappings
@@ -212,17 +205,17 @@ func compileExtraMappings(extraTemplates []config.Extra, tmpl *template.Template
 func (m *internalMapper) ApplyTemplates(data map[string]any, parentResourceInfo ParentItemInfo) (MappedData, []ExtraMappedData, error) {
 	identifier, err := executeIdentifierTemplate(m.idTemplate, "identifier", data)
 	if err != nil {
-		return MappedData{}, nil, err
+		return MappedData{}, nil, &TemplateError{Field: "identifier", Err: err}
 	}
 
 	metadataData, err := executeTemplatesMap(m.metadataTemplate, "metadata", data)
 	if err != nil {
-		return MappedData{}, nil, err
+		return MappedData{}, nil, m.metadataFields.wrap(err)
 	}
 
 	specData, err := executeTemplatesMap(m.specTemplate, "spec", data)
 	if err != nil {
-		return MappedData{}, nil, err
+		return MappedData{}, nil, m.specFields.wrap(err)
 	}
 
 	extraData, err := executeExtraMappings(data, m.extraMappings)
@@ -241,7 +234,7 @@ func (m *internalMapper) ApplyTemplates(data map[string]any, parentResourceInfo
 func (m *internalMapper) ApplyIdentifierTemplate(data map[string]any) (string, []ExtraMappedData, error) {
 	identifier, err := executeIdentifierTemplate(m.idTemplate, "identifier", data)
 	if err != nil {
-		return identifier, nil, err
+		return identifier, nil, &TemplateError{Field: "identifier", Err: err}
 	}
 
 	if len(m.extraMappings) == 0 {
@@ -257,13 +250,14 @@ func (m *internalMapper) ApplyIdentifierTemplate(data map[string]any) (string, [
 
 		extraIdentifier, err := executeIdentifierTemplate(extraMapping.IDTemplate, "extra-id", data)
 		if err != nil {
-			return "", nil, err
+			return "", nil, &TemplateError{Field: extraMapping.field + ".identifier", Err: err}
 		}
 
 		extras = append(extras, ExtraMappedData{
-			APIVersion: extraMapping.APIVersion,
-			ItemFamily: extraMapping.ItemFamily,
-			Identifier: extraIdentifier,
+			APIVersion:   extraMapping.APIVersion,
+			ItemFamily:   extraMapping.ItemFamily,
+			DeletePolicy: extraMapping.DeletePolicy,
+			Identifier:   extraIdentifier,
 		})
 	}
 
@@ -326,7 +320,7 @@ func executeExtraMappings(data map[string]any, extraMappings []ExtraMapping) ([]
 		if extraMapping.CreateIfTemplate != nil {
 			createIf, err := executeExtraCreateIfTemplate(data, extraMapping)
 			if err != nil {
-				return nil, err
+				return nil, &TemplateError{Field: extraMapping.field + ".createIf", Err: err}
 			}
 
 			if !createIf {
@@ -337,26 +331,27 @@ func executeExtraMappings(data map[string]any, extraMappings []ExtraMapping) ([]
 		// Generate Identifier
 		identifier, err := executeIdentifierTemplate(extraMapping.IDTemplate, "extra-id", data)
 		if err != nil {
-			return nil, fmt.Errorf("%w: %w", errParsingExtra, err)
+			return nil, &TemplateError{Field: extraMapping.field + ".identifier", Err: fmt.Errorf("%w: %w", errParsingExtra, err)}
 		}
 
 		// Generate Body (Spec)
 		var bodyBuf bytes.Buffer
 		if err := extraMapping.BodyTemplate.Execute(&bodyBuf, data); err != nil {
-			return nil, fmt.Errorf("%w: %w", errParsingExtra, err)
+			return nil, extraMapping.bodyFields.wrap(fmt.Errorf("%w: %w", errParsingExtra, err))
 		}
 
 		// Unmarshal the executed YAML back into a map
 		var spec map[string]any
 		if err := yaml.Unmarshal(bodyBuf.Bytes(), &spec); err != nil {
-			return nil, fmt.Errorf("%w: %w", errParsingExtra, err)
+			return nil, &TemplateError{Field: extraMapping.field, Err: fmt.Errorf("%w: %w", errParsingExtra, err)}
 		}
 
 		output = append(output, ExtraMappedData{
-			APIVersion: extraMapping.APIVersion,
-			ItemFamily: extraMapping.ItemFamily,
-			Identifier: identifier,
-			Spec:       spec,
+			APIVersion:   extraMapping.APIVersion,
+			ItemFamily:   extraMapping.ItemFamily,
+			DeletePolicy: extraMapping.DeletePolicy,
+			Identifier:   identifier,
+			Spec:         spec,
 		})
 	}
 
@@ -380,13 +375,22 @@ func templateFunctions() template.FuncMap {
 		"decode64":   functions.DecodeBase64,
 		"isString":   functions.IsString,
 
+		// regular expression functions
+		"regexMatch":   functions.RegexMatch,
+		"regexFind":    functions.RegexFind,
+		"regexReplace": functions.RegexReplace,
+
 		// object functions
-		"object": functions.Object,
-		"toJSON": functions.ToJSON,
-		"pick":   functions.Pick,
-		"get":    functions.Get,
-		"set":    functions.Set,
-		"keys":   functions.Keys,
+		"object":   functions.Object,
+		"toJSON":   functions.ToJSON,
+		"pick":     functions.Pick,
+		"get":      functions.Get,
+		"set":      functions.Set,
+		"keys":     functions.Keys,
+		"dig":      functions.Dig,
+		"merge":    functions.Merge,
+		"omit":     functions.Omit,
+		"jsonpath": functions.JSONPath,
 
 		// list functions
 		"list":    functions.List,
@@ -394,13 +398,46 @@ func templateFunctions() template.FuncMap {
 		"prepend": functions.Prepend,
 		"first":   functions.First,
 		"last":    functions.Last,
+		"map":     functions.Map,
+		"filter":  functions.Filter,
+		"sortBy":  functions.SortBy,
+		"uniq":    functions.Uniq,
+		"join":    functions.Join,
+		"groupBy": functions.GroupBy,
 
 		// number functions
 		"isNumber": functions.IsNumber,
+		"add":      functions.Add,
+		"sub":      functions.Sub,
+		"div":      functions.Div,
+		"round":    functions.Round,
+
+		// conditional functions
+		"default":  functions.Default,
+		"coalesce": functions.Coalesce,
+		"ternary":  functions.Ternary,
+
+		// encoding functions
+		"fromJSON": functions.FromJSON,
+		"fromYAML": functions.FromYAML,
+		"toYAML":   functions.ToYAML,
 
 		// time functions
 		"now":                  functions.Now,
 		"convertFromTimestamp": functions.ConvertFromTimestamp,
+		"dateFormat":           functions.DateFormat,
+		"dateParse":            functions.DateParse,
+		"duration":             functions.Duration,
+		"ago":                  functions.Ago,
+
+		// version functions
+		"semver":        functions.Semver,
+		"semverCompare": functions.SemverCompare,
+		"parseImageRef": functions.ParseImageRef,
+
+		// url functions
+		"urlParse": functions.URLParse,
+		"urlJoin":  functions.URLJoin,
 
 		// cryptographic functions
 		"sha256sum": functions.Sha256Sum,
@@ -291,6 +291,44 @@ func TestMapper(t *testing.T) {
 				},
 			},
 		},
+		"shape lists and objects with collection functions": {
+			mapper: func() Mapper {
+				m, err := New("{{ .name }}", nil, map[string]string{
+					"topics":  `{{ .topics | uniq | sortBy "" | join "," }}`,
+					"owners":  `{{ filter "role" "owner" .members | map "login" | toJSON }}`,
+					"tags":    `{{ omit (merge .defaultTags .tags) "internal" | toJSON }}`,
+					"region":  `{{ dig "location.region" "unknown" . }}`,
+					"size":    `{{ div .sizeInBytes 1024 | round 1 }}`,
+					"private": `{{ ternary "private" "public" .private }}`,
+				}, nil)
+				require.NoError(t, err)
+				return m
+			}(),
+			input: map[string]any{
+				"name":   "example",
+				"topics": []any{"go", "catalog", "go"},
+				"members": []any{
+					map[string]any{"login": "alice", "role": "owner"},
+					map[string]any{"login": "bob", "role": "member"},
+				},
+				"defaultTags": map[string]any{"env": "dev", "team": "platform"},
+				"tags":        map[string]any{"env": "prod", "internal": "true"},
+				"sizeInBytes": float64(2100),
+				"private":     true,
+			},
+			expected: MappedData{
+				Identifier: "example",
+				Metadata:   map[string]any{},
+				Spec: map[string]any{
+					"topics":  "catalog,go",
+					"owners":  []any{"alice"},
+					"tags":    map[string]any{"env": "prod", "team": "platform"},
+					"region":  "unknown",
+					"size":    2.1,
+					"private": "private",
+				},
+			},
+		},
 		"simple mapping with metadata": {
 			mapper: func() Mapper {
 				m, err := New("{{ .name }}",
@@ -436,9 +474,10 @@ func TestMapper(t *testing.T) {
 			},
 			expectedExtra: []ExtraMappedData{
 				{
-					APIVersion: "api/v1",
-					ItemFamily: "relationships",
-					Identifier: "example",
+					APIVersion:   "api/v1",
+					ItemFamily:   "relationships",
+					DeletePolicy: "cascade",
+					Identifier:   "example",
 					Spec: map[string]any{
 						"sourceRef": "urn:mia-platform-catalog:resource.custom-platform:v1:Family1:null:42",
 						"targetRef": "urn:mia-platform-catalog:resource.custom-platform:v1:Family1:null:example",
@@ -446,9 +485,10 @@ func TestMapper(t *testing.T) {
 					},
 				},
 				{
-					APIVersion: "api/v1",
-					ItemFamily: "relationships",
-					Identifier: "example-create",
+					APIVersion:   "api/v1",
+					ItemFamily:   "relationships",
+					DeletePolicy: "cascade",
+					Identifier:   "example-create",
 					Spec: map[string]any{
 						"sourceRef": "urn:mia-platform-catalog:resource.custom-platform:v1:Family1:null:42",
 						"targetRef": "urn:mia-platform-catalog:resource.custom-platform:v1:Family1:null:example",
@@ -656,3 +696,213 @@ func TestIdentifierOnly(t *testing.T) {
 		})
 	}
 }
+
+func TestSourceKeyMapper(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		template      string
+		input         map[string]any
+		expected      string
+		expectedError bool
+	}{
+		"native key is not validated as identifier": {
+			template: "{{ .id }}",
+			input: map[string]any{
+				"id": "/subscriptions/ID/resourceGroups/RG",
+			},
+			expected: "/subscriptions/ID/resourceGroups/RG",
+		},
+		"surrounding whitespaces are removed": {
+			template: "{{ printf \"%s/%s\" .group .name }}\n",
+			input: map[string]any{
+				"group": "group",
+				"name":  "name",
+			},
+			expected: "group/name",
+		},
+		"missing key returns error": {
+			template:      "{{ .id }}",
+			input:         map[string]any{},
+			expectedError: true,
+		},
+		"empty key returns error": {
+			template: "{{ .id }}",
+			input: map[string]any{
+				"id": "",
+			},
+			expectedError: true,
+		},
+	}
+
+	for testName, test := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			mapper, err := NewSourceKeyMapper(test.template)
+			require.NoError(t, err)
+
+			output, err := mapper.ApplySourceKeyTemplate(test.input)
+			if test.expectedError {
+				var expectedError template.ExecError
+				assert.ErrorAs(t, err, &expectedError)
+				assert.Empty(t, output)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, output)
+		})
+	}
+
+	t.Run("invalid template returns parsing error", func(t *testing.T) {
+		t.Parallel()
+
+		mapper, err := NewSourceKeyMapper("{{ .id | unknownFunc }}")
+		assert.Nil(t, mapper)
+		var targetError *ParsingError
+		assert.ErrorAs(t, err, &targetError)
+	})
+}
+
+func TestTombstoneMapper(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		templates     map[string]string
+		input         map[string]any
+		expected      map[string]any
+		expectedError bool
+	}{
+		"default fields without templates": {
+			input: map[string]any{},
+			expected: map[string]any{
+				"status":    "deleted",
+				"deletedAt": "2024-06-01T12:00:00Z",
+			},
+		},
+		"templated fields are added to the defaults": {
+			templates: map[string]string{
+				"deletedBy": "{{ .user }}",
+			},
+			input: map[string]any{
+				"user": "admin",
+			},
+			expected: map[string]any{
+				"status":    "deleted",
+				"deletedAt": "2024-06-01T12:00:00Z",
+				"deletedBy": "admin",
+			},
+		},
+		"templated fields override the defaults": {
+			templates: map[string]string{
+				"status": "retired",
+			},
+			input: map[string]any{},
+			expected: map[string]any{
+				"status":    "retired",
+				"deletedAt": "2024-06-01T12:00:00Z",
+			},
+		},
+		"missing key returns error": {
+			templates: map[string]string{
+				"deletedBy": "{{ .user }}",
+			},
+			input:         map[string]any{},
+			expectedError: true,
+		},
+	}
+
+	for testName, test := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			mapper, err := NewTombstoneMapper(test.templates)
+			require.NoError(t, err)
+
+			output, err := mapper.ApplyTombstoneTemplates(test.input, "2024-06-01T12:00:00Z")
+			if test.expectedError {
+				var expectedError template.ExecError
+				assert.ErrorAs(t, err, &expectedError)
+				assert.Nil(t, output)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, output)
+		})
+	}
+
+	t.Run("invalid template returns parsing error", func(t *testing.T) {
+		t.Parallel()
+
+		mapper, err := NewTombstoneMapper(map[string]string{"status": "{{ .id | unknownFunc }}"})
+		assert.Nil(t, mapper)
+		var targetError *ParsingError
+		assert.ErrorAs(t, err, &targetError)
+	})
+}
+
+func TestTemplateErrorField(t *testing.T) {
+	t.Parallel()
+
+	extra := config.Extra{
+		"apiVersion":   "relationships/v1",
+		"itemFamily":   "relationships",
+		"deletePolicy": config.DeletePolicyCascade,
+		"identifier":   "{{ .name }}-relationship",
+		"sourceRef":    "{{ .source }}",
+		"targetRef":    "{{ .name }}",
+		"typeRef":      "{{ .name }}",
+	}
+
+	testCases := map[string]struct {
+		identifier    string
+		spec          map[string]string
+		extra         []config.Extra
+		input         map[string]any
+		expectedField string
+	}{
+		"identifier": {
+			identifier:    "{{ .missing }}",
+			input:         map[string]any{"name": "example"},
+			expectedField: "identifier",
+		},
+		"spec key after multiline values": {
+			identifier: "{{ .name }}",
+			spec: map[string]string{
+				"a": "|-\n  {{ .name }}\n  {{ .name }}",
+				"b": "{{ .missing }}",
+				"c": "{{ .name }}",
+			},
+			input:         map[string]any{"name": "example"},
+			expectedField: "spec.b",
+		},
+		"extra identifier": {
+			identifier:    "{{ .name }}",
+			extra:         []config.Extra{extra, {"apiVersion": "relationships/v1", "itemFamily": "relationships", "deletePolicy": "none", "identifier": "{{ .missing }}"}},
+			input:         map[string]any{"name": "example", "source": "source"},
+			expectedField: "extra[1].identifier",
+		},
+		"extra body key": {
+			identifier:    "{{ .name }}",
+			extra:         []config.Extra{extra},
+			input:         map[string]any{"name": "example"},
+			expectedField: "extra[0].sourceRef",
+		},
+	}
+
+	for testName, test := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			mapper, err := New(test.identifier, nil, test.spec, test.extra)
+			require.NoError(t, err)
+
+			_, _, err = mapper.ApplyTemplates(test.input, ParentItemInfo{})
+			var templateErr *TemplateError
+			require.ErrorAs(t, err, &templateErr)
+			assert.Equal(t, test.expectedField, templateErr.Field)
+		})
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package mapper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/mia-platform/ibdm/internal/config"
+)
+
+const (
+	redactedValue = "[REDACTED]"
+
+	// maxExcerptValueLength bounds the length of every string value in an input excerpt.
+	maxExcerptValueLength = 64
+	// maxExcerptLength bounds the length of an input excerpt.
+	maxExcerptLength = 512
+)
+
+var (
+	// sensitiveKeyRegex matches the input keys whose values are never shown in excerpts.
+	sensitiveKeyRegex = regexp.MustCompile(`(?i)(password|passwd|secret|token|credential|authorization|api[-_]?key|private)`)
+)
+
+// Ensure MappingError satisfies error.
+var _ error = &MappingError{}
+
+// MappingError enriches a mapper error with the location of the failing field inside its mapping
+// file and with the data that caused it.
+type MappingError struct {
+	// Path and Document locate the mapping configuration, they are empty when unknown.
+	Path     string
+	Document int
+	// Field is the failing mapping field, Line and Column the position of its key in the file.
+	Field  string
+	Line   int
+	Column int
+	// Type is the type of the mapped data, Excerpt a redacted and truncated copy of its values.
+	Type    string
+	Excerpt string
+
+	Err error
+}
+
+// NewMappingError wraps err, returned by a mapper configured from source, with the details of
+// the failing field and of the data of type dataType that caused it.
+func NewMappingError(err error, source config.Source, dataType string, data map[string]any) *MappingError {
+	mappingErr := &MappingError{
+		Path:     source.Path,
+		Document: source.Document,
+		Type:     dataType,
+		Excerpt:  redactedExcerpt(data),
+		Err:      err,
+	}
+
+	var templateErr *TemplateError
+	if errors.As(err, &templateErr) {
+		mappingErr.Field = templateErr.Field
+		if position, found := source.Position(templateErr.Field); found {
+			mappingErr.Line = position.Line
+			mappingErr.Column = position.Column
+		}
+	}
+
+	return mappingErr
+}
+
+func (e *MappingError) Error() string {
+	location := fmt.Sprintf("type %q", e.Type)
+	if e.Path != "" {
+		location = fmt.Sprintf("mapping %q document %d", e.Path, e.Document)
+		if e.Line > 0 {
+			location += fmt.Sprintf(" line %d column %d", e.Line, e.Column)
+		}
+		location += fmt.Sprintf(" (type %q)", e.Type)
+	}
+
+	return location + ": " + e.Err.Error()
+}
+
+func (e *MappingError) Unwrap() error {
+	return e.Err
+}
+
+// LogAttributes returns the error details as key value pairs for structured logging.
+func (e *MappingError) LogAttributes() []any {
+	return []any{
+		"type", e.Type,
+		"file", e.Path,
+		"document", e.Document,
+		"field", e.Field,
+		"line", e.Line,
+		"column", e.Column,
+		"input", e.Excerpt,
+		"error", e.Err,
+	}
+}
+
+// redactedExcerpt renders data as JSON, hiding the values of sensitive keys and truncating long
+// strings and the excerpt itself.
+func redactedExcerpt(data map[string]any) string {
+	encoded, err := json.Marshal(redactValue(data))
+	if err != nil {
+		return ""
+	}
+
+	if len(encoded) > maxExcerptLength {
+		return string(encoded[:maxExcerptLength]) + "..."
+	}
+	return string(encoded)
+}
+
+// redactValue returns a copy of value with sensitive keys redacted and long strings truncated.
+func redactValue(value any) any {
+	switch typedValue := value.(type) {
+	case map[string]any:
+		redacted := make(map[string]any, len(typedValue))
+		for key, nestedValue := range typedValue {
+			if sensitiveKeyRegex.MatchString(key) {
+				redacted[key] = redactedValue
+				continue
+			}
+			redacted[key] = redactValue(nestedValue)
+		}
+		return redacted
+	case []any:
+		redacted := make([]any, 0, len(typedValue))
+		for _, nestedValue := range typedValue {
+			redacted = append(redacted, redactValue(nestedValue))
+		}
+		return redacted
+	case string:
+		if len(typedValue) > maxExcerptValueLength {
+			return typedValue[:maxExcerptValueLength] + "..."
+		}
+		return typedValue
+	default:
+		return typedValue
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package mapper
+
+import (
+	"errors"
+	"strings"
+	"text/template"
+)
+
+var (
+	errEmptySourceKey = errors.New("generated source key is empty")
+)
+
+// SourceKeyMapper renders the source-native key of an item.
+// The key must be computable from both upsert and delete payloads of the same item.
+type SourceKeyMapper interface {
+	// ApplySourceKeyTemplate applies the source key template to the given input data.
+	ApplySourceKeyTemplate(data map[string]any) (string, error)
+}
+
+var _ SourceKeyMapper = &sourceKeyMapper{}
+
+// sourceKeyMapper is the default SourceKeyMapper implementation backed by text/template.
+type sourceKeyMapper struct {
+	template *template.Template
+}
+
+// NewSourceKeyMapper constructs a SourceKeyMapper from sourceKeyTemplate.
+// Unlike identifiers, the rendered key is not validated against the identifier rules, so native
+// keys like Azure resource IDs or repository paths can be used verbatim.
+func NewSourceKeyMapper(sourceKeyTemplate string) (SourceKeyMapper, error) {
+	tmpl, err := template.New("sourceKey").Option("missingkey=error").Funcs(templateFunctions()).Parse(sourceKeyTemplate)
+	if err != nil {
+		return nil, NewParsingError(err)
+	}
+
+	return &sourceKeyMapper{template: tmpl}, nil
+}
+
+// ApplySourceKeyTemplate implements SourceKeyMapper.ApplySourceKeyTemplate.
+func (m *sourceKeyMapper) ApplySourceKeyTemplate(data map[string]any) (string, error) {
+	outputStrBuilder := new(strings.Builder)
+	if err := m.template.Execute(outputStrBuilder, data); err != nil {
+		return "", &TemplateError{Field: "sourceKey", Err: err}
+	}
+
+	sourceKey := strings.TrimSpace(outputStrBuilder.String())
+	if sourceKey == "" {
+		return "", &TemplateError{Field: "sourceKey", Err: template.ExecError{Name: "sourceKey", Err: errEmptySourceKey}}
+	}
+
+	return sourceKey, nil
+}
@@ -0,0 +1,61 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package mapper
+
+import (
+	"maps"
+	"text/template"
+)
+
+const (
+	// TombstoneStatusField is the spec field marking an item as deleted.
+	TombstoneStatusField = "status"
+	// TombstoneStatusDeleted is the value of TombstoneStatusField for deleted items.
+	TombstoneStatusDeleted = "deleted"
+	// TombstoneDeletedAtField is the spec field holding the time of the delete event.
+	TombstoneDeletedAtField = "deletedAt"
+)
+
+// TombstoneMapper renders the fields that mark an item as retired instead of deleting it.
+type TombstoneMapper interface {
+	// ApplyTombstoneTemplates applies the tombstone templates to the data of a delete event.
+	// The returned fields always contain TombstoneStatusField and TombstoneDeletedAtField, set to
+	// TombstoneStatusDeleted and deletedAt unless the templates override them.
+	ApplyTombstoneTemplates(data map[string]any, deletedAt string) (map[string]any, error)
+}
+
+var _ TombstoneMapper = &tombstoneMapper{}
+
+// tombstoneMapper is the default TombstoneMapper implementation backed by text/template.
+type tombstoneMapper struct {
+	template *template.Template
+	fields   templateFields
+}
+
+// NewTombstoneMapper constructs a TombstoneMapper from the optional tombstoneTemplates.
+func NewTombstoneMapper(tombstoneTemplates map[string]string) (TombstoneMapper, error) {
+	var parsingErrs error
+	tmpl := template.New("main").Option("missingkey=error").Funcs(templateFunctions())
+	tombstoneTemplate, tombstoneFields := compileFieldTemplates("tombstone", tombstoneTemplates, tmpl, &parsingErrs)
+	if parsingErrs != nil {
+		return nil, NewParsingError(parsingErrs)
+	}
+
+	return &tombstoneMapper{template: tombstoneTemplate, fields: tombstoneFields}, nil
+}
+
+// ApplyTombstoneTemplates implements TombstoneMapper.ApplyTombstoneTemplates.
+func (m *tombstoneMapper) ApplyTombstoneTemplates(data map[string]any, deletedAt string) (map[string]any, error) {
+	renderedFields, err := executeTemplatesMap(m.template, "tombstone", data)
+	if err != nil {
+		return nil, m.fields.wrap(err)
+	}
+
+	fields := map[string]any{
+		TombstoneStatusField:    TombstoneStatusDeleted,
+		TombstoneDeletedAtField: deletedAt,
+	}
+	maps.Copy(fields, renderedFields)
+	return fields, nil
+}
@@ -0,0 +1,7 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+// Package migration moves the items generated by a version of the mappings to the identifiers
+// generated by a newer one. The old and new identifiers are computed from previously collected
+// source data, so that the old items can be deleted and the new ones created in a single run.
+package migration
@@ -0,0 +1,272 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/mia-platform/ibdm/internal/config"
+	"github.com/mia-platform/ibdm/internal/destination"
+	"github.com/mia-platform/ibdm/internal/identifiercache"
+	"github.com/mia-platform/ibdm/internal/logger"
+	"github.com/mia-platform/ibdm/internal/mapper"
+	"github.com/mia-platform/ibdm/internal/pipeline"
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+const (
+	loggerName = "ibdm:migration"
+)
+
+var (
+	// ErrMigration wraps errors returned while applying a migration.
+	ErrMigration = errors.New("mapping migration")
+)
+
+// Item identifies a single item of the catalog.
+type Item struct {
+	APIVersion string
+	ItemFamily string
+	Identifier string
+}
+
+// Change holds the operations needed to migrate the items generated for a single source item.
+type Change struct {
+	Type string
+	// Deleted lists the items generated by the old mapping that are not generated anymore.
+	Deleted []Item
+	// Upserted lists the items generated by the new mapping.
+	Upserted []*destination.Data
+
+	oldKey   *identifiercache.Key
+	newKey   *identifiercache.Key
+	newEntry identifiercache.Entry
+}
+
+// Migrator computes the changes between two versions of the same mappings.
+type Migrator struct {
+	from  map[string]pipeline.DataMapper
+	to    map[string]pipeline.DataMapper
+	cache identifiercache.Cache
+}
+
+// New returns a Migrator from the from mappers to the to mappers. Only the types found in both sets
+// with a different version are migrated. cache is optional, when set the old identifiers are
+// resolved from it and it is updated with the new ones once the migration is applied.
+func New(from, to map[string]pipeline.DataMapper, cache identifiercache.Cache) *Migrator {
+	return &Migrator{
+		from:  from,
+		to:    to,
+		cache: cache,
+	}
+}
+
+// Plan computes the changes needed to migrate the items generated from records. Delete records,
+// types that are not migrated, and records that do not produce any difference are skipped.
+func (m *Migrator) Plan(ctx context.Context, records []source.Data) []Change {
+	log := logger.FromContext(ctx).WithName(loggerName)
+	changes := make([]Change, 0)
+	for _, data := range records {
+		if data.Operation != source.DataOperationUpsert {
+			continue
+		}
+
+		fromMapper, fromFound := m.from[data.Type]
+		toMapper, toFound := m.to[data.Type]
+		if !fromFound || !toFound || fromMapper.Version == toMapper.Version {
+			log.Trace("type not migrated, skipping", "type", data.Type)
+			continue
+		}
+
+		change, err := m.change(ctx, data, fromMapper, toMapper)
+		if err != nil {
+			var mappingErr *mapper.MappingError
+			if errors.As(err, &mappingErr) {
+				log.Error("error computing migration", mappingErr.LogAttributes()...)
+				continue
+			}
+			log.Error("error computing migration", "type", data.Type, "error", err)
+			continue
+		}
+
+		if len(change.Deleted) == 0 {
+			log.Trace("identifiers not changed, skipping", "type", data.Type)
+			continue
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+// Apply sends the changes to dest. The new items are upserted before deleting the old ones, and
+// the migration stops at the first error to avoid deleting items that have not been replaced.
+func (m *Migrator) Apply(ctx context.Context, dest destination.Sender, changes []Change) error {
+	for _, change := range changes {
+		for _, data := range change.Upserted {
+			if err := dest.SendData(ctx, data); err != nil {
+				return fmt.Errorf("%w: upserting %q: %w", ErrMigration, data.Name, err)
+			}
+		}
+
+		for _, item := range change.Deleted {
+			data := &destination.Data{
+				APIVersion:    item.APIVersion,
+				ItemFamily:    item.ItemFamily,
+				Name:          item.Identifier,
+				OperationTime: change.Upserted[0].OperationTime,
+			}
+			if err := dest.DeleteData(ctx, data); err != nil {
+				return fmt.Errorf("%w: deleting %q: %w", ErrMigration, item.Identifier, err)
+			}
+		}
+
+		if err := m.updateCache(change); err != nil {
+			return fmt.Errorf("%w: %w", ErrMigration, err)
+		}
+	}
+
+	return nil
+}
+
+// change computes the items generated by the two mappers for data.
+func (m *Migrator) change(ctx context.Context, data source.Data, fromMapper, toMapper pipeline.DataMapper) (Change, error) {
+	oldItem, oldExtras, oldKey, err := m.oldItems(ctx, data, fromMapper)
+	if err != nil {
+		return Change{}, mapper.NewMappingError(err, fromMapper.Source, data.Type, data.Values)
+	}
+
+	output, extras, err := toMapper.Mapper.ApplyTemplates(data.Values, mapper.ParentItemInfo{
+		APIVersion: toMapper.APIVersion,
+		ItemFamily: toMapper.ItemFamily,
+	})
+	if err != nil {
+		return Change{}, mapper.NewMappingError(err, toMapper.Source, data.Type, data.Values)
+	}
+
+	change := Change{
+		Type: data.Type,
+		Upserted: []*destination.Data{
+			{
+				APIVersion:    toMapper.APIVersion,
+				ItemFamily:    toMapper.ItemFamily,
+				Name:          output.Identifier,
+				Metadata:      output.Metadata,
+				Data:          output.Spec,
+				OperationTime: data.Timestamp(),
+			},
+		},
+		oldKey: oldKey,
+		newEntry: identifiercache.Entry{
+			APIVersion: toMapper.APIVersion,
+			ItemFamily: toMapper.ItemFamily,
+			Identifier: output.Identifier,
+		},
+	}
+
+	newItems := []Item{{APIVersion: toMapper.APIVersion, ItemFamily: toMapper.ItemFamily, Identifier: output.Identifier}}
+	for _, extra := range extras {
+		change.Upserted = append(change.Upserted, &destination.Data{
+			APIVersion:    extra.APIVersion,
+			ItemFamily:    extra.ItemFamily,
+			Name:          extra.Identifier,
+			Data:          extra.Spec,
+			OperationTime: data.Timestamp(),
+		})
+		change.newEntry.Extras = append(change.newEntry.Extras, identifiercache.Extra{
+			APIVersion:   extra.APIVersion,
+			ItemFamily:   extra.ItemFamily,
+			Identifier:   extra.Identifier,
+			DeletePolicy: extra.DeletePolicy,
+		})
+		newItems = append(newItems, Item{APIVersion: extra.APIVersion, ItemFamily: extra.ItemFamily, Identifier: extra.Identifier})
+	}
+
+	for _, item := range append([]Item{oldItem}, oldExtras...) {
+		if !slices.Contains(newItems, item) {
+			change.Deleted = append(change.Deleted, item)
+		}
+	}
+
+	if m.cache != nil {
+		change.newKey = cacheKey(data, toMapper, output.Identifier)
+	}
+
+	return change, nil
+}
+
+// oldItems resolves the item and the cascading extras generated by fromMapper for data, preferring
+// the identifiers found in the cache when available.
+func (m *Migrator) oldItems(ctx context.Context, data source.Data, fromMapper pipeline.DataMapper) (Item, []Item, *identifiercache.Key, error) {
+	log := logger.FromContext(ctx).WithName(loggerName)
+	identifier, extras, err := fromMapper.Mapper.ApplyIdentifierTemplate(data.Values)
+	if err != nil && (m.cache == nil || fromMapper.SourceKey == nil) {
+		return Item{}, nil, nil, err
+	}
+
+	if m.cache != nil {
+		if key := cacheKey(data, fromMapper, identifier); key != nil {
+			if entry, found := m.cache.Get(*key); found {
+				log.Trace("old identifiers resolved from cache", "type", data.Type, "sourceKey", key.SourceKey)
+				oldExtras := make([]Item, 0, len(entry.Extras))
+				for _, extra := range entry.Extras {
+					if extra.DeletePolicy == config.DeletePolicyCascade {
+						oldExtras = append(oldExtras, Item{APIVersion: extra.APIVersion, ItemFamily: extra.ItemFamily, Identifier: extra.Identifier})
+					}
+				}
+				return Item{APIVersion: entry.APIVersion, ItemFamily: entry.ItemFamily, Identifier: entry.Identifier}, oldExtras, key, nil
+			}
+		}
+	}
+
+	if err != nil {
+		return Item{}, nil, nil, err
+	}
+
+	oldExtras := make([]Item, 0, len(extras))
+	for _, extra := range extras {
+		oldExtras = append(oldExtras, Item{APIVersion: extra.APIVersion, ItemFamily: extra.ItemFamily, Identifier: extra.Identifier})
+	}
+	return Item{APIVersion: fromMapper.APIVersion, ItemFamily: fromMapper.ItemFamily, Identifier: identifier}, oldExtras, nil, nil
+}
+
+// updateCache replaces the cached identifiers of a migrated item.
+func (m *Migrator) updateCache(change Change) error {
+	if m.cache == nil {
+		return nil
+	}
+
+	if change.oldKey != nil && (change.newKey == nil || *change.oldKey != *change.newKey) {
+		if err := m.cache.Delete(*change.oldKey); err != nil {
+			return err
+		}
+	}
+
+	if change.newKey != nil {
+		return m.cache.Set(*change.newKey, change.newEntry)
+	}
+
+	return nil
+}
+
+// cacheKey returns the identifier cache key used by the pipeline for data mapped by dataMapper.
+func cacheKey(data source.Data, dataMapper pipeline.DataMapper, identifier string) *identifiercache.Key {
+	if dataMapper.SourceKey == nil {
+		if identifier == "" {
+			return nil
+		}
+		return &identifiercache.Key{Type: data.Type, SourceKey: identifier}
+	}
+
+	sourceKey, err := dataMapper.SourceKey.ApplySourceKeyTemplate(data.Values)
+	if err != nil {
+		return nil
+	}
+
+	return &identifiercache.Key{Type: data.Type, SourceKey: sourceKey}
+}
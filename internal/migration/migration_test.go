@@ -0,0 +1,204 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package migration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mia-platform/ibdm/internal/config"
+	"github.com/mia-platform/ibdm/internal/destination"
+	fakedestination "github.com/mia-platform/ibdm/internal/destination/fake"
+	"github.com/mia-platform/ibdm/internal/identifiercache"
+	"github.com/mia-platform/ibdm/internal/mapper"
+	"github.com/mia-platform/ibdm/internal/pipeline"
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+var testTime = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func testMapper(tb testing.TB, version int, identifier, sourceKey string, extra ...config.Extra) pipeline.DataMapper {
+	tb.Helper()
+
+	itemMapper, err := mapper.New(identifier, nil, map[string]string{"name": "{{ .name }}"}, extra)
+	require.NoError(tb, err)
+
+	dataMapper := pipeline.DataMapper{
+		APIVersion: "v1",
+		ItemFamily: "family",
+		Version:    version,
+		Mapper:     itemMapper,
+	}
+
+	if sourceKey != "" {
+		sourceKeyMapper, err := mapper.NewSourceKeyMapper(sourceKey)
+		require.NoError(tb, err)
+		dataMapper.SourceKey = sourceKeyMapper
+	}
+
+	return dataMapper
+}
+
+func testRelationship(identifier string) config.Extra {
+	return config.Extra{
+		"apiVersion":   "relationships/v1",
+		"itemFamily":   "relationships",
+		"deletePolicy": config.DeletePolicyCascade,
+		"identifier":   identifier,
+		"sourceRef":    "urn:source",
+		"targetRef":    "urn:target",
+		"typeRef":      "urn:type",
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	t.Parallel()
+
+	item := source.Data{
+		Type:      "type1",
+		Operation: source.DataOperationUpsert,
+		Values: map[string]any{
+			"id":   "1",
+			"name": "item",
+			"path": "group/item",
+		},
+		Time: testTime,
+	}
+
+	testCases := map[string]struct {
+		from             pipeline.DataMapper
+		to               pipeline.DataMapper
+		records          []source.Data
+		cache            func(tb testing.TB) identifiercache.Cache
+		expectedUpserted []string
+		expectedDeleted  []*destination.Data
+		expectedCache    map[identifiercache.Key]identifiercache.Entry
+	}{
+		"changed identifier deletes the old item": {
+			from:             testMapper(t, 1, "{{ .id }}", ""),
+			to:               testMapper(t, 2, "{{ .path | sha256sum }}", ""),
+			records:          []source.Data{item},
+			expectedUpserted: []string{"e072114ac035795b14dd1fab4325de393d7db343da5b44cfe5d2b89fb36fda28"},
+			expectedDeleted: []*destination.Data{
+				{APIVersion: "v1", ItemFamily: "family", Name: "1", OperationTime: "2024-06-01T12:00:00Z"},
+			},
+		},
+		"same version is skipped": {
+			from:    testMapper(t, 1, "{{ .id }}", ""),
+			to:      testMapper(t, 1, "{{ .name }}", ""),
+			records: []source.Data{item},
+		},
+		"unchanged identifier is skipped": {
+			from:    testMapper(t, 1, "{{ .id }}", ""),
+			to:      testMapper(t, 2, "{{ .id }}", ""),
+			records: []source.Data{item},
+		},
+		"delete records are skipped": {
+			from: testMapper(t, 1, "{{ .id }}", ""),
+			to:   testMapper(t, 2, "{{ .name }}", ""),
+			records: []source.Data{
+				{Type: "type1", Operation: source.DataOperationDelete, Values: item.Values, Time: testTime},
+			},
+		},
+		"changed extra identifier deletes the old extra": {
+			from:             testMapper(t, 1, "{{ .id }}", "", testRelationship("rel-{{ .id }}")),
+			to:               testMapper(t, 2, "{{ .id }}", "", testRelationship("rel-{{ .name }}")),
+			records:          []source.Data{item},
+			expectedUpserted: []string{"1", "rel-item"},
+			expectedDeleted: []*destination.Data{
+				{APIVersion: "relationships/v1", ItemFamily: "relationships", Name: "rel-1", OperationTime: "2024-06-01T12:00:00Z"},
+			},
+		},
+		"old identifiers are resolved from cache and replaced": {
+			from:    testMapper(t, 1, "{{ .missing }}", "{{ .id }}"),
+			to:      testMapper(t, 2, "{{ .name }}", "{{ .id }}"),
+			records: []source.Data{item},
+			cache: func(tb testing.TB) identifiercache.Cache {
+				tb.Helper()
+				cache := identifiercache.NewMemoryCache()
+				require.NoError(tb, cache.Set(identifiercache.Key{Type: "type1", SourceKey: "1"}, identifiercache.Entry{
+					APIVersion: "v1",
+					ItemFamily: "family",
+					Identifier: "cached",
+				}))
+				return cache
+			},
+			expectedUpserted: []string{"item"},
+			expectedDeleted: []*destination.Data{
+				{APIVersion: "v1", ItemFamily: "family", Name: "cached", OperationTime: "2024-06-01T12:00:00Z"},
+			},
+			expectedCache: map[identifiercache.Key]identifiercache.Entry{
+				{Type: "type1", SourceKey: "1"}: {APIVersion: "v1", ItemFamily: "family", Identifier: "item"},
+			},
+		},
+		"identifier keyed cache is moved to the new identifier": {
+			from:    testMapper(t, 1, "{{ .id }}", ""),
+			to:      testMapper(t, 2, "{{ .name }}", ""),
+			records: []source.Data{item},
+			cache: func(tb testing.TB) identifiercache.Cache {
+				tb.Helper()
+				cache := identifiercache.NewMemoryCache()
+				require.NoError(tb, cache.Set(identifiercache.Key{Type: "type1", SourceKey: "1"}, identifiercache.Entry{
+					APIVersion: "v1",
+					ItemFamily: "family",
+					Identifier: "1",
+				}))
+				return cache
+			},
+			expectedUpserted: []string{"item"},
+			expectedDeleted: []*destination.Data{
+				{APIVersion: "v1", ItemFamily: "family", Name: "1", OperationTime: "2024-06-01T12:00:00Z"},
+			},
+			expectedCache: map[identifiercache.Key]identifiercache.Entry{
+				{Type: "type1", SourceKey: "item"}: {APIVersion: "v1", ItemFamily: "family", Identifier: "item"},
+			},
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var cache identifiercache.Cache
+			if test.cache != nil {
+				cache = test.cache(t)
+			}
+
+			migrator := New(
+				map[string]pipeline.DataMapper{"type1": test.from},
+				map[string]pipeline.DataMapper{"type1": test.to},
+				cache,
+			)
+
+			changes := migrator.Plan(t.Context(), test.records)
+			destination := fakedestination.NewFakeDestination(t)
+			require.NoError(t, migrator.Apply(t.Context(), destination, changes))
+
+			upserted := make([]string, 0, len(destination.SentData))
+			for _, data := range destination.SentData {
+				upserted = append(upserted, data.Name)
+			}
+			if test.expectedUpserted == nil {
+				assert.Empty(t, upserted)
+			} else {
+				assert.Equal(t, test.expectedUpserted, upserted)
+			}
+			assert.Equal(t, test.expectedDeleted, destination.DeletedData)
+
+			for key, expectedEntry := range test.expectedCache {
+				entry, found := cache.Get(key)
+				assert.True(t, found)
+				assert.Equal(t, expectedEntry, entry)
+			}
+			if len(test.expectedCache) > 0 {
+				_, found := cache.Get(identifiercache.Key{Type: "type1", SourceKey: "1"})
+				_, expected := test.expectedCache[identifiercache.Key{Type: "type1", SourceKey: "1"}]
+				assert.Equal(t, expected, found)
+			}
+		})
+	}
+}
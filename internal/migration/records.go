@@ -0,0 +1,67 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package migration
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+const (
+	// maxRecordSize bounds the length of a single data line.
+	maxRecordSize = 10 * 1024 * 1024
+)
+
+// ReadRecords decodes the source data read from r, one JSON object per line with the data type,
+// its values, and optionally the operation and the time of the event. A missing operation is
+// read as an upsert.
+func ReadRecords(r io.Reader) ([]source.Data, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRecordSize)
+
+	records := make([]source.Data, 0)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var data source.Data
+		if err := json.Unmarshal(line, &data); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrMigration, lineNumber, err)
+		}
+
+		records = append(records, data)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMigration, err)
+	}
+
+	return records, nil
+}
+
+// WriteDiff prints a human readable summary of changes to w, listing for every migrated item the
+// items that will be deleted and the ones that will be upserted.
+func WriteDiff(w io.Writer, changes []Change) error {
+	writer := bufio.NewWriter(w)
+	for _, change := range changes {
+		fmt.Fprintf(writer, "%s:\n", change.Type)
+		for _, item := range change.Deleted {
+			fmt.Fprintf(writer, "  - %s %s %s\n", item.APIVersion, item.ItemFamily, item.Identifier)
+		}
+		for _, data := range change.Upserted {
+			fmt.Fprintf(writer, "  + %s %s %s\n", data.APIVersion, data.ItemFamily, data.Name)
+		}
+	}
+
+	fmt.Fprintf(writer, "%d items to migrate\n", len(changes))
+	return writer.Flush()
+}
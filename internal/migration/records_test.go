@@ -0,0 +1,78 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package migration
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mia-platform/ibdm/internal/destination"
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+func TestReadRecords(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		input           string
+		expectedRecords []source.Data
+		expectedErr     string
+	}{
+		"valid records": {
+			input: `{"type":"type1","values":{"id":"1"},"time":"2024-06-01T12:00:00Z"}
+
+{"type":"type1","operation":"delete","values":{"id":"2"}}
+`,
+			expectedRecords: []source.Data{
+				{Type: "type1", Operation: source.DataOperationUpsert, Values: map[string]any{"id": "1"}, Time: testTime},
+				{Type: "type1", Operation: source.DataOperationDelete, Values: map[string]any{"id": "2"}},
+			},
+		},
+		"invalid json": {
+			input:       `{"type":"type1"`,
+			expectedErr: "mapping migration: line 1: unexpected end of JSON input",
+		},
+		"unknown operation": {
+			input:       `{"type":"type1","operation":"patch","values":{}}`,
+			expectedErr: `mapping migration: line 1: unknown operation "patch"`,
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			records, err := ReadRecords(strings.NewReader(test.input))
+			if test.expectedErr != "" {
+				assert.ErrorIs(t, err, ErrMigration)
+				assert.EqualError(t, err, test.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedRecords, records)
+		})
+	}
+}
+
+func TestWriteDiff(t *testing.T) {
+	t.Parallel()
+
+	buffer := new(bytes.Buffer)
+	err := WriteDiff(buffer, []Change{
+		{
+			Type:    "type1",
+			Deleted: []Item{{APIVersion: "v1", ItemFamily: "family", Identifier: "old"}},
+			Upserted: []*destination.Data{
+				{APIVersion: "v1", ItemFamily: "family", Name: "new"},
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "type1:\n  - v1 family old\n  + v1 family new\n1 items to migrate\n", buffer.String())
+}
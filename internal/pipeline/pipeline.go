@@ -5,9 +5,16 @@ package pipeline
 
 import (
 	"context"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"sync/atomic"
 	"time"
 
+	"github.com/mia-platform/ibdm/internal/config"
 	"github.com/mia-platform/ibdm/internal/destination"
+	"github.com/mia-platform/ibdm/internal/identifiercache"
 	"github.com/mia-platform/ibdm/internal/logger"
 	"github.com/mia-platform/ibdm/internal/mapper"
 	"github.com/mia-platform/ibdm/internal/server"
@@ -25,33 +32,129 @@ type dataPipeline = func(ctx context.Context, channel chan<- source.Data) error
 type DataMapper struct {
 	APIVersion string
 	ItemFamily string
+	Version    int
 	Extra      source.Extra
 	Mapper     mapper.Mapper
+	// Source locates the mapping configuration, it is used to report mapping errors.
+	Source config.Source
+	// SourceKey is optional, when set the identifiers generated for each item are cached under
+	// its source-native key and used to resolve delete operations.
+	SourceKey mapper.SourceKeyMapper
+	// Tombstone is optional, when set delete operations mark the items as deleted with the
+	// rendered tombstone fields instead of removing them from the destination.
+	Tombstone mapper.TombstoneMapper
 }
 
 // Pipeline orchestrates the flow from a source through mappers into a destination.
 type Pipeline struct {
-	source        any
-	mappers       map[string]DataMapper
-	mapperTypes   map[string]source.Extra
-	destination   destination.Sender
-	serverCreator func(ctx context.Context) (server.Server, error)
+	source          any
+	mappings        atomic.Pointer[mappingSet]
+	destination     destination.Sender
+	identifierCache identifiercache.Cache
+	recorder        Recorder
+	deadLetter      DeadLetter
+	serverCreator   func(ctx context.Context) (server.Server, error)
 }
 
-// New wires together the given source, mappers, and destination into a Pipeline.
-func New(ctx context.Context, src any, mappers map[string]DataMapper, destination destination.Sender) (*Pipeline, error) {
+// Recorder receives every data emitted by the source before it is mapped.
+type Recorder interface {
+	Record(data source.Data) error
+}
+
+// DeadLetter receives the data that cannot be mapped together with the mapping error, which
+// carries the position of the failing template when known.
+type DeadLetter interface {
+	RecordFailure(data source.Data, err error) error
+}
+
+// mappingSet holds the mappers in use by the pipeline together with the data types they cover,
+// they are always replaced as a whole so items are never mapped with a mix of old and new mappers.
+type mappingSet struct {
+	mappers     map[string]DataMapper
+	mapperTypes map[string]source.Extra
+}
+
+// newMappingSet builds a mappingSet for mappers.
+func newMappingSet(mappers map[string]DataMapper) *mappingSet {
 	mapperTypes := make(map[string]source.Extra, len(mappers))
 	for dataType, mapping := range mappers {
 		mapperTypes[dataType] = mapping.Extra
 	}
 
-	return &Pipeline{
-		source:        src,
-		mappers:       mappers,
-		mapperTypes:   mapperTypes,
-		destination:   destination,
-		serverCreator: server.NewServer,
-	}, nil
+	return &mappingSet{
+		mappers:     mappers,
+		mapperTypes: mapperTypes,
+	}
+}
+
+// Option customizes a Pipeline built with New.
+type Option func(*Pipeline)
+
+// WithIdentifierCache sets the cache used to remember the identifiers generated for each item.
+// When not set, the pipeline keeps them in memory for its whole lifetime.
+func WithIdentifierCache(cache identifiercache.Cache) Option {
+	return func(p *Pipeline) {
+		p.identifierCache = cache
+	}
+}
+
+// WithRecorder sets a recorder that receives the raw data emitted by the source, including the
+// data of types that are not mapped.
+func WithRecorder(recorder Recorder) Option {
+	return func(p *Pipeline) {
+		p.recorder = recorder
+	}
+}
+
+// WithDeadLetter sets a dead letter that receives the data that cannot be mapped, so that it can be
+// inspected and replayed once the mappings are fixed.
+func WithDeadLetter(deadLetter DeadLetter) Option {
+	return func(p *Pipeline) {
+		p.deadLetter = deadLetter
+	}
+}
+
+// New wires together the given source, mappers, and destination into a Pipeline.
+func New(ctx context.Context, src any, mappers map[string]DataMapper, destination destination.Sender, options ...Option) (*Pipeline, error) {
+	pipeline := &Pipeline{
+		source:          src,
+		destination:     destination,
+		identifierCache: identifiercache.NewMemoryCache(),
+		serverCreator:   server.NewServer,
+	}
+	pipeline.mappings.Store(newMappingSet(mappers))
+
+	for _, option := range options {
+		option(pipeline)
+	}
+
+	return pipeline, nil
+}
+
+// UpdateMappers replaces the mappers used for the items received from now on.
+// When the set of mapped data types changes the source is notified of the new types; sources that
+// do not implement source.TypesUpdatableSource cannot start or stop receiving a type, so the
+// mappers are not replaced and an error is returned.
+func (p *Pipeline) UpdateMappers(ctx context.Context, mappers map[string]DataMapper) error {
+	log := logger.FromContext(ctx).WithName(loggerName)
+
+	newMappings := newMappingSet(mappers)
+	typesChanged := !reflect.DeepEqual(p.mappings.Load().mapperTypes, newMappings.mapperTypes)
+	updatableSource, updatable := p.source.(source.TypesUpdatableSource)
+	if typesChanged && !updatable {
+		return &unsupportedSourceError{
+			Message: "source does not support updating its data types, restart it to apply the changed types",
+		}
+	}
+
+	p.mappings.Store(newMappings)
+	log.Info("mappings updated", "types", slices.Sorted(maps.Keys(newMappings.mapperTypes)))
+	if !typesChanged {
+		return nil
+	}
+
+	log.Debug("notifying source of the updated data types")
+	return updatableSource.UpdateTypes(ctx, newMappings.mapperTypes)
 }
 
 // Start begins streaming data from a source.EventSource or source.WebhookSource.
@@ -80,17 +183,17 @@ func (p *Pipeline) Start(ctx context.Context) error {
 				}
 				log.Trace("server closed")
 			}()
-			return streamSource.StartEventStream(ctx, p.mapperTypes, channel)
+			return streamSource.StartEventStream(ctx, p.mappings.Load().mapperTypes, channel)
 		}
 	case isWebhook:
 		dataPipeline = func(ctx context.Context, channel chan<- source.Data) error {
 			// server start here and keeps pipeline alive, server error = pipeline error
-			webhook, err := webhookSource.GetWebhook(ctx, p.mapperTypes, channel)
+			webhook, err := webhookSource.GetWebhook(ctx, p.mappings.Load().mapperTypes, channel)
 			if err != nil {
 				return err
 			}
 			log.Trace("registering webhook")
-			server.AddRoute(webhook.Method, webhook.Path, webhook.Handler)
+			server.AddRoute(webhook.Method, webhook.Path, withWebhookPath(webhook.Handler))
 			log.Trace("registered webhook, starting server")
 			log.Trace("starting server")
 			return server.Start()
@@ -108,6 +211,14 @@ func (p *Pipeline) Start(ctx context.Context) error {
 	return err
 }
 
+// withWebhookPath wraps handler to pass it the path of the request handled by the server, which
+// the webhooks registered with a wildcard path use to route the requests they receive.
+func withWebhookPath(handler source.WebhookHandler) source.WebhookHandler {
+	return func(ctx context.Context, headers http.Header, body []byte) error {
+		return handler(source.ContextWithWebhookPath(ctx, server.RequestPathFromContext(ctx)), headers, body)
+	}
+}
+
 // Sync performs a one-off synchronization using a source.SyncableSource.
 func (p *Pipeline) Sync(ctx context.Context) error {
 	log := logger.FromContext(ctx).WithName(loggerName)
@@ -121,7 +232,7 @@ func (p *Pipeline) Sync(ctx context.Context) error {
 
 	log.Trace("starting data synchronization")
 	err := p.runDataPipeline(ctx, func(ctx context.Context, channel chan<- source.Data) error {
-		return syncSource.StartSyncProcess(ctx, p.mapperTypes, channel)
+		return syncSource.StartSyncProcess(ctx, p.mappings.Load().mapperTypes, channel)
 	})
 	log.Trace("synchronization finished")
 	return err
@@ -173,7 +284,12 @@ func (p *Pipeline) mappingData(ctx context.Context, channel <-chan source.Data)
 			if !ok {
 				return
 			}
-			dataMapper, found := p.mappers[data.Type]
+			if p.recorder != nil {
+				if err := p.recorder.Record(data); err != nil {
+					log.Error("error recording source data", "type", data.Type, "error", err)
+				}
+			}
+			dataMapper, found := p.mappings.Load().mappers[data.Type]
 			if !found {
 				log.Debug("data type not mapped, skipping", "type", data.Type)
 				continue
@@ -191,37 +307,77 @@ func (p *Pipeline) mappingData(ctx context.Context, channel <-chan source.Data)
 			}
 			switch data.Operation {
 			case source.DataOperationUpsert:
-				output, extra, err := dataMapper.Mapper.ApplyTemplates(data.Values, parentResourceInfo)
-				if err != nil {
-					log.Error("error applying mapper templates", "type", data.Type, "error", err)
-					continue
-				}
-				dataToSend.Name = output.Identifier
-				if output.Metadata != nil {
-					dataToSend.Metadata = output.Metadata
-				}
-				dataToSend.Data = output.Spec
-				if err := p.destination.SendData(ctx, dataToSend); err != nil {
-					log.Error("error sending data to destination", "type", data.Type, "error", err)
-					continue
-				}
-				p.upsertExtraMappedData(ctx, data, extra)
+				p.upsertData(ctx, data, dataMapper, dataToSend, parentResourceInfo)
 			case source.DataOperationDelete:
-				identifier, extra, err := dataMapper.Mapper.ApplyIdentifierTemplate(data.Values)
-				dataToSend.Name = identifier
-				if err != nil {
-					log.Error("error applying mapper templates", "type", data.Type, "error", err)
-					continue
-				}
-				if err := p.destination.DeleteData(ctx, dataToSend); err != nil {
-					log.Error("error deleting data from destination", "type", data.Type, "error", err)
-					continue
-				}
-				p.deleteExtraMappedData(ctx, data, extra)
+				p.deleteData(ctx, data, dataMapper, dataToSend)
 			}
+		}
+	}
+}
+
+// upsertData maps data, sends it to the destination with its extras, and caches the generated
+// identifiers.
+func (p *Pipeline) upsertData(ctx context.Context, data source.Data, dataMapper DataMapper, dataToSend *destination.Data, parentResourceInfo mapper.ParentItemInfo) {
+	log := logger.FromContext(ctx).WithName(loggerName)
+	output, extra, err := dataMapper.Mapper.ApplyTemplates(data.Values, parentResourceInfo)
+	if err != nil {
+		p.mappingFailed(ctx, data, mapper.NewMappingError(err, dataMapper.Source, data.Type, data.Values))
+		return
+	}
+	dataToSend.Name = output.Identifier
+	if output.Metadata != nil {
+		dataToSend.Metadata = output.Metadata
+	}
+	dataToSend.Data = output.Spec
+	if err := p.destination.SendData(ctx, dataToSend); err != nil {
+		log.Error("error sending data to destination", "type", data.Type, "error", err)
+		return
+	}
+	p.upsertExtraMappedData(ctx, data, extra)
+	p.cacheIdentifiers(ctx, data, dataMapper, output, extra)
 
-			log.Trace("data sent", "type", data.Type, "operation", data.Operation.String())
+	log.Trace("data sent", "type", data.Type, "operation", data.Operation.String())
+}
+
+// deleteData deletes the item generated by data and its extras from the destination, or marks it
+// as deleted when the mapping uses the tombstone delete mode.
+func (p *Pipeline) deleteData(ctx context.Context, data source.Data, dataMapper DataMapper, dataToSend *destination.Data) {
+	log := logger.FromContext(ctx).WithName(loggerName)
+	if dataMapper.Tombstone != nil {
+		if err := p.tombstoneData(ctx, data, dataMapper, dataToSend); err != nil {
+			log.Error("error marking data as deleted in destination", "type", data.Type, "error", err)
+			return
 		}
+		log.Trace("data sent", "type", data.Type, "operation", data.Operation.String())
+		return
+	}
+
+	identifier, extra, err := p.deletedItemIdentifiers(ctx, data, dataMapper)
+	dataToSend.Name = identifier
+	if err != nil {
+		p.mappingFailed(ctx, data, mapper.NewMappingError(err, dataMapper.Source, data.Type, data.Values))
+		return
+	}
+	if err := p.destination.DeleteData(ctx, dataToSend); err != nil {
+		log.Error("error deleting data from destination", "type", data.Type, "error", err)
+		return
+	}
+	p.deleteExtraMappedData(ctx, data, extra)
+	p.forgetIdentifiers(ctx, data, dataMapper, identifier)
+
+	log.Trace("data sent", "type", data.Type, "operation", data.Operation.String())
+}
+
+// mappingFailed logs the mapping error of data and sends data to the dead letter, if any.
+func (p *Pipeline) mappingFailed(ctx context.Context, data source.Data, mappingErr *mapper.MappingError) {
+	log := logger.FromContext(ctx).WithName(loggerName)
+	log.Error("error applying mapper templates", mappingErr.LogAttributes()...)
+	if p.deadLetter == nil {
+		return
+	}
+
+	if err := p.deadLetter.RecordFailure(data, mappingErr); err != nil {
+		log.Error("error recording data to the dead letter", "type", data.Type, "error", err)
 	}
 }
 
@@ -262,3 +418,285 @@ func (p *Pipeline) deleteExtraMappedData(ctx context.Context, data source.Data,
 		}
 	}
 }
+
+// itemKey returns the identifier cache key of data. The rendered source key is used when the mapping
+// defines one, otherwise the item is tracked by its identifier. It reports false when the key cannot
+// be computed.
+func (p *Pipeline) itemKey(ctx context.Context, data source.Data, dataMapper DataMapper, identifier string) (identifiercache.Key, bool) {
+	if dataMapper.SourceKey == nil {
+		return identifiercache.Key{Type: data.Type, SourceKey: identifier}, identifier != ""
+	}
+
+	sourceKey, err := dataMapper.SourceKey.ApplySourceKeyTemplate(data.Values)
+	if err != nil {
+		log := logger.FromContext(ctx).WithName(loggerName)
+		mappingErr := mapper.NewMappingError(err, dataMapper.Source, data.Type, data.Values)
+		log.Debug("cannot render source key", mappingErr.LogAttributes()...)
+		return identifiercache.Key{}, false
+	}
+
+	return identifiercache.Key{Type: data.Type, SourceKey: sourceKey}, true
+}
+
+// cacheIdentifiers remembers the identifiers generated by an upsert operation under the item key.
+// Extras emitted by the previous upsert of the same item and not generated anymore are deleted
+// when their delete policy allows it; the ones that cannot be deleted are kept in the cache to be
+// retried on the next upsert.
+// When the mapping uses the tombstone delete mode the payloads are cached too, and stale extras
+// are marked as deleted instead of being removed.
+// Items are cached only when they are needed to resolve a later operation, see needsCache, so
+// mappings without a source key, a tombstone or cascading extras do not grow the cache.
+func (p *Pipeline) cacheIdentifiers(ctx context.Context, data source.Data, dataMapper DataMapper, output mapper.MappedData, extra []mapper.ExtraMappedData) {
+	log := logger.FromContext(ctx).WithName(loggerName)
+	key, ok := p.itemKey(ctx, data, dataMapper, output.Identifier)
+	if !ok {
+		return
+	}
+
+	keepPayloads := dataMapper.Tombstone != nil
+	entry := identifiercache.Entry{
+		APIVersion: dataMapper.APIVersion,
+		ItemFamily: dataMapper.ItemFamily,
+		Identifier: output.Identifier,
+	}
+	if keepPayloads {
+		entry.Metadata = output.Metadata
+		entry.Spec = output.Spec
+	}
+	for _, extraOutput := range extra {
+		cachedExtra := identifiercache.Extra{
+			APIVersion:   extraOutput.APIVersion,
+			ItemFamily:   extraOutput.ItemFamily,
+			Identifier:   extraOutput.Identifier,
+			DeletePolicy: extraOutput.DeletePolicy,
+		}
+		if keepPayloads {
+			cachedExtra.Spec = extraOutput.Spec
+		}
+		entry.Extras = append(entry.Extras, cachedExtra)
+	}
+
+	previous, found := p.identifierCache.Get(key)
+	if !found && !needsCache(dataMapper, entry.Extras) {
+		return
+	}
+
+	if found {
+		for _, staleExtra := range staleExtras(previous.Extras, entry.Extras) {
+			log.Trace("removing stale extra data", "type", staleExtra.ItemFamily, "identifier", staleExtra.Identifier)
+			if err := p.removeStaleExtra(ctx, data, dataMapper, staleExtra); err != nil {
+				log.Error("error removing stale extra data from destination", "type", staleExtra.ItemFamily, "error", err)
+				entry.Extras = append(entry.Extras, staleExtra)
+			}
+		}
+	}
+
+	if !needsCache(dataMapper, entry.Extras) {
+		if err := p.identifierCache.Delete(key); err != nil {
+			log.Error("error removing cached identifiers", "type", data.Type, "error", err)
+		}
+		return
+	}
+
+	if err := p.identifierCache.Set(key, entry); err != nil {
+		log.Error("error caching identifiers", "type", data.Type, "error", err)
+	}
+}
+
+// needsCache reports whether the entry of an item of dataMapper with extras must be cached: to
+// resolve the deletes by source key, to build the tombstones, or to find the cascading extras
+// that are not generated anymore.
+func needsCache(dataMapper DataMapper, extras []identifiercache.Extra) bool {
+	return dataMapper.SourceKey != nil || dataMapper.Tombstone != nil ||
+		slices.ContainsFunc(extras, func(extra identifiercache.Extra) bool {
+			return extra.DeletePolicy == config.DeletePolicyCascade
+		})
+}
+
+// removeStaleExtra deletes an extra item not generated anymore by its parent, or marks it as deleted
+// when the mapping uses the tombstone delete mode.
+func (p *Pipeline) removeStaleExtra(ctx context.Context, data source.Data, dataMapper DataMapper, staleExtra identifiercache.Extra) error {
+	extraData := &destination.Data{
+		APIVersion:    staleExtra.APIVersion,
+		ItemFamily:    staleExtra.ItemFamily,
+		OperationTime: data.Timestamp(),
+		Name:          staleExtra.Identifier,
+	}
+
+	if dataMapper.Tombstone == nil {
+		return p.destination.DeleteData(ctx, extraData)
+	}
+
+	tombstoneFields, err := dataMapper.Tombstone.ApplyTombstoneTemplates(data.Values, data.Timestamp())
+	if err != nil {
+		return err
+	}
+
+	extraData.Data = withTombstoneFields(staleExtra.Spec, tombstoneFields)
+	return p.destination.SendData(ctx, extraData)
+}
+
+// staleExtras returns the extras in previous with the cascade delete policy that are not part of current.
+func staleExtras(previous, current []identifiercache.Extra) []identifiercache.Extra {
+	stale := make([]identifiercache.Extra, 0)
+	for _, previousExtra := range previous {
+		if previousExtra.DeletePolicy != config.DeletePolicyCascade {
+			continue
+		}
+
+		found := slices.ContainsFunc(current, func(currentExtra identifiercache.Extra) bool {
+			return currentExtra.APIVersion == previousExtra.APIVersion &&
+				currentExtra.ItemFamily == previousExtra.ItemFamily &&
+				currentExtra.Identifier == previousExtra.Identifier
+		})
+		if !found {
+			stale = append(stale, previousExtra)
+		}
+	}
+
+	return stale
+}
+
+// forgetIdentifiers removes the cached identifiers of a deleted item.
+func (p *Pipeline) forgetIdentifiers(ctx context.Context, data source.Data, dataMapper DataMapper, identifier string) {
+	key, ok := p.itemKey(ctx, data, dataMapper, identifier)
+	if !ok {
+		return
+	}
+
+	if err := p.identifierCache.Delete(key); err != nil {
+		log := logger.FromContext(ctx).WithName(loggerName)
+		log.Error("error removing cached identifiers", "type", data.Type, "error", err)
+	}
+}
+
+// deletedItemIdentifiers resolves the identifier and the cascading extras of a deleted item.
+func (p *Pipeline) deletedItemIdentifiers(ctx context.Context, data source.Data, dataMapper DataMapper) (string, []mapper.ExtraMappedData, error) {
+	entry, _, err := p.deletedItemEntry(ctx, data, dataMapper)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return entry.Identifier, cascadingExtras(entry.Extras), nil
+}
+
+// deletedItemEntry resolves the cached entry of a deleted item, reporting whether it was found in
+// the cache.
+// When the mapping defines a source key, the entry cached during the last upsert takes precedence
+// and the identifier templates are applied to the delete payload only when the item is not found
+// in the cache. Otherwise the cached entry of the templated identifier is used when available.
+func (p *Pipeline) deletedItemEntry(ctx context.Context, data source.Data, dataMapper DataMapper) (identifiercache.Entry, bool, error) {
+	log := logger.FromContext(ctx).WithName(loggerName)
+	if dataMapper.SourceKey != nil {
+		if key, ok := p.itemKey(ctx, data, dataMapper, ""); ok {
+			if entry, found := p.identifierCache.Get(key); found {
+				log.Trace("identifiers resolved from cache", "type", data.Type, "sourceKey", key.SourceKey)
+				return entry, true, nil
+			}
+		}
+	}
+
+	identifier, extras, err := dataMapper.Mapper.ApplyIdentifierTemplate(data.Values)
+	if err != nil {
+		return identifiercache.Entry{}, false, err
+	}
+
+	if dataMapper.SourceKey == nil {
+		if entry, found := p.identifierCache.Get(identifiercache.Key{Type: data.Type, SourceKey: identifier}); found {
+			log.Trace("extra identifiers resolved from cache", "type", data.Type, "identifier", identifier)
+			return entry, true, nil
+		}
+	}
+
+	entry := identifiercache.Entry{
+		APIVersion: dataMapper.APIVersion,
+		ItemFamily: dataMapper.ItemFamily,
+		Identifier: identifier,
+	}
+	for _, extra := range extras {
+		entry.Extras = append(entry.Extras, identifiercache.Extra{
+			APIVersion:   extra.APIVersion,
+			ItemFamily:   extra.ItemFamily,
+			Identifier:   extra.Identifier,
+			DeletePolicy: extra.DeletePolicy,
+		})
+	}
+
+	return entry, false, nil
+}
+
+// tombstoneData marks a deleted item and its cascading extras as deleted, upserting them with the
+// tombstone fields merged over the last payload found in the cache. Items missing from the cache
+// are skipped, since upserting only the tombstone fields would overwrite their whole payload.
+func (p *Pipeline) tombstoneData(ctx context.Context, data source.Data, dataMapper DataMapper, dataToSend *destination.Data) error {
+	log := logger.FromContext(ctx).WithName(loggerName)
+	tombstoneFields, err := dataMapper.Tombstone.ApplyTombstoneTemplates(data.Values, data.Timestamp())
+	if err != nil {
+		return err
+	}
+
+	entry, found, err := p.deletedItemEntry(ctx, data, dataMapper)
+	if err != nil {
+		return err
+	}
+	if !found {
+		log.Warn("deleted item not found in the identifier cache, skipping tombstone", "type", data.Type, "identifier", entry.Identifier)
+		return nil
+	}
+
+	dataToSend.Name = entry.Identifier
+	dataToSend.Metadata = entry.Metadata
+	dataToSend.Data = withTombstoneFields(entry.Spec, tombstoneFields)
+	if err := p.destination.SendData(ctx, dataToSend); err != nil {
+		return err
+	}
+
+	for _, extra := range entry.Extras {
+		if extra.DeletePolicy != config.DeletePolicyCascade {
+			continue
+		}
+
+		extraDataToSend := &destination.Data{
+			APIVersion:    extra.APIVersion,
+			ItemFamily:    extra.ItemFamily,
+			OperationTime: data.Timestamp(),
+			Name:          extra.Identifier,
+			Data:          withTombstoneFields(extra.Spec, tombstoneFields),
+		}
+		log.Trace("sending data", "type", extra.ItemFamily, "operation", data.Operation.String())
+		if err := p.destination.SendData(ctx, extraDataToSend); err != nil {
+			log.Error("error marking extra data as deleted in destination", "type", extra.ItemFamily, "error", err)
+			continue
+		}
+	}
+
+	p.forgetIdentifiers(ctx, data, dataMapper, entry.Identifier)
+	return nil
+}
+
+// withTombstoneFields returns a copy of spec with the tombstone fields set.
+func withTombstoneFields(spec, tombstoneFields map[string]any) map[string]any {
+	fields := make(map[string]any, len(spec)+len(tombstoneFields))
+	maps.Copy(fields, spec)
+	maps.Copy(fields, tombstoneFields)
+	return fields
+}
+
+// cascadingExtras converts the cached extras with the cascade delete policy into mapped data.
+func cascadingExtras(cached []identifiercache.Extra) []mapper.ExtraMappedData {
+	extras := make([]mapper.ExtraMappedData, 0, len(cached))
+	for _, extra := range cached {
+		if extra.DeletePolicy != config.DeletePolicyCascade {
+			continue
+		}
+
+		extras = append(extras, mapper.ExtraMappedData{
+			APIVersion:   extra.APIVersion,
+			ItemFamily:   extra.ItemFamily,
+			DeletePolicy: extra.DeletePolicy,
+			Identifier:   extra.Identifier,
+		})
+	}
+
+	return extras
+}
@@ -7,6 +7,7 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"path/filepath"
 	"strconv"
 	"testing"
 	"time"
@@ -17,6 +18,7 @@ import (
 	"github.com/mia-platform/ibdm/internal/config"
 	"github.com/mia-platform/ibdm/internal/destination"
 	fakedestination "github.com/mia-platform/ibdm/internal/destination/fake"
+	"github.com/mia-platform/ibdm/internal/identifiercache"
 	"github.com/mia-platform/ibdm/internal/mapper"
 	"github.com/mia-platform/ibdm/internal/server"
 	fakeserver "github.com/mia-platform/ibdm/internal/server/fake"
@@ -723,6 +725,70 @@ func TestSyncPipeline(t *testing.T) {
 	}
 }
 
+// sliceRecorder collects every recorded data and every failure.
+type sliceRecorder struct {
+	recorded []source.Data
+	failures []error
+}
+
+func (r *sliceRecorder) Record(data source.Data) error {
+	r.recorded = append(r.recorded, data)
+	return nil
+}
+
+func (r *sliceRecorder) RecordFailure(data source.Data, err error) error {
+	r.recorded = append(r.recorded, data)
+	r.failures = append(r.failures, err)
+	return nil
+}
+
+func TestSyncPipelineRecorder(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 1*time.Second)
+	defer cancel()
+
+	data := []source.Data{type1, brokenType, unknownType, type2}
+	recorder := &sliceRecorder{}
+	destination := fakedestination.NewFakeDestination(t)
+	pipeline, err := New(ctx, fakesource.NewFakeSyncableSource(t, data), testMappers(t, nil), destination, WithRecorder(recorder))
+	require.NoError(t, err)
+
+	require.NoError(t, pipeline.Sync(ctx))
+	assert.Equal(t, data, recorder.recorded, "every data is recorded, even when it cannot be mapped")
+	assert.Len(t, destination.SentData, 1)
+	assert.Len(t, destination.DeletedData, 1)
+}
+
+func TestSyncPipelineDeadLetter(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 1*time.Second)
+	defer cancel()
+
+	mappers := testMappers(t, nil)
+	type1Mapper := mappers["type1"]
+	type1Mapper.Source = config.Source{Path: "mappings.yaml", Document: 1}
+	mappers["type1"] = type1Mapper
+
+	deadLetter := &sliceRecorder{}
+	destination := fakedestination.NewFakeDestination(t)
+	data := []source.Data{type1, brokenType, unknownType, type2}
+	pipeline, err := New(ctx, fakesource.NewFakeSyncableSource(t, data), mappers, destination, WithDeadLetter(deadLetter))
+	require.NoError(t, err)
+
+	require.NoError(t, pipeline.Sync(ctx))
+	assert.Equal(t, []source.Data{brokenType}, deadLetter.recorded, "only the data that cannot be mapped is sent to the dead letter")
+	require.Len(t, deadLetter.failures, 1)
+
+	var mappingErr *mapper.MappingError
+	require.ErrorAs(t, deadLetter.failures[0], &mappingErr)
+	assert.Equal(t, "mappings.yaml", mappingErr.Path)
+	assert.Equal(t, 1, mappingErr.Document)
+	assert.Len(t, destination.SentData, 1)
+	assert.Len(t, destination.DeletedData, 1)
+}
+
 func TestSyncPipelineCancellation(t *testing.T) {
 	t.Parallel()
 	ctx, cancel := context.WithCancel(t.Context())
@@ -753,3 +819,545 @@ func TestSyncClosableSource(t *testing.T) {
 	assert.Empty(t, destination.SentData)
 	assert.Empty(t, destination.DeletedData)
 }
+
+func TestPipelineIdentifierCache(t *testing.T) {
+	t.Parallel()
+
+	sourceKeyMapper, err := mapper.NewSourceKeyMapper("{{ .id }}")
+	require.NoError(t, err)
+
+	minimalDelete := source.Data{
+		Type:      "type1",
+		Operation: source.DataOperationDelete,
+		Values: map[string]any{
+			"id": "item1",
+		},
+		Time: testTime,
+	}
+
+	testCases := map[string]struct {
+		data             []source.Data
+		cache            func(tb testing.TB) identifiercache.Cache
+		expectedDeletion []*destination.Data
+	}{
+		"delete with minimal payload is resolved from cache": {
+			data: []source.Data{type1, minimalDelete},
+			cache: func(tb testing.TB) identifiercache.Cache {
+				tb.Helper()
+				return identifiercache.NewMemoryCache()
+			},
+			expectedDeletion: []*destination.Data{
+				{
+					APIVersion:    "v1",
+					ItemFamily:    "family",
+					Name:          "item1",
+					OperationTime: "2024-06-01T12:00:00Z",
+				},
+				{
+					APIVersion:    "relationships/v1",
+					ItemFamily:    "relationships",
+					Name:          "relationship--value1--value2--dependency",
+					OperationTime: "2024-06-01T12:00:00Z",
+				},
+			},
+		},
+		"delete with minimal payload is resolved from persisted cache": {
+			data: []source.Data{minimalDelete},
+			cache: func(tb testing.TB) identifiercache.Cache {
+				tb.Helper()
+				cache, err := identifiercache.NewFileCache(filepath.Join(tb.TempDir(), "cache.jsonl"))
+				require.NoError(tb, err)
+				require.NoError(tb, cache.Set(identifiercache.Key{Type: "type1", SourceKey: "item1"}, identifiercache.Entry{
+					APIVersion: "v1",
+					ItemFamily: "family",
+					Identifier: "cached-item1",
+					Extras: []identifiercache.Extra{
+						{APIVersion: "relationships/v1", ItemFamily: "relationships", Identifier: "cascade-relationship", DeletePolicy: config.DeletePolicyCascade},
+						{APIVersion: "relationships/v1", ItemFamily: "relationships", Identifier: "kept-relationship", DeletePolicy: config.DeletePolicyNone},
+					},
+				}))
+				tb.Cleanup(func() { cache.Close() })
+				return cache
+			},
+			expectedDeletion: []*destination.Data{
+				{
+					APIVersion:    "v1",
+					ItemFamily:    "family",
+					Name:          "cached-item1",
+					OperationTime: "2024-06-01T12:00:00Z",
+				},
+				{
+					APIVersion:    "relationships/v1",
+					ItemFamily:    "relationships",
+					Name:          "cascade-relationship",
+					OperationTime: "2024-06-01T12:00:00Z",
+				},
+			},
+		},
+		"cache miss falls back to identifier templates": {
+			data: []source.Data{type1D},
+			cache: func(tb testing.TB) identifiercache.Cache {
+				tb.Helper()
+				return identifiercache.NewMemoryCache()
+			},
+			expectedDeletion: []*destination.Data{
+				{
+					APIVersion:    "v1",
+					ItemFamily:    "family",
+					Name:          "item1",
+					OperationTime: "2024-06-01T12:00:00Z",
+				},
+				{
+					APIVersion:    "relationships/v1",
+					ItemFamily:    "relationships",
+					Name:          "relationship--value1--value2--dependency",
+					OperationTime: "2024-06-01T12:00:00Z",
+				},
+			},
+		},
+		"second delete does not reuse the forgotten identifiers": {
+			data: []source.Data{type1, minimalDelete, minimalDelete},
+			cache: func(tb testing.TB) identifiercache.Cache {
+				tb.Helper()
+				return identifiercache.NewMemoryCache()
+			},
+			expectedDeletion: []*destination.Data{
+				{
+					APIVersion:    "v1",
+					ItemFamily:    "family",
+					Name:          "item1",
+					OperationTime: "2024-06-01T12:00:00Z",
+				},
+				{
+					APIVersion:    "relationships/v1",
+					ItemFamily:    "relationships",
+					Name:          "relationship--value1--value2--dependency",
+					OperationTime: "2024-06-01T12:00:00Z",
+				},
+			},
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx, cancel := context.WithTimeout(t.Context(), 1*time.Second)
+			defer cancel()
+
+			mappers := testMappers(t, getMappingsExtra(t, true, config.DeletePolicyCascade, 1))
+			dataMapper := mappers["type1"]
+			dataMapper.SourceKey = sourceKeyMapper
+			mappers["type1"] = dataMapper
+
+			destination := fakedestination.NewFakeDestination(t)
+			pipeline, err := New(ctx, fakesource.NewFakeSyncableSource(t, test.data), mappers, destination, WithIdentifierCache(test.cache(t)))
+			require.NoError(t, err)
+
+			require.NoError(t, pipeline.Sync(ctx))
+			assert.Equal(t, test.expectedDeletion, destination.DeletedData)
+		})
+	}
+}
+
+func TestPipelineIdentifierCacheEntries(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		deletePolicy string
+		useSourceKey bool
+		expectCached bool
+	}{
+		"items without source key and cascading extras are not cached": {
+			deletePolicy: config.DeletePolicyNone,
+		},
+		"items with cascading extras are cached": {
+			deletePolicy: config.DeletePolicyCascade,
+			expectCached: true,
+		},
+		"items with source key are cached": {
+			deletePolicy: config.DeletePolicyNone,
+			useSourceKey: true,
+			expectCached: true,
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx, cancel := context.WithTimeout(t.Context(), 1*time.Second)
+			defer cancel()
+
+			mappers := testMappers(t, getMappingsExtra(t, true, test.deletePolicy, 1))
+			if test.useSourceKey {
+				sourceKeyMapper, err := mapper.NewSourceKeyMapper("{{ .id }}")
+				require.NoError(t, err)
+				dataMapper := mappers["type1"]
+				dataMapper.SourceKey = sourceKeyMapper
+				mappers["type1"] = dataMapper
+			}
+
+			cache := identifiercache.NewMemoryCache()
+			destination := fakedestination.NewFakeDestination(t)
+			pipeline, err := New(ctx, fakesource.NewFakeSyncableSource(t, []source.Data{type1}), mappers, destination, WithIdentifierCache(cache))
+			require.NoError(t, err)
+
+			require.NoError(t, pipeline.Sync(ctx))
+			_, found := cache.Get(identifiercache.Key{Type: "type1", SourceKey: "item1"})
+			assert.Equal(t, test.expectCached, found)
+		})
+	}
+}
+
+func TestPipelineStaleExtras(t *testing.T) {
+	t.Parallel()
+
+	type1Updated := source.Data{
+		Type:      "type1",
+		Operation: source.DataOperationUpsert,
+		Values: map[string]any{
+			"id":     "item1",
+			"field1": "value1",
+			"field2": "changed",
+		},
+		Time: testTime,
+	}
+
+	testCases := map[string]struct {
+		data             []source.Data
+		deletePolicy     string
+		useSourceKey     bool
+		expectedDeletion []*destination.Data
+	}{
+		"changed extra identifier deletes the previous extra": {
+			data:         []source.Data{type1, type1Updated},
+			deletePolicy: config.DeletePolicyCascade,
+			expectedDeletion: []*destination.Data{
+				{
+					APIVersion:    "relationships/v1",
+					ItemFamily:    "relationships",
+					Name:          "relationship--value1--value2--dependency",
+					OperationTime: "2024-06-01T12:00:00Z",
+				},
+			},
+		},
+		"changed extra identifier with source key deletes the previous extra": {
+			data:         []source.Data{type1, type1Updated},
+			deletePolicy: config.DeletePolicyCascade,
+			useSourceKey: true,
+			expectedDeletion: []*destination.Data{
+				{
+					APIVersion:    "relationships/v1",
+					ItemFamily:    "relationships",
+					Name:          "relationship--value1--value2--dependency",
+					OperationTime: "2024-06-01T12:00:00Z",
+				},
+			},
+		},
+		"extra with none delete policy is kept": {
+			data:         []source.Data{type1, type1Updated},
+			deletePolicy: config.DeletePolicyNone,
+		},
+		"unchanged extra is not deleted": {
+			data:         []source.Data{type1, type1},
+			deletePolicy: config.DeletePolicyCascade,
+		},
+		"delete after update removes only the current extra": {
+			data:         []source.Data{type1, type1Updated, type1D},
+			deletePolicy: config.DeletePolicyCascade,
+			expectedDeletion: []*destination.Data{
+				{
+					APIVersion:    "relationships/v1",
+					ItemFamily:    "relationships",
+					Name:          "relationship--value1--value2--dependency",
+					OperationTime: "2024-06-01T12:00:00Z",
+				},
+				{
+					APIVersion:    "v1",
+					ItemFamily:    "family",
+					Name:          "item1",
+					OperationTime: "2024-06-01T12:00:00Z",
+				},
+				{
+					APIVersion:    "relationships/v1",
+					ItemFamily:    "relationships",
+					Name:          "relationship--value1--changed--dependency",
+					OperationTime: "2024-06-01T12:00:00Z",
+				},
+			},
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx, cancel := context.WithTimeout(t.Context(), 1*time.Second)
+			defer cancel()
+
+			mappers := testMappers(t, getMappingsExtra(t, true, test.deletePolicy, 1))
+			if test.useSourceKey {
+				sourceKeyMapper, err := mapper.NewSourceKeyMapper("{{ .id }}")
+				require.NoError(t, err)
+				dataMapper := mappers["type1"]
+				dataMapper.SourceKey = sourceKeyMapper
+				mappers["type1"] = dataMapper
+			}
+
+			destination := fakedestination.NewFakeDestination(t)
+			pipeline, err := New(ctx, fakesource.NewFakeSyncableSource(t, test.data), mappers, destination)
+			require.NoError(t, err)
+
+			require.NoError(t, pipeline.Sync(ctx))
+			assert.Equal(t, test.expectedDeletion, destination.DeletedData)
+		})
+	}
+}
+
+func TestPipelineTombstone(t *testing.T) {
+	t.Parallel()
+
+	cachedRelationship := map[string]any{
+		"sourceRef": "urn:mia-platform-catalog:resource.custom-platform:v1:Family1:null:value2",
+		"targetRef": "urn:mia-platform-catalog:mia-platform.eu:v1:Family:null:item1",
+		"typeRef":   "urn:mia-platform-catalog:mia-platform.eu:v1:RelationshipType:null:dependency",
+		"status":    "deleted",
+		"deletedAt": "2024-06-01T12:00:00Z",
+	}
+
+	type1Updated := source.Data{
+		Type:      "type1",
+		Operation: source.DataOperationUpsert,
+		Values: map[string]any{
+			"id":     "item1",
+			"field1": "value1",
+			"field2": "changed",
+		},
+		Time: testTime,
+	}
+
+	testCases := map[string]struct {
+		data              []source.Data
+		expectedSentNames []string
+		expectedTombstone []*destination.Data
+	}{
+		"delete marks the cached item and extras as deleted": {
+			data:              []source.Data{type1, type1D},
+			expectedSentNames: []string{"item1", "relationship--value1--value2--dependency"},
+			expectedTombstone: []*destination.Data{
+				{
+					APIVersion:    "v1",
+					ItemFamily:    "family",
+					Name:          "item1",
+					Metadata:      map[string]any{},
+					Data:          map[string]any{"field1": "value1", "field2": "value2", "status": "deleted", "deletedAt": "2024-06-01T12:00:00Z"},
+					OperationTime: "2024-06-01T12:00:00Z",
+				},
+				{
+					APIVersion:    "relationships/v1",
+					ItemFamily:    "relationships",
+					Name:          "relationship--value1--value2--dependency",
+					Data:          cachedRelationship,
+					OperationTime: "2024-06-01T12:00:00Z",
+				},
+			},
+		},
+		"delete with an empty cache is skipped": {
+			data:              []source.Data{type1D},
+			expectedSentNames: []string{},
+			expectedTombstone: []*destination.Data{},
+		},
+		"delete of an already retired item is skipped": {
+			data:              []source.Data{type1, type1D, type1D},
+			expectedSentNames: []string{"item1", "relationship--value1--value2--dependency"},
+			expectedTombstone: []*destination.Data{
+				{
+					APIVersion:    "v1",
+					ItemFamily:    "family",
+					Name:          "item1",
+					Metadata:      map[string]any{},
+					Data:          map[string]any{"field1": "value1", "field2": "value2", "status": "deleted", "deletedAt": "2024-06-01T12:00:00Z"},
+					OperationTime: "2024-06-01T12:00:00Z",
+				},
+				{
+					APIVersion:    "relationships/v1",
+					ItemFamily:    "relationships",
+					Name:          "relationship--value1--value2--dependency",
+					Data:          cachedRelationship,
+					OperationTime: "2024-06-01T12:00:00Z",
+				},
+			},
+		},
+		"stale extra is marked as deleted": {
+			data:              []source.Data{type1, type1Updated},
+			expectedSentNames: []string{"item1", "relationship--value1--value2--dependency", "item1", "relationship--value1--changed--dependency"},
+			expectedTombstone: []*destination.Data{
+				{
+					APIVersion:    "relationships/v1",
+					ItemFamily:    "relationships",
+					Name:          "relationship--value1--value2--dependency",
+					Data:          cachedRelationship,
+					OperationTime: "2024-06-01T12:00:00Z",
+				},
+			},
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx, cancel := context.WithTimeout(t.Context(), 1*time.Second)
+			defer cancel()
+
+			tombstoneMapper, err := mapper.NewTombstoneMapper(nil)
+			require.NoError(t, err)
+			mappers := testMappers(t, getMappingsExtra(t, true, config.DeletePolicyCascade, 1))
+			dataMapper := mappers["type1"]
+			dataMapper.Tombstone = tombstoneMapper
+			mappers["type1"] = dataMapper
+
+			fakeDestination := fakedestination.NewFakeDestination(t)
+			pipeline, err := New(ctx, fakesource.NewFakeSyncableSource(t, test.data), mappers, fakeDestination)
+			require.NoError(t, err)
+
+			require.NoError(t, pipeline.Sync(ctx))
+			assert.Empty(t, fakeDestination.DeletedData)
+			sentNames := make([]string, 0)
+			tombstones := make([]*destination.Data, 0)
+			for _, data := range fakeDestination.SentData {
+				if data.Data["status"] == "deleted" {
+					tombstones = append(tombstones, data)
+					continue
+				}
+				sentNames = append(sentNames, data.Name)
+			}
+			assert.Equal(t, test.expectedSentNames, sentNames)
+			assert.Equal(t, test.expectedTombstone, tombstones)
+		})
+	}
+}
+
+// updatableSource is a syncable source that records the data types it is notified of.
+type updatableSource struct {
+	source.SyncableSource
+
+	err          error
+	updatedTypes []map[string]source.Extra
+}
+
+func (s *updatableSource) UpdateTypes(_ context.Context, types map[string]source.Extra) error {
+	s.updatedTypes = append(s.updatedTypes, types)
+	return s.err
+}
+
+func TestPipelineUpdateMappers(t *testing.T) {
+	t.Parallel()
+
+	onlyType1 := func(t *testing.T) map[string]DataMapper {
+		t.Helper()
+		mappers := testMappers(t, nil)
+		delete(mappers, "type2")
+		return mappers
+	}
+
+	testCases := map[string]struct {
+		mappers          func(t *testing.T) map[string]DataMapper
+		sourceErr        error
+		expectedTypes    []map[string]source.Extra
+		expectedErr      error
+		expectedDeletion []*destination.Data
+	}{
+		"unchanged types do not notify the source": {
+			mappers: func(t *testing.T) map[string]DataMapper {
+				t.Helper()
+				return testMappers(t, nil)
+			},
+			expectedDeletion: []*destination.Data{
+				{
+					APIVersion:    "v2",
+					ItemFamily:    "family2",
+					Name:          "item2",
+					OperationTime: "2024-06-01T12:00:00Z",
+				},
+			},
+		},
+		"removed types notify the source and are not mapped anymore": {
+			mappers: onlyType1,
+			expectedTypes: []map[string]source.Extra{
+				{"type1": nil},
+			},
+		},
+		"changed extra notify the source": {
+			mappers: func(t *testing.T) map[string]DataMapper {
+				t.Helper()
+				mappers := testMappers(t, nil)
+				dataMapper := mappers["type2"]
+				dataMapper.Extra = source.Extra{"apiVersion": "v3"}
+				mappers["type2"] = dataMapper
+				return mappers
+			},
+			expectedTypes: []map[string]source.Extra{
+				{"type1": nil, "type2": {"apiVersion": "v3"}},
+			},
+			expectedDeletion: []*destination.Data{
+				{
+					APIVersion:    "v2",
+					ItemFamily:    "family2",
+					Name:          "item2",
+					OperationTime: "2024-06-01T12:00:00Z",
+				},
+			},
+		},
+		"source error is returned after the mappers are replaced": {
+			mappers:   onlyType1,
+			sourceErr: assert.AnError,
+			expectedTypes: []map[string]source.Extra{
+				{"type1": nil},
+			},
+			expectedErr: assert.AnError,
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx, cancel := context.WithTimeout(t.Context(), 1*time.Second)
+			defer cancel()
+
+			src := &updatableSource{
+				SyncableSource: fakesource.NewFakeSyncableSource(t, []source.Data{type1, type2}),
+				err:            test.sourceErr,
+			}
+			fakeDestination := fakedestination.NewFakeDestination(t)
+			pipeline, err := New(ctx, src, testMappers(t, nil), fakeDestination)
+			require.NoError(t, err)
+
+			err = pipeline.UpdateMappers(ctx, test.mappers(t))
+			assert.ErrorIs(t, err, test.expectedErr)
+			assert.Equal(t, test.expectedTypes, src.updatedTypes)
+
+			require.NoError(t, pipeline.Sync(ctx))
+			assert.Len(t, fakeDestination.SentData, 1)
+			assert.Equal(t, test.expectedDeletion, fakeDestination.DeletedData)
+		})
+	}
+}
+
+func TestPipelineUpdateMappersUnsupportedSource(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 1*time.Second)
+	defer cancel()
+
+	fakeDestination := fakedestination.NewFakeDestination(t)
+	pipeline, err := New(ctx, fakesource.NewFakeSyncableSource(t, []source.Data{type1, type2}), testMappers(t, nil), fakeDestination)
+	require.NoError(t, err)
+
+	mappers := testMappers(t, nil)
+	delete(mappers, "type2")
+	require.ErrorIs(t, pipeline.UpdateMappers(ctx, mappers), errors.ErrUnsupported)
+
+	require.NoError(t, pipeline.Sync(ctx))
+	assert.Len(t, fakeDestination.SentData, 1)
+	assert.Len(t, fakeDestination.DeletedData, 1, "the mappers of the removed type are kept")
+
+	require.NoError(t, pipeline.UpdateMappers(ctx, testMappers(t, nil)), "mappers with the same types are replaced")
+}
@@ -0,0 +1,8 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+// Package ratelimit provides an http.RoundTripper that keeps the requests sent to a REST API
+// within its rate limits. It tracks the budget advertised by the response headers, slows down
+// the requests when the budget is running low, waits for the reset when it is exhausted and
+// retries the requests rejected by primary or secondary rate limits.
+package ratelimit
@@ -0,0 +1,392 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package ratelimit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mia-platform/ibdm/internal/logger"
+)
+
+const (
+	loggerName = "ibdm:ratelimit"
+
+	// defaultMaxRetries is the number of times a rate limited request is retried.
+	defaultMaxRetries = 3
+	// defaultMaxWait is the longest wait accepted before a request; longer waits are not
+	// performed and the rate limited response is returned to the caller instead.
+	defaultMaxWait = time.Hour
+	// defaultBackoff is the first wait before retrying a rate limited request when the
+	// response does not tell how long to wait; it doubles at every retry.
+	defaultBackoff = time.Minute
+	// defaultThrottleThreshold is the fraction of the budget below which the requests are
+	// spread evenly until the reset.
+	defaultThrottleThreshold = 0.1
+	// maxDrainBytes caps how much of a rate limited response body is read before closing it.
+	maxDrainBytes = 4096
+)
+
+// Headers are the names of the response headers advertising the rate limit budget of an API.
+// Empty names are ignored, for the APIs that do not advertise that part of the budget.
+type Headers struct {
+	// Limit is the header with the number of requests allowed in the current window.
+	Limit string
+	// Remaining is the header with the number of requests left in the current window.
+	Remaining string
+	// Reset is the header with the Unix time, in seconds, when the current window resets.
+	Reset string
+	// SeparatePaths are the suffixes of the URL paths whose requests are counted against a
+	// budget of their own, instead of the one shared by the other requests of the credential.
+	SeparatePaths []string
+}
+
+var (
+	// GitHub are the rate limit headers of the GitHub REST API; the GraphQL API has a budget
+	// of its own.
+	GitHub = Headers{
+		Limit:         "X-RateLimit-Limit",
+		Remaining:     "X-RateLimit-Remaining",
+		Reset:         "X-RateLimit-Reset",
+		SeparatePaths: []string{"/graphql"},
+	}
+	// GitLab are the rate limit headers of the GitLab REST API.
+	GitLab = Headers{Limit: "RateLimit-Limit", Remaining: "RateLimit-Remaining", Reset: "RateLimit-Reset"}
+	// Bitbucket are the rate limit headers of the Bitbucket Cloud REST API, which only
+	// advertises the limit: its budget is enforced through 429 responses.
+	Bitbucket = Headers{Limit: "X-RateLimit-Limit"}
+)
+
+// budget is the last known rate limit budget of a credential.
+type budget struct {
+	limit     int
+	remaining int
+	reset     time.Time
+	known     bool
+	// throttling reports whether the requests are already being spread until reset.
+	throttling bool
+}
+
+// throttle tells why a request is delayed before being sent.
+type throttle int
+
+const (
+	// throttleSpread delays the request to spread the low budget until the reset.
+	throttleSpread throttle = iota
+	// throttleStart is a throttleSpread delay of the first request throttled until the reset.
+	throttleStart
+	// throttleExhausted delays the request until the reset of the exhausted budget.
+	throttleExhausted
+)
+
+// Transport is an http.RoundTripper keeping the requests within the rate limits of an API.
+// Budgets are tracked separately for every credential, so that the different tokens used by
+// the same source do not slow each other down. A Transport is safe for concurrent use.
+type Transport struct {
+	api     string
+	headers Headers
+	base    http.RoundTripper
+
+	attemptTimeout    time.Duration
+	maxRetries        int
+	maxWait           time.Duration
+	backoff           time.Duration
+	throttleThreshold float64
+	now               func() time.Time
+	sleep             func(ctx context.Context, d time.Duration) error
+
+	mu      sync.Mutex
+	budgets map[string]*budget
+}
+
+var _ http.RoundTripper = &Transport{}
+
+// Option customizes a Transport built with NewTransport.
+type Option func(*Transport)
+
+// WithAttemptTimeout bounds every attempt to send a request, reading its response body
+// included, to timeout. Unlike http.Client.Timeout it does not count the waits before the
+// attempts, so a client using the Transport must not set its own timeout.
+func WithAttemptTimeout(timeout time.Duration) Option {
+	return func(t *Transport) {
+		t.attemptTimeout = timeout
+	}
+}
+
+// NewTransport returns a Transport sending the requests to the api, whose name is only used
+// in logs, through base, reading the budget from headers. When base is nil
+// http.DefaultTransport is used.
+func NewTransport(api string, headers Headers, base http.RoundTripper, options ...Option) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	transport := &Transport{
+		api:               api,
+		headers:           headers,
+		base:              base,
+		maxRetries:        defaultMaxRetries,
+		maxWait:           defaultMaxWait,
+		backoff:           defaultBackoff,
+		throttleThreshold: defaultThrottleThreshold,
+		now:               time.Now,
+		sleep:             sleep,
+		budgets:           make(map[string]*budget),
+	}
+
+	for _, option := range options {
+		option(transport)
+	}
+
+	return transport
+}
+
+// RoundTrip implements http.RoundTripper. Before sending req it waits when the budget of its
+// credential is low or exhausted, and it retries req when the response is rate limited, as
+// long as its body can be sent again.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	log := logger.FromContext(ctx).WithName(loggerName)
+	key := t.budgetKey(req)
+
+	for attempt := 0; ; attempt++ {
+		if delay, reason := t.throttleDelay(key); delay > 0 {
+			switch reason {
+			case throttleExhausted:
+				log.Warn("rate limit budget exhausted, waiting for the reset", "api", t.api, "wait", delay.String())
+			case throttleStart:
+				log.Info("rate limit budget running low, throttling the requests until the reset", "api", t.api, "delay", delay.String())
+			default:
+				log.Debug("throttling request", "api", t.api, "delay", delay.String())
+			}
+			if err := t.sleep(ctx, delay); err != nil {
+				return nil, err
+			}
+		}
+
+		attemptReq, err := rewind(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := t.roundTripAttempt(attemptReq)
+		if err != nil {
+			return nil, err
+		}
+
+		t.update(key, resp.Header, log)
+
+		wait, limited := t.retryWait(key, resp, attempt)
+		if !limited || attempt >= t.maxRetries || wait > t.maxWait || !replayable(req) {
+			return resp, nil
+		}
+
+		log.Warn("rate limit reached, waiting before retrying", "api", t.api, "status", resp.StatusCode, "wait", wait.String(), "attempt", attempt+1)
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainBytes))
+		resp.Body.Close()
+
+		if err := t.sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// roundTripAttempt sends req through the base transport within the attempt timeout, if any.
+// The timeout keeps running while the response body is read, and is released when it is closed.
+func (t *Transport) roundTripAttempt(req *http.Request) (*http.Response, error) {
+	if t.attemptTimeout <= 0 {
+		return t.base.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), t.attemptTimeout)
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelBody releases the context of an attempt when its response body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// throttleDelay returns how long to wait before sending a request with the credential key, and
+// why: until the reset when the budget is exhausted, or an even share of the time left until the
+// reset when the budget is below the throttle threshold.
+func (t *Transport) throttleDelay(key string) (time.Duration, throttle) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	b, ok := t.budgets[key]
+	if !ok || !b.known || b.reset.IsZero() {
+		return 0, throttleSpread
+	}
+
+	untilReset := b.reset.Sub(t.now())
+	switch {
+	case untilReset <= 0:
+		return 0, throttleSpread
+	case b.remaining <= 0:
+		if untilReset > t.maxWait {
+			return 0, throttleSpread
+		}
+		return untilReset, throttleExhausted
+	case b.limit > 0 && float64(b.remaining)/float64(b.limit) < t.throttleThreshold:
+		reason := throttleSpread
+		if !b.throttling {
+			b.throttling = true
+			reason = throttleStart
+		}
+		return untilReset / time.Duration(b.remaining+1), reason
+	}
+	return 0, throttleSpread
+}
+
+// update records the budget advertised by the response headers of the credential key.
+func (t *Transport) update(key string, headers http.Header, log logger.Logger) {
+	remaining, hasRemaining := intHeader(headers, t.headers.Remaining)
+	if !hasRemaining {
+		return
+	}
+
+	limit, _ := intHeader(headers, t.headers.Limit)
+	var reset time.Time
+	if resetUnix, ok := intHeader(headers, t.headers.Reset); ok {
+		reset = time.Unix(int64(resetUnix), 0)
+	}
+
+	t.mu.Lock()
+	previous, found := t.budgets[key]
+	throttling := found && previous.throttling && previous.reset.Equal(reset)
+	t.budgets[key] = &budget{limit: limit, remaining: remaining, reset: reset, known: true, throttling: throttling}
+	t.mu.Unlock()
+
+	log.Debug("rate limit budget", "api", t.api, "limit", limit, "remaining", remaining, "used", limit-remaining, "reset", reset.Format(time.RFC3339))
+}
+
+// retryWait reports whether resp has been rejected by a rate limit and how long to wait before
+// retrying: the Retry-After header when present, the time until the reset when the budget is
+// exhausted, or an exponential backoff for the 429 responses without any hint.
+func (t *Transport) retryWait(key string, resp *http.Response, attempt int) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusForbidden {
+		return 0, false
+	}
+
+	now := t.now()
+	if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After"), now); ok {
+		return wait, true
+	}
+
+	t.mu.Lock()
+	b, ok := t.budgets[key]
+	t.mu.Unlock()
+	if ok && b.known && b.remaining <= 0 && b.reset.After(now) {
+		return b.reset.Sub(now), true
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return t.backoff << attempt, true
+	}
+	return 0, false
+}
+
+// parseRetryAfter parses the Retry-After header value, either a number of seconds or an HTTP
+// date, returning how long to wait from now.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// intHeader returns the integer value of the header name, reporting whether it was found.
+func intHeader(headers http.Header, name string) (int, bool) {
+	if name == "" {
+		return 0, false
+	}
+
+	value, err := strconv.Atoi(headers.Get(name))
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
+
+// budgetKey identifies the budget req is counted against: the one of its credential, or a
+// separate one of the credential when the path of req matches one of the separate paths.
+func (t *Transport) budgetKey(req *http.Request) string {
+	key := credentialKey(req)
+	for _, path := range t.headers.SeparatePaths {
+		if strings.HasSuffix(req.URL.Path, path) {
+			return key + " " + path
+		}
+	}
+	return key
+}
+
+// credentialKey identifies the credential of req, so that every credential has its own budget.
+// The credential is hashed to avoid keeping it as map key.
+func credentialKey(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.Header.Get("Authorization") + "\x00" + req.Header.Get("Private-Token")))
+	return hex.EncodeToString(sum[:])
+}
+
+// replayable reports whether the body of req can be sent again.
+func replayable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// rewind returns req for the first attempt, or a clone of req with a fresh body for the retries.
+func rewind(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 || req.GetBody == nil {
+		return req, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+
+	clone := req.Clone(req.Context())
+	clone.Body = body
+	return clone, nil
+}
+
+// sleep waits for d, returning early with the error of ctx when it is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
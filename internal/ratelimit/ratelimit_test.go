@@ -0,0 +1,477 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package ratelimit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mia-platform/ibdm/internal/logger"
+)
+
+// fakeClock is a clock whose sleeps advance the time instantly, recording their durations.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	c.sleeps = append(c.sleeps, d)
+	return nil
+}
+
+func newTestTransport(headers Headers) (*Transport, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	transport := NewTransport("test", headers, nil)
+	transport.now = clock.Now
+	transport.sleep = clock.Sleep
+	return transport, clock
+}
+
+// response describes a response of the test server.
+type response struct {
+	status  int
+	headers map[string]string
+}
+
+// newServer returns a test server replying with responses in order, repeating the last one.
+func newServer(t *testing.T, responses ...response) (*httptest.Server, *int) {
+	t.Helper()
+
+	var mu sync.Mutex
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		mu.Lock()
+		resp := responses[min(calls, len(responses)-1)]
+		calls++
+		mu.Unlock()
+
+		for name, value := range resp.headers {
+			w.Header().Set(name, value)
+		}
+		w.WriteHeader(resp.status)
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &calls
+}
+
+func githubHeaders(limit, remaining int, reset time.Time) map[string]string {
+	return map[string]string{
+		"X-RateLimit-Limit":     strconv.Itoa(limit),
+		"X-RateLimit-Remaining": strconv.Itoa(remaining),
+		"X-RateLimit-Reset":     strconv.FormatInt(reset.Unix(), 10),
+	}
+}
+
+func TestRoundTripRetries(t *testing.T) {
+	t.Parallel()
+
+	start := time.Unix(1_700_000_000, 0)
+	testCases := map[string]struct {
+		headers        Headers
+		responses      []response
+		expectedStatus int
+		expectedCalls  int
+		expectedSleeps []time.Duration
+	}{
+		"successful response is not retried": {
+			headers:        GitHub,
+			responses:      []response{{status: http.StatusOK, headers: githubHeaders(5000, 4999, start.Add(time.Hour))}},
+			expectedStatus: http.StatusOK,
+			expectedCalls:  1,
+		},
+		"secondary rate limit honours retry after": {
+			headers: GitHub,
+			responses: []response{
+				{status: http.StatusForbidden, headers: map[string]string{"Retry-After": "30"}},
+				{status: http.StatusOK},
+			},
+			expectedStatus: http.StatusOK,
+			expectedCalls:  2,
+			expectedSleeps: []time.Duration{30 * time.Second},
+		},
+		"exhausted primary rate limit waits until reset": {
+			headers: GitHub,
+			responses: []response{
+				{status: http.StatusForbidden, headers: githubHeaders(5000, 0, start.Add(10*time.Minute))},
+				{status: http.StatusOK, headers: githubHeaders(5000, 4999, start.Add(70*time.Minute))},
+			},
+			expectedStatus: http.StatusOK,
+			expectedCalls:  2,
+			expectedSleeps: []time.Duration{10 * time.Minute},
+		},
+		"forbidden response without rate limit is not retried": {
+			headers:        GitHub,
+			responses:      []response{{status: http.StatusForbidden, headers: githubHeaders(5000, 4000, start.Add(time.Hour))}},
+			expectedStatus: http.StatusForbidden,
+			expectedCalls:  1,
+		},
+		"too many requests without hints backs off exponentially": {
+			headers:        Bitbucket,
+			responses:      []response{{status: http.StatusTooManyRequests}},
+			expectedStatus: http.StatusTooManyRequests,
+			expectedCalls:  defaultMaxRetries + 1,
+			expectedSleeps: []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute},
+		},
+		"wait longer than the maximum is not performed": {
+			headers:        GitLab,
+			responses:      []response{{status: http.StatusTooManyRequests, headers: map[string]string{"Retry-After": "7200"}}},
+			expectedStatus: http.StatusTooManyRequests,
+			expectedCalls:  1,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			server, calls := newServer(t, tc.responses...)
+			transport, clock := newTestTransport(tc.headers)
+
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL, nil)
+			require.NoError(t, err)
+			resp, err := transport.RoundTrip(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+			assert.Equal(t, tc.expectedCalls, *calls)
+			assert.Equal(t, tc.expectedSleeps, clock.sleeps)
+		})
+	}
+}
+
+func TestRoundTripThrottling(t *testing.T) {
+	t.Parallel()
+
+	start := time.Unix(1_700_000_000, 0)
+	testCases := map[string]struct {
+		remaining      int
+		expectedSleeps []time.Duration
+	}{
+		"plenty of budget": {
+			remaining: 4000,
+		},
+		"low budget spreads the requests until the reset": {
+			remaining:      9,
+			expectedSleeps: []time.Duration{time.Minute},
+		},
+		"exhausted budget waits until the reset": {
+			remaining:      0,
+			expectedSleeps: []time.Duration{10 * time.Minute},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			server, _ := newServer(t, response{status: http.StatusOK, headers: githubHeaders(5000, tc.remaining, start.Add(10*time.Minute))})
+			transport, clock := newTestTransport(GitHub)
+
+			for range 2 {
+				req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL, nil)
+				require.NoError(t, err)
+				resp, err := transport.RoundTrip(req)
+				require.NoError(t, err)
+				resp.Body.Close()
+			}
+
+			assert.Equal(t, tc.expectedSleeps, clock.sleeps)
+		})
+	}
+}
+
+func TestRoundTripThrottlingLogs(t *testing.T) {
+	t.Parallel()
+
+	start := time.Unix(1_700_000_000, 0)
+	testCases := map[string]struct {
+		remaining    int
+		expectedLogs []string
+	}{
+		"plenty of budget": {
+			remaining: 4000,
+		},
+		"throttling start is logged once": {
+			remaining:    9,
+			expectedLogs: []string{"rate limit budget running low, throttling the requests until the reset"},
+		},
+		"every wait for the reset is logged": {
+			remaining: 0,
+			expectedLogs: []string{
+				"rate limit budget exhausted, waiting for the reset",
+				"rate limit budget exhausted, waiting for the reset",
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			server, _ := newServer(t, response{status: http.StatusOK, headers: githubHeaders(5000, tc.remaining, start.Add(10*time.Minute))})
+			transport, _ := newTestTransport(GitHub)
+			// keep the reset in the future for every request
+			transport.now = func() time.Time { return start }
+
+			buffer := new(bytes.Buffer)
+			ctx := logger.WithContext(t.Context(), logger.NewLogger(buffer))
+			for range 3 {
+				req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+				require.NoError(t, err)
+				resp, err := transport.RoundTrip(req)
+				require.NoError(t, err)
+				resp.Body.Close()
+			}
+
+			var messages []string
+			for line := range strings.Lines(buffer.String()) {
+				var entry struct {
+					Message string `json:"@message"`
+				}
+				require.NoError(t, json.Unmarshal([]byte(line), &entry))
+				messages = append(messages, entry.Message)
+			}
+			assert.Equal(t, tc.expectedLogs, messages)
+		})
+	}
+}
+
+func TestRoundTripBudgetsPerCredential(t *testing.T) {
+	t.Parallel()
+
+	start := time.Unix(1_700_000_000, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remaining := 4000
+		if r.Header.Get("Authorization") == "Bearer exhausted" {
+			remaining = 0
+		}
+		for name, value := range githubHeaders(5000, remaining, start.Add(10*time.Minute)) {
+			w.Header().Set(name, value)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	transport, clock := newTestTransport(GitHub)
+	for _, token := range []string{"exhausted", "available", "available"} {
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := transport.RoundTrip(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	assert.Empty(t, clock.sleeps)
+}
+
+func TestRoundTripSeparateBudgets(t *testing.T) {
+	t.Parallel()
+
+	start := time.Unix(1_700_000_000, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remaining := 4000
+		if r.URL.Path == "/graphql" {
+			remaining = 0
+		}
+		for name, value := range githubHeaders(5000, remaining, start.Add(10*time.Minute)) {
+			w.Header().Set(name, value)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	transport, clock := newTestTransport(GitHub)
+	for _, path := range []string{"/graphql", "/repos", "/repos", "/graphql"} {
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL+path, nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer token")
+		resp, err := transport.RoundTrip(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	assert.Equal(t, []time.Duration{10 * time.Minute}, clock.sleeps)
+}
+
+func TestRoundTripReplaysBody(t *testing.T) {
+	t.Parallel()
+
+	var bodies []string
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		first := len(bodies) == 1
+		mu.Unlock()
+
+		if first {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	transport, _ := newTestTransport(GitHub)
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, server.URL, strings.NewReader("payload"))
+	require.NoError(t, err)
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"payload", "payload"}, bodies)
+}
+
+func TestRoundTripContextCancellation(t *testing.T) {
+	t.Parallel()
+
+	server, calls := newServer(t, response{status: http.StatusTooManyRequests, headers: map[string]string{"Retry-After": "1"}})
+	transport := NewTransport("test", GitHub, nil)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	t.Cleanup(cancel)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	resp, err := transport.RoundTrip(req)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Nil(t, resp)
+	assert.Equal(t, 1, *calls)
+}
+
+func TestRoundTripAttemptTimeout(t *testing.T) {
+	t.Parallel()
+
+	t.Run("waits longer than the attempt timeout do not fail the request", func(t *testing.T) {
+		t.Parallel()
+
+		server, calls := newServer(t,
+			response{status: http.StatusTooManyRequests},
+			response{status: http.StatusOK},
+		)
+		transport := NewTransport("test", GitHub, nil, WithAttemptTimeout(100*time.Millisecond))
+		transport.backoff = 300 * time.Millisecond
+		client := &http.Client{Transport: transport}
+
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "{}", string(body))
+		assert.Equal(t, 2, *calls)
+	})
+
+	t.Run("a client timeout shorter than the wait fails the request", func(t *testing.T) {
+		t.Parallel()
+
+		server, _ := newServer(t,
+			response{status: http.StatusTooManyRequests},
+			response{status: http.StatusOK},
+		)
+		transport := NewTransport("test", GitHub, nil)
+		transport.backoff = 300 * time.Millisecond
+		client := &http.Client{Transport: transport, Timeout: 100 * time.Millisecond}
+
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Nil(t, resp)
+	})
+
+	t.Run("slow attempts time out", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		t.Cleanup(server.Close)
+		client := &http.Client{Transport: NewTransport("test", GitHub, nil, WithAttemptTimeout(50*time.Millisecond))}
+
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, time.October, 1, 10, 0, 0, 0, time.UTC)
+	testCases := map[string]struct {
+		value        string
+		expectedWait time.Duration
+		expectedOK   bool
+	}{
+		"seconds": {
+			value:        "120",
+			expectedWait: 2 * time.Minute,
+			expectedOK:   true,
+		},
+		"http date": {
+			value:        "Thu, 01 Oct 2026 10:00:30 GMT",
+			expectedWait: 30 * time.Second,
+			expectedOK:   true,
+		},
+		"past http date": {
+			value:      "Thu, 01 Oct 2026 09:00:00 GMT",
+			expectedOK: true,
+		},
+		"empty": {},
+		"invalid": {
+			value: "soon",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			wait, ok := parseRetryAfter(tc.value, now)
+			assert.Equal(t, tc.expectedOK, ok)
+			assert.Equal(t, tc.expectedWait, wait)
+		})
+	}
+}
@@ -0,0 +1,7 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+// Package replay sends previously recorded outputs to a destination. The outputs are read as
+// NDJSON, one destination.Data per line labelled with its operation, as written by the file
+// destination, so that a catalog can be backfilled or recovered without querying the sources again.
+package replay
@@ -0,0 +1,250 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package replay
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"golang.org/x/time/rate"
+
+	"github.com/mia-platform/ibdm/internal/destination"
+	"github.com/mia-platform/ibdm/internal/logger"
+)
+
+const (
+	loggerName = "ibdm:replay"
+
+	// OperationUpsert is the operation of the recorded lines that create or update an item.
+	OperationUpsert = "upsert"
+	// OperationDelete is the operation of the recorded lines that remove an item.
+	OperationDelete = "delete"
+
+	// maxLineSize bounds the length of a single recorded line.
+	maxLineSize = 10 * 1024 * 1024
+)
+
+var (
+	// ErrReplay wraps errors returned while replaying recorded outputs.
+	ErrReplay = errors.New("replay")
+
+	errInvalidOperation = errors.New("invalid operation")
+	errMissingName      = errors.New("missing item name")
+)
+
+// record is a single recorded line, the destination data labelled with its operation.
+type record struct {
+	destination.Data
+
+	Operation string `json:"operation"`
+}
+
+// Failure describes a recorded line that could not be replayed.
+type Failure struct {
+	// Line is the position of the line counted across every input, starting from 1.
+	Line       int
+	Operation  string
+	ItemFamily string
+	Name       string
+	Err        error
+}
+
+// Report summarizes the outcome of a replay.
+type Report struct {
+	Upserted int
+	Deleted  int
+	Skipped  int
+	Failures []Failure
+	// Offset is the number of lines read, pass it to WithOffset to resume an interrupted replay.
+	Offset int
+}
+
+// Write prints a human readable summary of the report to w.
+func (r *Report) Write(w io.Writer) error {
+	writer := bufio.NewWriter(w)
+	for _, failure := range r.Failures {
+		if failure.Name == "" {
+			fmt.Fprintf(writer, "line %d: %s\n", failure.Line, failure.Err)
+			continue
+		}
+		fmt.Fprintf(writer, "line %d: %s %s %s: %s\n", failure.Line, failure.Operation, failure.ItemFamily, failure.Name, failure.Err)
+	}
+
+	fmt.Fprintf(writer, "%d upserted, %d deleted, %d skipped, %d failed, offset %d\n", r.Upserted, r.Deleted, r.Skipped, len(r.Failures), r.Offset)
+	return writer.Flush()
+}
+
+// Replayer sends recorded outputs to a destination.
+type Replayer struct {
+	destination  destination.Sender
+	limiter      *rate.Limiter
+	offset       int
+	itemFamilies []string
+	operations   []string
+}
+
+// Option customizes a Replayer built with New.
+type Option func(*Replayer)
+
+// WithRateLimit caps the operations sent to the destination to perSecond, a value of zero or
+// less disables the limit.
+func WithRateLimit(perSecond float64) Option {
+	return func(r *Replayer) {
+		if perSecond > 0 {
+			r.limiter = rate.NewLimiter(rate.Limit(perSecond), 1)
+		}
+	}
+}
+
+// WithOffset skips the first offset lines, to resume a replay from the Offset of its Report.
+func WithOffset(offset int) Option {
+	return func(r *Replayer) {
+		r.offset = offset
+	}
+}
+
+// WithItemFamilies replays only the lines of the given item families.
+func WithItemFamilies(itemFamilies ...string) Option {
+	return func(r *Replayer) {
+		r.itemFamilies = itemFamilies
+	}
+}
+
+// WithOperations replays only the lines with one of the given operations.
+func WithOperations(operations ...string) Option {
+	return func(r *Replayer) {
+		r.operations = operations
+	}
+}
+
+// New returns a Replayer sending the recorded outputs to dest.
+func New(dest destination.Sender, options ...Option) (*Replayer, error) {
+	r := &Replayer{destination: dest}
+	for _, option := range options {
+		option(r)
+	}
+
+	for _, operation := range r.operations {
+		if operation != OperationUpsert && operation != OperationDelete {
+			return nil, fmt.Errorf("%w: %w: %q", ErrReplay, errInvalidOperation, operation)
+		}
+	}
+
+	if r.offset < 0 {
+		return nil, fmt.Errorf("%w: offset must not be negative", ErrReplay)
+	}
+
+	return r, nil
+}
+
+// Replay reads the recorded lines from inputs, in order, and sends them to the destination.
+// Lines that cannot be decoded or sent are added to the report failures without stopping the
+// replay; an error is returned only when ctx is cancelled or an input cannot be read, and the
+// returned report is always valid.
+func (r *Replayer) Replay(ctx context.Context, inputs ...io.Reader) (*Report, error) {
+	log := logger.FromContext(ctx).WithName(loggerName)
+	report := &Report{Failures: make([]Failure, 0)}
+
+	for _, input := range inputs {
+		scanner := bufio.NewScanner(input)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+		for scanner.Scan() {
+			report.Offset++
+			if report.Offset <= r.offset {
+				continue
+			}
+
+			line := scanner.Bytes()
+			if len(line) == 0 {
+				report.Skipped++
+				continue
+			}
+
+			var rec record
+			if err := json.Unmarshal(line, &rec); err != nil {
+				report.Failures = append(report.Failures, Failure{Line: report.Offset, Err: err})
+				continue
+			}
+
+			if !r.selected(rec) {
+				log.Trace("line filtered out, skipping", "line", report.Offset, "itemFamily", rec.ItemFamily, "operation", rec.Operation)
+				report.Skipped++
+				continue
+			}
+
+			if r.limiter != nil {
+				if err := r.limiter.Wait(ctx); err != nil {
+					report.Offset--
+					return report, fmt.Errorf("%w: %w", ErrReplay, ctx.Err())
+				}
+			}
+
+			if err := r.send(ctx, rec); err != nil {
+				if ctx.Err() != nil {
+					report.Offset--
+					return report, fmt.Errorf("%w: %w", ErrReplay, ctx.Err())
+				}
+
+				log.Error("error replaying line", "line", report.Offset, "itemFamily", rec.ItemFamily, "name", rec.Name, "error", err)
+				report.Failures = append(report.Failures, Failure{
+					Line:       report.Offset,
+					Operation:  rec.Operation,
+					ItemFamily: rec.ItemFamily,
+					Name:       rec.Name,
+					Err:        err,
+				})
+				continue
+			}
+
+			if rec.Operation == OperationDelete {
+				report.Deleted++
+			} else {
+				report.Upserted++
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			return report, fmt.Errorf("%w: %w", ErrReplay, err)
+		}
+	}
+
+	return report, nil
+}
+
+// selected reports whether rec matches the configured filters.
+func (r *Replayer) selected(rec record) bool {
+	if len(r.itemFamilies) > 0 && !slices.Contains(r.itemFamilies, rec.ItemFamily) {
+		return false
+	}
+
+	return len(r.operations) == 0 || slices.Contains(r.operations, rec.Operation)
+}
+
+// send applies the operation of rec to the destination.
+func (r *Replayer) send(ctx context.Context, rec record) error {
+	if rec.Name == "" {
+		return errMissingName
+	}
+
+	data := rec.Data
+	switch rec.Operation {
+	case OperationUpsert:
+		// empty specs are omitted when recorded, but an upsert always carries data
+		if data.Data == nil {
+			data.Data = map[string]any{}
+		}
+		return r.destination.SendData(ctx, &data)
+	case OperationDelete:
+		data.Data = nil
+		return r.destination.DeleteData(ctx, &data)
+	default:
+		return fmt.Errorf("%w: %q", errInvalidOperation, rec.Operation)
+	}
+}
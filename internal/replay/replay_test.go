@@ -0,0 +1,198 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package replay
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mia-platform/ibdm/internal/destination"
+	"github.com/mia-platform/ibdm/internal/destination/fake"
+)
+
+const (
+	testRecords = `{"apiVersion":"v1","itemFamily":"repositories","name":"repo-1","data":{"name":"repo-1"},"operationTime":"2024-06-01T12:00:00Z","operation":"upsert"}
+{"apiVersion":"v1","itemFamily":"pipelines","name":"pipeline-1","data":{"name":"pipeline-1"},"operationTime":"2024-06-01T12:00:01Z","operation":"upsert"}
+
+{"apiVersion":"v1","itemFamily":"repositories","name":"repo-2","operationTime":"2024-06-01T12:00:02Z","operation":"delete"}
+{"apiVersion":"v1","itemFamily":"repositories","name":"repo-3","operationTime":"2024-06-01T12:00:03Z","operation":"upsert"}
+`
+)
+
+var (
+	testRepo1 = &destination.Data{
+		APIVersion:    "v1",
+		ItemFamily:    "repositories",
+		Name:          "repo-1",
+		Data:          map[string]any{"name": "repo-1"},
+		OperationTime: "2024-06-01T12:00:00Z",
+	}
+	testPipeline1 = &destination.Data{
+		APIVersion:    "v1",
+		ItemFamily:    "pipelines",
+		Name:          "pipeline-1",
+		Data:          map[string]any{"name": "pipeline-1"},
+		OperationTime: "2024-06-01T12:00:01Z",
+	}
+	testRepo2 = &destination.Data{
+		APIVersion:    "v1",
+		ItemFamily:    "repositories",
+		Name:          "repo-2",
+		OperationTime: "2024-06-01T12:00:02Z",
+	}
+	testRepo3 = &destination.Data{
+		APIVersion:    "v1",
+		ItemFamily:    "repositories",
+		Name:          "repo-3",
+		Data:          map[string]any{},
+		OperationTime: "2024-06-01T12:00:03Z",
+	}
+)
+
+// failingDestination fails every operation on the items in names.
+type failingDestination struct {
+	*fake.FakeDestination
+
+	names []string
+}
+
+func (d *failingDestination) SendData(ctx context.Context, data *destination.Data) error {
+	for _, name := range d.names {
+		if name == data.Name {
+			return errors.New("send failed")
+		}
+	}
+	return d.FakeDestination.SendData(ctx, data)
+}
+
+func TestReplay(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		inputs          []string
+		options         []Option
+		failingNames    []string
+		expectedSent    []*destination.Data
+		expectedDeleted []*destination.Data
+		expectedReport  string
+	}{
+		"replay every line": {
+			inputs:          []string{testRecords},
+			expectedSent:    []*destination.Data{testRepo1, testPipeline1, testRepo3},
+			expectedDeleted: []*destination.Data{testRepo2},
+			expectedReport:  "3 upserted, 1 deleted, 1 skipped, 0 failed, offset 5\n",
+		},
+		"lines are counted across inputs": {
+			inputs:          []string{testRecords, testRecords},
+			options:         []Option{WithOffset(6)},
+			expectedSent:    []*destination.Data{testPipeline1, testRepo3},
+			expectedDeleted: []*destination.Data{testRepo2},
+			expectedReport:  "2 upserted, 1 deleted, 1 skipped, 0 failed, offset 10\n",
+		},
+		"filter by item family": {
+			inputs:         []string{testRecords},
+			options:        []Option{WithItemFamilies("pipelines")},
+			expectedSent:   []*destination.Data{testPipeline1},
+			expectedReport: "1 upserted, 0 deleted, 4 skipped, 0 failed, offset 5\n",
+		},
+		"filter by operation": {
+			inputs:          []string{testRecords},
+			options:         []Option{WithItemFamilies("repositories", "pipelines"), WithOperations(OperationDelete)},
+			expectedDeleted: []*destination.Data{testRepo2},
+			expectedReport:  "0 upserted, 1 deleted, 4 skipped, 0 failed, offset 5\n",
+		},
+		"failures do not stop the replay": {
+			inputs: []string{
+				`{"apiVersion":"v1","itemFamily":"repositories"` + "\n" +
+					`{"apiVersion":"v1","itemFamily":"repositories","operation":"upsert"}` + "\n" +
+					`{"apiVersion":"v1","itemFamily":"repositories","name":"repo-1","operation":"patch"}` + "\n" +
+					testRecords,
+			},
+			failingNames:    []string{"pipeline-1"},
+			expectedSent:    []*destination.Data{testRepo1, testRepo3},
+			expectedDeleted: []*destination.Data{testRepo2},
+			expectedReport: `line 1: unexpected end of JSON input
+line 2: missing item name
+line 3: patch repositories repo-1: invalid operation: "patch"
+line 5: upsert pipelines pipeline-1: send failed
+2 upserted, 1 deleted, 1 skipped, 4 failed, offset 8
+`,
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dest := &failingDestination{FakeDestination: fake.NewFakeDestination(t), names: test.failingNames}
+			replayer, err := New(dest, test.options...)
+			require.NoError(t, err)
+
+			inputs := make([]io.Reader, 0, len(test.inputs))
+			for _, input := range test.inputs {
+				inputs = append(inputs, strings.NewReader(input))
+			}
+
+			report, err := replayer.Replay(t.Context(), inputs...)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedSent, dest.SentData)
+			assert.Equal(t, test.expectedDeleted, dest.DeletedData)
+
+			out := new(bytes.Buffer)
+			require.NoError(t, report.Write(out))
+			assert.Equal(t, test.expectedReport, out.String())
+		})
+	}
+}
+
+func TestReplayRateLimit(t *testing.T) {
+	t.Parallel()
+
+	dest := fake.NewFakeDestination(t)
+	replayer, err := New(dest, WithRateLimit(20))
+	require.NoError(t, err)
+
+	start := time.Now()
+	report, err := replayer.Replay(t.Context(), strings.NewReader(testRecords))
+	require.NoError(t, err)
+	assert.Equal(t, 5, report.Offset)
+	// the first operation is sent immediately, the other three wait 50ms each
+	assert.GreaterOrEqual(t, time.Since(start), 140*time.Millisecond)
+}
+
+func TestReplayCancelled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	dest := fake.NewFakeDestination(t)
+	replayer, err := New(dest, WithRateLimit(0.001))
+	require.NoError(t, err)
+
+	// consume the burst with the first line, then stop while waiting for the second one
+	time.AfterFunc(50*time.Millisecond, cancel)
+	report, err := replayer.Replay(ctx, strings.NewReader(testRecords))
+	require.ErrorIs(t, err, ErrReplay)
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []*destination.Data{testRepo1}, dest.SentData)
+	assert.Equal(t, 1, report.Offset, "the offset resumes from the line that was not sent")
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	_, err := New(nil, WithOperations(OperationUpsert, "patch"))
+	require.ErrorIs(t, err, ErrReplay)
+	assert.EqualError(t, err, `replay: invalid operation: "patch"`)
+
+	_, err = New(nil, WithOffset(-1))
+	assert.ErrorIs(t, err, ErrReplay)
+}
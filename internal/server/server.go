@@ -32,6 +32,17 @@ type impServer struct {
 	app *fiber.App
 }
 
+// requestPathKey is the context key of the path of the request passed to a route handler.
+type requestPathKey struct{}
+
+// RequestPathFromContext returns the path of the request carried by the context passed to a
+// route handler, so handlers registered with a wildcard path can route the requests they
+// receive. It returns an empty string when ctx does not carry one.
+func RequestPathFromContext(ctx context.Context) string {
+	path, _ := ctx.Value(requestPathKey{}).(string)
+	return path
+}
+
 var (
 	ErrServerListen   = errors.New("server listen error")
 	ErrServerShutdown = errors.New("server shutdown error")
@@ -60,7 +71,8 @@ func NewServer(ctx context.Context) (Server, error) {
 
 func (s *impServer) AddRoute(method string, path string, handler func(ctx context.Context, headers http.Header, body []byte) error) {
 	s.app.Add(method, path, func(ctx *fiber.Ctx) error {
-		if err := handler(ctx.UserContext(), ctx.GetReqHeaders(), ctx.Body()); err != nil {
+		handlerCtx := context.WithValue(ctx.UserContext(), requestPathKey{}, ctx.Path())
+		if err := handler(handlerCtx, ctx.GetReqHeaders(), ctx.Body()); err != nil {
 			return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
 				"statusCode": http.StatusInternalServerError,
 				"error":      http.StatusText(http.StatusInternalServerError),
@@ -99,15 +99,16 @@ func TestFiberHandlerWrapper(t *testing.T) {
 	}
 
 	processed := false
-	handler := func(_ context.Context, headers http.Header, body []byte) error {
+	handler := func(ctx context.Context, headers http.Header, body []byte) error {
 		processed = true
 		require.Equal(t, "test body", string(body))
+		require.Equal(t, "/test/nested", RequestPathFromContext(ctx))
 		return nil
 	}
 
-	request := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader("test body"))
+	request := httptest.NewRequest(http.MethodPost, "/test/nested", strings.NewReader("test body"))
 
-	srv.AddRoute(http.MethodPost, "/test", handler)
+	srv.AddRoute(http.MethodPost, "/test/*", handler)
 	response, err := srv.app.Test(request)
 	require.NoError(t, err)
 	require.Equal(t, http.StatusNoContent, response.StatusCode)
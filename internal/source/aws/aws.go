@@ -0,0 +1,348 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/caarlos0/env/v11"
+
+	"github.com/mia-platform/ibdm/internal/logger"
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+const (
+	loggerName = "ibdm:source:aws"
+
+	// receiveMaxMessages is the maximum number of messages read from the queue at once.
+	receiveMaxMessages = 10
+	// receiveWaitSeconds is the duration of the long polling of the queue.
+	receiveWaitSeconds = 20
+)
+
+var (
+	// ErrAWSSource is the sentinel error for all AWS Source errors.
+	ErrAWSSource = errors.New("aws source")
+
+	// timeSource is a package-level function for the current time, replaceable in tests.
+	timeSource = time.Now
+)
+
+var _ source.SyncableSource = &Source{}
+var _ source.EventSource = &Source{}
+var _ source.ClosableSource = &Source{}
+
+// Source implements [source.SyncableSource] and [source.EventSource] for the AWS resources recorded
+// by AWS Config, identified by their CloudFormation type like AWS::EC2::Instance. The sync lists
+// the resources of every configured account and region, while the event stream consumes the
+// configuration changes that EventBridge forwards to an SQS queue.
+type Source struct {
+	config
+
+	awsConfig     aws.Config
+	accounts      []account
+	configService *configService
+
+	syncLock           sync.Mutex
+	syncContext        atomic.Pointer[processContext]
+	streamLock         sync.Mutex
+	eventStreamContext atomic.Pointer[processContext]
+}
+
+// processContext holds references needed for a process lifecycle.
+type processContext struct {
+	cancel context.CancelFunc
+}
+
+// NewSource creates a new AWS Source reading the needed configuration from the env variables and
+// the default AWS credentials chain.
+func NewSource() (*Source, error) {
+	cfg, err := env.ParseAs[config]()
+	if err != nil {
+		return nil, handleError(err)
+	}
+
+	awsConfig, err := awsconfig.LoadDefaultConfig(context.Background())
+	if err != nil {
+		return nil, handleError(err)
+	}
+
+	return newSource(cfg, awsConfig)
+}
+
+// newSource builds a Source from cfg, using awsConfig as the base for the clients of every account.
+// When no region is configured, the region of awsConfig is used.
+func newSource(cfg config, awsConfig aws.Config) (*Source, error) {
+	if len(cfg.Regions) == 0 && len(awsConfig.Region) > 0 {
+		cfg.Regions = []string{awsConfig.Region}
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, handleError(err)
+	}
+
+	return &Source{
+		config:        cfg,
+		awsConfig:     awsConfig,
+		accounts:      cfg.accounts(awsConfig),
+		configService: newConfigService(awsConfig),
+	}, nil
+}
+
+// StartSyncProcess sends an upsert for every resource of the requested types in every configured
+// account and region. A failing type, account or region is reported and does not stop the others.
+func (s *Source) StartSyncProcess(ctx context.Context, typesToSync map[string]source.Extra, results chan<- source.Data) error {
+	log := logger.FromContext(ctx).WithName(loggerName)
+	if !s.syncLock.TryLock() {
+		log.Debug("sync process already running")
+		return nil
+	}
+	defer s.syncLock.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s.syncContext.Store(&processContext{cancel: cancel})
+	defer s.syncContext.Swap(nil)
+
+	for _, resourceType := range slices.Sorted(maps.Keys(typesToSync)) {
+		for _, acc := range s.accounts {
+			for _, region := range s.Regions {
+				log.Trace("starting sync for resource type", "type", resourceType, "account", acc.id, "region", region)
+				if err := s.syncResources(ctx, acc, region, resourceType, results); err != nil {
+					if ctx.Err() != nil {
+						return nil
+					}
+					log.Error("error syncing resource type", "type", resourceType, "account", acc.id, "region", region, "error", err.Error())
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// syncResources lists the resources of resourceType page by page, sending an upsert for each of them.
+func (s *Source) syncResources(ctx context.Context, acc account, region, resourceType string, results chan<- source.Data) error {
+	nextToken := ""
+	for {
+		timestamp := timeSource()
+		keys, next, err := s.configService.listResources(ctx, acc, region, resourceType, nextToken)
+		if err != nil {
+			return err
+		}
+
+		if len(keys) > 0 {
+			if err := s.sendResources(ctx, acc, region, resourceType, keys, timestamp, results); err != nil {
+				return err
+			}
+		}
+
+		nextToken = next
+		if len(nextToken) == 0 {
+			return nil
+		}
+	}
+}
+
+// sendResources reads the current configuration of the resources identified by keys and sends an
+// upsert for each of them.
+func (s *Source) sendResources(ctx context.Context, acc account, region, resourceType string, keys []types.ResourceKey, timestamp time.Time, results chan<- source.Data) error {
+	items, unprocessed, err := s.configService.resourceConfigs(ctx, acc, region, keys)
+	if err != nil {
+		return err
+	}
+
+	if len(unprocessed) > 0 {
+		logger.FromContext(ctx).WithName(loggerName).Warn("some resources were not processed by AWS Config", "type", resourceType, "account", acc.id, "region", region, "count", len(unprocessed))
+	}
+
+	for _, item := range items {
+		data := source.Data{
+			Type:      resourceType,
+			Operation: source.DataOperationUpsert,
+			Time:      timestamp,
+			Values:    item,
+		}
+
+		if err := send(ctx, results, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// StartEventStream receives the configuration changes from the SQS queue, sending the current state
+// of the changed resources of the requested types, or their deletion. Every message is deleted from
+// the queue once handled, so that messages failing for transient errors are received again.
+func (s *Source) StartEventStream(ctx context.Context, typesToStream map[string]source.Extra, results chan<- source.Data) error {
+	log := logger.FromContext(ctx).WithName(loggerName)
+	if !s.streamLock.TryLock() {
+		log.Debug("event stream already running")
+		return nil
+	}
+	defer s.streamLock.Unlock()
+
+	if err := s.validateForEventStream(); err != nil {
+		return handleError(err)
+	}
+
+	client := sqs.NewFromConfig(s.awsConfig, func(o *sqs.Options) {
+		o.Region = s.queueRegion(s.awsConfig.Region)
+	})
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s.eventStreamContext.Store(&processContext{cancel: cancel})
+	defer s.eventStreamContext.Swap(nil)
+
+	log.Debug("starting to receive messages from the queue", "queue", s.QueueURL)
+	for {
+		output, err := client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:            aws.String(s.QueueURL),
+			MaxNumberOfMessages: receiveMaxMessages,
+			WaitTimeSeconds:     receiveWaitSeconds,
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return handleError(err)
+		}
+
+		for _, message := range output.Messages {
+			if err := s.handleMessage(ctx, aws.ToString(message.Body), typesToStream, results); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				log.Error("failed to handle message, it will be received again", "messageId", aws.ToString(message.MessageId), "error", err.Error())
+				continue
+			}
+
+			_, err := client.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+				QueueUrl:      aws.String(s.QueueURL),
+				ReceiptHandle: message.ReceiptHandle,
+			})
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				log.Error("failed to delete message from the queue", "messageId", aws.ToString(message.MessageId), "error", err.Error())
+			}
+		}
+	}
+}
+
+// handleMessage sends the change reported by the message body. Invalid messages and changes of
+// types not requested, or of accounts without a configured role, are discarded, while an error is
+// returned when the resource state cannot be read.
+func (s *Source) handleMessage(ctx context.Context, body string, typesToStream map[string]source.Extra, results chan<- source.Data) error {
+	log := logger.FromContext(ctx).WithName(loggerName)
+	item, eventTime, err := parseConfigEvent(body)
+	switch {
+	case err != nil:
+		log.Error("discarding invalid message", "error", err.Error())
+		return nil
+	case item == nil:
+		log.Trace("skipping message not reporting a configuration change")
+		return nil
+	}
+
+	if _, ok := typesToStream[item.ResourceType]; !ok {
+		log.Trace("skipping change based on type", "type", item.ResourceType)
+		return nil
+	}
+
+	if item.deleted() {
+		return send(ctx, results, source.Data{
+			Type:      item.ResourceType,
+			Operation: source.DataOperationDelete,
+			Time:      eventTime,
+			Values:    item.deleteValues(),
+		})
+	}
+
+	acc, found := s.account(item.AccountID)
+	if !found {
+		log.Warn("discarding change of an account without a configured role", "account", item.AccountID, "type", item.ResourceType)
+		return nil
+	}
+
+	return s.sendResources(ctx, acc, item.Region, item.ResourceType, []types.ResourceKey{item.key()}, eventTime, results)
+}
+
+// account returns the configured account with the given identifier. Without roles, every change is
+// read with the default credentials, while with roles only the accounts owning one of them are found.
+func (s *Source) account(id string) (account, bool) {
+	if len(s.RoleARNs) == 0 {
+		return s.accounts[0], true
+	}
+
+	for _, acc := range s.accounts {
+		if acc.id == id {
+			return acc, true
+		}
+	}
+
+	return account{}, false
+}
+
+// Close implement source.ClosableSource.
+func (s *Source) Close(ctx context.Context, _ time.Duration) error {
+	log := logger.FromContext(ctx).WithName(loggerName)
+	log.Debug("closing AWS client")
+
+	if syncContext := s.syncContext.Swap(nil); syncContext != nil {
+		log.Debug("cancelling sync process")
+		syncContext.cancel()
+	}
+
+	if eventStreamContext := s.eventStreamContext.Swap(nil); eventStreamContext != nil {
+		log.Debug("cancelling event stream process")
+		eventStreamContext.cancel()
+	}
+
+	log.Trace("closed AWS client")
+	return nil
+}
+
+// send delivers data on results unless ctx is done first.
+func send(ctx context.Context, results chan<- source.Data, data source.Data) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case results <- data:
+		return nil
+	}
+}
+
+// handleError always wraps the given error with ErrAWSSource.
+// It also unwraps some errors to cleanup the error message and removing unnecessary layers.
+func handleError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var parseErr env.AggregateError
+	if errors.As(err, &parseErr) {
+		err = parseErr.Errors[0]
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %w", ErrAWSSource, err)
+}
@@ -0,0 +1,469 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package aws
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+const (
+	defaultAccountID  = "000000000000"
+	brokenType        = "AWS::Broken::Type"
+	configContentType = "application/x-amz-json-1.1"
+)
+
+// fakeResourceKey is the key of a resource in the requests and responses of AWS Config.
+type fakeResourceKey struct {
+	ResourceType string `json:"resourceType"`
+	ResourceID   string `json:"resourceId"`
+}
+
+// fakeAWS is a local stand-in for STS, AWS Config and SQS. AWS Config returns the items of the
+// account and region of the credentials signing the request, one resource per page.
+type fakeAWS struct {
+	items    []map[string]any
+	messages []string
+
+	lock     sync.Mutex
+	received bool
+	deleted  []string
+}
+
+func (f *fakeAWS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Content-Type") == "application/x-www-form-urlencoded" {
+		f.assumeRole(w, r)
+		return
+	}
+
+	switch r.Header.Get("X-Amz-Target") {
+	case "StarlingDoveService.ListDiscoveredResources":
+		f.listDiscoveredResources(w, r)
+	case "StarlingDoveService.BatchGetResourceConfig":
+		f.batchGetResourceConfig(w, r)
+	case "AmazonSQS.ReceiveMessage":
+		f.receiveMessage(w, r)
+	case "AmazonSQS.DeleteMessage":
+		f.deleteMessage(w, r)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func (f *fakeAWS) assumeRole(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	accountID, _ := roleAccountID(r.Form.Get("RoleArn"))
+	w.Header().Set("Content-Type", "text/xml")
+	fmt.Fprintf(w, `<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+<AssumeRoleResult>
+<Credentials><AccessKeyId>AKID%s</AccessKeyId><SecretAccessKey>secret</SecretAccessKey><SessionToken>token</SessionToken><Expiration>2100-01-01T00:00:00Z</Expiration></Credentials>
+<AssumedRoleUser><Arn>%s</Arn><AssumedRoleId>id:session</AssumedRoleId></AssumedRoleUser>
+</AssumeRoleResult>
+</AssumeRoleResponse>`, accountID, r.Form.Get("RoleArn"))
+}
+
+// signer returns the account and the region of the credentials that signed the request.
+func (f *fakeAWS) signer(r *http.Request) (string, string) {
+	_, credential, _ := strings.Cut(r.Header.Get("Authorization"), "Credential=")
+	credential, _, _ = strings.Cut(credential, ",")
+	parts := strings.Split(credential, "/")
+	accountID := strings.TrimPrefix(parts[0], "AKID")
+	if accountID == "DEFAULT" {
+		accountID = defaultAccountID
+	}
+
+	return accountID, parts[2]
+}
+
+func (f *fakeAWS) matchingItems(r *http.Request, resourceType string, ids []string) []map[string]any {
+	accountID, region := f.signer(r)
+	items := make([]map[string]any, 0)
+	for _, item := range f.items {
+		if item["accountId"] == accountID && item["awsRegion"] == region && item["resourceType"] == resourceType &&
+			(ids == nil || slices.Contains(ids, item["resourceId"].(string))) {
+			items = append(items, item)
+		}
+	}
+
+	slices.SortFunc(items, func(a, b map[string]any) int {
+		return cmp.Compare(a["resourceId"].(string), b["resourceId"].(string))
+	})
+	return items
+}
+
+func (f *fakeAWS) listDiscoveredResources(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		ResourceType string `json:"resourceType"`
+		NextToken    string `json:"nextToken"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.ResourceType == brokenType {
+		writeValidationError(w)
+		return
+	}
+
+	items := f.matchingItems(r, input.ResourceType, nil)
+	output := struct {
+		ResourceIdentifiers []fakeResourceKey `json:"resourceIdentifiers"`
+		NextToken           string            `json:"nextToken,omitempty"`
+	}{ResourceIdentifiers: []fakeResourceKey{}}
+	index, _ := strconv.Atoi(input.NextToken)
+	if index < len(items) {
+		output.ResourceIdentifiers = append(output.ResourceIdentifiers, fakeResourceKey{
+			ResourceType: input.ResourceType,
+			ResourceID:   items[index]["resourceId"].(string),
+		})
+	}
+	if index+1 < len(items) {
+		output.NextToken = strconv.Itoa(index + 1)
+	}
+
+	writeJSON(w, output)
+}
+
+func (f *fakeAWS) batchGetResourceConfig(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		ResourceKeys []fakeResourceKey `json:"resourceKeys"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || len(input.ResourceKeys) == 0 || input.ResourceKeys[0].ResourceType == brokenType {
+		writeValidationError(w)
+		return
+	}
+
+	ids := make([]string, 0, len(input.ResourceKeys))
+	for _, key := range input.ResourceKeys {
+		ids = append(ids, key.ResourceID)
+	}
+
+	items := make([]map[string]any, 0)
+	for _, item := range f.matchingItems(r, input.ResourceKeys[0].ResourceType, ids) {
+		encoded, _ := json.Marshal(item["configuration"])
+		items = append(items, map[string]any{
+			"accountId":     item["accountId"],
+			"awsRegion":     item["awsRegion"],
+			"resourceType":  item["resourceType"],
+			"resourceId":    item["resourceId"],
+			"configuration": string(encoded),
+		})
+	}
+
+	writeJSON(w, map[string]any{"baseConfigurationItems": items})
+}
+
+func (f *fakeAWS) receiveMessage(w http.ResponseWriter, r *http.Request) {
+	f.lock.Lock()
+	received := f.received
+	f.received = true
+	f.lock.Unlock()
+
+	messages := make([]map[string]string, 0, len(f.messages))
+	if !received {
+		for i, body := range f.messages {
+			messages = append(messages, map[string]string{
+				"MessageId":     fmt.Sprintf("message-%d", i),
+				"ReceiptHandle": fmt.Sprintf("receipt-%d", i),
+				"Body":          body,
+			})
+		}
+	} else {
+		// simulate the long polling of an empty queue
+		select {
+		case <-r.Context().Done():
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+	writeJSON(w, map[string]any{"Messages": messages})
+}
+
+func (f *fakeAWS) deleteMessage(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		ReceiptHandle string
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	f.lock.Lock()
+	f.deleted = append(f.deleted, input.ReceiptHandle)
+	f.lock.Unlock()
+
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+	writeJSON(w, map[string]any{})
+}
+
+func (f *fakeAWS) deletedReceipts() []string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return slices.Clone(f.deleted)
+}
+
+func writeJSON(w http.ResponseWriter, value any) {
+	if len(w.Header().Get("Content-Type")) == 0 {
+		w.Header().Set("Content-Type", configContentType)
+	}
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func writeValidationError(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", configContentType)
+	w.WriteHeader(http.StatusBadRequest)
+	_, _ = w.Write([]byte(`{"__type":"com.amazonaws.starling.dove#ValidationException","message":"invalid request"}`))
+}
+
+func testItem(accountID, region, resourceType, resourceID string) map[string]any {
+	return map[string]any{
+		"accountId":     accountID,
+		"awsRegion":     region,
+		"resourceType":  resourceType,
+		"resourceId":    resourceID,
+		"configuration": map[string]any{"name": resourceID},
+	}
+}
+
+func testSource(t *testing.T, fake *fakeAWS, cfg config) *Source {
+	t.Helper()
+
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	s, err := newSource(cfg, aws.Config{
+		Region:           "eu-west-1",
+		Credentials:      credentials.NewStaticCredentialsProvider("AKIDDEFAULT", "secret", ""),
+		BaseEndpoint:     aws.String(server.URL),
+		RetryMaxAttempts: 1,
+	})
+	require.NoError(t, err)
+	return s
+}
+
+func TestStartSyncProcess(t *testing.T) {
+	t.Parallel()
+
+	items := []map[string]any{
+		testItem(defaultAccountID, "eu-west-1", "AWS::S3::Bucket", "bucket-a"),
+		testItem(defaultAccountID, "eu-west-1", "AWS::S3::Bucket", "bucket-b"),
+		testItem(defaultAccountID, "eu-west-1", "AWS::EC2::Instance", "i-default"),
+		testItem(defaultAccountID, "us-east-1", "AWS::S3::Bucket", "bucket-us"),
+		testItem("111111111111", "eu-west-1", "AWS::EC2::Instance", "i-111-eu"),
+		testItem("111111111111", "us-east-1", "AWS::EC2::Instance", "i-111-us"),
+		testItem("222222222222", "us-east-1", "AWS::EC2::Instance", "i-222-us"),
+	}
+
+	upsert := func(accountID, region, resourceType, resourceID string) source.Data {
+		return source.Data{
+			Type:      resourceType,
+			Operation: source.DataOperationUpsert,
+			Values:    testItem(accountID, region, resourceType, resourceID),
+		}
+	}
+
+	tests := map[string]struct {
+		config   config
+		types    map[string]source.Extra
+		expected []source.Data
+	}{
+		"default credentials and region": {
+			config: config{},
+			types: map[string]source.Extra{
+				"AWS::S3::Bucket":    {},
+				"AWS::EC2::Instance": {},
+			},
+			expected: []source.Data{
+				upsert(defaultAccountID, "eu-west-1", "AWS::EC2::Instance", "i-default"),
+				upsert(defaultAccountID, "eu-west-1", "AWS::S3::Bucket", "bucket-a"),
+				upsert(defaultAccountID, "eu-west-1", "AWS::S3::Bucket", "bucket-b"),
+			},
+		},
+		"assumed roles across regions": {
+			config: config{
+				Regions: []string{"eu-west-1", "us-east-1"},
+				RoleARNs: []string{
+					"arn:aws:iam::111111111111:role/ibdm",
+					"arn:aws:iam::222222222222:role/ibdm",
+				},
+			},
+			types: map[string]source.Extra{
+				"AWS::EC2::Instance": {},
+			},
+			expected: []source.Data{
+				upsert("111111111111", "eu-west-1", "AWS::EC2::Instance", "i-111-eu"),
+				upsert("111111111111", "us-east-1", "AWS::EC2::Instance", "i-111-us"),
+				upsert("222222222222", "us-east-1", "AWS::EC2::Instance", "i-222-us"),
+			},
+		},
+		"failing type is skipped": {
+			config: config{Regions: []string{"eu-west-1", "us-east-1"}},
+			types: map[string]source.Extra{
+				brokenType:        {},
+				"AWS::S3::Bucket": {},
+			},
+			expected: []source.Data{
+				upsert(defaultAccountID, "eu-west-1", "AWS::S3::Bucket", "bucket-a"),
+				upsert(defaultAccountID, "eu-west-1", "AWS::S3::Bucket", "bucket-b"),
+				upsert(defaultAccountID, "us-east-1", "AWS::S3::Bucket", "bucket-us"),
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			s := testSource(t, &fakeAWS{items: items}, test.config)
+			results := make(chan source.Data, 10)
+			require.NoError(t, s.StartSyncProcess(t.Context(), test.types, results))
+			close(results)
+
+			received := make([]source.Data, 0)
+			for data := range results {
+				assert.False(t, data.Time.IsZero())
+				data.Time = time.Time{}
+				received = append(received, data)
+			}
+			assert.Equal(t, test.expected, received)
+		})
+	}
+}
+
+func TestStartEventStream(t *testing.T) {
+	t.Parallel()
+
+	changeTime := time.Date(2026, time.October, 1, 10, 0, 0, 0, time.UTC)
+	message := func(messageType, itemKey, accountID, resourceType, resourceID, status string) string {
+		return fmt.Sprintf(`{
+			"detail-type": "Config Configuration Item Change",
+			"source": "aws.config",
+			"time": "2026-10-01T10:00:05Z",
+			"detail": {
+				"messageType": %q,
+				%q: {
+					"awsAccountId": %q,
+					"awsRegion": "us-east-1",
+					"resourceType": %q,
+					"resourceId": %q,
+					"configurationItemStatus": %q,
+					"configurationItemCaptureTime": "2026-10-01T10:00:00Z"
+				}
+			}
+		}`, messageType, itemKey, accountID, resourceType, resourceID, status)
+	}
+
+	fake := &fakeAWS{
+		items: []map[string]any{
+			testItem("111111111111", "us-east-1", "AWS::EC2::Instance", "i-111-us"),
+			testItem("111111111111", "us-east-1", "AWS::S3::Bucket", "bucket-us"),
+			// readable with the default credentials, that must not be used for accounts without a role
+			testItem(defaultAccountID, "us-east-1", "AWS::EC2::Instance", "i-333-us"),
+		},
+		messages: []string{
+			message(configItemChangeMessageType, "configurationItem", "111111111111", "AWS::EC2::Instance", "i-111-us", "OK"),
+			message(configItemChangeMessageType, "configurationItem", "111111111111", "AWS::EC2::Instance", "i-deleted", resourceDeletedStatus),
+			message(oversizedConfigItemChangeMessageType, "configurationItemSummary", "111111111111", "AWS::S3::Bucket", "bucket-us", "ResourceDiscovered"),
+			message(configItemChangeMessageType, "configurationItem", "111111111111", brokenType, "broken", "OK"),
+			message(configItemChangeMessageType, "configurationItem", defaultAccountID, "AWS::SQS::Queue", "queue", "OK"),
+			message("ComplianceChangeNotification", "configurationItem", defaultAccountID, "AWS::S3::Bucket", "bucket-us", "OK"),
+			`not json`,
+			message(configItemChangeMessageType, "configurationItem", "333333333333", "AWS::EC2::Instance", "i-333-us", "OK"),
+		},
+	}
+
+	s := testSource(t, fake, config{
+		QueueURL: "https://sqs.us-east-1.amazonaws.com/000000000000/ibdm",
+		RoleARNs: []string{"arn:aws:iam::111111111111:role/ibdm"},
+	})
+	types := map[string]source.Extra{
+		"AWS::EC2::Instance": {},
+		"AWS::S3::Bucket":    {},
+		brokenType:           {},
+	}
+
+	ctx := t.Context()
+	results := make(chan source.Data, 10)
+	done := make(chan error)
+	go func() {
+		done <- s.StartEventStream(ctx, types, results)
+	}()
+
+	expected := []source.Data{
+		{
+			Type:      "AWS::EC2::Instance",
+			Operation: source.DataOperationUpsert,
+			Time:      changeTime,
+			Values:    testItem("111111111111", "us-east-1", "AWS::EC2::Instance", "i-111-us"),
+		},
+		{
+			Type:      "AWS::EC2::Instance",
+			Operation: source.DataOperationDelete,
+			Time:      changeTime,
+			Values: map[string]any{
+				"accountId":    "111111111111",
+				"awsRegion":    "us-east-1",
+				"resourceType": "AWS::EC2::Instance",
+				"resourceId":   "i-deleted",
+			},
+		},
+		{
+			Type:      "AWS::S3::Bucket",
+			Operation: source.DataOperationUpsert,
+			Time:      changeTime,
+			Values:    testItem("111111111111", "us-east-1", "AWS::S3::Bucket", "bucket-us"),
+		},
+	}
+
+	for _, data := range expected {
+		select {
+		case received := <-results:
+			assert.Equal(t, data, received)
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "timeout waiting for data")
+		}
+	}
+
+	// the message failing to read the resource state is kept in the queue to be received again, while
+	// the change of an account without a configured role is discarded
+	expectedDeleted := []string{"receipt-0", "receipt-1", "receipt-2", "receipt-4", "receipt-5", "receipt-6", "receipt-7"}
+	require.Eventually(t, func() bool {
+		return slices.Equal(expectedDeleted, fake.deletedReceipts())
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, s.Close(ctx, time.Second))
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timeout waiting for the event stream to stop")
+	}
+	assert.Empty(t, results)
+}
+
+func TestStartEventStreamMissingQueue(t *testing.T) {
+	t.Parallel()
+
+	s := testSource(t, &fakeAWS{}, config{})
+	err := s.StartEventStream(t.Context(), map[string]source.Extra{}, make(chan source.Data))
+	require.ErrorIs(t, err, ErrAWSSource)
+	require.ErrorIs(t, err, ErrMissingEnvVariable)
+}
@@ -0,0 +1,132 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+const (
+	// roleResourcePrefix is the resource prefix of the ARN of an IAM role.
+	roleResourcePrefix = "role/"
+	// queueHostPrefix is the prefix of the host of the regional SQS endpoints.
+	queueHostPrefix = "sqs."
+)
+
+var (
+	// ErrMissingEnvVariable reports missing mandatory environment variables.
+	ErrMissingEnvVariable = errors.New("missing environment variable")
+	// ErrInvalidEnvVariable reports malformed environment variable values.
+	ErrInvalidEnvVariable = errors.New("invalid environment value")
+)
+
+// config holds the environment-driven AWS settings.
+type config struct {
+	Regions    []string `env:"AWS_SOURCE_REGIONS" envSeparator:","`
+	RoleARNs   []string `env:"AWS_SOURCE_ROLE_ARNS" envSeparator:","`
+	ExternalID string   `env:"AWS_SOURCE_EXTERNAL_ID"`
+	QueueURL   string   `env:"AWS_SOURCE_QUEUE_URL"`
+}
+
+// account holds the credentials used to read the resources of a single AWS account.
+type account struct {
+	// id is the account identifier, empty for the account of the default credentials.
+	id          string
+	credentials aws.CredentialsProvider
+}
+
+// validate checks the configuration shared by the sync and the event stream.
+func (c config) validate() error {
+	if len(c.Regions) == 0 {
+		return fmt.Errorf("%w: %s", ErrMissingEnvVariable, "AWS_SOURCE_REGIONS")
+	}
+
+	for _, roleARN := range c.RoleARNs {
+		if _, err := roleAccountID(roleARN); err != nil {
+			return fmt.Errorf("%w: AWS_SOURCE_ROLE_ARNS: %w", ErrInvalidEnvVariable, err)
+		}
+	}
+
+	return nil
+}
+
+// validateForEventStream checks if the configuration is valid for event stream operations.
+func (c config) validateForEventStream() error {
+	if len(c.QueueURL) == 0 {
+		return fmt.Errorf("%w: %s", ErrMissingEnvVariable, "AWS_SOURCE_QUEUE_URL")
+	}
+
+	if _, err := url.ParseRequestURI(c.QueueURL); err != nil {
+		return fmt.Errorf("%w: AWS_SOURCE_QUEUE_URL: %w", ErrInvalidEnvVariable, err)
+	}
+
+	return nil
+}
+
+// accounts returns an account for every configured role, whose credentials are obtained by
+// assuming the role with the default credentials. Without roles, the default credentials are used
+// for the single account they belong to.
+func (c config) accounts(awsConfig aws.Config) []account {
+	if len(c.RoleARNs) == 0 {
+		return []account{{credentials: awsConfig.Credentials}}
+	}
+
+	client := sts.NewFromConfig(awsConfig)
+	accounts := make([]account, 0, len(c.RoleARNs))
+	for _, roleARN := range c.RoleARNs {
+		roleARN = strings.TrimSpace(roleARN)
+		// the role ARNs are already checked by validate
+		accountID, _ := roleAccountID(roleARN)
+		provider := stscreds.NewAssumeRoleProvider(client, roleARN, func(o *stscreds.AssumeRoleOptions) {
+			if len(c.ExternalID) > 0 {
+				o.ExternalID = aws.String(c.ExternalID)
+			}
+		})
+
+		accounts = append(accounts, account{
+			id:          accountID,
+			credentials: aws.NewCredentialsCache(provider),
+		})
+	}
+
+	return accounts
+}
+
+// queueRegion returns the region of the queue, read from the host of the queue URL when it is a
+// regional SQS endpoint, falling back to defaultRegion otherwise.
+func (c config) queueRegion(defaultRegion string) string {
+	queueURL, err := url.Parse(c.QueueURL)
+	if err != nil || !strings.HasPrefix(queueURL.Hostname(), queueHostPrefix) {
+		return defaultRegion
+	}
+
+	region, _, found := strings.Cut(strings.TrimPrefix(queueURL.Hostname(), queueHostPrefix), ".")
+	if !found {
+		return defaultRegion
+	}
+
+	return region
+}
+
+// roleAccountID returns the identifier of the account owning the IAM role with the given ARN.
+func roleAccountID(roleARN string) (string, error) {
+	parsed, err := arn.Parse(strings.TrimSpace(roleARN))
+	if err != nil {
+		return "", err
+	}
+
+	if parsed.Service != "iam" || !strings.HasPrefix(parsed.Resource, roleResourcePrefix) || len(parsed.AccountID) == 0 {
+		return "", fmt.Errorf("%q is not the ARN of an IAM role", roleARN)
+	}
+
+	return parsed.AccountID, nil
+}
@@ -0,0 +1,188 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package aws
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSource(t *testing.T) {
+	tests := map[string]struct {
+		env              map[string]string
+		expectedErr      error
+		expectedRegions  []string
+		expectedAccounts []string
+	}{
+		"missing regions": {
+			env:         map[string]string{},
+			expectedErr: ErrMissingEnvVariable,
+		},
+		"region of the default configuration": {
+			env: map[string]string{
+				"AWS_REGION": "eu-south-1",
+			},
+			expectedRegions:  []string{"eu-south-1"},
+			expectedAccounts: []string{""},
+		},
+		"regions and roles": {
+			env: map[string]string{
+				"AWS_REGION":             "eu-south-1",
+				"AWS_SOURCE_REGIONS":     "eu-west-1,us-east-1",
+				"AWS_SOURCE_ROLE_ARNS":   "arn:aws:iam::111111111111:role/ibdm,arn:aws:iam::222222222222:role/path/ibdm",
+				"AWS_SOURCE_EXTERNAL_ID": "external",
+			},
+			expectedRegions:  []string{"eu-west-1", "us-east-1"},
+			expectedAccounts: []string{"111111111111", "222222222222"},
+		},
+		"invalid role arn": {
+			env: map[string]string{
+				"AWS_SOURCE_REGIONS":   "eu-west-1",
+				"AWS_SOURCE_ROLE_ARNS": "arn:aws:iam::111111111111:user/ibdm",
+			},
+			expectedErr: ErrInvalidEnvVariable,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+			t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+			for _, key := range []string{"AWS_REGION", "AWS_DEFAULT_REGION", "AWS_SOURCE_REGIONS", "AWS_SOURCE_ROLE_ARNS", "AWS_SOURCE_EXTERNAL_ID"} {
+				t.Setenv(key, "")
+			}
+			for key, value := range test.env {
+				t.Setenv(key, value)
+			}
+
+			s, err := NewSource()
+			if test.expectedErr != nil {
+				require.ErrorIs(t, err, ErrAWSSource)
+				require.ErrorIs(t, err, test.expectedErr)
+				assert.Nil(t, s)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedRegions, s.Regions)
+			accounts := make([]string, 0, len(s.accounts))
+			for _, acc := range s.accounts {
+				assert.NotNil(t, acc.credentials)
+				accounts = append(accounts, acc.id)
+			}
+			assert.Equal(t, test.expectedAccounts, accounts)
+		})
+	}
+}
+
+func TestValidateForEventStream(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		queueURL    string
+		expectedErr error
+	}{
+		"valid queue": {
+			queueURL: "https://sqs.eu-west-1.amazonaws.com/000000000000/ibdm",
+		},
+		"missing queue": {
+			expectedErr: ErrMissingEnvVariable,
+		},
+		"invalid queue": {
+			queueURL:    "ibdm",
+			expectedErr: ErrInvalidEnvVariable,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := config{QueueURL: test.queueURL}.validateForEventStream()
+			if test.expectedErr != nil {
+				require.ErrorIs(t, err, test.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestQueueRegion(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		queueURL string
+		expected string
+	}{
+		"regional endpoint": {
+			queueURL: "https://sqs.us-east-2.amazonaws.com/000000000000/ibdm",
+			expected: "us-east-2",
+		},
+		"custom endpoint": {
+			queueURL: "http://localhost:4566/000000000000/ibdm",
+			expected: "eu-west-1",
+		},
+		"legacy endpoint": {
+			queueURL: "https://sqs/000000000000/ibdm",
+			expected: "eu-west-1",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, config{QueueURL: test.queueURL}.queueRegion("eu-west-1"))
+		})
+	}
+}
+
+func TestRoleAccountID(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		roleARN     string
+		expected    string
+		expectedErr bool
+	}{
+		"role": {
+			roleARN:  "arn:aws:iam::111111111111:role/ibdm",
+			expected: "111111111111",
+		},
+		"role with path": {
+			roleARN:  " arn:aws:iam::111111111111:role/service/ibdm ",
+			expected: "111111111111",
+		},
+		"user": {
+			roleARN:     "arn:aws:iam::111111111111:user/ibdm",
+			expectedErr: true,
+		},
+		"other service": {
+			roleARN:     "arn:aws:s3:::bucket",
+			expectedErr: true,
+		},
+		"not an arn": {
+			roleARN:     "ibdm",
+			expectedErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			accountID, err := roleAccountID(test.roleARN)
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, accountID)
+		})
+	}
+}
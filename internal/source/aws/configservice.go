@@ -0,0 +1,147 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/configservice"
+	"github.com/aws/aws-sdk-go-v2/service/configservice/types"
+)
+
+// configPageSize is the maximum number of resources listed by every list call to AWS Config.
+const configPageSize = 100
+
+var errConfigService = errors.New("aws config request failed")
+
+// configService reads the resources recorded by AWS Config. A single SDK client is shared by every
+// account and region, overriding the region and the credentials of each request with the ones of
+// the account it targets.
+type configService struct {
+	client *configservice.Client
+}
+
+// newConfigService returns a configService whose client is configured from awsConfig, including
+// its retries, its endpoint settings and its endpoint override, if any.
+func newConfigService(awsConfig aws.Config) *configService {
+	return &configService{client: configservice.NewFromConfig(awsConfig)}
+}
+
+// listResources returns a page of the keys of the resources of resourceType recorded in the account
+// and region, together with the token of the next page, empty on the last one.
+func (c *configService) listResources(ctx context.Context, acc account, region, resourceType, nextToken string) ([]types.ResourceKey, string, error) {
+	input := &configservice.ListDiscoveredResourcesInput{
+		ResourceType: types.ResourceType(resourceType),
+		Limit:        configPageSize,
+	}
+	if len(nextToken) > 0 {
+		input.NextToken = aws.String(nextToken)
+	}
+
+	output, err := c.client.ListDiscoveredResources(ctx, input, accountOptions(acc, region))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", errConfigService, err)
+	}
+
+	keys := make([]types.ResourceKey, 0, len(output.ResourceIdentifiers))
+	for _, identifier := range output.ResourceIdentifiers {
+		keys = append(keys, types.ResourceKey{
+			ResourceType: identifier.ResourceType,
+			ResourceId:   identifier.ResourceId,
+		})
+	}
+
+	return keys, aws.ToString(output.NextToken), nil
+}
+
+// resourceConfigs returns the current configuration items of the resources identified by keys,
+// together with the keys AWS Config was not able to process.
+func (c *configService) resourceConfigs(ctx context.Context, acc account, region string, keys []types.ResourceKey) ([]map[string]any, []types.ResourceKey, error) {
+	input := &configservice.BatchGetResourceConfigInput{ResourceKeys: keys}
+	output, err := c.client.BatchGetResourceConfig(ctx, input, accountOptions(acc, region))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", errConfigService, err)
+	}
+
+	items := make([]map[string]any, 0, len(output.BaseConfigurationItems))
+	for _, item := range output.BaseConfigurationItems {
+		items = append(items, configurationItemValues(item))
+	}
+
+	return items, output.UnprocessedResourceKeys, nil
+}
+
+// accountOptions returns the options sending a request to the region with the credentials of acc.
+func accountOptions(acc account, region string) func(*configservice.Options) {
+	return func(o *configservice.Options) {
+		o.Region = region
+		o.Credentials = acc.credentials
+	}
+}
+
+// configurationItemValues returns the values of a configuration item, using the same keys of the
+// AWS Config API. The configuration and the supplementary configuration, which AWS Config returns
+// as JSON encoded strings, are decoded, and the times are formatted as RFC 3339 strings.
+func configurationItemValues(item types.BaseConfigurationItem) map[string]any {
+	values := make(map[string]any)
+	setString := func(key string, value *string) {
+		if value != nil {
+			values[key] = *value
+		}
+	}
+	setTime := func(key string, value *time.Time) {
+		if value != nil {
+			values[key] = value.UTC().Format(time.RFC3339)
+		}
+	}
+	setEnum := func(key, value string) {
+		if len(value) > 0 {
+			values[key] = value
+		}
+	}
+
+	setString("accountId", item.AccountId)
+	setString("arn", item.Arn)
+	setString("availabilityZone", item.AvailabilityZone)
+	setString("awsRegion", item.AwsRegion)
+	setTime("configurationItemCaptureTime", item.ConfigurationItemCaptureTime)
+	setTime("configurationItemDeliveryTime", item.ConfigurationItemDeliveryTime)
+	setEnum("configurationItemStatus", string(item.ConfigurationItemStatus))
+	setString("configurationStateId", item.ConfigurationStateId)
+	setEnum("recordingFrequency", string(item.RecordingFrequency))
+	setTime("resourceCreationTime", item.ResourceCreationTime)
+	setString("resourceId", item.ResourceId)
+	setString("resourceName", item.ResourceName)
+	setEnum("resourceType", string(item.ResourceType))
+	setString("version", item.Version)
+
+	if item.Configuration != nil {
+		values["configuration"] = decodeJSONString(*item.Configuration)
+	}
+
+	if item.SupplementaryConfiguration != nil {
+		supplementary := make(map[string]any, len(item.SupplementaryConfiguration))
+		for key, value := range item.SupplementaryConfiguration {
+			supplementary[key] = decodeJSONString(value)
+		}
+		values["supplementaryConfiguration"] = supplementary
+	}
+
+	return values
+}
+
+// decodeJSONString returns the value encoded in value, or value itself if it is not valid JSON.
+func decodeJSONString(value string) any {
+	var decoded any
+	if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+		return value
+	}
+
+	return decoded
+}
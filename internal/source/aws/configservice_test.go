@@ -0,0 +1,176 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package aws
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hostRecorder is an HTTP client recording the host of the requests and answering with an empty
+// list of resources.
+type hostRecorder struct {
+	host string
+}
+
+func (r *hostRecorder) Do(req *http.Request) (*http.Response, error) {
+	r.host = req.URL.Host
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{configContentType}},
+		Body:       io.NopCloser(strings.NewReader(`{"resourceIdentifiers":[]}`)),
+	}, nil
+}
+
+func TestConfigServiceEndpoint(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		options  awsconfig.LoadOptions
+		region   string
+		expected string
+	}{
+		"regional endpoint": {
+			region:   "eu-west-1",
+			expected: "config.eu-west-1.amazonaws.com",
+		},
+		"china regional endpoint": {
+			region:   "cn-north-1",
+			expected: "config.cn-north-1.amazonaws.com.cn",
+		},
+		"govcloud regional endpoint": {
+			region:   "us-gov-west-1",
+			expected: "config.us-gov-west-1.amazonaws.com",
+		},
+		"fips endpoint": {
+			options:  awsconfig.LoadOptions{UseFIPSEndpoint: aws.FIPSEndpointStateEnabled},
+			region:   "us-east-1",
+			expected: "config-fips.us-east-1.amazonaws.com",
+		},
+		"dual-stack endpoint": {
+			options:  awsconfig.LoadOptions{UseDualStackEndpoint: aws.DualStackEndpointStateEnabled},
+			region:   "us-east-1",
+			expected: "config.us-east-1.api.aws",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			recorder := new(hostRecorder)
+			awsConfig := aws.Config{
+				Region:        "eu-west-1",
+				HTTPClient:    recorder,
+				ConfigSources: []any{test.options},
+			}
+
+			acc := account{credentials: credentials.NewStaticCredentialsProvider("AKID", "secret", "")}
+			_, _, err := newConfigService(awsConfig).listResources(t.Context(), acc, test.region, "AWS::S3::Bucket", "")
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, recorder.host)
+		})
+	}
+}
+
+func TestConfigServiceRetriesThrottling(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", configContentType)
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"ThrottlingException","message":"Rate exceeded"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"resourceIdentifiers":[{"resourceType":"AWS::S3::Bucket","resourceId":"bucket"}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	service := newConfigService(aws.Config{
+		Region:       "eu-west-1",
+		BaseEndpoint: aws.String(server.URL),
+		Retryer: func() aws.Retryer {
+			return retry.NewStandard(func(o *retry.StandardOptions) {
+				o.Backoff = retry.BackoffDelayerFunc(func(int, error) (time.Duration, error) { return 0, nil })
+			})
+		},
+	})
+
+	acc := account{credentials: credentials.NewStaticCredentialsProvider("AKID", "secret", "")}
+	keys, nextToken, err := service.listResources(t.Context(), acc, "eu-west-1", "AWS::S3::Bucket", "")
+	require.NoError(t, err)
+	assert.Equal(t, []types.ResourceKey{{ResourceType: "AWS::S3::Bucket", ResourceId: aws.String("bucket")}}, keys)
+	assert.Empty(t, nextToken)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestConfigServiceError(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeValidationError(w)
+	}))
+	t.Cleanup(server.Close)
+
+	service := newConfigService(aws.Config{
+		Region:           "eu-west-1",
+		BaseEndpoint:     aws.String(server.URL),
+		RetryMaxAttempts: 1,
+	})
+
+	acc := account{credentials: credentials.NewStaticCredentialsProvider("AKID", "secret", "")}
+	_, _, err := service.resourceConfigs(t.Context(), acc, "eu-west-1", []types.ResourceKey{{ResourceType: "AWS::S3::Bucket", ResourceId: aws.String("bucket")}})
+	require.ErrorIs(t, err, errConfigService)
+	assert.ErrorContains(t, err, "BatchGetResourceConfig")
+	assert.ErrorContains(t, err, "ValidationException: invalid request")
+}
+
+func TestConfigurationItemValues(t *testing.T) {
+	t.Parallel()
+
+	captureTime := time.Date(2026, time.October, 1, 10, 0, 0, 0, time.UTC)
+	item := configurationItemValues(types.BaseConfigurationItem{
+		AccountId:                    aws.String(defaultAccountID),
+		ResourceId:                   aws.String("bucket"),
+		ResourceType:                 types.ResourceTypeBucket,
+		ConfigurationItemStatus:      types.ConfigurationItemStatusOk,
+		ConfigurationItemCaptureTime: &captureTime,
+		Configuration:                aws.String(`{"name":"bucket","tags":["a"]}`),
+		SupplementaryConfiguration: map[string]string{
+			"BucketPolicy": `{"policyText":null}`,
+			"Plain":        "not json",
+		},
+	})
+
+	assert.Equal(t, map[string]any{
+		"accountId":                    defaultAccountID,
+		"resourceId":                   "bucket",
+		"resourceType":                 "AWS::S3::Bucket",
+		"configurationItemStatus":      "OK",
+		"configurationItemCaptureTime": "2026-10-01T10:00:00Z",
+		"configuration": map[string]any{
+			"name": "bucket",
+			"tags": []any{"a"},
+		},
+		"supplementaryConfiguration": map[string]any{
+			"BucketPolicy": map[string]any{"policyText": nil},
+			"Plain":        "not json",
+		},
+	}, item)
+}
@@ -0,0 +1,6 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+// Package aws provides a source implementation to integrate with Amazon Web Services resources
+// recorded by AWS Config.
+package aws
@@ -0,0 +1,99 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package aws
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/configservice/types"
+)
+
+const (
+	configItemChangeMessageType          = "ConfigurationItemChangeNotification"
+	oversizedConfigItemChangeMessageType = "OversizedConfigurationItemChangeNotification"
+
+	resourceDeletedStatus            = "ResourceDeleted"
+	resourceDeletedNotRecordedStatus = "ResourceDeletedNotRecorded"
+)
+
+var errInvalidMessage = errors.New("invalid configuration change message")
+
+// configEvent is the EventBridge event delivered by AWS Config for a configuration item change.
+// Oversized notifications carry a summary of the configuration item instead of the item itself.
+type configEvent struct {
+	Time   time.Time `json:"time"`
+	Detail struct {
+		MessageType              string                    `json:"messageType"`
+		ConfigurationItem        *configurationItemSummary `json:"configurationItem"`
+		ConfigurationItemSummary *configurationItemSummary `json:"configurationItemSummary"`
+	} `json:"detail"`
+}
+
+// configurationItemSummary holds the fields of a changed configuration item needed to identify
+// the resource and to read its current state.
+type configurationItemSummary struct {
+	AccountID    string    `json:"awsAccountId"`
+	Region       string    `json:"awsRegion"`
+	ResourceType string    `json:"resourceType"`
+	ResourceID   string    `json:"resourceId"`
+	Status       string    `json:"configurationItemStatus"`
+	CaptureTime  time.Time `json:"configurationItemCaptureTime"`
+}
+
+// parseConfigEvent returns the configuration item changed by the event in body and the time of the
+// change. A nil item without error is returned for messages that are not configuration changes.
+func parseConfigEvent(body string) (*configurationItemSummary, time.Time, error) {
+	var event configEvent
+	if err := json.Unmarshal([]byte(body), &event); err != nil {
+		return nil, time.Time{}, errors.Join(errInvalidMessage, err)
+	}
+
+	var item *configurationItemSummary
+	switch event.Detail.MessageType {
+	case configItemChangeMessageType:
+		item = event.Detail.ConfigurationItem
+	case oversizedConfigItemChangeMessageType:
+		item = event.Detail.ConfigurationItemSummary
+	default:
+		return nil, time.Time{}, nil
+	}
+
+	if item == nil || len(item.ResourceType) == 0 || len(item.ResourceID) == 0 {
+		return nil, time.Time{}, errInvalidMessage
+	}
+
+	eventTime := item.CaptureTime
+	if eventTime.IsZero() {
+		eventTime = event.Time
+	}
+	if eventTime.IsZero() {
+		eventTime = timeSource()
+	}
+
+	return item, eventTime, nil
+}
+
+// deleted reports whether the configuration item records the deletion of the resource.
+func (i configurationItemSummary) deleted() bool {
+	return i.Status == resourceDeletedStatus || i.Status == resourceDeletedNotRecordedStatus
+}
+
+// key returns the key identifying the resource of the configuration item in AWS Config.
+func (i configurationItemSummary) key() types.ResourceKey {
+	return types.ResourceKey{ResourceType: types.ResourceType(i.ResourceType), ResourceId: aws.String(i.ResourceID)}
+}
+
+// deleteValues returns the values sent for the deletion of the resource, using the same keys of
+// the configuration items returned by AWS Config.
+func (i configurationItemSummary) deleteValues() map[string]any {
+	return map[string]any{
+		"accountId":    i.AccountID,
+		"awsRegion":    i.Region,
+		"resourceType": i.ResourceType,
+		"resourceId":   i.ResourceID,
+	}
+}
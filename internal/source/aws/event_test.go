@@ -0,0 +1,95 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package aws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfigEvent(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		body         string
+		expectedItem *configurationItemSummary
+		expectedTime time.Time
+		expectedErr  error
+	}{
+		"configuration item change": {
+			body: `{"time":"2026-10-01T10:00:05Z","detail":{"messageType":"ConfigurationItemChangeNotification","configurationItem":{
+				"awsAccountId":"111111111111","awsRegion":"eu-west-1","resourceType":"AWS::S3::Bucket","resourceId":"bucket",
+				"configurationItemStatus":"OK","configurationItemCaptureTime":"2026-10-01T10:00:00.123Z","configuration":{"name":"bucket"}}}}`,
+			expectedItem: &configurationItemSummary{
+				AccountID:    "111111111111",
+				Region:       "eu-west-1",
+				ResourceType: "AWS::S3::Bucket",
+				ResourceID:   "bucket",
+				Status:       "OK",
+				CaptureTime:  time.Date(2026, time.October, 1, 10, 0, 0, 123000000, time.UTC),
+			},
+			expectedTime: time.Date(2026, time.October, 1, 10, 0, 0, 123000000, time.UTC),
+		},
+		"oversized change uses the event time without capture time": {
+			body: `{"time":"2026-10-01T10:00:05Z","detail":{"messageType":"OversizedConfigurationItemChangeNotification","configurationItemSummary":{
+				"awsAccountId":"111111111111","awsRegion":"eu-west-1","resourceType":"AWS::S3::Bucket","resourceId":"bucket",
+				"configurationItemStatus":"ResourceDeleted"}}}`,
+			expectedItem: &configurationItemSummary{
+				AccountID:    "111111111111",
+				Region:       "eu-west-1",
+				ResourceType: "AWS::S3::Bucket",
+				ResourceID:   "bucket",
+				Status:       "ResourceDeleted",
+			},
+			expectedTime: time.Date(2026, time.October, 1, 10, 0, 5, 0, time.UTC),
+		},
+		"other message type": {
+			body: `{"detail":{"messageType":"ComplianceChangeNotification"}}`,
+		},
+		"missing configuration item": {
+			body:        `{"detail":{"messageType":"ConfigurationItemChangeNotification"}}`,
+			expectedErr: errInvalidMessage,
+		},
+		"missing resource id": {
+			body:        `{"detail":{"messageType":"ConfigurationItemChangeNotification","configurationItem":{"resourceType":"AWS::S3::Bucket"}}}`,
+			expectedErr: errInvalidMessage,
+		},
+		"invalid json": {
+			body:        `{`,
+			expectedErr: errInvalidMessage,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			item, eventTime, err := parseConfigEvent(test.body)
+			if test.expectedErr != nil {
+				require.ErrorIs(t, err, test.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedItem, item)
+			assert.True(t, test.expectedTime.Equal(eventTime))
+		})
+	}
+}
+
+func TestConfigurationItemSummaryDeleted(t *testing.T) {
+	t.Parallel()
+
+	for status, expected := range map[string]bool{
+		"OK":                             false,
+		"ResourceDiscovered":             false,
+		resourceDeletedStatus:            true,
+		resourceDeletedNotRecordedStatus: true,
+	} {
+		assert.Equal(t, expected, configurationItemSummary{Status: status}.deleted(), status)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"sync"
 
+	"github.com/mia-platform/ibdm/internal/ratelimit"
 	"github.com/mia-platform/ibdm/internal/source"
 )
 
@@ -56,7 +57,7 @@ func NewSource() (*Source, error) {
 			apiUsername: srcCfg.APIUsername,
 			apiToken:    srcCfg.APIToken,
 			httpClient: &http.Client{
-				Timeout: srcCfg.HTTPTimeout,
+				Transport: ratelimit.NewTransport("bitbucket", ratelimit.Bitbucket, nil, ratelimit.WithAttemptTimeout(srcCfg.HTTPTimeout)),
 			},
 		},
 	}, nil
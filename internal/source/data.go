@@ -3,7 +3,11 @@
 
 package source
 
-import "time"
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
 
 //go:generate ${TOOLS_BIN}/stringer -type=DataOperation -trimprefix DataOperation
 type DataOperation int
@@ -15,6 +19,11 @@ const (
 	DataOperationDelete
 )
 
+const (
+	recordOperationUpsert = "upsert"
+	recordOperationDelete = "delete"
+)
+
 var (
 	nowFunc = time.Now
 )
@@ -39,3 +48,52 @@ func (d *Data) Timestamp() string {
 
 	return dataTime.UTC().Format(time.RFC3339)
 }
+
+// record is the JSON form of Data, used to record and read back raw source data.
+type record struct {
+	Type      string         `json:"type"`
+	Operation string         `json:"operation,omitempty"`
+	Values    map[string]any `json:"values"`
+	Time      time.Time      `json:"time,omitzero"`
+}
+
+// MarshalJSON encodes the data with its type, values, the operation as upsert or delete, and the
+// time of the event when set.
+func (d Data) MarshalJSON() ([]byte, error) {
+	rec := record{
+		Type:      d.Type,
+		Operation: recordOperationUpsert,
+		Values:    d.Values,
+		Time:      d.Time,
+	}
+	if d.Operation == DataOperationDelete {
+		rec.Operation = recordOperationDelete
+	}
+
+	return json.Marshal(rec)
+}
+
+// UnmarshalJSON decodes the form written by MarshalJSON, a missing operation is read as an upsert.
+func (d *Data) UnmarshalJSON(data []byte) error {
+	var rec record
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return err
+	}
+
+	operation := DataOperationUpsert
+	switch rec.Operation {
+	case "", recordOperationUpsert:
+	case recordOperationDelete:
+		operation = DataOperationDelete
+	default:
+		return fmt.Errorf("unknown operation %q", rec.Operation)
+	}
+
+	*d = Data{
+		Type:      rec.Type,
+		Operation: operation,
+		Values:    rec.Values,
+		Time:      rec.Time,
+	}
+	return nil
+}
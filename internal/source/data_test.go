@@ -4,10 +4,12 @@
 package source
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDataOperationString(t *testing.T) {
@@ -68,3 +70,60 @@ func TestDataTimestamp(t *testing.T) {
 		})
 	}
 }
+
+func TestDataJSON(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		data     Data
+		expected string
+	}{
+		"upsert with time": {
+			data: Data{
+				Type:      "repository",
+				Operation: DataOperationUpsert,
+				Values:    map[string]any{"id": "1"},
+				Time:      time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+			},
+			expected: `{"type":"repository","operation":"upsert","values":{"id":"1"},"time":"2024-06-01T12:00:00Z"}`,
+		},
+		"delete without time": {
+			data: Data{
+				Type:      "repository",
+				Operation: DataOperationDelete,
+				Values:    map[string]any{"id": "1"},
+			},
+			expected: `{"type":"repository","operation":"delete","values":{"id":"1"}}`,
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			marshaled, err := json.Marshal(test.data)
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expected, string(marshaled))
+
+			var unmarshaled Data
+			require.NoError(t, json.Unmarshal(marshaled, &unmarshaled))
+			assert.Equal(t, test.data, unmarshaled)
+		})
+	}
+
+	t.Run("missing operation is read as upsert", func(t *testing.T) {
+		t.Parallel()
+
+		var data Data
+		require.NoError(t, json.Unmarshal([]byte(`{"type":"repository","values":{}}`), &data))
+		assert.Equal(t, Data{Type: "repository", Operation: DataOperationUpsert, Values: map[string]any{}}, data)
+	})
+
+	t.Run("unknown operation returns error", func(t *testing.T) {
+		t.Parallel()
+
+		var data Data
+		err := json.Unmarshal([]byte(`{"type":"repository","operation":"patch"}`), &data)
+		assert.EqualError(t, err, `unknown operation "patch"`)
+	})
+}
@@ -0,0 +1,67 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package file
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/caarlos0/env/v11"
+)
+
+const (
+	// typeGroupName is the name of the pattern group that captures the data type.
+	typeGroupName = "type"
+)
+
+var (
+	// ErrMissingEnvVariable reports missing mandatory environment variables.
+	ErrMissingEnvVariable = errors.New("missing environment variable")
+	// ErrInvalidEnvVariable reports malformed environment variable values.
+	ErrInvalidEnvVariable = errors.New("invalid environment value")
+)
+
+// config holds the environment-driven file source settings.
+type config struct {
+	Dir         string        `env:"FILE_SOURCE_DIR"`
+	TypePattern string        `env:"FILE_SOURCE_TYPE_PATTERN"`
+	TypeField   string        `env:"FILE_SOURCE_TYPE_FIELD"`
+	KeyField    string        `env:"FILE_SOURCE_KEY_FIELD"`
+	WatchDelay  time.Duration `env:"FILE_SOURCE_WATCH_DELAY" envDefault:"500ms"`
+
+	typePattern *regexp.Regexp
+}
+
+// loadConfigFromEnv parses configuration from environment variables and
+// validates the result.
+func loadConfigFromEnv() (*config, error) {
+	cfg, err := env.ParseAs[config]()
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// validate checks that all required fields are present and compiles the type pattern.
+func (c *config) validate() error {
+	if len(c.Dir) == 0 {
+		return fmt.Errorf("%w: %s", ErrMissingEnvVariable, "FILE_SOURCE_DIR")
+	}
+	if c.WatchDelay < 0 {
+		return fmt.Errorf("%w: FILE_SOURCE_WATCH_DELAY must not be negative, got %s", ErrInvalidEnvVariable, c.WatchDelay)
+	}
+	if len(c.TypePattern) > 0 {
+		pattern, err := regexp.Compile(c.TypePattern)
+		if err != nil {
+			return fmt.Errorf("%w: FILE_SOURCE_TYPE_PATTERN: %w", ErrInvalidEnvVariable, err)
+		}
+		c.typePattern = pattern
+	}
+	return nil
+}
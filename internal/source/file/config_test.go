@@ -0,0 +1,78 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package file
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfigFromEnv(t *testing.T) {
+	t.Run("valid full configuration", func(t *testing.T) {
+		t.Setenv("FILE_SOURCE_DIR", "inventory")
+		t.Setenv("FILE_SOURCE_TYPE_PATTERN", `^(?P<type>[a-z]+)-`)
+		t.Setenv("FILE_SOURCE_TYPE_FIELD", "kind")
+		t.Setenv("FILE_SOURCE_KEY_FIELD", "id")
+		t.Setenv("FILE_SOURCE_WATCH_DELAY", "1s")
+
+		cfg, err := loadConfigFromEnv()
+		require.NoError(t, err)
+		assert.Equal(t, "inventory", cfg.Dir)
+		assert.Equal(t, "kind", cfg.TypeField)
+		assert.Equal(t, "id", cfg.KeyField)
+		assert.Equal(t, time.Second, cfg.WatchDelay)
+		require.NotNil(t, cfg.typePattern)
+		assert.Equal(t, `^(?P<type>[a-z]+)-`, cfg.typePattern.String())
+	})
+
+	t.Run("valid minimal configuration with defaults", func(t *testing.T) {
+		t.Setenv("FILE_SOURCE_DIR", "inventory")
+
+		cfg, err := loadConfigFromEnv()
+		require.NoError(t, err)
+		assert.Equal(t, 500*time.Millisecond, cfg.WatchDelay)
+		assert.Nil(t, cfg.typePattern)
+	})
+}
+
+func TestConfigValidation(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		config    config
+		expectErr error
+	}{
+		"valid config": {
+			config: config{Dir: "inventory", TypePattern: `^([a-z]+)`},
+		},
+		"missing directory": {
+			config:    config{},
+			expectErr: ErrMissingEnvVariable,
+		},
+		"negative watch delay": {
+			config:    config{Dir: "inventory", WatchDelay: -time.Second},
+			expectErr: ErrInvalidEnvVariable,
+		},
+		"invalid type pattern": {
+			config:    config{Dir: "inventory", TypePattern: `([a-z]+`},
+			expectErr: ErrInvalidEnvVariable,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := test.config.validate()
+			if test.expectErr != nil {
+				assert.ErrorIs(t, err, test.expectErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
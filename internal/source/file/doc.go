@@ -0,0 +1,6 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+// Package file provides a source implementation that reads the records of the JSON, YAML and CSV
+// files found in a directory, and watches it to send the records added, changed or removed.
+package file
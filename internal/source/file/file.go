@@ -0,0 +1,350 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/mia-platform/ibdm/internal/logger"
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+const (
+	loggerName = "ibdm:source:file"
+)
+
+var (
+	// ErrFileSource wraps all errors originating from the file source.
+	ErrFileSource = errors.New("file source")
+
+	// timeSource is a package-level function for the current time, replaceable in tests.
+	timeSource = time.Now
+)
+
+var _ source.SyncableSource = &Source{}
+var _ source.EventSource = &Source{}
+
+// Source implements [source.SyncableSource] and [source.EventSource] for the records stored in
+// the JSON, YAML and CSV files of a directory. The sync sends every record, while the event stream
+// watches the directory and sends the records added, changed or removed since it started.
+type Source struct {
+	config config
+
+	syncLock   sync.Mutex
+	streamLock sync.Mutex
+}
+
+// NewSource constructs a [Source] by reading its configuration from environment variables.
+// It returns [ErrFileSource] if the configuration is invalid.
+func NewSource() (*Source, error) {
+	cfg, err := loadConfigFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFileSource, err)
+	}
+
+	return &Source{config: *cfg}, nil
+}
+
+// StartSyncProcess sends an upsert for every record of the requested types found in the files of
+// the directory. Files that cannot be read are reported and skipped.
+func (s *Source) StartSyncProcess(ctx context.Context, typesToSync map[string]source.Extra, results chan<- source.Data) error {
+	log := logger.FromContext(ctx).WithName(loggerName)
+	if !s.syncLock.TryLock() {
+		log.Debug("sync process already running")
+		return nil
+	}
+	defer s.syncLock.Unlock()
+
+	paths, err := s.filePaths()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFileSource, err)
+	}
+
+	for _, path := range paths {
+		records, err := s.fileRecords(ctx, path, typesToSync)
+		if err != nil {
+			log.Error("error reading file", "path", path, "error", err)
+			continue
+		}
+
+		for _, data := range records {
+			select {
+			case <-ctx.Done():
+				return nil
+			case results <- data:
+			}
+		}
+	}
+
+	return nil
+}
+
+// StartEventStream watches the directory until ctx is cancelled. Every time a file changes, an
+// upsert is sent for its new and changed records and a delete for the records no longer found.
+// The records found when the stream starts are not sent, use a sync to load them.
+func (s *Source) StartEventStream(ctx context.Context, typesToStream map[string]source.Extra, results chan<- source.Data) error {
+	log := logger.FromContext(ctx).WithName(loggerName)
+	if !s.streamLock.TryLock() {
+		log.Debug("event stream already running")
+		return nil
+	}
+	defer s.streamLock.Unlock()
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFileSource, err)
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(s.config.Dir); err != nil {
+		return fmt.Errorf("%w: %w", ErrFileSource, err)
+	}
+
+	state, err := s.currentRecords(ctx, typesToStream)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFileSource, err)
+	}
+
+	timer := time.NewTimer(s.config.WatchDelay)
+	timer.Stop()
+	defer timer.Stop()
+
+	changed := make(map[string]struct{})
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op == fsnotify.Chmod || !supportedFile(event.Name) {
+				continue
+			}
+			log.Trace("file changed", "path", event.Name, "operation", event.Op.String())
+			changed[event.Name] = struct{}{}
+			timer.Reset(s.config.WatchDelay)
+		case <-timer.C:
+			if err := s.sendChangedFiles(ctx, changed, typesToStream, state, results); err != nil {
+				return nil
+			}
+			clear(changed)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Error("error watching files", "error", err)
+		}
+	}
+}
+
+// currentRecords returns the records of the files in the directory by their path, the files that
+// cannot be read are reported and skipped.
+func (s *Source) currentRecords(ctx context.Context, types map[string]source.Extra) (map[string][]source.Data, error) {
+	log := logger.FromContext(ctx).WithName(loggerName)
+	paths, err := s.filePaths()
+	if err != nil {
+		return nil, err
+	}
+
+	state := make(map[string][]source.Data, len(paths))
+	for _, path := range paths {
+		records, err := s.fileRecords(ctx, path, types)
+		if err != nil {
+			log.Error("error reading file", "path", path, "error", err)
+			continue
+		}
+		state[path] = records
+	}
+	return state, nil
+}
+
+// sendChangedFiles sends the changes of every changed file, keeping the previous records of the
+// files that cannot be read. The deletes of all the files are sent before their upserts, so a
+// record moved from one file to another in the same batch is not removed after being upserted.
+// It returns an error only when ctx is cancelled.
+func (s *Source) sendChangedFiles(ctx context.Context, changed map[string]struct{}, types map[string]source.Extra, state map[string][]source.Data, results chan<- source.Data) error {
+	log := logger.FromContext(ctx).WithName(loggerName)
+	deletes := make([]source.Data, 0)
+	upserts := make([]source.Data, 0)
+	for _, path := range slices.Sorted(maps.Keys(changed)) {
+		fileDeletes, fileUpserts, err := s.fileChanges(ctx, path, types, state)
+		if err != nil {
+			log.Error("error reading changed file, keeping its previous records", "path", path, "error", err)
+			continue
+		}
+		deletes = append(deletes, fileDeletes...)
+		upserts = append(upserts, fileUpserts...)
+	}
+
+	now := timeSource()
+	for _, data := range slices.Concat(deletes, upserts) {
+		data.Time = now
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case results <- data:
+		}
+	}
+	return nil
+}
+
+// fileChanges compares the records of the file at path with the ones in state, returns the deleted
+// records and the new or changed ones, and updates state.
+func (s *Source) fileChanges(ctx context.Context, path string, types map[string]source.Extra, state map[string][]source.Data) ([]source.Data, []source.Data, error) {
+	records, err := s.fileRecords(ctx, path, types)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		records = nil
+	case err != nil:
+		return nil, nil, err
+	}
+
+	oldRecords := s.recordsByKey(state[path])
+	newRecords := s.recordsByKey(records)
+
+	// a record whose key is its whole content is replaced by a delete and an upsert
+	deletes := make([]source.Data, 0)
+	for _, deleted := range state[path] {
+		if _, found := newRecords[s.recordKey(deleted)]; !found {
+			deleted.Operation = source.DataOperationDelete
+			deletes = append(deletes, deleted)
+		}
+	}
+	upserts := make([]source.Data, 0)
+	for _, data := range records {
+		if old, found := oldRecords[s.recordKey(data)]; !found || !reflect.DeepEqual(old.Values, data.Values) {
+			upserts = append(upserts, data)
+		}
+	}
+
+	if records == nil {
+		delete(state, path)
+	} else {
+		state[path] = records
+	}
+	return deletes, upserts, nil
+}
+
+// filePaths returns the supported files of the directory sorted by name.
+func (s *Source) filePaths() ([]string, error) {
+	entries, err := os.ReadDir(s.config.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !supportedFile(entry.Name()) {
+			continue
+		}
+		paths = append(paths, filepath.Join(s.config.Dir, entry.Name()))
+	}
+
+	return paths, nil
+}
+
+// fileRecords reads the file at path and returns an upsert for each of its records of the
+// requested types, timed with the last modification of the file.
+func (s *Source) fileRecords(ctx context.Context, path string, types map[string]source.Extra) ([]source.Data, error) {
+	log := logger.FromContext(ctx).WithName(loggerName)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := readRecords(path)
+	if err != nil {
+		return nil, err
+	}
+
+	fileType, hasFileType := s.fileType(path)
+	data := make([]source.Data, 0, len(records))
+	for _, record := range records {
+		dataType := fileType
+		if len(s.config.TypeField) > 0 {
+			value, found := record[s.config.TypeField]
+			if !found {
+				log.Debug("record without type field, skipping", "path", path, "field", s.config.TypeField)
+				continue
+			}
+			dataType = fmt.Sprint(value)
+		} else if !hasFileType {
+			log.Debug("file name does not match the type pattern, skipping", "path", path)
+			return data, nil
+		}
+
+		if _, found := types[dataType]; !found {
+			continue
+		}
+
+		data = append(data, source.Data{
+			Type:      dataType,
+			Operation: source.DataOperationUpsert,
+			Values:    record,
+			Time:      info.ModTime().UTC(),
+		})
+	}
+
+	return data, nil
+}
+
+// fileType derives the data type from the name of the file at path: the type group of the
+// configured pattern, its first group or its whole match, or the file name without its extension
+// when no pattern is configured.
+func (s *Source) fileType(path string) (string, bool) {
+	name := filepath.Base(path)
+	if s.config.typePattern == nil {
+		return strings.TrimSuffix(name, filepath.Ext(name)), true
+	}
+
+	match := s.config.typePattern.FindStringSubmatch(name)
+	if match == nil {
+		return "", false
+	}
+
+	if index := s.config.typePattern.SubexpIndex(typeGroupName); index > 0 {
+		return match[index], true
+	}
+	if len(match) > 1 {
+		return match[1], true
+	}
+	return match[0], true
+}
+
+// recordsByKey indexes records by their key.
+func (s *Source) recordsByKey(records []source.Data) map[string]source.Data {
+	indexed := make(map[string]source.Data, len(records))
+	for _, data := range records {
+		indexed[s.recordKey(data)] = data
+	}
+	return indexed
+}
+
+// recordKey identifies a record between two reads of the same file: its type and the value of the
+// configured key field, or its whole content when no key field is configured.
+func (s *Source) recordKey(data source.Data) string {
+	if len(s.config.KeyField) > 0 {
+		return data.Type + "/" + fmt.Sprint(data.Values[s.config.KeyField])
+	}
+
+	// the encoding of a map is stable because keys are sorted
+	encoded, _ := json.Marshal(data.Values)
+	return data.Type + "/" + string(encoded)
+}
@@ -0,0 +1,271 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package file
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+var (
+	testModTime = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+)
+
+// writeFiles writes files in dir with the same modification time.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		require.NoError(t, os.Chtimes(path, testModTime, testModTime))
+	}
+}
+
+// syncData runs a sync of s and returns the data it sends.
+func syncData(t *testing.T, s *Source, types map[string]source.Extra) []source.Data {
+	t.Helper()
+
+	results := make(chan source.Data, 100)
+	require.NoError(t, s.StartSyncProcess(t.Context(), types, results))
+	close(results)
+
+	data := make([]source.Data, 0)
+	for item := range results {
+		data = append(data, item)
+	}
+	return data
+}
+
+func TestStartSyncProcess(t *testing.T) {
+	t.Parallel()
+
+	files := map[string]string{
+		"servers.yaml":    "- id: srv-1\n  kind: server\n- id: saas-1\n  kind: saas\n",
+		"teams-2024.csv":  "name,owner\nplatform,alice\n",
+		"broken.json":     `{"id":`,
+		"notes.txt":       "not a record",
+		"unmapped.json":   `{"id":"1"}`,
+		"vendor-saas.yml": "id: saas-2\n",
+	}
+
+	testCases := map[string]struct {
+		config       config
+		types        map[string]source.Extra
+		expectedData []source.Data
+	}{
+		"type from the file name": {
+			types: map[string]source.Extra{"servers": nil, "teams-2024": nil, "vendor-saas": nil},
+			expectedData: []source.Data{
+				{Type: "servers", Operation: source.DataOperationUpsert, Values: map[string]any{"id": "srv-1", "kind": "server"}, Time: testModTime},
+				{Type: "servers", Operation: source.DataOperationUpsert, Values: map[string]any{"id": "saas-1", "kind": "saas"}, Time: testModTime},
+				{Type: "teams-2024", Operation: source.DataOperationUpsert, Values: map[string]any{"name": "platform", "owner": "alice"}, Time: testModTime},
+				{Type: "vendor-saas", Operation: source.DataOperationUpsert, Values: map[string]any{"id": "saas-2"}, Time: testModTime},
+			},
+		},
+		"type from the file name pattern": {
+			config: config{typePattern: regexp.MustCompile(`^(?:vendor-)?(?P<type>[a-z]+)`)},
+			types:  map[string]source.Extra{"teams": nil, "saas": nil},
+			expectedData: []source.Data{
+				{Type: "teams", Operation: source.DataOperationUpsert, Values: map[string]any{"name": "platform", "owner": "alice"}, Time: testModTime},
+				{Type: "saas", Operation: source.DataOperationUpsert, Values: map[string]any{"id": "saas-2"}, Time: testModTime},
+			},
+		},
+		"type from a field": {
+			config: config{TypeField: "kind"},
+			types:  map[string]source.Extra{"saas": nil},
+			expectedData: []source.Data{
+				{Type: "saas", Operation: source.DataOperationUpsert, Values: map[string]any{"id": "saas-1", "kind": "saas"}, Time: testModTime},
+			},
+		},
+		"files not matching the pattern are skipped": {
+			config:       config{typePattern: regexp.MustCompile(`^inventory-(.+)\.`)},
+			types:        map[string]source.Extra{"servers": nil},
+			expectedData: []source.Data{},
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			writeFiles(t, dir, files)
+
+			test.config.Dir = dir
+			s := &Source{config: test.config}
+			assert.Equal(t, test.expectedData, syncData(t, s, test.types))
+		})
+	}
+
+	t.Run("missing directory returns error", func(t *testing.T) {
+		t.Parallel()
+
+		s := &Source{config: config{Dir: filepath.Join(t.TempDir(), "missing")}}
+		err := s.StartSyncProcess(t.Context(), map[string]source.Extra{}, make(chan source.Data))
+		assert.ErrorIs(t, err, ErrFileSource)
+	})
+}
+
+func TestStartEventStream(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		keyField string
+		expected []source.Data
+	}{
+		"records are identified by the key field": {
+			keyField: "id",
+			expected: []source.Data{
+				{Type: "servers", Operation: source.DataOperationDelete, Values: map[string]any{"id": "2", "cpu": 2}},
+				{Type: "servers", Operation: source.DataOperationUpsert, Values: map[string]any{"id": "1", "cpu": 8}},
+				{Type: "servers", Operation: source.DataOperationUpsert, Values: map[string]any{"id": "3", "cpu": 2}},
+			},
+		},
+		"records are identified by their content": {
+			expected: []source.Data{
+				{Type: "servers", Operation: source.DataOperationDelete, Values: map[string]any{"id": "1", "cpu": 4}},
+				{Type: "servers", Operation: source.DataOperationDelete, Values: map[string]any{"id": "2", "cpu": 2}},
+				{Type: "servers", Operation: source.DataOperationUpsert, Values: map[string]any{"id": "1", "cpu": 8}},
+				{Type: "servers", Operation: source.DataOperationUpsert, Values: map[string]any{"id": "3", "cpu": 2}},
+			},
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{
+				"servers.yaml": "- {id: \"1\", cpu: 4}\n- {id: \"2\", cpu: 2}\n",
+			})
+
+			s := &Source{config: config{Dir: dir, KeyField: test.keyField, WatchDelay: 50 * time.Millisecond}}
+			types := map[string]source.Extra{"servers": nil, "probe": nil}
+			results := make(chan source.Data, 100)
+
+			ctx, cancel := context.WithCancel(t.Context())
+			var wg sync.WaitGroup
+			wg.Go(func() {
+				assert.NoError(t, s.StartEventStream(ctx, types, results))
+			})
+			defer func() {
+				cancel()
+				wg.Wait()
+			}()
+
+			receive := func(t *testing.T, count int) []source.Data {
+				t.Helper()
+
+				received := make([]source.Data, 0, count)
+				for range count {
+					select {
+					case data := <-results:
+						assert.False(t, data.Time.IsZero())
+						data.Time = time.Time{}
+						received = append(received, data)
+					case <-time.After(5 * time.Second):
+						require.Fail(t, "timeout waiting for file changes")
+					}
+				}
+				return received
+			}
+
+			// wait for the watcher to be registered before changing the files
+			attempt := 0
+			require.Eventually(t, func() bool {
+				attempt++
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "probe.json"), fmt.Appendf(nil, `{"attempt":%d}`, attempt), 0o600))
+				select {
+				case data := <-results:
+					assert.Equal(t, "probe", data.Type)
+					return true
+				case <-time.After(200 * time.Millisecond):
+					return false
+				}
+			}, 5*time.Second, 10*time.Millisecond)
+			require.Eventually(t, func() bool {
+				select {
+				case <-results:
+					return false
+				case <-time.After(200 * time.Millisecond):
+					return true
+				}
+			}, 5*time.Second, 10*time.Millisecond, "drain the events of the previous attempts")
+
+			// changed files send the differences
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "servers.yaml"), []byte("- {id: \"1\", cpu: 8}\n- {id: \"3\", cpu: 2}\n"), 0o600))
+			assert.Equal(t, test.expected, receive(t, len(test.expected)))
+
+			// invalid files keep the previous records
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "servers.yaml"), []byte("- {id: "), 0o600))
+			select {
+			case data := <-results:
+				assert.Fail(t, "invalid files must not send changes", "received %v", data)
+			case <-time.After(300 * time.Millisecond):
+			}
+
+			// removed files delete their records
+			require.NoError(t, os.Remove(filepath.Join(dir, "servers.yaml")))
+			assert.ElementsMatch(t, []source.Data{
+				{Type: "servers", Operation: source.DataOperationDelete, Values: map[string]any{"id": "1", "cpu": 8}},
+				{Type: "servers", Operation: source.DataOperationDelete, Values: map[string]any{"id": "3", "cpu": 2}},
+			}, receive(t, 2))
+		})
+	}
+}
+
+func TestSendChangedFilesSendsDeletesFirst(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	firstPath := filepath.Join(dir, "b-servers.yaml")
+	secondPath := filepath.Join(dir, "a-servers.yaml")
+	writeFiles(t, dir, map[string]string{
+		"a-servers.yaml": "[]\n",
+		"b-servers.yaml": "- {id: \"1\", cpu: 4}\n",
+	})
+
+	s := &Source{config: config{Dir: dir, KeyField: "id", typePattern: regexp.MustCompile(`-(?P<type>\w+)\.yaml$`)}}
+	types := map[string]source.Extra{"servers": nil}
+	state, err := s.currentRecords(t.Context(), types)
+	require.NoError(t, err)
+
+	// the record moves from the first file to the second one, that is read before, in the same batch
+	writeFiles(t, dir, map[string]string{
+		"a-servers.yaml": "- {id: \"1\", cpu: 4}\n",
+		"b-servers.yaml": "[]\n",
+	})
+	results := make(chan source.Data, 10)
+	changed := map[string]struct{}{secondPath: {}, firstPath: {}}
+	require.NoError(t, s.sendChangedFiles(t.Context(), changed, types, state, results))
+	close(results)
+
+	operations := make([]source.DataOperation, 0)
+	for data := range results {
+		assert.Equal(t, map[string]any{"id": "1", "cpu": 4}, data.Values)
+		operations = append(operations, data.Operation)
+	}
+	assert.Equal(t, []source.DataOperation{source.DataOperationDelete, source.DataOperationUpsert}, operations)
+}
+
+func TestNewSource(t *testing.T) {
+	t.Setenv("FILE_SOURCE_DIR", "")
+
+	_, err := NewSource()
+	assert.ErrorIs(t, err, ErrFileSource)
+	assert.ErrorIs(t, err, ErrMissingEnvVariable)
+}
@@ -0,0 +1,140 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package file
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	jsonExtension = ".json"
+	yamlExtension = ".yaml"
+	ymlExtension  = ".yml"
+	csvExtension  = ".csv"
+)
+
+var (
+	errInvalidRecord = errors.New("records must be objects")
+)
+
+// supportedFile reports whether the file at path is read by the source.
+func supportedFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case jsonExtension, yamlExtension, ymlExtension, csvExtension:
+		return true
+	default:
+		return false
+	}
+}
+
+// readRecords decodes the records of the file at path. JSON and YAML files can contain a single
+// object or a list of objects, and YAML files can contain multiple documents; the first row of
+// CSV files names the fields of the following ones.
+func readRecords(path string) ([]map[string]any, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case jsonExtension:
+		return readJSONRecords(content)
+	case yamlExtension, ymlExtension:
+		return readYAMLRecords(content)
+	case csvExtension:
+		return readCSVRecords(content)
+	default:
+		return nil, fmt.Errorf("unsupported file extension %q", filepath.Ext(path))
+	}
+}
+
+// readJSONRecords decodes a JSON object or a list of objects.
+func readJSONRecords(content []byte) ([]map[string]any, error) {
+	var document any
+	if err := json.Unmarshal(content, &document); err != nil {
+		return nil, err
+	}
+
+	return toRecords(document)
+}
+
+// readYAMLRecords decodes every document of a YAML stream, each one an object or a list of objects.
+func readYAMLRecords(content []byte) ([]map[string]any, error) {
+	records := make([]map[string]any, 0)
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var document any
+		if err := decoder.Decode(&document); err != nil {
+			if errors.Is(err, io.EOF) {
+				return records, nil
+			}
+			return nil, err
+		}
+
+		if document == nil {
+			continue
+		}
+
+		documentRecords, err := toRecords(document)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, documentRecords...)
+	}
+}
+
+// readCSVRecords decodes the rows of a CSV file, using the first one as the field names.
+func readCSVRecords(content []byte) ([]map[string]any, error) {
+	reader := csv.NewReader(bytes.NewReader(content))
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return []map[string]any{}, nil
+	}
+
+	header := rows[0]
+	records := make([]map[string]any, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		record := make(map[string]any, len(header))
+		for index, field := range header {
+			record[field] = row[index]
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// toRecords returns document as a list of records.
+func toRecords(document any) ([]map[string]any, error) {
+	switch typed := document.(type) {
+	case map[string]any:
+		return []map[string]any{typed}, nil
+	case []any:
+		records := make([]map[string]any, 0, len(typed))
+		for _, item := range typed {
+			record, ok := item.(map[string]any)
+			if !ok {
+				return nil, errInvalidRecord
+			}
+			records = append(records, record)
+		}
+		return records, nil
+	default:
+		return nil, errInvalidRecord
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadRecords(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		fileName        string
+		content         string
+		expectedRecords []map[string]any
+		expectedErr     string
+	}{
+		"json object": {
+			fileName:        "servers.json",
+			content:         `{"id":"1","cpu":4}`,
+			expectedRecords: []map[string]any{{"id": "1", "cpu": float64(4)}},
+		},
+		"json list": {
+			fileName:        "servers.json",
+			content:         `[{"id":"1"},{"id":"2"}]`,
+			expectedRecords: []map[string]any{{"id": "1"}, {"id": "2"}},
+		},
+		"json list of scalars": {
+			fileName:    "servers.json",
+			content:     `["1","2"]`,
+			expectedErr: errInvalidRecord.Error(),
+		},
+		"yaml documents": {
+			fileName: "servers.yaml",
+			content: `id: "1"
+---
+- id: "2"
+- id: "3"
+---
+`,
+			expectedRecords: []map[string]any{{"id": "1"}, {"id": "2"}, {"id": "3"}},
+		},
+		"yml extension": {
+			fileName:        "servers.YML",
+			content:         `{id: "1", cpu: 4}`,
+			expectedRecords: []map[string]any{{"id": "1", "cpu": 4}},
+		},
+		"invalid yaml": {
+			fileName:    "servers.yaml",
+			content:     "id: [",
+			expectedErr: "yaml: line 1: did not find expected node content",
+		},
+		"csv rows": {
+			fileName: "servers.csv",
+			content: `id,owner
+1,team-a
+2,team-b
+`,
+			expectedRecords: []map[string]any{{"id": "1", "owner": "team-a"}, {"id": "2", "owner": "team-b"}},
+		},
+		"empty csv": {
+			fileName:        "servers.csv",
+			expectedRecords: []map[string]any{},
+		},
+		"csv row with a wrong number of fields": {
+			fileName: "servers.csv",
+			content: `id,owner
+1
+`,
+			expectedErr: "record on line 2: wrong number of fields",
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), test.fileName)
+			require.NoError(t, os.WriteFile(path, []byte(test.content), 0o600))
+
+			records, err := readRecords(path)
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedRecords, records)
+		})
+	}
+}
@@ -73,6 +73,7 @@ func checkAssetConfig(cfg assetConfig) error {
 var _ source.SyncableSource = &Source{}
 var _ source.EventSource = &Source{}
 var _ source.ClosableSource = &Source{}
+var _ source.TypesUpdatableSource = &Source{}
 
 // NewSource returns a ready-to-use GCPSource backed by Cloud Asset and Pub/Sub clients.
 func NewSource() (*Source, error) {
@@ -279,6 +280,8 @@ func (g *Source) StartSyncProcess(ctx context.Context, typesToSync map[string]so
 func (g *Source) StartEventStream(ctx context.Context, typesToStream map[string]source.Extra, results chan<- source.Data) error {
 	log := logger.FromContext(ctx).WithName(loggerName)
 	types := slices.Sorted(maps.Keys(typesToStream))
+	// types received through UpdateTypes before the stream started are newer than typesToStream
+	g.streamTypes.CompareAndSwap(nil, &types)
 	client, err := g.p.initPubSubClient(ctx)
 	if err := handleError(err); err != nil {
 		return err
@@ -305,7 +308,7 @@ func (g *Source) StartEventStream(ctx context.Context, typesToStream map[string]
 			return
 		}
 
-		if !event.IsTypeIn(types) {
+		if !event.IsTypeIn(*g.streamTypes.Load()) {
 			log.Debug("skipping event of unrequested type",
 				"messageId", msg.ID,
 				"eventType", event.GetAssetType(),
@@ -336,6 +339,18 @@ func (g *Source) StartEventStream(ctx context.Context, typesToStream map[string]
 	return handleError(err)
 }
 
+// UpdateTypes replaces the asset types accepted by the running event stream.
+// Messages for the added types are forwarded as soon as they are received, the Pub/Sub
+// subscription is not changed because the asset feed decides which types are published.
+func (g *Source) UpdateTypes(ctx context.Context, types map[string]source.Extra) error {
+	log := logger.FromContext(ctx).WithName(loggerName)
+
+	sortedTypes := slices.Sorted(maps.Keys(types))
+	g.streamTypes.Store(&sortedTypes)
+	log.Debug("updated asset types", "types", sortedTypes)
+	return nil
+}
+
 // gcpListenerHandler decodes Pub/Sub payloads into GCPEvent instances.
 func gcpListenerHandler(data []byte) (*GCPEvent, error) {
 	var event *GCPEvent
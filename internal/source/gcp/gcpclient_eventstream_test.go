@@ -292,3 +292,127 @@ func TestStartEventStream_NoEvents_DeleteCase(t *testing.T) {
 	<-closeChannel
 	assert.Empty(t, results)
 }
+
+func TestStartEventStream_UpdateTypes(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 1*time.Second)
+	defer cancel()
+
+	bucketModifyEventJSONPath := "testdata/event/original/message-gcp-bucket-modify.json"
+	bucketModifyPayloadJSONPath := "testdata/event/expected/payload-gcp-bucket-modify.json"
+	typeToStream := map[string]source.Extra{"compute.googleapis.com/Network": nil}
+	config := pubSubConfig{
+		ProjectID:      "test-project",
+		SubscriptionID: "subscription-id",
+	}
+
+	topicName := fmt.Sprintf(testTopicTemplate, config.ProjectID)
+	subscriptionName := fmt.Sprintf("projects/%s/subscriptions/%s", config.ProjectID, config.SubscriptionID)
+
+	payload, err := os.ReadFile(bucketModifyEventJSONPath)
+	require.NoError(t, err)
+
+	payloadMap, err := os.ReadFile(bucketModifyPayloadJSONPath)
+	require.NoError(t, err)
+	var payloadMapResource map[string]any
+	err = json.Unmarshal(payloadMap, &payloadMapResource)
+	require.NoError(t, err)
+
+	srv, client, cleanup := newFakePubSubClient(t, config, topicName, subscriptionName)
+	defer cleanup()
+
+	msgID := srv.Publish(topicName, payload, nil)
+	gcpInstance := setupInstancesForEventStreamTest(t, config, client)
+	results := make(chan source.Data)
+
+	closeChannel := make(chan struct{})
+	go func() {
+		if err := gcpInstance.StartEventStream(ctx, typeToStream, results); err != nil {
+			assert.ErrorIs(t, err, ErrGCPSource)
+			assert.ErrorContains(t, err, "the client connection is closing")
+		}
+
+		close(closeChannel)
+	}()
+
+	// the first message is skipped because its type is not requested yet
+	for {
+		message := srv.Message(msgID)
+		if message.Acks > 0 {
+			break
+		}
+	}
+
+	err = gcpInstance.UpdateTypes(ctx, map[string]source.Extra{"storage.googleapis.com/Bucket": nil})
+	require.NoError(t, err)
+	srv.Publish(topicName, payload, nil)
+
+	select {
+	case res := <-results:
+		assert.Equal(t, payloadMapResource, res.Values)
+	case <-ctx.Done():
+		require.Fail(t, "timeout waiting for event")
+	}
+
+	gcpInstance.Close(ctx, 1*time.Second)
+	<-closeChannel
+}
+
+func TestStartEventStream_UpdateTypesBeforeStart(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 1*time.Second)
+	defer cancel()
+
+	bucketModifyEventJSONPath := "testdata/event/original/message-gcp-bucket-modify.json"
+	bucketModifyPayloadJSONPath := "testdata/event/expected/payload-gcp-bucket-modify.json"
+	config := pubSubConfig{
+		ProjectID:      "test-project",
+		SubscriptionID: "subscription-id",
+	}
+
+	topicName := fmt.Sprintf(testTopicTemplate, config.ProjectID)
+	subscriptionName := fmt.Sprintf("projects/%s/subscriptions/%s", config.ProjectID, config.SubscriptionID)
+
+	payload, err := os.ReadFile(bucketModifyEventJSONPath)
+	require.NoError(t, err)
+
+	payloadMap, err := os.ReadFile(bucketModifyPayloadJSONPath)
+	require.NoError(t, err)
+	var payloadMapResource map[string]any
+	err = json.Unmarshal(payloadMap, &payloadMapResource)
+	require.NoError(t, err)
+
+	srv, client, cleanup := newFakePubSubClient(t, config, topicName, subscriptionName)
+	defer cleanup()
+
+	srv.Publish(topicName, payload, nil)
+	gcpInstance := setupInstancesForEventStreamTest(t, config, client)
+	results := make(chan source.Data)
+
+	// a mapping reload landing before the stream has started must not be overwritten by the
+	// types the stream is started with
+	err = gcpInstance.UpdateTypes(ctx, map[string]source.Extra{"storage.googleapis.com/Bucket": nil})
+	require.NoError(t, err)
+
+	closeChannel := make(chan struct{})
+	go func() {
+		if err := gcpInstance.StartEventStream(ctx, map[string]source.Extra{"compute.googleapis.com/Network": nil}, results); err != nil {
+			assert.ErrorIs(t, err, ErrGCPSource)
+			assert.ErrorContains(t, err, "the client connection is closing")
+		}
+
+		close(closeChannel)
+	}()
+
+	select {
+	case res := <-results:
+		assert.Equal(t, payloadMapResource, res.Values)
+	case <-ctx.Done():
+		require.Fail(t, "timeout waiting for event")
+	}
+
+	gcpInstance.Close(ctx, 1*time.Second)
+	<-closeChannel
+}
@@ -26,6 +26,9 @@ type assetConfig struct {
 type Source struct {
 	p *pubSubClient
 	a *assetClient
+
+	// streamTypes holds the asset types accepted by the running event stream.
+	streamTypes atomic.Pointer[[]string]
 }
 
 // pubSubClient lazily initializes a Pub/Sub client.
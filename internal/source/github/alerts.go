@@ -0,0 +1,51 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package github
+
+import (
+	"context"
+
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+// syncAlertAssets fetches the Dependabot, code scanning and secret scanning alerts of
+// the organization of c depending on which types are present in typesToSync.
+func (s *Source) syncAlertAssets(ctx context.Context, c *client, typesToSync map[string]source.Extra, results chan<- source.Data) error {
+	listers := map[string]func(apiVersion string) iterator{
+		dependabotAlertType:     c.listDependabotAlerts,
+		codeScanningAlertType:   c.listCodeScanningAlerts,
+		secretScanningAlertType: c.listSecretScanningAlerts,
+	}
+
+	for _, dataType := range alertTypes {
+		extra, ok := typesToSync[dataType]
+		if !ok {
+			continue
+		}
+
+		it := listers[dataType](apiVersionFromExtra(extra))
+		if err := forEachItem(ctx, it, func(alert map[string]any) error {
+			results <- upsertData(dataType, alertValues(dataType, alert, nil))
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// alertValues returns the values of an alert entry of dataType, carrying the repository
+// the alert belongs to. The organization alert endpoints embed the repository in the
+// alert, while webhook events send it alongside: repo is used when not nil.
+func alertValues(dataType string, alert, repo map[string]any) map[string]any {
+	if repo == nil {
+		repo, _ = alert[repositoryType].(map[string]any)
+	}
+
+	return map[string]any{
+		dataType:       alert,
+		repositoryType: repo,
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+func TestStartSyncProcessWithAlertTypes(t *testing.T) {
+	fixedTime := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	originalTimeSource := timeSource
+	t.Cleanup(func() { timeSource = originalTimeSource })
+	timeSource = func() time.Time { return fixedTime }
+
+	repo := map[string]any{"id": float64(2), "full_name": "test-org/repo1"}
+	dependabotAlert := map[string]any{"number": float64(1), "state": "fixed", "repository": repo}
+	codeScanningAlert := map[string]any{"number": float64(2), "state": "dismissed", "repository": repo}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/orgs/test-org/dependabot/alerts":
+			json.NewEncoder(w).Encode([]map[string]any{dependabotAlert})
+		case "/orgs/test-org/code-scanning/alerts":
+			json.NewEncoder(w).Encode([]map[string]any{codeScanningAlert})
+		case "/orgs/test-org/secret-scanning/alerts":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"Secret scanning is disabled on this organization"}`))
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	testCases := map[string]struct {
+		typesToSync  map[string]source.Extra
+		expectedData []source.Data
+		expectErr    error
+	}{
+		"dependabot and code scanning alerts": {
+			typesToSync: map[string]source.Extra{dependabotAlertType: {}, codeScanningAlertType: {}},
+			expectedData: []source.Data{
+				upsertData(dependabotAlertType, map[string]any{dependabotAlertType: dependabotAlert, repositoryType: repo}),
+				upsertData(codeScanningAlertType, map[string]any{codeScanningAlertType: codeScanningAlert, repositoryType: repo}),
+			},
+		},
+		"disabled secret scanning does not stop the other alerts": {
+			typesToSync: map[string]source.Extra{dependabotAlertType: {}, secretScanningAlertType: {}},
+			expectedData: []source.Data{
+				upsertData(dependabotAlertType, map[string]any{dependabotAlertType: dependabotAlert, repositoryType: repo}),
+			},
+			expectErr: ErrRetrievingAssets,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(handler)
+			t.Cleanup(server.Close)
+
+			s := newOrganizationsSource(server.URL, server.Client(), config{Orgs: []string{"test-org"}})
+
+			results := make(chan source.Data, 100)
+			err := s.StartSyncProcess(t.Context(), tc.typesToSync, results)
+			close(results)
+
+			if tc.expectErr != nil {
+				require.ErrorIs(t, err, tc.expectErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			var got []source.Data
+			for d := range results {
+				got = append(got, d)
+			}
+			assert.Equal(t, tc.expectedData, got)
+		})
+	}
+}
@@ -11,6 +11,9 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"time"
+
+	"golang.org/x/oauth2"
 )
 
 const (
@@ -21,11 +24,15 @@ const (
 
 // client wraps an HTTP client with GitHub-specific configuration.
 type client struct {
-	baseURL    string
-	org        string
-	token      string
-	pageSize   int
-	httpClient *http.Client
+	baseURL  string
+	org      string
+	token    string
+	pageSize int
+	// tokenSource, when set, provides the GitHub App installation tokens used instead of token.
+	tokenSource oauth2.TokenSource
+	httpClient  *http.Client
+	// state, when set, caches the responses to send conditional requests during the syncs.
+	state *syncState
 }
 
 // doRequest executes an authenticated GET request against the GitHub REST API
@@ -41,17 +48,56 @@ func (c *client) doRequest(ctx context.Context, path, apiVersion string, page in
 	q.Set("page", strconv.Itoa(page))
 	u.RawQuery = q.Encode()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	return c.doRequestURL(ctx, u.String(), apiVersion)
+}
+
+// doRequestURL executes an authenticated GET request against the absolute URL
+// rawURL and returns the raw response. The caller is responsible for closing the body.
+func (c *client) doRequestURL(ctx context.Context, rawURL, apiVersion string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.token)
+	token, err := c.accessToken()
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
 	req.Header.Set("Accept", "application/vnd.github+json")
 	req.Header.Set("X-GitHub-Api-Version", apiVersion)
 	req.Header.Set("User-Agent", userAgent)
 
-	return c.httpClient.Do(req)
+	state := c.state
+	if !conditionalRequests(ctx) {
+		state = nil
+	}
+
+	cacheKey := apiVersion + " " + rawURL
+	if etag := state.etag(cacheKey); etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	return state.handleResponse(cacheKey, resp)
+}
+
+// accessToken returns the token authenticating the requests: an installation token of the GitHub
+// App when configured, refreshed transparently once expired, or the static token otherwise.
+func (c *client) accessToken() (string, error) {
+	if c.tokenSource == nil {
+		return c.token, nil
+	}
+
+	token, err := c.tokenSource.Token()
+	if err != nil {
+		return "", fmt.Errorf("failed to obtain GitHub App installation token: %w", err)
+	}
+	return token.AccessToken, nil
 }
 
 // newPageIterator returns a new page iterator for the given path and API version.
@@ -63,22 +109,146 @@ func (c *client) newPageIterator(path, apiVersion string) iterator {
 	}
 }
 
+// forOrganization returns a client for org sharing the settings of c, authenticated with
+// tokenSource when not nil.
+func (c *client) forOrganization(org string, tokenSource oauth2.TokenSource) *client {
+	return &client{
+		baseURL:     c.baseURL,
+		org:         org,
+		token:       c.token,
+		pageSize:    c.pageSize,
+		tokenSource: tokenSource,
+		httpClient:  c.httpClient,
+		state:       c.state,
+	}
+}
+
+// listOrganizations returns an iterator that pages through all the organizations the
+// authenticated user is a member of.
+func (c *client) listOrganizations(apiVersion string) iterator {
+	return c.newPageIterator("/user/orgs", apiVersion)
+}
+
 // listRepositories returns an iterator that pages through all organization repositories.
 func (c *client) listRepositories(apiVersion string) iterator {
 	return c.newPageIterator("/orgs/"+url.PathEscape(c.org)+"/repos?type=all", apiVersion)
 }
 
-// listWorkflowRuns returns an iterator that pages through all workflow runs
-// for the given repository identified by owner and repo name.
-func (c *client) listWorkflowRuns(owner, repo, apiVersion string) iterator {
+// listWorkflowRuns returns an iterator that pages through the workflow runs
+// for the given repository identified by owner and repo name, created from since
+// onwards, or all of them when since is the zero time.
+func (c *client) listWorkflowRuns(owner, repo, apiVersion string, since time.Time) iterator {
+	path := repositoryPath(owner, repo) + "/actions/runs"
+	if !since.IsZero() {
+		path += "?created=" + url.QueryEscape(">="+since.UTC().Format(time.RFC3339))
+	}
+
 	return &wrappedPageIterator{
 		client:      c,
-		path:        "/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo) + "/actions/runs",
+		path:        path,
 		apiVersion:  apiVersion,
 		responseKey: "workflow_runs",
 	}
 }
 
+// listTeams returns an iterator that pages through all organization teams.
+func (c *client) listTeams(apiVersion string) iterator {
+	return c.newPageIterator("/orgs/"+url.PathEscape(c.org)+"/teams", apiVersion)
+}
+
+// listTeamRepositories returns an iterator that pages through the repositories
+// the team identified by its slug has access to.
+func (c *client) listTeamRepositories(teamSlug, apiVersion string) iterator {
+	return c.newPageIterator("/orgs/"+url.PathEscape(c.org)+"/teams/"+url.PathEscape(teamSlug)+"/repos", apiVersion)
+}
+
+// listTeamMembers returns an iterator that pages through the members of the
+// team identified by its slug, including the members of its child teams.
+func (c *client) listTeamMembers(teamSlug, apiVersion string) iterator {
+	return c.newPageIterator("/orgs/"+url.PathEscape(c.org)+"/teams/"+url.PathEscape(teamSlug)+"/members", apiVersion)
+}
+
+// listReleases returns an iterator that pages through all releases of the
+// given repository.
+func (c *client) listReleases(owner, repo, apiVersion string) iterator {
+	return c.newPageIterator(repositoryPath(owner, repo)+"/releases", apiVersion)
+}
+
+// listEnvironments returns an iterator that pages through all deployment
+// environments of the given repository.
+func (c *client) listEnvironments(owner, repo, apiVersion string) iterator {
+	return &wrappedPageIterator{
+		client:      c,
+		path:        repositoryPath(owner, repo) + "/environments",
+		apiVersion:  apiVersion,
+		responseKey: "environments",
+	}
+}
+
+// listDeployments returns an iterator that pages through all deployments of
+// the given repository.
+func (c *client) listDeployments(owner, repo, apiVersion string) iterator {
+	return c.newPageIterator(repositoryPath(owner, repo)+"/deployments", apiVersion)
+}
+
+// listDeploymentStatuses returns an iterator that pages through all statuses
+// of the deployment identified by deploymentID in the given repository.
+func (c *client) listDeploymentStatuses(owner, repo, deploymentID, apiVersion string) iterator {
+	return c.newPageIterator(repositoryPath(owner, repo)+"/deployments/"+url.PathEscape(deploymentID)+"/statuses", apiVersion)
+}
+
+// newLinkIterator returns a new iterator for the given path and API version that
+// follows the rel="next" links, for the endpoints using cursor based pagination.
+func (c *client) newLinkIterator(path, apiVersion string) iterator {
+	return &linkIterator{
+		client:     c,
+		path:       path,
+		apiVersion: apiVersion,
+	}
+}
+
+// listDependabotAlerts returns an iterator that pages through all Dependabot
+// alerts of the organization.
+func (c *client) listDependabotAlerts(apiVersion string) iterator {
+	return c.newLinkIterator("/orgs/"+url.PathEscape(c.org)+"/dependabot/alerts", apiVersion)
+}
+
+// listCodeScanningAlerts returns an iterator that pages through all code scanning
+// alerts of the organization.
+func (c *client) listCodeScanningAlerts(apiVersion string) iterator {
+	return c.newLinkIterator("/orgs/"+url.PathEscape(c.org)+"/code-scanning/alerts", apiVersion)
+}
+
+// listSecretScanningAlerts returns an iterator that pages through all secret
+// scanning alerts of the organization.
+func (c *client) listSecretScanningAlerts(apiVersion string) iterator {
+	return c.newLinkIterator("/orgs/"+url.PathEscape(c.org)+"/secret-scanning/alerts", apiVersion)
+}
+
+// repositoryPath returns the API path of the repository identified by owner and repo name.
+func repositoryPath(owner, repo string) string {
+	return "/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo)
+}
+
+// getRepository fetches the repository identified by fullName, in "owner/repo" form.
+func (c *client) getRepository(ctx context.Context, fullName, apiVersion string) (map[string]any, error) {
+	resp, err := c.doRequest(ctx, "/repos/"+fullName, apiVersion, 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch repository: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("unexpected status code %d fetching repository", resp.StatusCode)
+	}
+
+	var repo map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&repo); err != nil {
+		return nil, fmt.Errorf("failed to decode repository response: %w", err)
+	}
+	return repo, nil
+}
+
 // getRepositoryLanguages fetches the programming languages used in a repository
 // and returns them as percentage values rounded to one decimal place.
 // fullName must be in "owner/repo" form.
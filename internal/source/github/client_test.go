@@ -5,14 +5,24 @@ package github
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
 )
 
+// tokenSourceFunc adapts a function to oauth2.TokenSource.
+type tokenSourceFunc func() (*oauth2.Token, error)
+
+func (f tokenSourceFunc) Token() (*oauth2.Token, error) {
+	return f()
+}
+
 func TestListRepositoriesFactoryURL(t *testing.T) {
 	t.Parallel()
 
@@ -80,6 +90,61 @@ func TestClientRequestHeaders(t *testing.T) {
 	assert.Equal(t, userAgent, capturedRequest.Header.Get("User-Agent"))
 }
 
+func TestClientRequestAppToken(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		tokenSource   oauth2.TokenSource
+		expectedToken string
+		expectErr     bool
+	}{
+		"installation token replaces the static token": {
+			tokenSource:   oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "ghs_installation"}),
+			expectedToken: "Bearer ghs_installation",
+		},
+		"token source failure is returned": {
+			tokenSource: tokenSourceFunc(func() (*oauth2.Token, error) {
+				return nil, errors.New("exchange failed")
+			}),
+			expectErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var capturedRequest *http.Request
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				capturedRequest = r
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`[]`))
+			}))
+			t.Cleanup(server.Close)
+
+			c := &client{
+				baseURL:     server.URL,
+				org:         "test-org",
+				pageSize:    100,
+				tokenSource: tc.tokenSource,
+				httpClient:  server.Client(),
+			}
+
+			resp, err := c.doRequest(t.Context(), "/test/path", "2026-03-10", 1)
+			if tc.expectErr {
+				require.ErrorContains(t, err, "exchange failed")
+				assert.Nil(t, capturedRequest)
+				return
+			}
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			require.NotNil(t, capturedRequest)
+			assert.Equal(t, tc.expectedToken, capturedRequest.Header.Get("Authorization"))
+		})
+	}
+}
+
 func TestListWorkflowRunsFactoryURL(t *testing.T) {
 	t.Parallel()
 
@@ -105,7 +170,7 @@ func TestListWorkflowRunsFactoryURL(t *testing.T) {
 		httpClient: server.Client(),
 	}
 
-	it := c.listWorkflowRuns("my-org", "my-repo", "2026-03-10")
+	it := c.listWorkflowRuns("my-org", "my-repo", "2026-03-10", time.Time{})
 	items, err := it.next(t.Context())
 	require.NoError(t, err)
 	assert.Len(t, items, 1)
@@ -6,6 +6,8 @@ package github
 import (
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/caarlos0/env/v11"
@@ -26,13 +28,18 @@ var (
 
 // config holds the environment-driven GitHub settings.
 type config struct {
-	URL           string        `env:"GITHUB_URL"            envDefault:"https://api.github.com"`
-	Token         string        `env:"GITHUB_TOKEN"`
-	Org           string        `env:"GITHUB_ORG"`
-	HTTPTimeout   time.Duration `env:"GITHUB_HTTP_TIMEOUT"   envDefault:"30s"`
-	PageSize      int           `env:"GITHUB_PAGE_SIZE"      envDefault:"100"`
-	WebhookSecret string        `env:"GITHUB_WEBHOOK_SECRET"`
-	WebhookPath   string        `env:"GITHUB_WEBHOOK_PATH"   envDefault:"/github/webhook"`
+	URL               string        `env:"GITHUB_URL"                  envDefault:"https://api.github.com"`
+	Token             string        `env:"GITHUB_TOKEN"`
+	AppID             string        `env:"GITHUB_APP_ID"`
+	AppPrivateKeyPath string        `env:"GITHUB_APP_PRIVATE_KEY_PATH"`
+	AppInstallationID int64         `env:"GITHUB_APP_INSTALLATION_ID"`
+	Orgs              []string      `env:"GITHUB_ORG"                  envSeparator:","`
+	DiscoverOrgs      bool          `env:"GITHUB_DISCOVER_ORGS"`
+	HTTPTimeout       time.Duration `env:"GITHUB_HTTP_TIMEOUT"         envDefault:"30s"`
+	PageSize          int           `env:"GITHUB_PAGE_SIZE"            envDefault:"100"`
+	WebhookSecret     string        `env:"GITHUB_WEBHOOK_SECRET"`
+	WebhookPath       string        `env:"GITHUB_WEBHOOK_PATH"         envDefault:"/github/webhook"`
+	SyncStatePath     string        `env:"GITHUB_SYNC_STATE_PATH"`
 }
 
 // loadConfigFromEnv parses configuration from environment variables and
@@ -42,6 +49,9 @@ func loadConfigFromEnv() (*config, error) {
 	if err != nil {
 		return nil, err
 	}
+	for i, org := range cfg.Orgs {
+		cfg.Orgs[i] = strings.TrimSpace(org)
+	}
 	if err := cfg.validate(); err != nil {
 		return nil, err
 	}
@@ -51,14 +61,46 @@ func loadConfigFromEnv() (*config, error) {
 // validate checks that all required fields are present and that optional
 // fields are within acceptable bounds.
 func (c config) validate() error {
-	if len(c.Token) == 0 {
-		return fmt.Errorf("%w: %s", ErrMissingEnvVariable, "GITHUB_TOKEN")
+	if err := c.validateAuth(); err != nil {
+		return err
 	}
-	if len(c.Org) == 0 {
-		return fmt.Errorf("%w: %s", ErrMissingEnvVariable, "GITHUB_ORG")
+	if err := c.validateOrgs(); err != nil {
+		return err
 	}
 	if c.PageSize < 1 || c.PageSize > githubMaxPageSize {
 		return fmt.Errorf("%w: GITHUB_PAGE_SIZE must be between 1 and %d, got %d", ErrInvalidEnvVariable, githubMaxPageSize, c.PageSize)
 	}
 	return nil
 }
+
+// validateAuth checks that exactly one authentication method is configured: a static token or a
+// GitHub App with its private key.
+func (c config) validateAuth() error {
+	switch {
+	case len(c.Token) == 0 && len(c.AppID) == 0:
+		return fmt.Errorf("%w: %s", ErrMissingEnvVariable, "GITHUB_TOKEN or GITHUB_APP_ID")
+	case len(c.Token) > 0 && len(c.AppID) > 0:
+		return fmt.Errorf("%w: GITHUB_TOKEN cannot be used with GITHUB_APP_ID", ErrInvalidEnvVariable)
+	case len(c.AppID) > 0 && len(c.AppPrivateKeyPath) == 0:
+		return fmt.Errorf("%w: %s", ErrMissingEnvVariable, "GITHUB_APP_PRIVATE_KEY_PATH")
+	case c.AppInstallationID < 0:
+		return fmt.Errorf("%w: GITHUB_APP_INSTALLATION_ID must be a positive number, got %d", ErrInvalidEnvVariable, c.AppInstallationID)
+	}
+	return nil
+}
+
+// validateOrgs checks that the organizations to sync are either listed or discovered, and that an
+// installation id is only set for a single organization.
+func (c config) validateOrgs() error {
+	switch {
+	case len(c.Orgs) == 0 && !c.DiscoverOrgs:
+		return fmt.Errorf("%w: %s", ErrMissingEnvVariable, "GITHUB_ORG")
+	case len(c.Orgs) > 0 && c.DiscoverOrgs:
+		return fmt.Errorf("%w: GITHUB_ORG cannot be used with GITHUB_DISCOVER_ORGS", ErrInvalidEnvVariable)
+	case slices.Contains(c.Orgs, ""):
+		return fmt.Errorf("%w: GITHUB_ORG contains an empty organization name", ErrInvalidEnvVariable)
+	case c.AppInstallationID > 0 && len(c.Orgs) != 1:
+		return fmt.Errorf("%w: GITHUB_APP_INSTALLATION_ID can only be used with a single GITHUB_ORG", ErrInvalidEnvVariable)
+	}
+	return nil
+}
@@ -19,23 +19,25 @@ func TestLoadConfigFromEnv(t *testing.T) {
 	}{
 		"valid full configuration": {
 			envVars: map[string]string{
-				"GITHUB_TOKEN":          "ghp_test123",
-				"GITHUB_ORG":            "my-org",
-				"GITHUB_URL":            "https://github.example.com/api/v3",
-				"GITHUB_HTTP_TIMEOUT":   "10s",
-				"GITHUB_PAGE_SIZE":      "50",
-				"GITHUB_WEBHOOK_SECRET": "mysecret",
-				"GITHUB_WEBHOOK_PATH":   "/webhook/custom",
+				"GITHUB_TOKEN":           "ghp_test123",
+				"GITHUB_ORG":             "my-org",
+				"GITHUB_URL":             "https://github.example.com/api/v3",
+				"GITHUB_HTTP_TIMEOUT":    "10s",
+				"GITHUB_PAGE_SIZE":       "50",
+				"GITHUB_WEBHOOK_SECRET":  "mysecret",
+				"GITHUB_WEBHOOK_PATH":    "/webhook/custom",
+				"GITHUB_SYNC_STATE_PATH": "/var/lib/ibdm/github-state.json",
 			},
 			expectNoErr: true,
 			expectCfg: &config{
 				URL:           "https://github.example.com/api/v3",
 				Token:         "ghp_test123",
-				Org:           "my-org",
+				Orgs:          []string{"my-org"},
 				HTTPTimeout:   10_000_000_000,
 				PageSize:      50,
 				WebhookSecret: "mysecret",
 				WebhookPath:   "/webhook/custom",
+				SyncStatePath: "/var/lib/ibdm/github-state.json",
 			},
 		},
 		"valid minimal configuration uses defaults": {
@@ -47,7 +49,7 @@ func TestLoadConfigFromEnv(t *testing.T) {
 			expectCfg: &config{
 				URL:         "https://api.github.com",
 				Token:       "ghp_test123",
-				Org:         "my-org",
+				Orgs:        []string{"my-org"},
 				HTTPTimeout: 30_000_000_000,
 				PageSize:    100,
 				WebhookPath: "/github/webhook",
@@ -59,12 +61,110 @@ func TestLoadConfigFromEnv(t *testing.T) {
 			},
 			expectErr: ErrMissingEnvVariable,
 		},
+		"valid GitHub App configuration": {
+			envVars: map[string]string{
+				"GITHUB_APP_ID":               "123456",
+				"GITHUB_APP_PRIVATE_KEY_PATH": "/etc/github/app.pem",
+				"GITHUB_APP_INSTALLATION_ID":  "42",
+				"GITHUB_ORG":                  "my-org",
+			},
+			expectNoErr: true,
+			expectCfg: &config{
+				URL:               "https://api.github.com",
+				AppID:             "123456",
+				AppPrivateKeyPath: "/etc/github/app.pem",
+				AppInstallationID: 42,
+				Orgs:              []string{"my-org"},
+				HTTPTimeout:       30_000_000_000,
+				PageSize:          100,
+				WebhookPath:       "/github/webhook",
+			},
+		},
+		"GITHUB_TOKEN with GITHUB_APP_ID returns error": {
+			envVars: map[string]string{
+				"GITHUB_TOKEN":                "ghp_test123",
+				"GITHUB_APP_ID":               "123456",
+				"GITHUB_APP_PRIVATE_KEY_PATH": "/etc/github/app.pem",
+				"GITHUB_ORG":                  "my-org",
+			},
+			expectErr: ErrInvalidEnvVariable,
+		},
+		"missing GITHUB_APP_PRIVATE_KEY_PATH returns error": {
+			envVars: map[string]string{
+				"GITHUB_APP_ID": "123456",
+				"GITHUB_ORG":    "my-org",
+			},
+			expectErr: ErrMissingEnvVariable,
+		},
+		"negative GITHUB_APP_INSTALLATION_ID returns error": {
+			envVars: map[string]string{
+				"GITHUB_APP_ID":               "123456",
+				"GITHUB_APP_PRIVATE_KEY_PATH": "/etc/github/app.pem",
+				"GITHUB_APP_INSTALLATION_ID":  "-1",
+				"GITHUB_ORG":                  "my-org",
+			},
+			expectErr: ErrInvalidEnvVariable,
+		},
 		"missing GITHUB_ORG returns error": {
 			envVars: map[string]string{
 				"GITHUB_TOKEN": "ghp_test123",
 			},
 			expectErr: ErrMissingEnvVariable,
 		},
+		"comma-separated GITHUB_ORG is trimmed": {
+			envVars: map[string]string{
+				"GITHUB_TOKEN": "ghp_test123",
+				"GITHUB_ORG":   "my-org, other-org",
+			},
+			expectNoErr: true,
+			expectCfg: &config{
+				URL:         "https://api.github.com",
+				Token:       "ghp_test123",
+				Orgs:        []string{"my-org", "other-org"},
+				HTTPTimeout: 30_000_000_000,
+				PageSize:    100,
+				WebhookPath: "/github/webhook",
+			},
+		},
+		"GITHUB_DISCOVER_ORGS without GITHUB_ORG is accepted": {
+			envVars: map[string]string{
+				"GITHUB_TOKEN":         "ghp_test123",
+				"GITHUB_DISCOVER_ORGS": "true",
+			},
+			expectNoErr: true,
+			expectCfg: &config{
+				URL:          "https://api.github.com",
+				Token:        "ghp_test123",
+				DiscoverOrgs: true,
+				HTTPTimeout:  30_000_000_000,
+				PageSize:     100,
+				WebhookPath:  "/github/webhook",
+			},
+		},
+		"GITHUB_ORG with GITHUB_DISCOVER_ORGS returns error": {
+			envVars: map[string]string{
+				"GITHUB_TOKEN":         "ghp_test123",
+				"GITHUB_ORG":           "my-org",
+				"GITHUB_DISCOVER_ORGS": "true",
+			},
+			expectErr: ErrInvalidEnvVariable,
+		},
+		"empty organization in GITHUB_ORG returns error": {
+			envVars: map[string]string{
+				"GITHUB_TOKEN": "ghp_test123",
+				"GITHUB_ORG":   "my-org,,other-org",
+			},
+			expectErr: ErrInvalidEnvVariable,
+		},
+		"GITHUB_APP_INSTALLATION_ID with multiple organizations returns error": {
+			envVars: map[string]string{
+				"GITHUB_APP_ID":               "123456",
+				"GITHUB_APP_PRIVATE_KEY_PATH": "/etc/github/app.pem",
+				"GITHUB_APP_INSTALLATION_ID":  "42",
+				"GITHUB_ORG":                  "my-org,other-org",
+			},
+			expectErr: ErrInvalidEnvVariable,
+		},
 		"page size at lower bound is accepted": {
 			envVars: map[string]string{
 				"GITHUB_TOKEN":     "ghp_test123",
@@ -75,7 +175,7 @@ func TestLoadConfigFromEnv(t *testing.T) {
 			expectCfg: &config{
 				URL:         "https://api.github.com",
 				Token:       "ghp_test123",
-				Org:         "my-org",
+				Orgs:        []string{"my-org"},
 				HTTPTimeout: 30_000_000_000,
 				PageSize:    1,
 				WebhookPath: "/github/webhook",
@@ -91,7 +191,7 @@ func TestLoadConfigFromEnv(t *testing.T) {
 			expectCfg: &config{
 				URL:         "https://api.github.com",
 				Token:       "ghp_test123",
-				Org:         "my-org",
+				Orgs:        []string{"my-org"},
 				HTTPTimeout: 30_000_000_000,
 				PageSize:    100,
 				WebhookPath: "/github/webhook",
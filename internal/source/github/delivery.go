@@ -0,0 +1,99 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package github
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+// syncRepositoryDelivery fetches the releases, environments, deployments and
+// deployment statuses of the given repository depending on which types are present
+// in typesToSync. Every entry carries the repository it belongs to.
+func (s *Source) syncRepositoryDelivery(ctx context.Context, c *client, repo map[string]any, typesToSync map[string]source.Extra, results chan<- source.Data) error {
+	owner, repoName := extractOwnerRepo(repo)
+	if owner == "" || repoName == "" {
+		return nil
+	}
+
+	if extra, ok := typesToSync[releaseType]; ok {
+		it := c.listReleases(owner, repoName, apiVersionFromExtra(extra))
+		if err := forEachItem(ctx, it, func(item map[string]any) error {
+			results <- upsertData(releaseType, map[string]any{releaseType: item, repositoryType: repo})
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+
+	if extra, ok := typesToSync[environmentType]; ok {
+		it := c.listEnvironments(owner, repoName, apiVersionFromExtra(extra))
+		if err := forEachItem(ctx, it, func(item map[string]any) error {
+			results <- upsertData(environmentType, map[string]any{environmentType: item, repositoryType: repo})
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+
+	return s.syncRepositoryDeployments(ctx, c, repo, owner, repoName, typesToSync, results)
+}
+
+// syncRepositoryDeployments fetches the deployments of the given repository and,
+// when deployment_status is requested, the statuses of each of them.
+func (s *Source) syncRepositoryDeployments(ctx context.Context, c *client, repo map[string]any, owner, repoName string, typesToSync map[string]source.Extra, results chan<- source.Data) error {
+	deploymentExtra, syncDeployments := typesToSync[deploymentType]
+	statusExtra, syncStatuses := typesToSync[deploymentStatusType]
+	if !syncDeployments && !syncStatuses {
+		return nil
+	}
+
+	listAPIVersion := apiVersionFromExtra(deploymentExtra)
+	if !syncDeployments {
+		listAPIVersion = apiVersionFromExtra(statusExtra)
+	}
+
+	return forEachItem(ctx, c.listDeployments(owner, repoName, listAPIVersion), func(deployment map[string]any) error {
+		if syncDeployments {
+			results <- upsertData(deploymentType, map[string]any{deploymentType: deployment, repositoryType: repo})
+		}
+
+		deploymentID, ok := deployment["id"].(float64)
+		if !syncStatuses || !ok {
+			return nil
+		}
+
+		it := c.listDeploymentStatuses(owner, repoName, strconv.FormatFloat(deploymentID, 'f', -1, 64), apiVersionFromExtra(statusExtra))
+		return forEachItem(ctx, it, func(item map[string]any) error {
+			results <- upsertData(deploymentStatusType, map[string]any{
+				deploymentStatusType: item,
+				deploymentType:       deployment,
+				repositoryType:       repo,
+			})
+			return nil
+		})
+	})
+}
+
+// upsertData returns an upsert of dataType carrying values, timestamped now.
+func upsertData(dataType string, values map[string]any) source.Data {
+	return source.Data{
+		Type:      dataType,
+		Operation: source.DataOperationUpsert,
+		Values:    values,
+		Time:      timeSource(),
+	}
+}
+
+// deleteData returns a delete of dataType carrying values, timestamped now.
+func deleteData(dataType string, values map[string]any) source.Data {
+	return source.Data{
+		Type:      dataType,
+		Operation: source.DataOperationDelete,
+		Values:    values,
+		Time:      timeSource(),
+	}
+}
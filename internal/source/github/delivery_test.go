@@ -0,0 +1,111 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+func TestSyncRepositoryDelivery(t *testing.T) {
+	fixedTime := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	originalTimeSource := timeSource
+	t.Cleanup(func() { timeSource = originalTimeSource })
+	timeSource = func() time.Time { return fixedTime }
+
+	repo := map[string]any{"name": "repo1", "owner": map[string]any{"login": "test-org"}}
+	release := map[string]any{"id": float64(1), "tag_name": "v1.0.0"}
+	environment := map[string]any{"id": float64(2), "name": "production"}
+	deployment := map[string]any{"id": float64(3), "environment": "production"}
+	status := map[string]any{"id": float64(4), "state": "success"}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/repos/test-org/repo1/releases":
+			json.NewEncoder(w).Encode([]map[string]any{release})
+		case "/repos/test-org/repo1/environments":
+			json.NewEncoder(w).Encode(map[string]any{"total_count": 1, "environments": []map[string]any{environment}})
+		case "/repos/test-org/repo1/deployments":
+			json.NewEncoder(w).Encode([]map[string]any{deployment})
+		case "/repos/test-org/repo1/deployments/3/statuses":
+			json.NewEncoder(w).Encode([]map[string]any{status})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	testCases := map[string]struct {
+		typesToSync  map[string]source.Extra
+		expectedData []source.Data
+	}{
+		"releases and environments": {
+			typesToSync: map[string]source.Extra{releaseType: {}, environmentType: {}},
+			expectedData: []source.Data{
+				upsertData(releaseType, map[string]any{releaseType: release, repositoryType: repo}),
+				upsertData(environmentType, map[string]any{environmentType: environment, repositoryType: repo}),
+			},
+		},
+		"deployments and statuses": {
+			typesToSync: map[string]source.Extra{deploymentType: {}, deploymentStatusType: {}},
+			expectedData: []source.Data{
+				upsertData(deploymentType, map[string]any{deploymentType: deployment, repositoryType: repo}),
+				upsertData(deploymentStatusType, map[string]any{deploymentStatusType: status, deploymentType: deployment, repositoryType: repo}),
+			},
+		},
+		"statuses only": {
+			typesToSync: map[string]source.Extra{deploymentStatusType: {}},
+			expectedData: []source.Data{
+				upsertData(deploymentStatusType, map[string]any{deploymentStatusType: status, deploymentType: deployment, repositoryType: repo}),
+			},
+		},
+		"no delivery types": {
+			typesToSync: map[string]source.Extra{repositoryType: {}},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(handler)
+			t.Cleanup(server.Close)
+
+			s := newOrganizationsSource(server.URL, server.Client(), config{Orgs: []string{"test-org"}})
+
+			results := make(chan source.Data, 100)
+			err := s.syncRepositoryDelivery(t.Context(), s.client, repo, tc.typesToSync, results)
+			close(results)
+			require.NoError(t, err)
+
+			var got []source.Data
+			for d := range results {
+				got = append(got, d)
+			}
+			assert.Equal(t, tc.expectedData, got)
+		})
+	}
+}
+
+func TestSyncRepositoryDeliveryError(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(server.Close)
+
+	s := newOrganizationsSource(server.URL, server.Client(), config{Orgs: []string{"test-org"}})
+	repo := map[string]any{"name": "repo1", "owner": map[string]any{"login": "test-org"}}
+
+	results := make(chan source.Data, 100)
+	err := s.syncRepositoryDelivery(t.Context(), s.client, repo, map[string]source.Extra{releaseType: {}}, results)
+	require.ErrorIs(t, err, ErrRetrievingAssets)
+}
@@ -8,11 +8,17 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"sync"
 	"time"
 
+	"golang.org/x/oauth2"
+
+	"github.com/mia-platform/ibdm/internal/jwk"
 	"github.com/mia-platform/ibdm/internal/logger"
+	"github.com/mia-platform/ibdm/internal/ratelimit"
 	"github.com/mia-platform/ibdm/internal/source"
+	"github.com/mia-platform/ibdm/internal/tokensource/githubappsource"
 )
 
 const (
@@ -30,6 +36,36 @@ const (
 	// workflowRunType is the data type key for GitHub workflow runs.
 	workflowRunType = "workflow_run"
 
+	// teamType is the data type key for GitHub organization teams.
+	teamType = "team"
+
+	// teamRepositoryType is the data type key for the permissions of a team on a repository.
+	teamRepositoryType = "team_repository"
+
+	// memberType is the data type key for the memberships of users in teams.
+	memberType = "member"
+
+	// releaseType is the data type key for GitHub repository releases.
+	releaseType = "release"
+
+	// environmentType is the data type key for GitHub deployment environments.
+	environmentType = "environment"
+
+	// deploymentType is the data type key for GitHub deployments.
+	deploymentType = "deployment"
+
+	// deploymentStatusType is the data type key for the statuses of GitHub deployments.
+	deploymentStatusType = "deployment_status"
+
+	// dependabotAlertType is the data type key for Dependabot alerts.
+	dependabotAlertType = "dependabot_alert"
+
+	// codeScanningAlertType is the data type key for code scanning alerts.
+	codeScanningAlertType = "code_scanning_alert"
+
+	// secretScanningAlertType is the data type key for secret scanning alerts.
+	secretScanningAlertType = "secret_scanning_alert"
+
 	// defaultAPIVersion is the GitHub REST API version used when the mapping
 	// config does not explicitly set extra["apiVersion"].
 	defaultAPIVersion = "2026-03-10"
@@ -45,13 +81,34 @@ var (
 	timeSource = time.Now
 )
 
+var (
+	// repositoryTypes are the data types synced by iterating the repositories of an
+	// organization; the API version of the first one requested is used to list them.
+	repositoryTypes = []string{repositoryType, workflowRunType, releaseType, environmentType, deploymentType, deploymentStatusType}
+
+	// teamTypes are the data types synced by iterating the teams of an organization.
+	teamTypes = []string{teamType, teamRepositoryType, memberType}
+
+	// alertTypes are the data types synced from the alert endpoints of an organization.
+	alertTypes = []string{dependabotAlertType, codeScanningAlertType, secretScanningAlertType}
+)
+
 var _ source.SyncableSource = &Source{}
 var _ source.WebhookSource = &Source{}
 
 // Source implements source.SyncableSource and source.WebhookSource for GitHub.
 type Source struct {
 	config config
+	// client is bound to the first configured organization, or to no organization when they
+	// are discovered, and is used as the template of the clients of the other organizations.
 	client *client
+	// app authenticates as the GitHub App, when configured instead of the static token.
+	app *githubappsource.App
+
+	clientsLock sync.Mutex
+	clients     map[string]*client
+	// state makes the syncs incremental, it is shared by the clients of all organizations.
+	state *syncState
 
 	syncLock sync.Mutex
 }
@@ -65,20 +122,71 @@ func NewSource() (*Source, error) {
 		return nil, fmt.Errorf("%w: %w", ErrGitHubSource, err)
 	}
 
+	httpClient := &http.Client{
+		Transport: ratelimit.NewTransport("github", ratelimit.GitHub, nil, ratelimit.WithAttemptTimeout(cfg.HTTPTimeout)),
+	}
+
+	app, err := newApp(cfg, httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrGitHubSource, err)
+	}
+
+	state, err := loadSyncState(cfg.SyncStatePath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrGitHubSource, err)
+	}
+
+	var org string
+	if len(cfg.Orgs) > 0 {
+		org = cfg.Orgs[0]
+	}
+
+	var tokenSource oauth2.TokenSource
+	if app != nil && org != "" {
+		if tokenSource, err = app.TokenSource(context.Background(), cfg.AppInstallationID, org); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrGitHubSource, err)
+		}
+	}
+
 	return &Source{
 		config: *cfg,
 		client: &client{
-			baseURL:  cfg.URL,
-			org:      cfg.Org,
-			token:    cfg.Token,
-			pageSize: cfg.PageSize,
-			httpClient: &http.Client{
-				Timeout: cfg.HTTPTimeout,
-			},
+			baseURL:     cfg.URL,
+			org:         org,
+			token:       cfg.Token,
+			pageSize:    cfg.PageSize,
+			tokenSource: tokenSource,
+			httpClient:  httpClient,
+			state:       state,
 		},
+		app:     app,
+		clients: make(map[string]*client),
+		state:   state,
 	}, nil
 }
 
+// newApp returns the GitHub App configured in cfg, loading its private key, or nil when the
+// static token is used instead. The app sends its requests with httpClient, and with the same API
+// version and user agent of the other requests of the source.
+func newApp(cfg *config, httpClient *http.Client) (*githubappsource.App, error) {
+	if len(cfg.AppID) == 0 {
+		return nil, nil
+	}
+
+	keys, err := jwk.LoadKeys(cfg.AppPrivateKeyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	api := githubappsource.API{
+		URL:        cfg.URL,
+		Version:    defaultAPIVersion,
+		UserAgent:  userAgent,
+		HTTPClient: httpClient,
+	}
+	return githubappsource.NewApp(api, cfg.AppID, keys.PrivateKey)
+}
+
 // StartSyncProcess performs a full synchronisation of the requested resource
 // types by querying the GitHub REST API and sending results to results.
 // Only known data types are processed; unknown types are skipped with a debug
@@ -91,24 +199,22 @@ func (s *Source) StartSyncProcess(ctx context.Context, typesToSync map[string]so
 	}
 	defer s.syncLock.Unlock()
 
-	_, wantsRepo := typesToSync[repositoryType]
-	_, wantsRuns := typesToSync[workflowRunType]
-	if wantsRepo || wantsRuns {
-		if err := s.syncRepositoryAssets(ctx, typesToSync, results); err != nil {
+	knownTypes := slices.Concat(repositoryTypes, teamTypes, alertTypes)
+	if requestsAny(typesToSync, knownTypes) {
+		err := s.syncOrganizations(withConditionalRequests(ctx), typesToSync, results)
+		if saveErr := s.state.save(); saveErr != nil {
+			log.Error("error saving sync state", "error", saveErr.Error())
+		}
+		if err != nil {
 			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 				return nil
 			}
-			log.Error("error syncing repository assets", "error", err.Error())
 			return fmt.Errorf("%w: %w", ErrGitHubSource, err)
 		}
 	}
 
-	knownTypes := map[string]bool{
-		repositoryType:  true,
-		workflowRunType: true,
-	}
 	for dataType := range typesToSync {
-		if !knownTypes[dataType] {
+		if !slices.Contains(knownTypes, dataType) {
 			log.Debug("skipping unknown data type", "type", dataType)
 		}
 	}
@@ -116,29 +222,69 @@ func (s *Source) StartSyncProcess(ctx context.Context, typesToSync map[string]so
 	return nil
 }
 
-// syncRepositoryAssets iterates all repositories for the configured organization once
-// and, for each repository, emits a repository entry and/or fetches workflow runs
-// depending on which types are present in typesToSync.
-func (s *Source) syncRepositoryAssets(ctx context.Context, typesToSync map[string]source.Extra, results chan<- source.Data) error {
-	_, syncRepo := typesToSync[repositoryType]
-	_, syncRuns := typesToSync[workflowRunType]
+// syncRepositoryAssets iterates all repositories of the organization of c once
+// and, for each repository, emits a repository entry and fetches its workflow runs,
+// releases, environments and deployments depending on which types are present in
+// typesToSync. Repositories are fetched with the GraphQL API when the repository
+// type selects it in its extra config.
+func (s *Source) syncRepositoryAssets(ctx context.Context, c *client, typesToSync map[string]source.Extra, results chan<- source.Data) error {
+	if !requestsAny(typesToSync, repositoryTypes) {
+		return nil
+	}
 
-	var repoAPIVersion, runAPIVersion string
-	if syncRepo {
-		repoAPIVersion = apiVersionFromExtra(typesToSync[repositoryType])
+	if extra, ok := typesToSync[repositoryType]; ok && fetchModeFromExtra(extra) == fetchModeGraphQL {
+		return forEachItem(ctx, c.listRepositoriesGraphQL(), func(values map[string]any) error {
+			repo, _ := values[repositoryType].(map[string]any)
+			return s.syncRepository(ctx, c, repo, values, typesToSync, results)
+		})
 	}
-	if syncRuns {
-		runAPIVersion = apiVersionFromExtra(typesToSync[workflowRunType])
+
+	var listAPIVersion string
+	for _, dataType := range repositoryTypes {
+		if extra, ok := typesToSync[dataType]; ok {
+			listAPIVersion = apiVersionFromExtra(extra)
+			break
+		}
 	}
 
-	// Use repo API version for the repository listing; fall back to run version
-	// when only workflow runs are requested.
-	listAPIVersion := repoAPIVersion
-	if listAPIVersion == "" {
-		listAPIVersion = runAPIVersion
+	return forEachItem(ctx, c.listRepositories(listAPIVersion), func(item map[string]any) error {
+		return s.syncRepository(ctx, c, item, nil, typesToSync, results)
+	})
+}
+
+// syncRepository emits the repository entry of repo, with values when already fetched
+// or with the languages retrieved from the REST API otherwise, and the entries of its
+// nested types present in typesToSync.
+func (s *Source) syncRepository(ctx context.Context, c *client, repo, values map[string]any, typesToSync map[string]source.Extra, results chan<- source.Data) error {
+	if extra, ok := typesToSync[repositoryType]; ok {
+		if values == nil {
+			values = repositoryValues(ctx, c, repo, apiVersionFromExtra(extra))
+		}
+		results <- upsertData(repositoryType, values)
 	}
 
-	it := s.client.listRepositories(listAPIVersion)
+	if extra, ok := typesToSync[workflowRunType]; ok {
+		if err := s.syncRepositoryWorkflowRuns(ctx, c, repo, apiVersionFromExtra(extra), results); err != nil {
+			return err
+		}
+	}
+
+	return s.syncRepositoryDelivery(ctx, c, repo, typesToSync, results)
+}
+
+// requestsAny reports whether typesToSync contains at least one of dataTypes.
+func requestsAny(typesToSync map[string]source.Extra, dataTypes []string) bool {
+	for _, dataType := range dataTypes {
+		if _, ok := typesToSync[dataType]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// forEachItem consumes every page of it, calling fn for each item, and stops at
+// the first error returned by fn or by the iterator.
+func forEachItem(ctx context.Context, it iterator, fn func(item map[string]any) error) error {
 	for {
 		if err := ctx.Err(); err != nil {
 			return err
@@ -146,7 +292,7 @@ func (s *Source) syncRepositoryAssets(ctx context.Context, typesToSync map[strin
 
 		items, err := it.next(ctx)
 		if errors.Is(err, ErrIteratorDone) {
-			break
+			return nil
 		}
 		if err != nil {
 			return fmt.Errorf("%w: %w", ErrRetrievingAssets, err)
@@ -156,31 +302,11 @@ func (s *Source) syncRepositoryAssets(ctx context.Context, typesToSync map[strin
 			if err := ctx.Err(); err != nil {
 				return err
 			}
-
-			if syncRepo {
-				fullName, _ := item["full_name"].(string)
-				values := map[string]any{repositoryType: item}
-				if fullName != "" {
-					if langs, err := s.client.getRepositoryLanguages(ctx, fullName, repoAPIVersion); err == nil {
-						values["repositoryLanguages"] = langs
-					}
-				}
-				results <- source.Data{
-					Type:      repositoryType,
-					Operation: source.DataOperationUpsert,
-					Values:    values,
-					Time:      timeSource(),
-				}
-			}
-
-			if syncRuns {
-				if err := s.syncRepositoryWorkflowRuns(ctx, item, runAPIVersion, results); err != nil {
-					return err
-				}
+			if err := fn(item); err != nil {
+				return err
 			}
 		}
 	}
-	return nil
 }
 
 // apiVersionFromExtra extracts the API version from the mapping extra config.
@@ -194,37 +320,30 @@ func apiVersionFromExtra(extra source.Extra) string {
 	return defaultAPIVersion
 }
 
-// syncRepositoryWorkflowRuns fetches all workflow runs for the given repository
-// and pushes each as a source.Data entry onto the results channel.
-func (s *Source) syncRepositoryWorkflowRuns(ctx context.Context, repo map[string]any, apiVersion string, results chan<- source.Data) error {
+// syncRepositoryWorkflowRuns fetches the workflow runs for the given repository
+// created since the cursor stored by the previous sync, and pushes each as a
+// source.Data entry onto the results channel. The cursor is advanced only when all
+// the runs have been fetched.
+func (s *Source) syncRepositoryWorkflowRuns(ctx context.Context, c *client, repo map[string]any, apiVersion string, results chan<- source.Data) error {
 	owner, repoName := extractOwnerRepo(repo)
 	if owner == "" || repoName == "" {
 		return nil
 	}
 
-	runIt := s.client.listWorkflowRuns(owner, repoName, apiVersion)
-	for {
-		if err := ctx.Err(); err != nil {
-			return err
-		}
-
-		items, err := runIt.next(ctx)
-		if errors.Is(err, ErrIteratorDone) {
-			break
-		}
-		if err != nil {
-			return fmt.Errorf("%w: %w", ErrRetrievingAssets, err)
-		}
+	fullName := owner + "/" + repoName
+	since := c.state.workflowRunsSince(fullName)
+	var cursor workflowRunCursor
 
-		for _, item := range items {
-			results <- source.Data{
-				Type:      workflowRunType,
-				Operation: source.DataOperationUpsert,
-				Values:    map[string]any{workflowRunType: item},
-				Time:      timeSource(),
-			}
-		}
+	runIt := c.listWorkflowRuns(owner, repoName, apiVersion, since)
+	if err := forEachItem(ctx, runIt, func(item map[string]any) error {
+		cursor.observe(item)
+		results <- upsertData(workflowRunType, map[string]any{workflowRunType: item})
+		return nil
+	}); err != nil {
+		return err
 	}
+
+	c.state.setWorkflowRunCursor(fullName, cursor.next(since))
 	return nil
 }
 
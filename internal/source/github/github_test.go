@@ -5,9 +5,15 @@ package github
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -48,6 +54,14 @@ func TestNewSource(t *testing.T) {
 			},
 			expectErr: ErrGitHubSource,
 		},
+		"missing GitHub App private key file": {
+			envVars: map[string]string{
+				"GITHUB_APP_ID":               "123456",
+				"GITHUB_APP_PRIVATE_KEY_PATH": "/nonexistent/app.pem",
+				"GITHUB_ORG":                  "my-org",
+			},
+			expectErr: ErrGitHubSource,
+		},
 	}
 
 	for name, tc := range testCases {
@@ -69,6 +83,41 @@ func TestNewSource(t *testing.T) {
 	}
 }
 
+func TestNewSourceWithGitHubApp(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	keyPath := filepath.Join(t.TempDir(), "app.pem")
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/app/installations/42/access_tokens", r.URL.Path)
+		assert.Equal(t, defaultAPIVersion, r.Header.Get("X-GitHub-Api-Version"))
+		assert.Equal(t, userAgent, r.Header.Get("User-Agent"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"token":"ghs_token","expires_at":"2100-01-01T00:00:00Z"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	t.Setenv("GITHUB_URL", server.URL)
+	t.Setenv("GITHUB_APP_ID", "123456")
+	t.Setenv("GITHUB_APP_PRIVATE_KEY_PATH", keyPath)
+	t.Setenv("GITHUB_APP_INSTALLATION_ID", "42")
+	t.Setenv("GITHUB_ORG", "my-org")
+
+	s, err := NewSource()
+	require.NoError(t, err)
+	require.NotNil(t, s)
+	assert.Empty(t, s.client.token)
+	require.NotNil(t, s.client.tokenSource)
+
+	token, err := s.client.tokenSource.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "ghs_token", token.AccessToken)
+}
+
 func TestStartSyncProcess(t *testing.T) {
 	fixedTime := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
 	originalTimeSource := timeSource
@@ -220,7 +269,7 @@ func TestStartSyncProcess(t *testing.T) {
 			s := &Source{
 				config: config{
 					URL:   server.URL,
-					Org:   "test-org",
+					Orgs:  []string{"test-org"},
 					Token: "test-token",
 				},
 				client: &client{
@@ -298,6 +347,7 @@ func TestStartSyncProcessContextCancellation(t *testing.T) {
 	t.Cleanup(server.Close)
 
 	s := &Source{
+		config: config{Orgs: []string{"test-org"}},
 		client: &client{
 			baseURL:    server.URL,
 			org:        "test-org",
@@ -489,7 +539,7 @@ func TestSyncRepositoryWorkflowRuns(t *testing.T) {
 			}
 
 			results := make(chan source.Data, 100)
-			err := s.syncRepositoryWorkflowRuns(t.Context(), tc.repo, "2026-03-10", results)
+			err := s.syncRepositoryWorkflowRuns(t.Context(), s.client, tc.repo, "2026-03-10", results)
 			close(results)
 
 			if tc.expectErr != nil {
@@ -535,7 +585,7 @@ func TestSyncRepositoryWorkflowRunsContextCancellation(t *testing.T) {
 
 	repo := map[string]any{"name": "repo1", "owner": map[string]any{"login": "test-org"}}
 	results := make(chan source.Data, 100)
-	err := s.syncRepositoryWorkflowRuns(ctx, repo, "2026-03-10", results)
+	err := s.syncRepositoryWorkflowRuns(ctx, s.client, repo, "2026-03-10", results)
 
 	require.ErrorIs(t, err, context.Canceled)
 }
@@ -651,7 +701,7 @@ func TestStartSyncProcessWithWorkflowRunType(t *testing.T) {
 			s := &Source{
 				config: config{
 					URL:   server.URL,
-					Org:   "test-org",
+					Orgs:  []string{"test-org"},
 					Token: "test-token",
 				},
 				client: &client{
@@ -0,0 +1,408 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/mia-platform/ibdm/internal/logger"
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+const (
+	// fetchModeField is the key of the mapping extra selecting the API used to fetch repositories.
+	fetchModeField = "fetchMode"
+	// fetchModeGraphQL fetches the repositories and their nested data with the GraphQL API.
+	fetchModeGraphQL = "graphql"
+
+	// repositoriesQuery retrieves a page of the organization repositories together with their
+	// languages, topics, default branch protection and CODEOWNERS file, which GitHub looks up
+	// in the .github directory, in the root and in the docs directory, in this order.
+	repositoriesQuery = `query($org: String!, $first: Int!, $after: String) {
+  organization(login: $org) {
+    repositories(first: $first, after: $after, orderBy: {field: NAME, direction: ASC}) {
+      pageInfo { hasNextPage endCursor }
+      nodes {
+        id databaseId name nameWithOwner description url sshUrl homepageUrl mirrorUrl
+        owner {
+          id login url avatarUrl __typename
+          ... on Organization { databaseId }
+          ... on User { databaseId }
+        }
+        isPrivate isFork isArchived isDisabled isTemplate visibility
+        hasIssuesEnabled hasWikiEnabled hasProjectsEnabled hasDiscussionsEnabled
+        mergeCommitAllowed squashMergeAllowed rebaseMergeAllowed autoMergeAllowed
+        deleteBranchOnMerge webCommitSignoffRequired
+        createdAt updatedAt pushedAt stargazerCount forkCount diskUsage watchers { totalCount }
+        primaryLanguage { name }
+        licenseInfo { key name spdxId }
+        repositoryTopics(first: 100) { nodes { topic { name } } }
+        languages(first: 100) { edges { size node { name } } }
+        defaultBranchRef {
+          name
+          branchProtectionRule {
+            pattern requiresApprovingReviews requiredApprovingReviewCount requiresCodeOwnerReviews
+            requiresStatusChecks requiresStrictStatusChecks requiredStatusCheckContexts
+            requiresLinearHistory requiresCommitSignatures isAdminEnforced allowsForcePushes allowsDeletions
+          }
+        }
+        codeownersGitHub: object(expression: "HEAD:.github/CODEOWNERS") { ... on Blob { text } }
+        codeownersRoot: object(expression: "HEAD:CODEOWNERS") { ... on Blob { text } }
+        codeownersDocs: object(expression: "HEAD:docs/CODEOWNERS") { ... on Blob { text } }
+      }
+    }
+  }
+}`
+)
+
+// graphqlError is an error reported in the body of a GraphQL response.
+type graphqlError struct {
+	Message string `json:"message"`
+}
+
+// graphqlResponse is the body of a GraphQL response.
+type graphqlResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []graphqlError  `json:"errors"`
+}
+
+// blob is a git object of a GraphQL response, with its text when it is a file.
+type blob struct {
+	Text string `json:"text"`
+}
+
+// graphqlRepository is a repository node of repositoriesQuery.
+type graphqlRepository struct {
+	ID            string  `json:"id"`
+	DatabaseID    float64 `json:"databaseId"`
+	Name          string  `json:"name"`
+	NameWithOwner string  `json:"nameWithOwner"`
+	Description   any     `json:"description"`
+	URL           string  `json:"url"`
+	SSHURL        string  `json:"sshUrl"`
+	HomepageURL   any     `json:"homepageUrl"`
+	MirrorURL     any     `json:"mirrorUrl"`
+	Owner         struct {
+		ID         string  `json:"id"`
+		DatabaseID float64 `json:"databaseId"`
+		Login      string  `json:"login"`
+		URL        string  `json:"url"`
+		AvatarURL  string  `json:"avatarUrl"`
+		Typename   string  `json:"__typename"`
+	} `json:"owner"`
+	IsPrivate                bool    `json:"isPrivate"`
+	IsFork                   bool    `json:"isFork"`
+	IsArchived               bool    `json:"isArchived"`
+	IsDisabled               bool    `json:"isDisabled"`
+	IsTemplate               bool    `json:"isTemplate"`
+	Visibility               string  `json:"visibility"`
+	HasIssuesEnabled         bool    `json:"hasIssuesEnabled"`
+	HasWikiEnabled           bool    `json:"hasWikiEnabled"`
+	HasProjectsEnabled       bool    `json:"hasProjectsEnabled"`
+	HasDiscussionsEnabled    bool    `json:"hasDiscussionsEnabled"`
+	MergeCommitAllowed       bool    `json:"mergeCommitAllowed"`
+	SquashMergeAllowed       bool    `json:"squashMergeAllowed"`
+	RebaseMergeAllowed       bool    `json:"rebaseMergeAllowed"`
+	AutoMergeAllowed         bool    `json:"autoMergeAllowed"`
+	DeleteBranchOnMerge      bool    `json:"deleteBranchOnMerge"`
+	WebCommitSignoffRequired bool    `json:"webCommitSignoffRequired"`
+	CreatedAt                string  `json:"createdAt"`
+	UpdatedAt                string  `json:"updatedAt"`
+	PushedAt                 any     `json:"pushedAt"`
+	StargazerCount           float64 `json:"stargazerCount"`
+	ForkCount                float64 `json:"forkCount"`
+	DiskUsage                float64 `json:"diskUsage"`
+	Watchers                 struct {
+		TotalCount float64 `json:"totalCount"`
+	} `json:"watchers"`
+	PrimaryLanguage *struct {
+		Name string `json:"name"`
+	} `json:"primaryLanguage"`
+	LicenseInfo *struct {
+		Key    string `json:"key"`
+		Name   string `json:"name"`
+		SpdxID string `json:"spdxId"`
+	} `json:"licenseInfo"`
+	RepositoryTopics struct {
+		Nodes []struct {
+			Topic struct {
+				Name string `json:"name"`
+			} `json:"topic"`
+		} `json:"nodes"`
+	} `json:"repositoryTopics"`
+	Languages struct {
+		Edges []struct {
+			Size float64 `json:"size"`
+			Node struct {
+				Name string `json:"name"`
+			} `json:"node"`
+		} `json:"edges"`
+	} `json:"languages"`
+	DefaultBranchRef *struct {
+		Name                 string         `json:"name"`
+		BranchProtectionRule map[string]any `json:"branchProtectionRule"`
+	} `json:"defaultBranchRef"`
+	CodeownersGitHub *blob `json:"codeownersGitHub"`
+	CodeownersRoot   *blob `json:"codeownersRoot"`
+	CodeownersDocs   *blob `json:"codeownersDocs"`
+}
+
+// repositoriesPage is the data of a repositoriesQuery response.
+type repositoriesPage struct {
+	Organization *struct {
+		Repositories struct {
+			PageInfo struct {
+				HasNextPage bool   `json:"hasNextPage"`
+				EndCursor   string `json:"endCursor"`
+			} `json:"pageInfo"`
+			Nodes []graphqlRepository `json:"nodes"`
+		} `json:"repositories"`
+	} `json:"organization"`
+}
+
+// fetchModeFromExtra extracts the API used to fetch repositories from the mapping extra config.
+// Falls back to the REST API if absent or unknown.
+func fetchModeFromExtra(extra source.Extra) string {
+	if v, ok := extra[fetchModeField].(string); ok && v == fetchModeGraphQL {
+		return fetchModeGraphQL
+	}
+	return ""
+}
+
+// graphqlURL returns the URL of the GraphQL API for the REST API baseURL: on GitHub Enterprise
+// Server the REST API is served under /api/v3 and the GraphQL one under /api/graphql.
+func graphqlURL(baseURL string) string {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	if trimmed, ok := strings.CutSuffix(baseURL, "/api/v3"); ok {
+		return trimmed + "/api/graphql"
+	}
+	return baseURL + "/graphql"
+}
+
+// doGraphQL executes query with variables against the GitHub GraphQL API and decodes its data
+// into data. The errors reported along with the data are logged, since GitHub returns them for
+// the fields the credential cannot access while still resolving the other ones.
+func (c *client) doGraphQL(ctx context.Context, query string, variables map[string]any, data any) error {
+	body, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		return fmt.Errorf("failed to encode GraphQL request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, graphqlURL(c.baseURL), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	token, err := c.accessToken()
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := checkPageResponse(resp); err != nil {
+		return err
+	}
+
+	var response graphqlResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("failed to decode GraphQL response: %w", err)
+	}
+
+	messages := make([]string, 0, len(response.Errors))
+	for _, graphqlErr := range response.Errors {
+		messages = append(messages, graphqlErr.Message)
+	}
+
+	if len(response.Data) == 0 || string(response.Data) == "null" {
+		return fmt.Errorf("GraphQL query failed: %s", strings.Join(messages, "; "))
+	}
+	if len(messages) > 0 {
+		logger.FromContext(ctx).WithName(loggerName).Warn("GraphQL query returned partial data", "errors", strings.Join(messages, "; "))
+	}
+
+	if err := json.Unmarshal(response.Data, data); err != nil {
+		return fmt.Errorf("failed to decode GraphQL data: %w", err)
+	}
+	return nil
+}
+
+// graphqlRepositoryIterator implements the iterator interface paging through the organization
+// repositories with the GraphQL API. Every item holds the values of a repository data entry.
+type graphqlRepositoryIterator struct {
+	client *client
+	cursor string
+	done   bool
+}
+
+// listRepositoriesGraphQL returns an iterator that pages through all organization repositories
+// with the GraphQL API, returning the values of their repository data entries.
+func (c *client) listRepositoriesGraphQL() iterator {
+	return &graphqlRepositoryIterator{client: c}
+}
+
+func (it *graphqlRepositoryIterator) next(ctx context.Context) ([]map[string]any, error) {
+	if it.done {
+		return nil, ErrIteratorDone
+	}
+
+	variables := map[string]any{"org": it.client.org, "first": it.client.pageSize, "after": nil}
+	if it.cursor != "" {
+		variables["after"] = it.cursor
+	}
+
+	var page repositoriesPage
+	if err := it.client.doGraphQL(ctx, repositoriesQuery, variables, &page); err != nil {
+		it.done = true
+		return nil, err
+	}
+	if page.Organization == nil {
+		it.done = true
+		return nil, fmt.Errorf("organization %q not found", it.client.org)
+	}
+
+	repositories := page.Organization.Repositories
+	if !repositories.PageInfo.HasNextPage {
+		it.done = true
+	}
+	it.cursor = repositories.PageInfo.EndCursor
+
+	if len(repositories.Nodes) == 0 {
+		it.done = true
+		return nil, ErrIteratorDone
+	}
+
+	items := make([]map[string]any, 0, len(repositories.Nodes))
+	for _, node := range repositories.Nodes {
+		items = append(items, node.values())
+	}
+	return items, nil
+}
+
+// values returns the values of the repository data entry of r: the repository in the shape of
+// the REST API, its languages, and the protection rule of its default branch and its CODEOWNERS
+// file, which are nil when missing.
+func (r graphqlRepository) values() map[string]any {
+	languages := make(map[string]float64, len(r.Languages.Edges))
+	for _, edge := range r.Languages.Edges {
+		languages[edge.Node.Name] = edge.Size
+	}
+
+	var protection any
+	if r.DefaultBranchRef != nil && r.DefaultBranchRef.BranchProtectionRule != nil {
+		protection = r.DefaultBranchRef.BranchProtectionRule
+	}
+
+	var codeowners any
+	for _, file := range []*blob{r.CodeownersGitHub, r.CodeownersRoot, r.CodeownersDocs} {
+		if file != nil {
+			codeowners = file.Text
+			break
+		}
+	}
+
+	return map[string]any{
+		repositoryType:            r.restRepository(),
+		"repositoryLanguages":     computeLanguagePercentages(languages),
+		"defaultBranchProtection": protection,
+		"codeowners":              codeowners,
+	}
+}
+
+// restRepository returns r with the field names and values of the repositories returned by the
+// REST API. The clone URLs are derived from the repository URL, while the API URLs, the
+// permissions and the fields without a GraphQL counterpart are missing.
+func (r graphqlRepository) restRepository() map[string]any {
+	topics := make([]any, 0, len(r.RepositoryTopics.Nodes))
+	for _, node := range r.RepositoryTopics.Nodes {
+		topics = append(topics, node.Topic.Name)
+	}
+
+	repository := map[string]any{
+		"id":        r.DatabaseID,
+		"node_id":   r.ID,
+		"name":      r.Name,
+		"full_name": r.NameWithOwner,
+		"owner": map[string]any{
+			"id":         r.Owner.DatabaseID,
+			"node_id":    r.Owner.ID,
+			"login":      r.Owner.Login,
+			"type":       r.Owner.Typename,
+			"html_url":   r.Owner.URL,
+			"avatar_url": r.Owner.AvatarURL,
+		},
+		"description":                 r.Description,
+		"html_url":                    r.URL,
+		"clone_url":                   r.URL + ".git",
+		"git_url":                     gitURL(r.URL),
+		"svn_url":                     r.URL,
+		"ssh_url":                     r.SSHURL,
+		"mirror_url":                  r.MirrorURL,
+		"homepage":                    r.HomepageURL,
+		"private":                     r.IsPrivate,
+		"fork":                        r.IsFork,
+		"archived":                    r.IsArchived,
+		"disabled":                    r.IsDisabled,
+		"is_template":                 r.IsTemplate,
+		"has_issues":                  r.HasIssuesEnabled,
+		"has_wiki":                    r.HasWikiEnabled,
+		"has_projects":                r.HasProjectsEnabled,
+		"has_discussions":             r.HasDiscussionsEnabled,
+		"allow_merge_commit":          r.MergeCommitAllowed,
+		"allow_squash_merge":          r.SquashMergeAllowed,
+		"allow_rebase_merge":          r.RebaseMergeAllowed,
+		"allow_auto_merge":            r.AutoMergeAllowed,
+		"delete_branch_on_merge":      r.DeleteBranchOnMerge,
+		"web_commit_signoff_required": r.WebCommitSignoffRequired,
+		"visibility":                  strings.ToLower(r.Visibility),
+		"created_at":                  r.CreatedAt,
+		"updated_at":                  r.UpdatedAt,
+		"pushed_at":                   r.PushedAt,
+		"stargazers_count":            r.StargazerCount,
+		"watchers_count":              r.StargazerCount,
+		"watchers":                    r.StargazerCount,
+		"subscribers_count":           r.Watchers.TotalCount,
+		"forks_count":                 r.ForkCount,
+		"forks":                       r.ForkCount,
+		"size":                        r.DiskUsage,
+		"topics":                      topics,
+		"language":                    nil,
+		"license":                     nil,
+		"default_branch":              nil,
+	}
+
+	if r.PrimaryLanguage != nil {
+		repository["language"] = r.PrimaryLanguage.Name
+	}
+	if r.LicenseInfo != nil {
+		repository["license"] = map[string]any{"key": r.LicenseInfo.Key, "name": r.LicenseInfo.Name, "spdx_id": r.LicenseInfo.SpdxID}
+	}
+	if r.DefaultBranchRef != nil {
+		repository["default_branch"] = r.DefaultBranchRef.Name
+	}
+	return repository
+}
+
+// gitURL returns the git protocol URL of the repository at htmlURL, like the git_url of the
+// REST API.
+func gitURL(htmlURL string) string {
+	_, hostAndPath, found := strings.Cut(htmlURL, "://")
+	if !found {
+		return ""
+	}
+	return "git://" + hostAndPath + ".git"
+}
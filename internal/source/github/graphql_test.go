@@ -0,0 +1,271 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+// graphqlRequest is the body of a GraphQL request received by the test server.
+type graphqlRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables"`
+}
+
+// graphqlRepositoriesHandler serves pages as the consecutive pages of the organization
+// repositories, recording the cursors of the requests in cursors.
+func graphqlRepositoriesHandler(t *testing.T, cursors *[]any, pages ...[]map[string]any) http.HandlerFunc {
+	t.Helper()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/graphql" {
+			http.NotFound(w, r)
+			return
+		}
+
+		var request graphqlRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		*cursors = append(*cursors, request.Variables["after"])
+
+		index := len(*cursors) - 1
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"data": map[string]any{
+				"organization": map[string]any{
+					"repositories": map[string]any{
+						"pageInfo": map[string]any{"hasNextPage": index < len(pages)-1, "endCursor": "cursor-" + string(rune('a'+index))},
+						"nodes":    pages[index],
+					},
+				},
+			},
+		})
+	}
+}
+
+func TestGraphqlURL(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		baseURL  string
+		expected string
+	}{
+		"github.com":                   {baseURL: "https://api.github.com", expected: "https://api.github.com/graphql"},
+		"trailing slash":               {baseURL: "https://api.github.com/", expected: "https://api.github.com/graphql"},
+		"github enterprise server":     {baseURL: "https://github.example.com/api/v3", expected: "https://github.example.com/api/graphql"},
+		"github enterprise with slash": {baseURL: "https://github.example.com/api/v3/", expected: "https://github.example.com/api/graphql"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.expected, graphqlURL(tc.baseURL))
+		})
+	}
+}
+
+func TestFetchModeFromExtra(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, fetchModeGraphQL, fetchModeFromExtra(source.Extra{"fetchMode": "graphql"}))
+	assert.Empty(t, fetchModeFromExtra(source.Extra{"fetchMode": "rest"}))
+	assert.Empty(t, fetchModeFromExtra(source.Extra{"fetchMode": 1}))
+	assert.Empty(t, fetchModeFromExtra(nil))
+}
+
+func TestListRepositoriesGraphQL(t *testing.T) {
+	t.Parallel()
+
+	fullNode := map[string]any{
+		"id": "R_1", "databaseId": 1, "name": "repo1", "nameWithOwner": "test-org/repo1",
+		"description": "First repository", "url": "https://github.com/test-org/repo1",
+		"owner": map[string]any{
+			"id": "O_1", "databaseId": 10, "login": "test-org", "__typename": "Organization",
+			"url": "https://github.com/test-org", "avatarUrl": "https://avatars.githubusercontent.com/u/10",
+		},
+		"isPrivate": true, "visibility": "INTERNAL", "watchers": map[string]any{"totalCount": 2},
+		"pushedAt": "2026-10-01T10:00:00Z", "stargazerCount": 3,
+		"primaryLanguage":  map[string]any{"name": "Go"},
+		"licenseInfo":      map[string]any{"key": "mit", "name": "MIT License", "spdxId": "MIT"},
+		"repositoryTopics": map[string]any{"nodes": []map[string]any{{"topic": map[string]any{"name": "catalog"}}}},
+		"languages": map[string]any{"edges": []map[string]any{
+			{"size": 300, "node": map[string]any{"name": "Go"}},
+			{"size": 100, "node": map[string]any{"name": "Shell"}},
+		}},
+		"defaultBranchRef": map[string]any{
+			"name":                 "main",
+			"branchProtectionRule": map[string]any{"pattern": "main", "requiresApprovingReviews": true},
+		},
+		"codeownersGitHub": nil,
+		"codeownersRoot":   map[string]any{"text": "* @test-org/owners\n"},
+		"codeownersDocs":   map[string]any{"text": "* @test-org/docs\n"},
+	}
+	emptyNode := map[string]any{
+		"id": "R_2", "databaseId": 2, "name": "empty", "nameWithOwner": "test-org/empty",
+		"owner": map[string]any{"login": "test-org"}, "visibility": "PUBLIC",
+	}
+
+	var cursors []any
+	server := httptest.NewServer(graphqlRepositoriesHandler(t, &cursors, []map[string]any{fullNode}, []map[string]any{emptyNode}))
+	t.Cleanup(server.Close)
+
+	c := &client{baseURL: server.URL, org: "test-org", pageSize: 1, httpClient: server.Client()}
+	it := c.listRepositoriesGraphQL()
+
+	first, err := it.next(t.Context())
+	require.NoError(t, err)
+	require.Len(t, first, 1)
+	repo, _ := first[0][repositoryType].(map[string]any)
+	assert.Equal(t, float64(1), repo["id"])
+	assert.Equal(t, "R_1", repo["node_id"])
+	assert.Equal(t, "test-org/repo1", repo["full_name"])
+	assert.Equal(t, map[string]any{
+		"id": float64(10), "node_id": "O_1", "login": "test-org", "type": "Organization",
+		"html_url": "https://github.com/test-org", "avatar_url": "https://avatars.githubusercontent.com/u/10",
+	}, repo["owner"])
+	assert.Equal(t, "https://github.com/test-org/repo1.git", repo["clone_url"])
+	assert.Equal(t, "git://github.com/test-org/repo1.git", repo["git_url"])
+	assert.Equal(t, "https://github.com/test-org/repo1", repo["svn_url"])
+	assert.Equal(t, float64(3), repo["watchers_count"])
+	assert.Equal(t, float64(2), repo["subscribers_count"])
+	assert.Equal(t, "internal", repo["visibility"])
+	assert.Equal(t, true, repo["private"])
+	assert.Equal(t, "main", repo["default_branch"])
+	assert.Equal(t, "Go", repo["language"])
+	assert.Equal(t, []any{"catalog"}, repo["topics"])
+	assert.Equal(t, map[string]any{"key": "mit", "name": "MIT License", "spdx_id": "MIT"}, repo["license"])
+	assert.Equal(t, map[string]float64{"Go": 75, "Shell": 25}, first[0]["repositoryLanguages"])
+	assert.Equal(t, map[string]any{"pattern": "main", "requiresApprovingReviews": true}, first[0]["defaultBranchProtection"])
+	assert.Equal(t, "* @test-org/owners\n", first[0]["codeowners"])
+
+	second, err := it.next(t.Context())
+	require.NoError(t, err)
+	require.Len(t, second, 1)
+	repo, _ = second[0][repositoryType].(map[string]any)
+	assert.Equal(t, "test-org/empty", repo["full_name"])
+	assert.Nil(t, repo["default_branch"])
+	assert.Nil(t, repo["description"])
+	assert.Equal(t, []any{}, repo["topics"])
+	assert.Equal(t, map[string]float64{}, second[0]["repositoryLanguages"])
+	assert.Nil(t, second[0]["defaultBranchProtection"])
+	assert.Nil(t, second[0]["codeowners"])
+
+	_, err = it.next(t.Context())
+	require.ErrorIs(t, err, ErrIteratorDone)
+	assert.Equal(t, []any{nil, "cursor-a"}, cursors)
+}
+
+func TestDoGraphQL(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		status    int
+		body      string
+		expected  map[string]any
+		expectErr string
+	}{
+		"data": {
+			status:   http.StatusOK,
+			body:     `{"data":{"organization":{"login":"test-org"}}}`,
+			expected: map[string]any{"organization": map[string]any{"login": "test-org"}},
+		},
+		"partial data": {
+			status:   http.StatusOK,
+			body:     `{"data":{"organization":{"login":"test-org"}},"errors":[{"message":"Resource not accessible by integration"}]}`,
+			expected: map[string]any{"organization": map[string]any{"login": "test-org"}},
+		},
+		"errors without data": {
+			status:    http.StatusOK,
+			body:      `{"data":null,"errors":[{"message":"Could not resolve to an Organization"},{"message":"Second"}]}`,
+			expectErr: "GraphQL query failed: Could not resolve to an Organization; Second",
+		},
+		"unexpected status": {
+			status:    http.StatusUnauthorized,
+			body:      `{"message":"Bad credentials"}`,
+			expectErr: "unexpected status 401",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.body))
+			}))
+			t.Cleanup(server.Close)
+
+			c := &client{baseURL: server.URL, org: "test-org", token: "test-token", httpClient: server.Client()}
+			var data map[string]any
+			err := c.doGraphQL(t.Context(), "query { viewer { login } }", nil, &data)
+			if tc.expectErr != "" {
+				require.ErrorContains(t, err, tc.expectErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, data)
+		})
+	}
+}
+
+func TestStartSyncProcessGraphQLRepositories(t *testing.T) {
+	t.Parallel()
+
+	var cursors []any
+	graphqlHandler := graphqlRepositoriesHandler(t, &cursors, []map[string]any{{
+		"databaseId": 1, "name": "repo1", "nameWithOwner": "test-org/repo1",
+		"owner": map[string]any{"login": "test-org"},
+	}})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/graphql":
+			graphqlHandler(w, r)
+		case "/repos/test-org/repo1/actions/runs":
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]any{
+				"total_count":   1,
+				"workflow_runs": []map[string]any{{"id": 10}},
+			})
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	s := newOrganizationsSource(server.URL, server.Client(), config{Orgs: []string{"test-org"}})
+	results := make(chan source.Data, 10)
+	err := s.StartSyncProcess(t.Context(), map[string]source.Extra{
+		repositoryType:  {"fetchMode": "graphql"},
+		workflowRunType: {},
+	}, results)
+	require.NoError(t, err)
+	close(results)
+
+	var types []string
+	for d := range results {
+		types = append(types, d.Type)
+		if d.Type == repositoryType {
+			assert.Contains(t, d.Values, "repositoryLanguages")
+			assert.Contains(t, d.Values, "defaultBranchProtection")
+			assert.Contains(t, d.Values, "codeowners")
+		}
+	}
+	assert.Equal(t, []string{repositoryType, workflowRunType}, types)
+	assert.Len(t, cursors, 1)
+}
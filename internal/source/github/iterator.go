@@ -10,7 +10,10 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"regexp"
+	"strconv"
+	"strings"
 )
 
 // relNextRegex matches the URL in a Link header with rel="next".
@@ -52,20 +55,70 @@ func (it *pageIterator) next(ctx context.Context) ([]map[string]any, error) {
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests {
-		remaining := resp.Header.Get("X-Ratelimit-Remaining")
-		if remaining == "0" {
+	if err := checkPageResponse(resp); err != nil {
+		it.done = true
+		return nil, err
+	}
+
+	var items []map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&items); err != nil {
+		it.done = true
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if len(items) == 0 {
+		it.done = true
+		return nil, ErrIteratorDone
+	}
+
+	linkHeader := resp.Header.Get("Link")
+	if !hasRelNext(linkHeader) {
+		it.done = true
+	}
+
+	return items, nil
+}
+
+// linkIterator implements the iterator interface for cursor based pagination,
+// requesting the first page at path and then following the rel="next" URL of the
+// Link header. Next URLs outside the base URL of the client are never followed, so
+// that the credentials are not sent to other hosts.
+type linkIterator struct {
+	client     *client
+	path       string
+	apiVersion string
+	nextURL    string
+	done       bool
+}
+
+func (it *linkIterator) next(ctx context.Context) ([]map[string]any, error) {
+	if it.done {
+		return nil, ErrIteratorDone
+	}
+
+	requestURL := it.nextURL
+	if requestURL == "" {
+		u, err := url.Parse(it.client.baseURL + it.path)
+		if err != nil {
 			it.done = true
-			reset := resp.Header.Get("X-Ratelimit-Reset")
-			body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
-			return nil, fmt.Errorf("rate limit exhausted (resets at %s): %s", reset, string(body))
+			return nil, fmt.Errorf("failed to build request URL: %w", err)
 		}
+		q := u.Query()
+		q.Set("per_page", strconv.Itoa(it.client.pageSize))
+		u.RawQuery = q.Encode()
+		requestURL = u.String()
 	}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+	resp, err := it.client.doRequestURL(ctx, requestURL, it.apiVersion)
+	if err != nil {
 		it.done = true
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
-		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := checkPageResponse(resp); err != nil {
+		it.done = true
+		return nil, err
 	}
 
 	var items []map[string]any
@@ -79,14 +132,39 @@ func (it *pageIterator) next(ctx context.Context) ([]map[string]any, error) {
 		return nil, ErrIteratorDone
 	}
 
-	linkHeader := resp.Header.Get("Link")
-	if !hasRelNext(linkHeader) {
+	it.nextURL = relNextURL(resp.Header.Get("Link"))
+	if it.nextURL == "" {
+		it.done = true
+	} else if !strings.HasPrefix(it.nextURL, it.client.baseURL+"/") {
 		it.done = true
+		return nil, fmt.Errorf("next page URL %q is outside of %s", it.nextURL, it.client.baseURL)
 	}
 
 	return items, nil
 }
 
+// checkPageResponse returns an error for the responses that are not successful.
+// Rate limited responses are retried by the ratelimit transport of the client, so
+// the ones reaching this point are reported like any other failure.
+func checkPageResponse(resp *http.Response) error {
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// relNextURL returns the URL of the rel="next" entry of the Link header, or an
+// empty string when missing.
+func relNextURL(linkHeader string) string {
+	matches := relNextRegex.FindStringSubmatch(linkHeader)
+	if matches == nil {
+		return ""
+	}
+	return matches[1]
+}
+
 // hasRelNext reports whether the Link header contains a rel="next" entry.
 func hasRelNext(linkHeader string) bool {
 	if linkHeader == "" {
@@ -124,20 +202,9 @@ func (it *wrappedPageIterator) next(ctx context.Context) ([]map[string]any, erro
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests {
-		remaining := resp.Header.Get("X-Ratelimit-Remaining")
-		if remaining == "0" {
-			it.done = true
-			reset := resp.Header.Get("X-Ratelimit-Reset")
-			body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
-			return nil, fmt.Errorf("rate limit exhausted (resets at %s): %s", reset, string(body))
-		}
-	}
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+	if err := checkPageResponse(resp); err != nil {
 		it.done = true
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
-		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+		return nil, err
 	}
 
 	var wrapper map[string]json.RawMessage
@@ -102,7 +102,7 @@ func TestPageIterator(t *testing.T) {
 			},
 			expectedItems: 0,
 			expectedPages: 1,
-			errContains:   "rate limit exhausted",
+			errContains:   "unexpected status 403",
 		},
 	}
 
@@ -341,7 +341,7 @@ func TestWrappedPageIterator(t *testing.T) {
 			},
 			expectedItems: 0,
 			expectedPages: 1,
-			errContains:   "rate limit exhausted",
+			errContains:   "unexpected status 403",
 		},
 		"non-2xx status returns error": {
 			responseKey: "workflow_runs",
@@ -449,3 +449,83 @@ func TestWrappedPageIteratorIdempotentDone(t *testing.T) {
 	_, err = it.next(t.Context())
 	require.ErrorIs(t, err, ErrIteratorDone)
 }
+
+func TestLinkIterator(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		handler       func(serverURL *string) http.HandlerFunc
+		expectedIDs   []float64
+		errContains   string
+		expectedQuery string
+	}{
+		"follows next links": {
+			handler: func(serverURL *string) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "application/json")
+					switch r.URL.Query().Get("after") {
+					case "":
+						assert.Equal(t, "50", r.URL.Query().Get("per_page"))
+						assert.Empty(t, r.URL.Query().Get("page"))
+						w.Header().Set("Link", `<`+*serverURL+`/orgs/test-org/dependabot/alerts?per_page=50&after=cursor1>; rel="next"`)
+						json.NewEncoder(w).Encode([]map[string]any{{"id": 1}})
+					case "cursor1":
+						json.NewEncoder(w).Encode([]map[string]any{{"id": 2}})
+					}
+				}
+			},
+			expectedIDs: []float64{1, 2},
+		},
+		"refuses next links outside of the base url": {
+			handler: func(_ *string) http.HandlerFunc {
+				return func(w http.ResponseWriter, _ *http.Request) {
+					w.Header().Set("Content-Type", "application/json")
+					w.Header().Set("Link", `<https://example.com/orgs/test-org/dependabot/alerts?after=cursor1>; rel="next"`)
+					json.NewEncoder(w).Encode([]map[string]any{{"id": 1}})
+				}
+			},
+			errContains: "is outside of",
+		},
+		"server error": {
+			handler: func(_ *string) http.HandlerFunc {
+				return func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(http.StatusNotFound)
+					w.Write([]byte(`{"message":"Not Found"}`))
+				}
+			},
+			errContains: "unexpected status 404",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var serverURL string
+			server := httptest.NewServer(tc.handler(&serverURL))
+			t.Cleanup(server.Close)
+			serverURL = server.URL
+
+			c := &client{baseURL: server.URL, org: "test-org", pageSize: 50, httpClient: server.Client()}
+			it := c.listDependabotAlerts(defaultAPIVersion)
+
+			var ids []float64
+			for {
+				items, err := it.next(t.Context())
+				if errors.Is(err, ErrIteratorDone) {
+					break
+				}
+				if tc.errContains != "" {
+					require.ErrorContains(t, err, tc.errContains)
+					return
+				}
+				require.NoError(t, err)
+				for _, item := range items {
+					ids = append(ids, item["id"].(float64))
+				}
+			}
+
+			assert.Equal(t, tc.expectedIDs, ids)
+		})
+	}
+}
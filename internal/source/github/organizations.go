@@ -0,0 +1,178 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"golang.org/x/oauth2"
+
+	"github.com/mia-platform/ibdm/internal/logger"
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+const (
+	// organizationAccountType is the account type of the GitHub App installations on organizations.
+	organizationAccountType = "Organization"
+)
+
+// syncOrganizations syncs the repository, team and alert assets of every configured or discovered organization.
+// A failure on one organization is logged and does not stop the sync of the others: the errors
+// are returned joined once every organization has been processed.
+func (s *Source) syncOrganizations(ctx context.Context, typesToSync map[string]source.Extra, results chan<- source.Data) error {
+	log := logger.FromContext(ctx).WithName(loggerName)
+
+	clients, err := s.organizationClients(ctx)
+	if err != nil {
+		log.Error("error listing organizations", "error", err.Error())
+		return err
+	}
+
+	syncs := []struct {
+		assets string
+		sync   func(context.Context, *client, map[string]source.Extra, chan<- source.Data) error
+	}{
+		{assets: "repository", sync: s.syncRepositoryAssets},
+		{assets: "team", sync: s.syncTeamAssets},
+		{assets: "alert", sync: s.syncAlertAssets},
+	}
+
+	var errs []error
+	for _, c := range clients {
+		for _, assets := range syncs {
+			if err := assets.sync(ctx, c, typesToSync, results); err != nil {
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					return ctxErr
+				}
+				log.Error("error syncing "+assets.assets+" assets", "org", c.org, "error", err.Error())
+				errs = append(errs, fmt.Errorf("organization %s: %w", c.org, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// organizationClients returns a client for every organization to sync: the configured ones, or
+// the ones discovered from the installations of the GitHub App or the memberships of the token.
+func (s *Source) organizationClients(ctx context.Context) ([]*client, error) {
+	if !s.config.DiscoverOrgs {
+		clients := make([]*client, 0, len(s.config.Orgs))
+		for _, org := range s.config.Orgs {
+			c, err := s.clientFor(org, 0)
+			if err != nil {
+				return nil, err
+			}
+			clients = append(clients, c)
+		}
+		return clients, nil
+	}
+
+	if s.app != nil {
+		return s.installationClients(ctx)
+	}
+
+	return s.membershipClients(ctx)
+}
+
+// installationClients returns a client for every organization the GitHub App is installed on.
+func (s *Source) installationClients(ctx context.Context) ([]*client, error) {
+	installations, err := s.app.Installations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRetrievingAssets, err)
+	}
+
+	clients := make([]*client, 0, len(installations))
+	for _, installation := range installations {
+		if installation.AccountType != organizationAccountType {
+			continue
+		}
+
+		c, err := s.clientFor(installation.Account, installation.ID)
+		if err != nil {
+			return nil, err
+		}
+		clients = append(clients, c)
+	}
+	return clients, nil
+}
+
+// membershipClients returns a client for every organization the owner of the token is a member of.
+func (s *Source) membershipClients(ctx context.Context) ([]*client, error) {
+	it := s.client.listOrganizations(defaultAPIVersion)
+	clients := make([]*client, 0)
+	for {
+		items, err := it.next(ctx)
+		if errors.Is(err, ErrIteratorDone) {
+			return clients, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrRetrievingAssets, err)
+		}
+
+		for _, item := range items {
+			login, _ := item["login"].(string)
+			if login == "" {
+				continue
+			}
+
+			c, err := s.clientFor(login, 0)
+			if err != nil {
+				return nil, err
+			}
+			clients = append(clients, c)
+		}
+	}
+}
+
+// clientFor returns the client of org, creating and caching it on first use. With a GitHub App,
+// the client uses the tokens of installationID, or of the installation on org when zero.
+func (s *Source) clientFor(org string, installationID int64) (*client, error) {
+	if s.client.org == org {
+		return s.client, nil
+	}
+
+	s.clientsLock.Lock()
+	defer s.clientsLock.Unlock()
+
+	if c, ok := s.clients[org]; ok {
+		return c, nil
+	}
+
+	var tokenSource oauth2.TokenSource
+	if s.app != nil {
+		var err error
+		if tokenSource, err = s.app.TokenSource(context.Background(), installationID, org); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.clients == nil {
+		s.clients = make(map[string]*client)
+	}
+	c := s.client.forOrganization(org, tokenSource)
+	s.clients[org] = c
+	return c, nil
+}
+
+// webhookClient returns the client of the organization the webhook event belongs to, or false
+// when the organization is neither configured nor discovered. Events without an organization are
+// handled with the primary client.
+func (s *Source) webhookClient(org string) (*client, bool, error) {
+	switch {
+	case org == "":
+		return s.client, true, nil
+	case !s.config.DiscoverOrgs && !slices.Contains(s.config.Orgs, org):
+		return nil, false, nil
+	}
+
+	c, err := s.clientFor(org, 0)
+	if err != nil {
+		return nil, false, err
+	}
+	return c, true, nil
+}
@@ -0,0 +1,292 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package github
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v3/jwk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mia-platform/ibdm/internal/source"
+	"github.com/mia-platform/ibdm/internal/tokensource/githubappsource"
+)
+
+// repositoryNames returns the full names of the repositories sent on results.
+func repositoryNames(results <-chan source.Data) []string {
+	names := make([]string, 0)
+	for d := range results {
+		repo, _ := d.Values[repositoryType].(map[string]any)
+		name, _ := repo["full_name"].(string)
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// organizationsHandler serves the repositories of orgs, the memberships of the token and a 403
+// for the repositories of any other organization.
+func organizationsHandler(orgs ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/user/orgs" {
+			memberships := make([]map[string]any, 0, len(orgs))
+			for _, org := range orgs {
+				memberships = append(memberships, map[string]any{"login": org})
+			}
+			json.NewEncoder(w).Encode(memberships)
+			return
+		}
+
+		org, found := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/orgs/"), "/repos")
+		switch {
+		case found && slices.Contains(orgs, org):
+			json.NewEncoder(w).Encode([]map[string]any{{"full_name": org + "/repo"}})
+		case found:
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message":"Resource not accessible by integration"}`))
+		default:
+			json.NewEncoder(w).Encode(map[string]any{})
+		}
+	}
+}
+
+func newOrganizationsSource(serverURL string, httpClient *http.Client, cfg config) *Source {
+	var org string
+	if len(cfg.Orgs) > 0 {
+		org = cfg.Orgs[0]
+	}
+
+	return &Source{
+		config: cfg,
+		client: &client{
+			baseURL:    serverURL,
+			org:        org,
+			token:      "test-token",
+			pageSize:   100,
+			httpClient: httpClient,
+		},
+		clients: make(map[string]*client),
+	}
+}
+
+func TestStartSyncProcessMultipleOrganizations(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(organizationsHandler("org-a", "org-c"))
+	t.Cleanup(server.Close)
+
+	s := newOrganizationsSource(server.URL, server.Client(), config{Orgs: []string{"org-a", "org-b", "org-c"}})
+
+	results := make(chan source.Data, 100)
+	err := s.StartSyncProcess(t.Context(), map[string]source.Extra{repositoryType: {}}, results)
+	close(results)
+
+	require.ErrorIs(t, err, ErrGitHubSource)
+	require.ErrorIs(t, err, ErrRetrievingAssets)
+	assert.ErrorContains(t, err, "organization org-b")
+	assert.Equal(t, []string{"org-a/repo", "org-c/repo"}, repositoryNames(results))
+}
+
+func TestStartSyncProcessDiscoveredOrganizations(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(organizationsHandler("org-a", "org-b"))
+	t.Cleanup(server.Close)
+
+	s := newOrganizationsSource(server.URL, server.Client(), config{DiscoverOrgs: true})
+
+	results := make(chan source.Data, 100)
+	err := s.StartSyncProcess(t.Context(), map[string]source.Extra{repositoryType: {}}, results)
+	close(results)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"org-a/repo", "org-b/repo"}, repositoryNames(results))
+}
+
+func TestStartSyncProcessDiscoveredInstallations(t *testing.T) {
+	t.Parallel()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	key, err := jwk.Import(rsaKey)
+	require.NoError(t, err)
+
+	repositories := organizationsHandler("org-a", "org-b")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/app/installations":
+			w.Write([]byte(`[{"id":1,"account":{"login":"org-a","type":"Organization"}},{"id":2,"account":{"login":"octocat","type":"User"}},{"id":3,"account":{"login":"org-b","type":"Organization"}}]`))
+		case strings.HasPrefix(r.URL.Path, "/app/installations/"):
+			installationID := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/app/installations/"), "/access_tokens")
+			json.NewEncoder(w).Encode(map[string]any{
+				"token":      "ghs_" + installationID,
+				"expires_at": time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+			})
+		case strings.HasPrefix(r.URL.Path, "/orgs/"):
+			org := strings.Split(strings.TrimPrefix(r.URL.Path, "/orgs/"), "/")[0]
+			expectedToken := map[string]string{"org-a": "ghs_1", "org-b": "ghs_3"}[org]
+			assert.Equal(t, "Bearer "+expectedToken, r.Header.Get("Authorization"))
+			repositories(w, r)
+		default:
+			repositories(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	app, err := githubappsource.NewApp(githubappsource.API{
+		URL:        server.URL,
+		Version:    defaultAPIVersion,
+		UserAgent:  userAgent,
+		HTTPClient: server.Client(),
+	}, "123456", key)
+	require.NoError(t, err)
+
+	s := newOrganizationsSource(server.URL, server.Client(), config{DiscoverOrgs: true})
+	s.client.token = ""
+	s.app = app
+
+	results := make(chan source.Data, 100)
+	err = s.StartSyncProcess(t.Context(), map[string]source.Extra{repositoryType: {}}, results)
+	close(results)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"org-a/repo", "org-b/repo"}, repositoryNames(results))
+}
+
+func TestEventOrganization(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		body     string
+		expected string
+	}{
+		"organization field": {
+			body:     `{"organization":{"login":"org-a"},"repository":{"owner":{"login":"org-b"}}}`,
+			expected: "org-a",
+		},
+		"repository owner": {
+			body:     `{"repository":{"owner":{"login":"org-b"}}}`,
+			expected: "org-b",
+		},
+		"installation account": {
+			body:     `{"installation":{"id":1,"account":{"login":"org-c"}},"repositories_added":[]}`,
+			expected: "org-c",
+		},
+		"no organization": {
+			body: `{"action":"ping"}`,
+		},
+		"invalid json": {
+			body: `not json`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, eventOrganization([]byte(tc.body)))
+		})
+	}
+}
+
+func TestWebhookHandlerOrganizations(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		config       config
+		org          string
+		expectResult bool
+	}{
+		"configured organization": {
+			config:       config{Orgs: []string{"org-a", "org-b"}},
+			org:          "org-b",
+			expectResult: true,
+		},
+		"unconfigured organization": {
+			config: config{Orgs: []string{"org-a", "org-b"}},
+			org:    "org-c",
+		},
+		"discovered organization": {
+			config:       config{DiscoverOrgs: true},
+			org:          "org-c",
+			expectResult: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			secret := "mysecret"
+			tc.config.WebhookSecret = secret
+			s := newOrganizationsSource("http://localhost", http.DefaultClient, tc.config)
+
+			results := make(chan source.Data, 10)
+			webhook, err := s.GetWebhook(t.Context(), map[string]source.Extra{repositoryType: {}}, results)
+			require.NoError(t, err)
+
+			body := []byte(`{"action":"created","repository":{"id":1,"name":"repo1","owner":{"login":"` + tc.org + `"}}}`)
+			headers := http.Header{}
+			headers.Set("X-Hub-Signature-256", computeSignature(body, secret))
+			headers.Set(githubEventHeader, repositoryEventHeaderValue)
+			headers.Set("Content-Type", "application/json")
+
+			require.NoError(t, webhook.Handler(t.Context(), headers, body))
+
+			select {
+			case d := <-results:
+				assert.True(t, tc.expectResult, "unexpected data on results channel: %v", d)
+				assert.Equal(t, repositoryType, d.Type)
+			case <-time.After(200 * time.Millisecond):
+				assert.False(t, tc.expectResult, "timeout waiting for webhook result")
+			}
+		})
+	}
+}
+
+func TestWebhookHandlerTransferOutOfOrganization(t *testing.T) {
+	t.Parallel()
+
+	secret := "mysecret"
+	s := newOrganizationsSource("http://localhost", http.DefaultClient, config{Orgs: []string{"org-a"}, WebhookSecret: secret})
+
+	results := make(chan source.Data, 10)
+	webhook, err := s.GetWebhook(t.Context(), map[string]source.Extra{repositoryType: {}}, results)
+	require.NoError(t, err)
+
+	body := []byte(`{"action":"transferred","changes":{"owner":{"from":{"organization":{"login":"org-a"}}}},` +
+		`"organization":{"login":"org-c"},"repository":{"id":1,"name":"repo1","full_name":"org-c/repo1","owner":{"login":"org-c"}}}`)
+	headers := http.Header{}
+	headers.Set("X-Hub-Signature-256", computeSignature(body, secret))
+	headers.Set(githubEventHeader, repositoryEventHeaderValue)
+	headers.Set("Content-Type", "application/json")
+
+	require.NoError(t, webhook.Handler(t.Context(), headers, body))
+
+	select {
+	case d := <-results:
+		assert.Equal(t, source.DataOperationDelete, d.Operation)
+		repo, _ := d.Values[repositoryType].(map[string]any)
+		assert.Equal(t, "org-a/repo1", repo["full_name"])
+	case <-time.After(time.Second):
+		require.FailNow(t, "timeout waiting for webhook result")
+	}
+
+	select {
+	case d := <-results:
+		assert.Fail(t, "unexpected data on results channel", "%v", d)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -5,6 +5,8 @@ package github
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 
 	"github.com/mia-platform/ibdm/internal/source"
 )
@@ -16,6 +18,15 @@ const (
 	personalAccessTokenRequestEventHeaderValue = "personal_access_token_request"
 	workflowDispatchEventHeaderValue           = "workflow_dispatch"
 	workflowRunEventHeaderValue                = "workflow_run"
+	teamEventHeaderValue                       = "team"
+	membershipEventHeaderValue                 = "membership"
+	releaseEventHeaderValue                    = "release"
+	deploymentEventHeaderValue                 = "deployment"
+	deploymentStatusEventHeaderValue           = "deployment_status"
+	dependabotAlertEventHeaderValue            = "dependabot_alert"
+	codeScanningAlertEventHeaderValue          = "code_scanning_alert"
+	secretScanningAlertEventHeaderValue        = "secret_scanning_alert"
+	installationRepositoriesEventHeaderValue   = "installation_repositories"
 )
 
 // eventProcessor handles a single GitHub webhook event type.
@@ -38,5 +49,51 @@ func newEventProcessors(c *client) map[string]eventProcessor {
 		personalAccessTokenRequestEventHeaderValue: &personalAccessTokenRequestProcessor{client: c},
 		workflowDispatchEventHeaderValue:           &workflowDispatchProcessor{client: c},
 		workflowRunEventHeaderValue:                &workflowRunProcessor{client: c},
+		teamEventHeaderValue:                       &teamEventProcessor{client: c},
+		membershipEventHeaderValue:                 &membershipEventProcessor{client: c},
+		releaseEventHeaderValue:                    &releaseEventProcessor{client: c},
+		deploymentEventHeaderValue:                 &deploymentEventProcessor{client: c},
+		deploymentStatusEventHeaderValue:           &deploymentStatusEventProcessor{client: c},
+		dependabotAlertEventHeaderValue: &alertEventProcessor{
+			client:            c,
+			dataType:          dependabotAlertType,
+			actionToOperation: dependabotAlertActionToOperation,
+		},
+		codeScanningAlertEventHeaderValue: &alertEventProcessor{
+			client:            c,
+			dataType:          codeScanningAlertType,
+			actionToOperation: codeScanningAlertActionToOperation,
+		},
+		secretScanningAlertEventHeaderValue: &alertEventProcessor{
+			client:            c,
+			dataType:          secretScanningAlertType,
+			actionToOperation: secretScanningAlertActionToOperation,
+		},
+		installationRepositoriesEventHeaderValue: &installationRepositoriesEventProcessor{client: c},
 	}
 }
+
+// parseEvent extracts the action and the objects found under keys from the payload
+// of the named webhook event. Every key must hold a JSON object.
+func parseEvent(body []byte, event string, keys ...string) (string, map[string]map[string]any, error) {
+	var payload map[string]any
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return "", nil, fmt.Errorf("failed to unmarshal %s event: %w", event, err)
+	}
+
+	action, ok := payload["action"].(string)
+	if !ok || action == "" {
+		return "", nil, fmt.Errorf("missing or invalid action field in %s event", event)
+	}
+
+	objects := make(map[string]map[string]any, len(keys))
+	for _, key := range keys {
+		object, ok := payload[key].(map[string]any)
+		if !ok {
+			return "", nil, fmt.Errorf("missing or invalid %s field in %s event", key, event)
+		}
+		objects[key] = object
+	}
+
+	return action, objects, nil
+}
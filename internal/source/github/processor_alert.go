@@ -0,0 +1,80 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package github
+
+import (
+	"context"
+
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+// alertEventProcessor handles the webhook events of security alerts: the
+// "dependabot_alert", "code_scanning_alert" and "secret_scanning_alert" events.
+// Every change of state, including fixed, dismissed and resolved alerts, is an
+// upsert carrying the new state, while deleted alerts are deleted.
+type alertEventProcessor struct {
+	client *client
+	// dataType is the data type of the alerts, matching the name of the event.
+	dataType string
+	// actionToOperation maps the actions of the event to data operations.
+	actionToOperation map[string]source.DataOperation
+}
+
+// dependabotAlertActionToOperation maps dependabot_alert webhook actions to data operations.
+var dependabotAlertActionToOperation = map[string]source.DataOperation{
+	"created":        source.DataOperationUpsert,
+	"reopened":       source.DataOperationUpsert,
+	"reintroduced":   source.DataOperationUpsert,
+	"auto_reopened":  source.DataOperationUpsert,
+	"dismissed":      source.DataOperationUpsert,
+	"auto_dismissed": source.DataOperationUpsert,
+	"fixed":          source.DataOperationUpsert,
+	"deleted":        source.DataOperationDelete,
+}
+
+// codeScanningAlertActionToOperation maps code_scanning_alert webhook actions to data operations.
+var codeScanningAlertActionToOperation = map[string]source.DataOperation{
+	"created":            source.DataOperationUpsert,
+	"appeared_in_branch": source.DataOperationUpsert,
+	"reopened":           source.DataOperationUpsert,
+	"reopened_by_user":   source.DataOperationUpsert,
+	"closed_by_user":     source.DataOperationUpsert,
+	"fixed":              source.DataOperationUpsert,
+	"deleted":            source.DataOperationDelete,
+}
+
+// secretScanningAlertActionToOperation maps secret_scanning_alert webhook actions to data operations.
+var secretScanningAlertActionToOperation = map[string]source.DataOperation{
+	"created":         source.DataOperationUpsert,
+	"reopened":        source.DataOperationUpsert,
+	"resolved":        source.DataOperationUpsert,
+	"validated":       source.DataOperationUpsert,
+	"publicly_leaked": source.DataOperationUpsert,
+	"deleted":         source.DataOperationDelete,
+}
+
+func (p *alertEventProcessor) process(_ context.Context, typesToStream map[string]source.Extra, body []byte) ([]source.Data, error) {
+	if _, ok := typesToStream[p.dataType]; !ok {
+		return nil, nil
+	}
+
+	action, objects, err := parseEvent(body, p.dataType, "alert", repositoryType)
+	if err != nil {
+		return nil, err
+	}
+
+	operation, ok := p.actionToOperation[action]
+	if !ok {
+		return nil, nil
+	}
+
+	return []source.Data{
+		{
+			Type:      p.dataType,
+			Operation: operation,
+			Values:    alertValues(p.dataType, objects["alert"], objects[repositoryType]),
+			Time:      timeSource(),
+		},
+	}, nil
+}
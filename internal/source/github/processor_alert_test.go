@@ -0,0 +1,116 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package github
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+func TestAlertEventProcessor(t *testing.T) {
+	fixedTime := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	originalTimeSource := timeSource
+	t.Cleanup(func() { timeSource = originalTimeSource })
+	timeSource = func() time.Time { return fixedTime }
+
+	processors := newEventProcessors(nil)
+	repo := map[string]any{"id": float64(2)}
+
+	testCases := map[string]struct {
+		event         string
+		typesToStream map[string]source.Extra
+		body          string
+		expectedData  []source.Data
+		expectErr     bool
+	}{
+		"fixed dependabot alert returns upsert": {
+			event:         dependabotAlertEventHeaderValue,
+			typesToStream: map[string]source.Extra{dependabotAlertType: {}},
+			body:          `{"action":"fixed","alert":{"number":1,"state":"fixed"},"repository":{"id":2}}`,
+			expectedData: []source.Data{
+				{
+					Type:      dependabotAlertType,
+					Operation: source.DataOperationUpsert,
+					Values:    map[string]any{dependabotAlertType: map[string]any{"number": float64(1), "state": "fixed"}, repositoryType: repo},
+					Time:      fixedTime,
+				},
+			},
+		},
+		"dismissed code scanning alert returns upsert": {
+			event:         codeScanningAlertEventHeaderValue,
+			typesToStream: map[string]source.Extra{codeScanningAlertType: {}},
+			body:          `{"action":"closed_by_user","alert":{"number":1,"state":"dismissed"},"repository":{"id":2}}`,
+			expectedData: []source.Data{
+				{
+					Type:      codeScanningAlertType,
+					Operation: source.DataOperationUpsert,
+					Values:    map[string]any{codeScanningAlertType: map[string]any{"number": float64(1), "state": "dismissed"}, repositoryType: repo},
+					Time:      fixedTime,
+				},
+			},
+		},
+		"resolved secret scanning alert returns upsert": {
+			event:         secretScanningAlertEventHeaderValue,
+			typesToStream: map[string]source.Extra{secretScanningAlertType: {}},
+			body:          `{"action":"resolved","alert":{"number":1,"state":"resolved"},"repository":{"id":2}}`,
+			expectedData: []source.Data{
+				{
+					Type:      secretScanningAlertType,
+					Operation: source.DataOperationUpsert,
+					Values:    map[string]any{secretScanningAlertType: map[string]any{"number": float64(1), "state": "resolved"}, repositoryType: repo},
+					Time:      fixedTime,
+				},
+			},
+		},
+		"deleted alert returns delete": {
+			event:         secretScanningAlertEventHeaderValue,
+			typesToStream: map[string]source.Extra{secretScanningAlertType: {}},
+			body:          `{"action":"deleted","alert":{"number":1,"state":"resolved"},"repository":{"id":2}}`,
+			expectedData: []source.Data{
+				{
+					Type:      secretScanningAlertType,
+					Operation: source.DataOperationDelete,
+					Values:    map[string]any{secretScanningAlertType: map[string]any{"number": float64(1), "state": "resolved"}, repositoryType: repo},
+					Time:      fixedTime,
+				},
+			},
+		},
+		"unknown action returns nil": {
+			event:         dependabotAlertEventHeaderValue,
+			typesToStream: map[string]source.Extra{dependabotAlertType: {}},
+			body:          `{"action":"assignees_changed","alert":{"number":1},"repository":{"id":2}}`,
+			expectedData:  nil,
+		},
+		"type not in typesToStream returns nil": {
+			event:         dependabotAlertEventHeaderValue,
+			typesToStream: map[string]source.Extra{codeScanningAlertType: {}},
+			body:          `{"action":"fixed","alert":{"number":1},"repository":{"id":2}}`,
+			expectedData:  nil,
+		},
+		"missing alert returns error": {
+			event:         codeScanningAlertEventHeaderValue,
+			typesToStream: map[string]source.Extra{codeScanningAlertType: {}},
+			body:          `{"action":"fixed","repository":{"id":2}}`,
+			expectErr:     true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			data, err := processors[tc.event].process(t.Context(), tc.typesToStream, []byte(tc.body))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedData, data)
+		})
+	}
+}
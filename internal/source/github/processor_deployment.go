@@ -0,0 +1,45 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package github
+
+import (
+	"context"
+
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+// deploymentEventProcessor handles "deployment" webhook events.
+type deploymentEventProcessor struct {
+	client *client
+}
+
+// deploymentActionToOperation maps deployment webhook actions to data operations.
+var deploymentActionToOperation = map[string]source.DataOperation{
+	"created": source.DataOperationUpsert,
+}
+
+func (p *deploymentEventProcessor) process(_ context.Context, typesToStream map[string]source.Extra, body []byte) ([]source.Data, error) {
+	if _, ok := typesToStream[deploymentType]; !ok {
+		return nil, nil
+	}
+
+	action, objects, err := parseEvent(body, deploymentEventHeaderValue, deploymentType, repositoryType)
+	if err != nil {
+		return nil, err
+	}
+
+	operation, ok := deploymentActionToOperation[action]
+	if !ok {
+		return nil, nil
+	}
+
+	return []source.Data{
+		{
+			Type:      deploymentType,
+			Operation: operation,
+			Values:    map[string]any{deploymentType: objects[deploymentType], repositoryType: objects[repositoryType]},
+			Time:      timeSource(),
+		},
+	}, nil
+}
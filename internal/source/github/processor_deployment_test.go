@@ -0,0 +1,74 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package github
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+func TestDeploymentEventProcessor(t *testing.T) {
+	fixedTime := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	originalTimeSource := timeSource
+	t.Cleanup(func() { timeSource = originalTimeSource })
+	timeSource = func() time.Time { return fixedTime }
+
+	processor := &deploymentEventProcessor{}
+
+	testCases := map[string]struct {
+		typesToStream map[string]source.Extra
+		body          string
+		expectedData  []source.Data
+		expectErr     bool
+	}{
+		"created action returns upsert": {
+			typesToStream: map[string]source.Extra{deploymentType: {}},
+			body:          `{"action":"created","deployment":{"id":1,"environment":"production"},"repository":{"id":2}}`,
+			expectedData: []source.Data{
+				{
+					Type:      deploymentType,
+					Operation: source.DataOperationUpsert,
+					Values: map[string]any{
+						deploymentType: map[string]any{"id": float64(1), "environment": "production"},
+						repositoryType: map[string]any{"id": float64(2)},
+					},
+					Time: fixedTime,
+				},
+			},
+		},
+		"unknown action returns nil": {
+			typesToStream: map[string]source.Extra{deploymentType: {}},
+			body:          `{"action":"unknown_action","deployment":{"id":1},"repository":{"id":2}}`,
+			expectedData:  nil,
+		},
+		"type not in typesToStream returns nil": {
+			typesToStream: map[string]source.Extra{"othertype": {}},
+			body:          `{"action":"created","deployment":{"id":1},"repository":{"id":2}}`,
+			expectedData:  nil,
+		},
+		"missing deployment returns error": {
+			typesToStream: map[string]source.Extra{deploymentType: {}},
+			body:          `{"action":"created","repository":{"id":2}}`,
+			expectErr:     true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			data, err := processor.process(t.Context(), tc.typesToStream, []byte(tc.body))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedData, data)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package github
+
+import (
+	"context"
+
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+// deploymentStatusEventProcessor handles "deployment_status" webhook events.
+type deploymentStatusEventProcessor struct {
+	client *client
+}
+
+// deploymentStatusActionToOperation maps deployment_status webhook actions to data operations.
+var deploymentStatusActionToOperation = map[string]source.DataOperation{
+	"created": source.DataOperationUpsert,
+}
+
+func (p *deploymentStatusEventProcessor) process(_ context.Context, typesToStream map[string]source.Extra, body []byte) ([]source.Data, error) {
+	if _, ok := typesToStream[deploymentStatusType]; !ok {
+		return nil, nil
+	}
+
+	action, objects, err := parseEvent(body, deploymentStatusEventHeaderValue, deploymentStatusType, deploymentType, repositoryType)
+	if err != nil {
+		return nil, err
+	}
+
+	operation, ok := deploymentStatusActionToOperation[action]
+	if !ok {
+		return nil, nil
+	}
+
+	return []source.Data{
+		{
+			Type:      deploymentStatusType,
+			Operation: operation,
+			Values: map[string]any{
+				deploymentStatusType: objects[deploymentStatusType],
+				deploymentType:       objects[deploymentType],
+				repositoryType:       objects[repositoryType],
+			},
+			Time: timeSource(),
+		},
+	}, nil
+}
@@ -0,0 +1,75 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package github
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+func TestDeploymentStatusEventProcessor(t *testing.T) {
+	fixedTime := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	originalTimeSource := timeSource
+	t.Cleanup(func() { timeSource = originalTimeSource })
+	timeSource = func() time.Time { return fixedTime }
+
+	processor := &deploymentStatusEventProcessor{}
+
+	testCases := map[string]struct {
+		typesToStream map[string]source.Extra
+		body          string
+		expectedData  []source.Data
+		expectErr     bool
+	}{
+		"created action returns upsert": {
+			typesToStream: map[string]source.Extra{deploymentStatusType: {}},
+			body:          `{"action":"created","deployment_status":{"id":3,"state":"success"},"deployment":{"id":1},"repository":{"id":2}}`,
+			expectedData: []source.Data{
+				{
+					Type:      deploymentStatusType,
+					Operation: source.DataOperationUpsert,
+					Values: map[string]any{
+						deploymentStatusType: map[string]any{"id": float64(3), "state": "success"},
+						deploymentType:       map[string]any{"id": float64(1)},
+						repositoryType:       map[string]any{"id": float64(2)},
+					},
+					Time: fixedTime,
+				},
+			},
+		},
+		"unknown action returns nil": {
+			typesToStream: map[string]source.Extra{deploymentStatusType: {}},
+			body:          `{"action":"unknown_action","deployment_status":{"id":3},"deployment":{"id":1},"repository":{"id":2}}`,
+			expectedData:  nil,
+		},
+		"type not in typesToStream returns nil": {
+			typesToStream: map[string]source.Extra{deploymentType: {}},
+			body:          `{"action":"created","deployment_status":{"id":3},"deployment":{"id":1},"repository":{"id":2}}`,
+			expectedData:  nil,
+		},
+		"missing deployment returns error": {
+			typesToStream: map[string]source.Extra{deploymentStatusType: {}},
+			body:          `{"action":"created","deployment_status":{"id":3},"repository":{"id":2}}`,
+			expectErr:     true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			data, err := processor.process(t.Context(), tc.typesToStream, []byte(tc.body))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedData, data)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+// installationRepositoriesEventProcessor handles "installation_repositories" webhook
+// events, sent to GitHub Apps when repositories are added to or removed from one of
+// their installations.
+type installationRepositoriesEventProcessor struct {
+	client *client
+}
+
+// installationRepositoriesEvent is the payload of an "installation_repositories" event.
+type installationRepositoriesEvent struct {
+	Action              string           `json:"action"`
+	RepositoriesAdded   []map[string]any `json:"repositories_added"`   //nolint:tagliatelle // GitHub API uses snake_case
+	RepositoriesRemoved []map[string]any `json:"repositories_removed"` //nolint:tagliatelle // GitHub API uses snake_case
+}
+
+func (p *installationRepositoriesEventProcessor) process(ctx context.Context, typesToStream map[string]source.Extra, body []byte) ([]source.Data, error) {
+	extra, ok := typesToStream[repositoryType]
+	if !ok {
+		return nil, nil
+	}
+
+	var event installationRepositoriesEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s event: %w", installationRepositoriesEventHeaderValue, err)
+	}
+	if event.Action != "added" && event.Action != "removed" {
+		return nil, nil
+	}
+
+	apiVersion := apiVersionFromExtra(extra)
+	data := make([]source.Data, 0, len(event.RepositoriesAdded)+len(event.RepositoriesRemoved))
+	for _, repo := range event.RepositoriesRemoved {
+		data = append(data, deleteData(repositoryType, map[string]any{repositoryType: withOwner(repo)}))
+	}
+	for _, repo := range event.RepositoriesAdded {
+		repo = refetchRepository(ctx, p.client, withOwner(repo), apiVersion)
+		data = append(data, upsertData(repositoryType, repositoryValues(ctx, p.client, repo, apiVersion)))
+	}
+	return data, nil
+}
+
+// withOwner returns repo with the owner login taken from its full name, since the
+// repositories of installation events only carry their identifiers and names.
+func withOwner(repo map[string]any) map[string]any {
+	if _, ok := repo["owner"]; ok {
+		return repo
+	}
+
+	fullName, _ := repo["full_name"].(string)
+	login, _, found := strings.Cut(fullName, "/")
+	if !found {
+		return repo
+	}
+
+	repo["owner"] = map[string]any{"login": login}
+	return repo
+}
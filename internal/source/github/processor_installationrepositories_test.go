@@ -0,0 +1,123 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+func TestInstallationRepositoriesEventProcessor(t *testing.T) {
+	fixedTime := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	originalTimeSource := timeSource
+	t.Cleanup(func() { timeSource = originalTimeSource })
+	timeSource = func() time.Time { return fixedTime }
+
+	processor := &installationRepositoriesEventProcessor{}
+
+	testCases := map[string]struct {
+		typesToStream map[string]source.Extra
+		body          string
+		expectedData  []source.Data
+		expectErr     bool
+	}{
+		"added action returns upserts": {
+			typesToStream: map[string]source.Extra{repositoryType: {}},
+			body:          `{"action":"added","installation":{"account":{"login":"my-org"}},"repositories_added":[{"id":1,"name":"repo1","full_name":"my-org/repo1"}],"repositories_removed":[]}`,
+			expectedData: []source.Data{
+				{
+					Type:      repositoryType,
+					Operation: source.DataOperationUpsert,
+					Values: map[string]any{repositoryType: map[string]any{
+						"id": float64(1), "name": "repo1", "full_name": "my-org/repo1", "owner": map[string]any{"login": "my-org"},
+					}},
+					Time: fixedTime,
+				},
+			},
+		},
+		"removed action returns deletes": {
+			typesToStream: map[string]source.Extra{repositoryType: {}},
+			body:          `{"action":"removed","repositories_added":[],"repositories_removed":[{"id":1,"name":"repo1","full_name":"my-org/repo1"},{"id":2,"name":"repo2","full_name":"my-org/repo2"}]}`,
+			expectedData: []source.Data{
+				{
+					Type:      repositoryType,
+					Operation: source.DataOperationDelete,
+					Values: map[string]any{repositoryType: map[string]any{
+						"id": float64(1), "name": "repo1", "full_name": "my-org/repo1", "owner": map[string]any{"login": "my-org"},
+					}},
+					Time: fixedTime,
+				},
+				{
+					Type:      repositoryType,
+					Operation: source.DataOperationDelete,
+					Values: map[string]any{repositoryType: map[string]any{
+						"id": float64(2), "name": "repo2", "full_name": "my-org/repo2", "owner": map[string]any{"login": "my-org"},
+					}},
+					Time: fixedTime,
+				},
+			},
+		},
+		"unknown action returns nil": {
+			typesToStream: map[string]source.Extra{repositoryType: {}},
+			body:          `{"action":"suspended","repositories_removed":[{"id":1}]}`,
+		},
+		"type not in typesToStream returns nil": {
+			typesToStream: map[string]source.Extra{teamType: {}},
+			body:          `{"action":"added","repositories_added":[{"id":1}]}`,
+		},
+		"malformed body returns error": {
+			typesToStream: map[string]source.Extra{repositoryType: {}},
+			body:          `not json`,
+			expectErr:     true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			data, err := processor.process(t.Context(), tc.typesToStream, []byte(tc.body))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedData, data)
+		})
+	}
+}
+
+func TestInstallationRepositoriesEventProcessorFetchesAddedRepositories(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/repos/my-org/repo1":
+			json.NewEncoder(w).Encode(map[string]any{"id": 1, "full_name": "my-org/repo1", "default_branch": "main"})
+		case "/repos/my-org/repo1/languages":
+			json.NewEncoder(w).Encode(map[string]float64{"Go": 10})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	processor := &installationRepositoriesEventProcessor{client: &client{baseURL: server.URL, org: "my-org", pageSize: 50, httpClient: server.Client()}}
+	body := []byte(`{"action":"added","repositories_added":[{"id":1,"name":"repo1","full_name":"my-org/repo1"}]}`)
+
+	data, err := processor.process(t.Context(), map[string]source.Extra{repositoryType: {}}, body)
+	require.NoError(t, err)
+	require.Len(t, data, 1)
+	assert.Equal(t, source.DataOperationUpsert, data[0].Operation)
+	assert.Equal(t, map[string]any{"id": float64(1), "full_name": "my-org/repo1", "default_branch": "main"}, data[0].Values[repositoryType])
+	assert.Equal(t, map[string]float64{"Go": 100}, data[0].Values["repositoryLanguages"])
+}
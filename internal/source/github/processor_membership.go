@@ -0,0 +1,62 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+// membershipEventProcessor handles "membership" webhook events, sent when users are
+// added to or removed from teams.
+type membershipEventProcessor struct {
+	client *client
+}
+
+// membershipTeamScope is the scope of membership events related to teams.
+const membershipTeamScope = "team"
+
+// membershipActionToOperation maps membership webhook actions to data operations.
+var membershipActionToOperation = map[string]source.DataOperation{
+	"added":   source.DataOperationUpsert,
+	"removed": source.DataOperationDelete,
+}
+
+func (p *membershipEventProcessor) process(_ context.Context, typesToStream map[string]source.Extra, body []byte) ([]source.Data, error) {
+	if _, ok := typesToStream[memberType]; !ok {
+		return nil, nil
+	}
+
+	var payload struct {
+		Scope string `json:"scope"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal membership event: %w", err)
+	}
+	if payload.Scope != membershipTeamScope {
+		return nil, nil
+	}
+
+	action, objects, err := parseEvent(body, membershipEventHeaderValue, "member", teamType)
+	if err != nil {
+		return nil, err
+	}
+
+	operation, ok := membershipActionToOperation[action]
+	if !ok {
+		return nil, nil
+	}
+
+	return []source.Data{
+		{
+			Type:      memberType,
+			Operation: operation,
+			Values:    map[string]any{memberType: objects["member"], teamType: objects[teamType]},
+			Time:      timeSource(),
+		},
+	}, nil
+}
@@ -0,0 +1,97 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package github
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+func TestMembershipEventProcessor(t *testing.T) {
+	fixedTime := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	originalTimeSource := timeSource
+	t.Cleanup(func() { timeSource = originalTimeSource })
+	timeSource = func() time.Time { return fixedTime }
+
+	processor := &membershipEventProcessor{}
+	values := map[string]any{
+		memberType: map[string]any{"login": "octocat"},
+		teamType:   map[string]any{"slug": "platform"},
+	}
+
+	testCases := map[string]struct {
+		typesToStream map[string]source.Extra
+		body          string
+		expectedData  []source.Data
+		expectErr     bool
+	}{
+		"added action returns upsert": {
+			typesToStream: map[string]source.Extra{memberType: {}},
+			body:          `{"action":"added","scope":"team","member":{"login":"octocat"},"team":{"slug":"platform"}}`,
+			expectedData: []source.Data{
+				{
+					Type:      memberType,
+					Operation: source.DataOperationUpsert,
+					Values:    values,
+					Time:      fixedTime,
+				},
+			},
+		},
+		"removed action returns delete": {
+			typesToStream: map[string]source.Extra{memberType: {}},
+			body:          `{"action":"removed","scope":"team","member":{"login":"octocat"},"team":{"slug":"platform"}}`,
+			expectedData: []source.Data{
+				{
+					Type:      memberType,
+					Operation: source.DataOperationDelete,
+					Values:    values,
+					Time:      fixedTime,
+				},
+			},
+		},
+		"other scope returns nil": {
+			typesToStream: map[string]source.Extra{memberType: {}},
+			body:          `{"action":"removed","scope":"organization","member":{"login":"octocat"}}`,
+			expectedData:  nil,
+		},
+		"unknown action returns nil": {
+			typesToStream: map[string]source.Extra{memberType: {}},
+			body:          `{"action":"unknown_action","scope":"team","member":{"login":"octocat"},"team":{"slug":"platform"}}`,
+			expectedData:  nil,
+		},
+		"type not in typesToStream returns nil": {
+			typesToStream: map[string]source.Extra{"othertype": {}},
+			body:          `{"action":"added","scope":"team","member":{"login":"octocat"},"team":{"slug":"platform"}}`,
+			expectedData:  nil,
+		},
+		"missing team returns error": {
+			typesToStream: map[string]source.Extra{memberType: {}},
+			body:          `{"action":"added","scope":"team","member":{"login":"octocat"}}`,
+			expectErr:     true,
+		},
+		"malformed body returns error": {
+			typesToStream: map[string]source.Extra{memberType: {}},
+			body:          `not json`,
+			expectErr:     true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			data, err := processor.process(t.Context(), tc.typesToStream, []byte(tc.body))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedData, data)
+		})
+	}
+}
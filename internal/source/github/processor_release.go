@@ -0,0 +1,51 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package github
+
+import (
+	"context"
+
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+// releaseEventProcessor handles "release" webhook events.
+type releaseEventProcessor struct {
+	client *client
+}
+
+// releaseActionToOperation maps release webhook actions to data operations.
+var releaseActionToOperation = map[string]source.DataOperation{
+	"created":     source.DataOperationUpsert,
+	"edited":      source.DataOperationUpsert,
+	"published":   source.DataOperationUpsert,
+	"unpublished": source.DataOperationUpsert,
+	"prereleased": source.DataOperationUpsert,
+	"released":    source.DataOperationUpsert,
+	"deleted":     source.DataOperationDelete,
+}
+
+func (p *releaseEventProcessor) process(_ context.Context, typesToStream map[string]source.Extra, body []byte) ([]source.Data, error) {
+	if _, ok := typesToStream[releaseType]; !ok {
+		return nil, nil
+	}
+
+	action, objects, err := parseEvent(body, releaseEventHeaderValue, releaseType, repositoryType)
+	if err != nil {
+		return nil, err
+	}
+
+	operation, ok := releaseActionToOperation[action]
+	if !ok {
+		return nil, nil
+	}
+
+	return []source.Data{
+		{
+			Type:      releaseType,
+			Operation: operation,
+			Values:    map[string]any{releaseType: objects[releaseType], repositoryType: objects[repositoryType]},
+			Time:      timeSource(),
+		},
+	}, nil
+}
@@ -0,0 +1,87 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package github
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+func TestReleaseEventProcessor(t *testing.T) {
+	fixedTime := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	originalTimeSource := timeSource
+	t.Cleanup(func() { timeSource = originalTimeSource })
+	timeSource = func() time.Time { return fixedTime }
+
+	processor := &releaseEventProcessor{}
+	values := map[string]any{
+		releaseType:    map[string]any{"id": float64(1), "tag_name": "v1.0.0"},
+		repositoryType: map[string]any{"id": float64(2)},
+	}
+
+	testCases := map[string]struct {
+		typesToStream map[string]source.Extra
+		body          string
+		expectedData  []source.Data
+		expectErr     bool
+	}{
+		"published action returns upsert": {
+			typesToStream: map[string]source.Extra{releaseType: {}},
+			body:          `{"action":"published","release":{"id":1,"tag_name":"v1.0.0"},"repository":{"id":2}}`,
+			expectedData: []source.Data{
+				{
+					Type:      releaseType,
+					Operation: source.DataOperationUpsert,
+					Values:    values,
+					Time:      fixedTime,
+				},
+			},
+		},
+		"deleted action returns delete": {
+			typesToStream: map[string]source.Extra{releaseType: {}},
+			body:          `{"action":"deleted","release":{"id":1,"tag_name":"v1.0.0"},"repository":{"id":2}}`,
+			expectedData: []source.Data{
+				{
+					Type:      releaseType,
+					Operation: source.DataOperationDelete,
+					Values:    values,
+					Time:      fixedTime,
+				},
+			},
+		},
+		"unknown action returns nil": {
+			typesToStream: map[string]source.Extra{releaseType: {}},
+			body:          `{"action":"unknown_action","release":{"id":1},"repository":{"id":2}}`,
+			expectedData:  nil,
+		},
+		"type not in typesToStream returns nil": {
+			typesToStream: map[string]source.Extra{"othertype": {}},
+			body:          `{"action":"published","release":{"id":1},"repository":{"id":2}}`,
+			expectedData:  nil,
+		},
+		"missing release returns error": {
+			typesToStream: map[string]source.Extra{releaseType: {}},
+			body:          `{"action":"published","repository":{"id":2}}`,
+			expectErr:     true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			data, err := processor.process(t.Context(), tc.typesToStream, []byte(tc.body))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedData, data)
+		})
+	}
+}
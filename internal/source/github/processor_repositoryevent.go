@@ -8,11 +8,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 
 	"github.com/mia-platform/ibdm/internal/source"
 )
 
-// repositoryEventProcessor handles "repository" webhook events.
+// repositoryEventProcessor handles "repository" webhook events. Renames and transfers
+// change the identity of the repository, so they produce a delete of the previous
+// identity before the upsert of the new one. A repository transferred to an owner that
+// is not managed by the client only produces the delete, since it leaves the catalog.
 type repositoryEventProcessor struct {
 	client *client
 }
@@ -30,8 +34,16 @@ var actionToOperation = map[string]source.DataOperation{
 	"deleted":     source.DataOperationDelete,
 }
 
+// refetchActions are the repository webhook actions after which the repository is
+// fetched again, since they change fields that are not all part of the payload.
+var refetchActions = map[string]bool{
+	"archived":   true,
+	"unarchived": true,
+}
+
 func (p *repositoryEventProcessor) process(ctx context.Context, typesToStream map[string]source.Extra, body []byte) ([]source.Data, error) {
-	if _, ok := typesToStream[repositoryType]; !ok {
+	extra, ok := typesToStream[repositoryType]
+	if !ok {
 		return nil, nil
 	}
 
@@ -45,24 +57,130 @@ func (p *repositoryEventProcessor) process(ctx context.Context, typesToStream ma
 		return nil, nil
 	}
 
-	values := map[string]any{repositoryType: repoObject}
-	if p.client != nil {
-		if fullName, _ := repoObject["full_name"].(string); fullName != "" {
-			apiVersion := apiVersionFromExtra(typesToStream[repositoryType])
-			if langs, err := p.client.getRepositoryLanguages(ctx, fullName, apiVersion); err == nil {
-				values["repositoryLanguages"] = langs
-			}
+	if operation == source.DataOperationDelete {
+		return []source.Data{deleteData(repositoryType, map[string]any{repositoryType: repoObject})}, nil
+	}
+
+	data := make([]source.Data, 0, 2)
+	if previous := previousRepository(body, action, repoObject); previous != nil {
+		data = append(data, deleteData(repositoryType, map[string]any{repositoryType: previous}))
+	}
+
+	if action == "transferred" && !p.manages(repoObject) {
+		return data, nil
+	}
+
+	apiVersion := apiVersionFromExtra(extra)
+	if refetchActions[action] {
+		repoObject = refetchRepository(ctx, p.client, repoObject, apiVersion)
+	}
+	return append(data, upsertData(repositoryType, repositoryValues(ctx, p.client, repoObject, apiVersion))), nil
+}
+
+// manages reports whether repo is owned by the organization of the client, or whether the
+// client is not bound to a single organization.
+func (p *repositoryEventProcessor) manages(repo map[string]any) bool {
+	if p.client == nil || p.client.org == "" {
+		return true
+	}
+
+	owner, _ := repo["owner"].(map[string]any)
+	login, _ := owner["login"].(string)
+	return login == p.client.org
+}
+
+// repositoryValues returns the values of the repository entry of repo, enriched with
+// its languages when c is not nil. A failure retrieving the languages is skipped.
+func repositoryValues(ctx context.Context, c *client, repo map[string]any, apiVersion string) map[string]any {
+	values := map[string]any{repositoryType: repo}
+	if c == nil {
+		return values
+	}
+
+	if fullName, _ := repo["full_name"].(string); fullName != "" {
+		if langs, err := c.getRepositoryLanguages(ctx, fullName, apiVersion); err == nil {
+			values["repositoryLanguages"] = langs
 		}
 	}
+	return values
+}
+
+// refetchRepository returns the current state of repo retrieved with c, or repo itself
+// when c is nil or the request fails.
+func refetchRepository(ctx context.Context, c *client, repo map[string]any, apiVersion string) map[string]any {
+	fullName, _ := repo["full_name"].(string)
+	if c == nil || fullName == "" {
+		return repo
+	}
+
+	current, err := c.getRepository(ctx, fullName, apiVersion)
+	if err != nil {
+		return repo
+	}
+	return current
+}
 
-	return []source.Data{
-		{
-			Type:      repositoryType,
-			Operation: operation,
-			Values:    values,
-			Time:      timeSource(),
-		},
-	}, nil
+// previousRepository returns repo as it was before a renamed or transferred action,
+// rebuilding its name, owner and full name from the changes of the event body, or nil
+// when the action does not change the identity of the repository.
+func previousRepository(body []byte, action string, repo map[string]any) map[string]any {
+	var event struct {
+		Changes struct {
+			Repository struct {
+				Name struct {
+					From string `json:"from"`
+				} `json:"name"`
+			} `json:"repository"`
+		} `json:"changes"`
+	}
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil
+	}
+
+	name, _ := repo["name"].(string)
+	owner, _ := repo["owner"].(map[string]any)
+	switch action {
+	case "renamed":
+		name = event.Changes.Repository.Name.From
+	case "transferred":
+		owner = previousOwner(body)
+	default:
+		return nil
+	}
+
+	login, _ := owner["login"].(string)
+	if name == "" || login == "" {
+		return nil
+	}
+
+	previous := maps.Clone(repo)
+	previous["name"] = name
+	previous["owner"] = owner
+	previous["full_name"] = login + "/" + name
+	return previous
+}
+
+// previousOwner returns the organization or the user owning a transferred repository before
+// the transfer, read from the changes of the event body, or nil when they are missing.
+func previousOwner(body []byte) map[string]any {
+	var event struct {
+		Changes struct {
+			Owner struct {
+				From struct {
+					Organization map[string]any `json:"organization"`
+					User         map[string]any `json:"user"`
+				} `json:"from"`
+			} `json:"owner"`
+		} `json:"changes"`
+	}
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil
+	}
+
+	if event.Changes.Owner.From.Organization != nil {
+		return event.Changes.Owner.From.Organization
+	}
+	return event.Changes.Owner.From.User
 }
 
 // parseRepositoryEvent extracts the action and repository object from a
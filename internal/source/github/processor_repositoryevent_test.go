@@ -101,6 +101,64 @@ func TestRepositoryEventProcessor(t *testing.T) {
 				},
 			},
 		},
+		"renamed action returns delete of the previous name and upsert": {
+			typesToStream: map[string]source.Extra{repositoryType: {}},
+			body: `{"action":"renamed","changes":{"repository":{"name":{"from":"old"}}},` +
+				`"repository":{"id":1,"name":"new","full_name":"my-org/new","owner":{"login":"my-org"}}}`,
+			expectedData: []source.Data{
+				{
+					Type:      repositoryType,
+					Operation: source.DataOperationDelete,
+					Values: map[string]any{repositoryType: map[string]any{
+						"id": float64(1), "name": "old", "full_name": "my-org/old", "owner": map[string]any{"login": "my-org"},
+					}},
+					Time: fixedTime,
+				},
+				{
+					Type:      repositoryType,
+					Operation: source.DataOperationUpsert,
+					Values: map[string]any{repositoryType: map[string]any{
+						"id": float64(1), "name": "new", "full_name": "my-org/new", "owner": map[string]any{"login": "my-org"},
+					}},
+					Time: fixedTime,
+				},
+			},
+		},
+		"transferred action returns delete of the previous owner and upsert": {
+			typesToStream: map[string]source.Extra{repositoryType: {}},
+			body: `{"action":"transferred","changes":{"owner":{"from":{"user":{"login":"someone"}}}},` +
+				`"repository":{"id":1,"name":"repo1","full_name":"my-org/repo1","owner":{"login":"my-org"}}}`,
+			expectedData: []source.Data{
+				{
+					Type:      repositoryType,
+					Operation: source.DataOperationDelete,
+					Values: map[string]any{repositoryType: map[string]any{
+						"id": float64(1), "name": "repo1", "full_name": "someone/repo1", "owner": map[string]any{"login": "someone"},
+					}},
+					Time: fixedTime,
+				},
+				{
+					Type:      repositoryType,
+					Operation: source.DataOperationUpsert,
+					Values: map[string]any{repositoryType: map[string]any{
+						"id": float64(1), "name": "repo1", "full_name": "my-org/repo1", "owner": map[string]any{"login": "my-org"},
+					}},
+					Time: fixedTime,
+				},
+			},
+		},
+		"renamed action without changes returns upsert": {
+			typesToStream: map[string]source.Extra{repositoryType: {}},
+			body:          `{"action":"renamed","repository":{"id":1,"name":"repo1"}}`,
+			expectedData: []source.Data{
+				{
+					Type:      repositoryType,
+					Operation: source.DataOperationUpsert,
+					Values:    map[string]any{repositoryType: map[string]any{"id": float64(1), "name": "repo1"}},
+					Time:      fixedTime,
+				},
+			},
+		},
 		"unknown action returns nil": {
 			typesToStream: map[string]source.Extra{repositoryType: {}},
 			body:          `{"action":"unknown_action","repository":{"id":1,"name":"repo1"}}`,
@@ -169,3 +227,112 @@ func TestRepositoryEventProcessorWithLanguages(t *testing.T) {
 	assert.Equal(t, source.DataOperationUpsert, data[0].Operation)
 	assert.Equal(t, map[string]float64{"Go": 100}, data[0].Values["repositoryLanguages"])
 }
+
+func TestRepositoryEventProcessorRefetch(t *testing.T) {
+	fixedTime := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	originalTimeSource := timeSource
+	t.Cleanup(func() { timeSource = originalTimeSource })
+	timeSource = func() time.Time { return fixedTime }
+
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/repos/my-org/my-repo":
+			json.NewEncoder(w).Encode(map[string]any{"id": 1, "full_name": "my-org/my-repo", "archived": true, "pushed_at": "2026-10-01T10:00:00Z"})
+		case "/repos/my-org/my-repo/languages":
+			json.NewEncoder(w).Encode(map[string]float64{"Go": 100})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	processor := &repositoryEventProcessor{client: &client{baseURL: server.URL, org: "my-org", pageSize: 50, httpClient: server.Client()}}
+	typesToStream := map[string]source.Extra{repositoryType: {}}
+
+	testCases := map[string]struct {
+		body             string
+		expectedRepo     map[string]any
+		expectedRequests []string
+	}{
+		"archived action fetches the repository again": {
+			body:             `{"action":"archived","repository":{"id":1,"full_name":"my-org/my-repo","archived":true}}`,
+			expectedRepo:     map[string]any{"id": float64(1), "full_name": "my-org/my-repo", "archived": true, "pushed_at": "2026-10-01T10:00:00Z"},
+			expectedRequests: []string{"/repos/my-org/my-repo", "/repos/my-org/my-repo/languages"},
+		},
+		"failed fetch keeps the payload": {
+			body:             `{"action":"unarchived","repository":{"id":2,"full_name":"my-org/missing"}}`,
+			expectedRepo:     map[string]any{"id": float64(2), "full_name": "my-org/missing"},
+			expectedRequests: []string{"/repos/my-org/missing", "/repos/my-org/missing/languages"},
+		},
+		"deleted action does not call the API": {
+			body:         `{"action":"deleted","repository":{"id":1,"full_name":"my-org/my-repo"}}`,
+			expectedRepo: map[string]any{"id": float64(1), "full_name": "my-org/my-repo"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			requests = nil
+			data, err := processor.process(t.Context(), typesToStream, []byte(tc.body))
+			require.NoError(t, err)
+			require.Len(t, data, 1)
+			assert.Equal(t, tc.expectedRepo, data[0].Values[repositoryType])
+			assert.Equal(t, tc.expectedRequests, requests)
+		})
+	}
+}
+
+func TestRepositoryEventProcessorTransferred(t *testing.T) {
+	fixedTime := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	originalTimeSource := timeSource
+	t.Cleanup(func() { timeSource = originalTimeSource })
+	timeSource = func() time.Time { return fixedTime }
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(server.Close)
+
+	processor := &repositoryEventProcessor{client: &client{baseURL: server.URL, org: "my-org", pageSize: 50, httpClient: server.Client()}}
+	typesToStream := map[string]source.Extra{repositoryType: {}}
+
+	testCases := map[string]struct {
+		body               string
+		expectedOperations []source.DataOperation
+		expectedNames      []string
+	}{
+		"transfer to the organization of the client upserts the new identity": {
+			body: `{"action":"transferred","changes":{"owner":{"from":{"organization":{"login":"other-org"}}}},` +
+				`"repository":{"id":1,"name":"repo1","full_name":"my-org/repo1","owner":{"login":"my-org"}}}`,
+			expectedOperations: []source.DataOperation{source.DataOperationDelete, source.DataOperationUpsert},
+			expectedNames:      []string{"other-org/repo1", "my-org/repo1"},
+		},
+		"transfer to another owner only deletes the previous identity": {
+			body: `{"action":"transferred","changes":{"owner":{"from":{"organization":{"login":"my-org"}}}},` +
+				`"repository":{"id":1,"name":"repo1","full_name":"someone/repo1","owner":{"login":"someone"}}}`,
+			expectedOperations: []source.DataOperation{source.DataOperationDelete},
+			expectedNames:      []string{"my-org/repo1"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			data, err := processor.process(t.Context(), typesToStream, []byte(tc.body))
+			require.NoError(t, err)
+
+			operations := make([]source.DataOperation, 0, len(data))
+			names := make([]string, 0, len(data))
+			for _, d := range data {
+				operations = append(operations, d.Operation)
+				repo, _ := d.Values[repositoryType].(map[string]any)
+				fullName, _ := repo["full_name"].(string)
+				names = append(names, fullName)
+			}
+			assert.Equal(t, tc.expectedOperations, operations)
+			assert.Equal(t, tc.expectedNames, names)
+		})
+	}
+}
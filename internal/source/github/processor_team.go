@@ -0,0 +1,97 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+// teamEventProcessor handles "team" webhook events, producing team entries and the
+// permissions of teams on repositories.
+// A deleted team only produces the delete of its team entry: GitHub sends no event for its
+// repositories and members, which cannot be listed anymore once the team is gone.
+type teamEventProcessor struct {
+	client *client
+}
+
+// teamActionToOperation maps team webhook actions to team data operations.
+var teamActionToOperation = map[string]source.DataOperation{
+	"created": source.DataOperationUpsert,
+	"edited":  source.DataOperationUpsert,
+	"deleted": source.DataOperationDelete,
+}
+
+// teamRepositoryActionToOperation maps team webhook actions to team_repository data operations.
+var teamRepositoryActionToOperation = map[string]source.DataOperation{
+	"added_to_repository":     source.DataOperationUpsert,
+	"removed_from_repository": source.DataOperationDelete,
+}
+
+func (p *teamEventProcessor) process(_ context.Context, typesToStream map[string]source.Extra, body []byte) ([]source.Data, error) {
+	_, streamTeams := typesToStream[teamType]
+	_, streamTeamRepositories := typesToStream[teamRepositoryType]
+	if !streamTeams && !streamTeamRepositories {
+		return nil, nil
+	}
+
+	action, objects, err := parseEvent(body, teamEventHeaderValue, teamType)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []source.Data
+	if operation, ok := teamActionToOperation[action]; ok && streamTeams {
+		data = append(data, source.Data{
+			Type:      teamType,
+			Operation: operation,
+			Values:    map[string]any{teamType: objects[teamType]},
+			Time:      timeSource(),
+		})
+	}
+
+	if operation, ok := teamRepositoryOperation(action, body); ok && streamTeamRepositories {
+		_, objects, err := parseEvent(body, teamEventHeaderValue, teamType, repositoryType)
+		if err != nil {
+			return nil, err
+		}
+
+		data = append(data, source.Data{
+			Type:      teamRepositoryType,
+			Operation: operation,
+			Values:    teamRepositoryValues(objects[teamType], objects[repositoryType]),
+			Time:      timeSource(),
+		})
+	}
+
+	return data, nil
+}
+
+// teamRepositoryOperation returns the team_repository operation of a team event. Besides the
+// repository actions, an edited event changing the permissions of the team on a repository
+// updates its team_repository entry.
+func teamRepositoryOperation(action string, body []byte) (source.DataOperation, bool) {
+	if operation, ok := teamRepositoryActionToOperation[action]; ok {
+		return operation, true
+	}
+
+	if action != "edited" {
+		return 0, false
+	}
+
+	var payload struct {
+		Changes struct {
+			Repository struct {
+				Permissions json.RawMessage `json:"permissions"`
+			} `json:"repository"`
+		} `json:"changes"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil || len(payload.Changes.Repository.Permissions) == 0 {
+		return 0, false
+	}
+
+	return source.DataOperationUpsert, true
+}
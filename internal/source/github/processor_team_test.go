@@ -0,0 +1,189 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package github
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+func TestTeamEventProcessor(t *testing.T) {
+	fixedTime := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	originalTimeSource := timeSource
+	t.Cleanup(func() { timeSource = originalTimeSource })
+	timeSource = func() time.Time { return fixedTime }
+
+	processor := &teamEventProcessor{}
+	team := map[string]any{"id": float64(1), "slug": "platform"}
+	repository := map[string]any{"id": float64(2), "permissions": map[string]any{"admin": false, "push": true, "pull": true}}
+
+	testCases := map[string]struct {
+		typesToStream map[string]source.Extra
+		body          string
+		expectedData  []source.Data
+		expectErr     bool
+	}{
+		"created action returns team upsert": {
+			typesToStream: map[string]source.Extra{teamType: {}},
+			body:          `{"action":"created","team":{"id":1,"slug":"platform"}}`,
+			expectedData: []source.Data{
+				{
+					Type:      teamType,
+					Operation: source.DataOperationUpsert,
+					Values:    map[string]any{teamType: team},
+					Time:      fixedTime,
+				},
+			},
+		},
+		"deleted action returns team delete": {
+			typesToStream: map[string]source.Extra{teamType: {}},
+			body:          `{"action":"deleted","team":{"id":1,"slug":"platform"}}`,
+			expectedData: []source.Data{
+				{
+					Type:      teamType,
+					Operation: source.DataOperationDelete,
+					Values:    map[string]any{teamType: team},
+					Time:      fixedTime,
+				},
+			},
+		},
+		"added_to_repository action returns team_repository upsert": {
+			typesToStream: map[string]source.Extra{teamRepositoryType: {}},
+			body:          `{"action":"added_to_repository","team":{"id":1,"slug":"platform"},"repository":{"id":2,"permissions":{"admin":false,"push":true,"pull":true}}}`,
+			expectedData: []source.Data{
+				{
+					Type:      teamRepositoryType,
+					Operation: source.DataOperationUpsert,
+					Values:    map[string]any{teamType: team, repositoryType: repository, "permission": "push"},
+					Time:      fixedTime,
+				},
+			},
+		},
+		"removed_from_repository action returns team_repository delete": {
+			typesToStream: map[string]source.Extra{teamRepositoryType: {}},
+			body:          `{"action":"removed_from_repository","team":{"id":1,"slug":"platform"},"repository":{"id":2,"permissions":{"admin":false,"push":true,"pull":true}}}`,
+			expectedData: []source.Data{
+				{
+					Type:      teamRepositoryType,
+					Operation: source.DataOperationDelete,
+					Values:    map[string]any{teamType: team, repositoryType: repository, "permission": "push"},
+					Time:      fixedTime,
+				},
+			},
+		},
+		"edited action changing repository permissions returns team and team_repository upserts": {
+			typesToStream: map[string]source.Extra{teamType: {}, teamRepositoryType: {}},
+			body:          `{"action":"edited","changes":{"repository":{"permissions":{"from":{"admin":false,"push":false,"pull":true}}}},"team":{"id":1,"slug":"platform"},"repository":{"id":2,"permissions":{"admin":false,"push":true,"pull":true}}}`,
+			expectedData: []source.Data{
+				{
+					Type:      teamType,
+					Operation: source.DataOperationUpsert,
+					Values:    map[string]any{teamType: team},
+					Time:      fixedTime,
+				},
+				{
+					Type:      teamRepositoryType,
+					Operation: source.DataOperationUpsert,
+					Values:    map[string]any{teamType: team, repositoryType: repository, "permission": "push"},
+					Time:      fixedTime,
+				},
+			},
+		},
+		"edited action changing repository permissions without team type returns team_repository upsert": {
+			typesToStream: map[string]source.Extra{teamRepositoryType: {}},
+			body:          `{"action":"edited","changes":{"repository":{"permissions":{"from":{"admin":false,"push":false,"pull":true}}}},"team":{"id":1,"slug":"platform"},"repository":{"id":2,"permissions":{"admin":false,"push":true,"pull":true}}}`,
+			expectedData: []source.Data{
+				{
+					Type:      teamRepositoryType,
+					Operation: source.DataOperationUpsert,
+					Values:    map[string]any{teamType: team, repositoryType: repository, "permission": "push"},
+					Time:      fixedTime,
+				},
+			},
+		},
+		"edited action without repository changes returns only team upsert": {
+			typesToStream: map[string]source.Extra{teamType: {}, teamRepositoryType: {}},
+			body:          `{"action":"edited","changes":{"name":{"from":"old"}},"team":{"id":1,"slug":"platform"}}`,
+			expectedData: []source.Data{
+				{
+					Type:      teamType,
+					Operation: source.DataOperationUpsert,
+					Values:    map[string]any{teamType: team},
+					Time:      fixedTime,
+				},
+			},
+		},
+		"repository action without team_repository type returns nil": {
+			typesToStream: map[string]source.Extra{teamType: {}},
+			body:          `{"action":"added_to_repository","team":{"id":1},"repository":{"id":2}}`,
+			expectedData:  nil,
+		},
+		"type not in typesToStream returns nil": {
+			typesToStream: map[string]source.Extra{"othertype": {}},
+			body:          `{"action":"created","team":{"id":1}}`,
+			expectedData:  nil,
+		},
+		"missing repository returns error": {
+			typesToStream: map[string]source.Extra{teamRepositoryType: {}},
+			body:          `{"action":"added_to_repository","team":{"id":1}}`,
+			expectErr:     true,
+		},
+		"malformed body returns error": {
+			typesToStream: map[string]source.Extra{teamType: {}},
+			body:          `not json`,
+			expectErr:     true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			data, err := processor.process(t.Context(), tc.typesToStream, []byte(tc.body))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedData, data)
+		})
+	}
+}
+
+func TestTeamRepositoryValues(t *testing.T) {
+	t.Parallel()
+
+	team := map[string]any{"slug": "platform"}
+	testCases := map[string]struct {
+		repo               map[string]any
+		expectedPermission string
+	}{
+		"role name": {
+			repo:               map[string]any{"role_name": "maintain", "permissions": map[string]any{"admin": true}},
+			expectedPermission: "maintain",
+		},
+		"highest granted permission": {
+			repo:               map[string]any{"permissions": map[string]any{"admin": true, "push": true, "pull": true}},
+			expectedPermission: "admin",
+		},
+		"no permissions": {
+			repo: map[string]any{"id": float64(1)},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			values := teamRepositoryValues(team, tc.repo)
+			assert.Equal(t, tc.expectedPermission, values["permission"])
+			assert.Equal(t, team, values[teamType])
+			assert.Equal(t, tc.repo, values[repositoryType])
+		})
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package github
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEvent(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		body        string
+		keys        []string
+		wantAction  string
+		wantObjects map[string]map[string]any
+		wantErr     string
+	}{
+		"valid payload": {
+			body:       `{"action":"published","release":{"id":1},"repository":{"id":2}}`,
+			keys:       []string{"release", "repository"},
+			wantAction: "published",
+			wantObjects: map[string]map[string]any{
+				"release":    {"id": float64(1)},
+				"repository": {"id": float64(2)},
+			},
+		},
+		"invalid JSON": {
+			body:    `not json`,
+			wantErr: "failed to unmarshal release event",
+		},
+		"missing action field": {
+			body:    `{"release":{"id":1}}`,
+			keys:    []string{"release"},
+			wantErr: "missing or invalid action field in release event",
+		},
+		"missing object field": {
+			body:    `{"action":"published","release":{"id":1}}`,
+			keys:    []string{"release", "repository"},
+			wantErr: "missing or invalid repository field in release event",
+		},
+		"object field wrong type": {
+			body:    `{"action":"published","release":"not-an-object"}`,
+			keys:    []string{"release"},
+			wantErr: "missing or invalid release field in release event",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			action, objects, err := parseEvent([]byte(tc.body), "release", tc.keys...)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantAction, action)
+			assert.Equal(t, tc.wantObjects, objects)
+		})
+	}
+}
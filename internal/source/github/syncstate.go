@@ -0,0 +1,255 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrSyncState wraps errors reading or writing the persisted sync state.
+	ErrSyncState = errors.New("sync state")
+)
+
+// conditionalRequestsKey marks the contexts of the syncs, whose requests are the only ones
+// sent as conditional requests and cached: the requests of the webhooks target single items
+// that are rarely read twice, so caching them would only grow the state.
+type conditionalRequestsKey struct{}
+
+// withConditionalRequests returns a copy of ctx whose requests use the cached responses.
+func withConditionalRequests(ctx context.Context) context.Context {
+	return context.WithValue(ctx, conditionalRequestsKey{}, true)
+}
+
+// conditionalRequests reports whether the requests sent with ctx use the cached responses.
+func conditionalRequests(ctx context.Context) bool {
+	enabled, _ := ctx.Value(conditionalRequestsKey{}).(bool)
+	return enabled
+}
+
+// cachedResponse is a response body stored together with its ETag, replayed when the
+// conditional request for the same URL is answered with 304 Not Modified.
+type cachedResponse struct {
+	ETag string          `json:"etag"`
+	Link string          `json:"link,omitempty"`
+	Body json.RawMessage `json:"body"`
+}
+
+// syncState holds the state that makes a sync incremental: the cached responses of the
+// conditional requests and the per-repository cursors of the workflow runs. It is loaded
+// from path, when set, and written back there at the end of every sync.
+type syncState struct {
+	path string
+
+	lock sync.Mutex
+	// Responses are the cached responses keyed by API version and request URL.
+	Responses map[string]cachedResponse `json:"responses"`
+	// WorkflowRunCursors are the creation times from which the workflow runs of each
+	// repository, keyed by its full name, are requested.
+	WorkflowRunCursors map[string]time.Time `json:"workflowRunCursors"`
+	// used tracks the responses requested since the last save, the others are dropped.
+	used map[string]bool
+}
+
+// loadSyncState returns the sync state persisted at path, or an empty state when the file
+// does not exist yet. With an empty path the state only lives in memory.
+func loadSyncState(path string) (*syncState, error) {
+	state := &syncState{
+		path:               path,
+		Responses:          make(map[string]cachedResponse),
+		WorkflowRunCursors: make(map[string]time.Time),
+		used:               make(map[string]bool),
+	}
+	if path == "" {
+		return state, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSyncState, err)
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("%w: %q: %w", ErrSyncState, path, err)
+	}
+	if state.Responses == nil {
+		state.Responses = make(map[string]cachedResponse)
+	}
+	if state.WorkflowRunCursors == nil {
+		state.WorkflowRunCursors = make(map[string]time.Time)
+	}
+	return state, nil
+}
+
+// save drops the cached responses not requested since the previous save and atomically writes
+// the state to its path, if any.
+func (s *syncState) save() error {
+	if s == nil {
+		return nil
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	maps.DeleteFunc(s.Responses, func(key string, _ cachedResponse) bool { return !s.used[key] })
+	s.used = make(map[string]bool)
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSyncState, err)
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSyncState, err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("%w: %w", ErrSyncState, err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("%w: %w", ErrSyncState, err)
+	}
+	if err := os.Rename(tmpFile.Name(), s.path); err != nil {
+		return fmt.Errorf("%w: %w", ErrSyncState, err)
+	}
+	return nil
+}
+
+// etag returns the ETag of the cached response for key, to be sent in If-None-Match.
+func (s *syncState) etag(key string) string {
+	if s == nil {
+		return ""
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.Responses[key].ETag
+}
+
+// handleResponse replaces a 304 Not Modified response with the cached response for key, and
+// caches the successful responses carrying an ETag. Other responses are returned unchanged.
+func (s *syncState) handleResponse(key string, resp *http.Response) (*http.Response, error) {
+	if s == nil {
+		return resp, nil
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified:
+		s.lock.Lock()
+		cached, ok := s.Responses[key]
+		if ok {
+			s.used[key] = true
+		}
+		s.lock.Unlock()
+		if !ok {
+			return resp, nil
+		}
+
+		resp.Body.Close()
+		header := resp.Header.Clone()
+		header.Set("Link", cached.Link)
+		return &http.Response{
+			Status:     http.StatusText(http.StatusOK),
+			StatusCode: http.StatusOK,
+			Header:     header,
+			Body:       io.NopCloser(bytes.NewReader(cached.Body)),
+			Request:    resp.Request,
+		}, nil
+	case resp.StatusCode == http.StatusOK && resp.Header.Get("ETag") != "":
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+
+		s.lock.Lock()
+		s.Responses[key] = cachedResponse{ETag: resp.Header.Get("ETag"), Link: resp.Header.Get("Link"), Body: body}
+		s.used[key] = true
+		s.lock.Unlock()
+
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		return resp, nil
+	}
+	return resp, nil
+}
+
+// workflowRunsSince returns the creation time from which the workflow runs of the repository
+// fullName are requested, or the zero time to request all of them.
+func (s *syncState) workflowRunsSince(fullName string) time.Time {
+	if s == nil {
+		return time.Time{}
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.WorkflowRunCursors[fullName]
+}
+
+// setWorkflowRunCursor stores the creation time from which the next sync requests the
+// workflow runs of the repository fullName.
+func (s *syncState) setWorkflowRunCursor(fullName string, cursor time.Time) {
+	if s == nil || cursor.IsZero() {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.WorkflowRunCursors[fullName] = cursor
+}
+
+// workflowRunCursor computes the cursor of the next sync while the workflow runs of a
+// repository are received: the creation time of the oldest run not yet completed, whose
+// changes must be fetched again, or of the newest run when all of them are completed.
+type workflowRunCursor struct {
+	newest        time.Time
+	oldestPending time.Time
+}
+
+// observe records the creation time and the status of run.
+func (c *workflowRunCursor) observe(run map[string]any) {
+	createdAt, _ := run["created_at"].(string)
+	created, err := time.Parse(time.RFC3339, createdAt)
+	if err != nil {
+		return
+	}
+
+	if created.After(c.newest) {
+		c.newest = created
+	}
+	if status, _ := run["status"].(string); status != "completed" && (c.oldestPending.IsZero() || created.Before(c.oldestPending)) {
+		c.oldestPending = created
+	}
+}
+
+// next returns the cursor for the next sync, or previous when no run has been observed.
+func (c *workflowRunCursor) next(previous time.Time) time.Time {
+	switch {
+	case !c.oldestPending.IsZero():
+		return c.oldestPending
+	case !c.newest.IsZero():
+		return c.newest
+	}
+	return previous
+}
@@ -0,0 +1,204 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+func TestLoadSyncState(t *testing.T) {
+	t.Parallel()
+
+	cursor := time.Date(2026, time.October, 1, 10, 0, 0, 0, time.UTC)
+	testCases := map[string]struct {
+		content        string
+		expectedCursor time.Time
+		expectErr      error
+	}{
+		"missing file": {},
+		"existing state": {
+			content:        `{"responses":{},"workflowRunCursors":{"test-org/repo1":"2026-10-01T10:00:00Z"}}`,
+			expectedCursor: cursor,
+		},
+		"invalid state": {
+			content:   `{`,
+			expectErr: ErrSyncState,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "state.json")
+			if tc.content != "" {
+				require.NoError(t, os.WriteFile(path, []byte(tc.content), 0o600))
+			}
+
+			state, err := loadSyncState(path)
+			if tc.expectErr != nil {
+				require.ErrorIs(t, err, tc.expectErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, tc.expectedCursor.Equal(state.workflowRunsSince("test-org/repo1")))
+		})
+	}
+}
+
+func TestClientConditionalRequests(t *testing.T) {
+	t.Parallel()
+
+	var notModified atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"v1"`)
+		json.NewEncoder(w).Encode([]map[string]any{{"id": 1, "full_name": "test-org/repo1"}})
+	}))
+	t.Cleanup(server.Close)
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	state, err := loadSyncState(path)
+	require.NoError(t, err)
+	c := &client{baseURL: server.URL, org: "test-org", pageSize: 100, httpClient: server.Client(), state: state}
+	ctx := withConditionalRequests(t.Context())
+
+	for range 2 {
+		items, err := c.listRepositories(defaultAPIVersion).next(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []map[string]any{{"id": float64(1), "full_name": "test-org/repo1"}}, items)
+	}
+	assert.Equal(t, int32(1), notModified.Load())
+	require.NoError(t, state.save())
+
+	// a new process reuses the persisted responses
+	state, err = loadSyncState(path)
+	require.NoError(t, err)
+	c.state = state
+
+	items, err := c.listRepositories(defaultAPIVersion).next(ctx)
+	require.NoError(t, err)
+	assert.Len(t, items, 1)
+	assert.Equal(t, int32(2), notModified.Load())
+
+	// the requests outside of a sync, like the ones of the webhooks, are neither conditional nor cached
+	state, err = loadSyncState("")
+	require.NoError(t, err)
+	c.state = state
+
+	for range 2 {
+		items, err = c.listRepositories(defaultAPIVersion).next(t.Context())
+		require.NoError(t, err)
+		assert.Len(t, items, 1)
+	}
+	assert.Equal(t, int32(2), notModified.Load())
+	assert.Empty(t, state.Responses)
+}
+
+func TestSyncStateSaveDropsUnusedResponses(t *testing.T) {
+	t.Parallel()
+
+	state, err := loadSyncState("")
+	require.NoError(t, err)
+	state.Responses["used"] = cachedResponse{ETag: `"a"`, Body: json.RawMessage(`[]`)}
+	state.Responses["stale"] = cachedResponse{ETag: `"b"`, Body: json.RawMessage(`[]`)}
+	state.used["used"] = true
+
+	require.NoError(t, state.save())
+	assert.Equal(t, `"a"`, state.etag("used"))
+	assert.Empty(t, state.etag("stale"))
+}
+
+func TestWorkflowRunCursor(t *testing.T) {
+	t.Parallel()
+
+	previous := time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC)
+	testCases := map[string]struct {
+		runs     []map[string]any
+		expected time.Time
+	}{
+		"no runs keeps the previous cursor": {
+			expected: previous,
+		},
+		"completed runs move to the newest run": {
+			runs: []map[string]any{
+				{"created_at": "2026-10-02T10:00:00Z", "status": "completed"},
+				{"created_at": "2026-10-01T10:00:00Z", "status": "completed"},
+			},
+			expected: time.Date(2026, time.October, 2, 10, 0, 0, 0, time.UTC),
+		},
+		"pending runs hold the cursor at the oldest one": {
+			runs: []map[string]any{
+				{"created_at": "2026-10-03T10:00:00Z", "status": "in_progress"},
+				{"created_at": "2026-10-02T10:00:00Z", "status": "queued"},
+				{"created_at": "2026-10-01T10:00:00Z", "status": "completed"},
+			},
+			expected: time.Date(2026, time.October, 2, 10, 0, 0, 0, time.UTC),
+		},
+		"runs without creation time are ignored": {
+			runs:     []map[string]any{{"status": "queued"}},
+			expected: previous,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var cursor workflowRunCursor
+			for _, run := range tc.runs {
+				cursor.observe(run)
+			}
+			assert.True(t, tc.expected.Equal(cursor.next(previous)))
+		})
+	}
+}
+
+func TestSyncRepositoryWorkflowRunsIncremental(t *testing.T) {
+	t.Parallel()
+
+	var createdFilters []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		createdFilters = append(createdFilters, r.URL.Query().Get("created"))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"total_count":   1,
+			"workflow_runs": []map[string]any{{"id": 1, "created_at": "2026-10-01T10:00:00Z", "status": "completed"}},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	state, err := loadSyncState("")
+	require.NoError(t, err)
+	s := newOrganizationsSource(server.URL, server.Client(), config{Orgs: []string{"test-org"}})
+	s.client.state = state
+
+	repo := map[string]any{"name": "repo1", "owner": map[string]any{"login": "test-org"}}
+	for range 2 {
+		results := make(chan source.Data, 10)
+		require.NoError(t, s.syncRepositoryWorkflowRuns(t.Context(), s.client, repo, defaultAPIVersion, results))
+		assert.Len(t, results, 1)
+	}
+
+	assert.Equal(t, []string{"", ">=2026-10-01T10:00:00Z"}, createdFilters)
+}
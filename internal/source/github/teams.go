@@ -0,0 +1,83 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package github
+
+import (
+	"context"
+
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+// syncTeamAssets iterates all teams of the organization of c once and, for each
+// team, emits a team entry, the permissions of the team on its repositories and the
+// memberships of its users depending on which types are present in typesToSync.
+func (s *Source) syncTeamAssets(ctx context.Context, c *client, typesToSync map[string]source.Extra, results chan<- source.Data) error {
+	if !requestsAny(typesToSync, teamTypes) {
+		return nil
+	}
+
+	var listAPIVersion string
+	for _, dataType := range teamTypes {
+		if extra, ok := typesToSync[dataType]; ok {
+			listAPIVersion = apiVersionFromExtra(extra)
+			break
+		}
+	}
+
+	return forEachItem(ctx, c.listTeams(listAPIVersion), func(team map[string]any) error {
+		if _, ok := typesToSync[teamType]; ok {
+			results <- upsertData(teamType, map[string]any{teamType: team})
+		}
+
+		slug, _ := team["slug"].(string)
+		if slug == "" {
+			return nil
+		}
+
+		if extra, ok := typesToSync[teamRepositoryType]; ok {
+			it := c.listTeamRepositories(slug, apiVersionFromExtra(extra))
+			if err := forEachItem(ctx, it, func(repo map[string]any) error {
+				results <- upsertData(teamRepositoryType, teamRepositoryValues(team, repo))
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+
+		if extra, ok := typesToSync[memberType]; ok {
+			it := c.listTeamMembers(slug, apiVersionFromExtra(extra))
+			return forEachItem(ctx, it, func(member map[string]any) error {
+				results <- upsertData(memberType, map[string]any{memberType: member, teamType: team})
+				return nil
+			})
+		}
+
+		return nil
+	})
+}
+
+// repositoryPermissions lists the permissions a team can have on a repository, from the
+// highest to the lowest.
+var repositoryPermissions = []string{"admin", "maintain", "push", "triage", "pull"}
+
+// teamRepositoryValues returns the values of a team_repository entry, linking team to
+// repo with the role the team has on it. The role is read from role_name, or derived
+// from the highest granted permission when missing, as in webhook payloads.
+func teamRepositoryValues(team, repo map[string]any) map[string]any {
+	permission, _ := repo["role_name"].(string)
+	if permissions, ok := repo["permissions"].(map[string]any); ok && permission == "" {
+		for _, candidate := range repositoryPermissions {
+			if granted, _ := permissions[candidate].(bool); granted {
+				permission = candidate
+				break
+			}
+		}
+	}
+
+	return map[string]any{
+		teamType:       team,
+		repositoryType: repo,
+		"permission":   permission,
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+func TestStartSyncProcessWithTeamTypes(t *testing.T) {
+	fixedTime := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	originalTimeSource := timeSource
+	t.Cleanup(func() { timeSource = originalTimeSource })
+	timeSource = func() time.Time { return fixedTime }
+
+	team := map[string]any{"id": float64(1), "slug": "platform"}
+	repo := map[string]any{"id": float64(2), "name": "repo1", "role_name": "maintain"}
+	member := map[string]any{"id": float64(3), "login": "octocat"}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/orgs/test-org/teams":
+			json.NewEncoder(w).Encode([]map[string]any{team})
+		case "/orgs/test-org/teams/platform/repos":
+			json.NewEncoder(w).Encode([]map[string]any{repo})
+		case "/orgs/test-org/teams/platform/members":
+			json.NewEncoder(w).Encode([]map[string]any{member})
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	testCases := map[string]struct {
+		typesToSync  map[string]source.Extra
+		expectedData []source.Data
+	}{
+		"teams only": {
+			typesToSync: map[string]source.Extra{teamType: {}},
+			expectedData: []source.Data{
+				upsertData(teamType, map[string]any{teamType: team}),
+			},
+		},
+		"all team types": {
+			typesToSync: map[string]source.Extra{teamType: {}, teamRepositoryType: {}, memberType: {}},
+			expectedData: []source.Data{
+				upsertData(teamType, map[string]any{teamType: team}),
+				upsertData(teamRepositoryType, map[string]any{teamType: team, repositoryType: repo, "permission": "maintain"}),
+				upsertData(memberType, map[string]any{memberType: member, teamType: team}),
+			},
+		},
+		"members without teams": {
+			typesToSync: map[string]source.Extra{memberType: {}},
+			expectedData: []source.Data{
+				upsertData(memberType, map[string]any{memberType: member, teamType: team}),
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(handler)
+			t.Cleanup(server.Close)
+
+			s := newOrganizationsSource(server.URL, server.Client(), config{Orgs: []string{"test-org"}})
+
+			results := make(chan source.Data, 100)
+			err := s.StartSyncProcess(t.Context(), tc.typesToSync, results)
+			close(results)
+			require.NoError(t, err)
+
+			var got []source.Data
+			for d := range results {
+				got = append(got, d)
+			}
+			assert.Equal(t, tc.expectedData, got)
+		})
+	}
+}
@@ -30,7 +30,6 @@ func (s *Source) GetWebhook(ctx context.Context, typesToStream map[string]source
 		Method: http.MethodPost,
 		Path:   s.config.WebhookPath,
 		Handler: func(ctx context.Context, headers http.Header, body []byte) error {
-			processors := newEventProcessors(s.client)
 			log := logger.FromContext(ctx).WithName(loggerName)
 
 			signature := headers.Get("X-Hub-Signature-256")
@@ -48,15 +47,31 @@ func (s *Source) GetWebhook(ctx context.Context, typesToStream map[string]source
 
 			go func(ctx context.Context) {
 				eventType := headers.Get(githubEventHeader)
-				processor, ok := processors[eventType]
+				jsonBody := extractJSONBody(headers, body)
+				if jsonBody == nil {
+					log.Debug("could not extract JSON body from webhook")
+					return
+				}
+
+				org := eventOrganization(jsonBody)
+				client, ok, err := s.webhookClient(org)
+				if previous := transferredFrom(eventType, jsonBody); err == nil && !ok && previous != "" {
+					// a repository transferred out of a managed organization is handled with the
+					// client of that organization, to delete it
+					client, ok, err = s.webhookClient(previous)
+				}
+				if err != nil {
+					log.Error("error creating client for webhook event", "event", eventType, "org", org, "error", err.Error())
+					return
+				}
 				if !ok {
-					log.Debug("ignoring unsupported event", githubEventHeader, eventType)
+					log.Debug("ignoring event from unconfigured organization", githubEventHeader, eventType, "org", org)
 					return
 				}
 
-				jsonBody := extractJSONBody(headers, body)
-				if jsonBody == nil {
-					log.Debug("could not extract JSON body from webhook")
+				processor, ok := newEventProcessors(client)[eventType]
+				if !ok {
+					log.Debug("ignoring unsupported event", githubEventHeader, eventType)
 					return
 				}
 
@@ -76,6 +91,56 @@ func (s *Source) GetWebhook(ctx context.Context, typesToStream map[string]source
 	}, nil
 }
 
+// eventOrganization returns the login of the organization a webhook event belongs to, read from
+// the organization field or, when missing, from the owner of the repository or the account of the
+// GitHub App installation. It returns an empty string for events that are not bound to any
+// organization.
+func eventOrganization(body []byte) string {
+	var event struct {
+		Organization struct {
+			Login string `json:"login"`
+		} `json:"organization"`
+		Repository struct {
+			Owner struct {
+				Login string `json:"login"`
+			} `json:"owner"`
+		} `json:"repository"`
+		Installation struct {
+			Account struct {
+				Login string `json:"login"`
+			} `json:"account"`
+		} `json:"installation"`
+	}
+	if err := json.Unmarshal(body, &event); err != nil {
+		return ""
+	}
+
+	switch {
+	case event.Organization.Login != "":
+		return event.Organization.Login
+	case event.Repository.Owner.Login != "":
+		return event.Repository.Owner.Login
+	default:
+		return event.Installation.Account.Login
+	}
+}
+
+// transferredFrom returns the login of the previous owner of the repository of a repository
+// transferred event, or an empty string for any other event.
+func transferredFrom(eventType string, body []byte) string {
+	if eventType != repositoryEventHeaderValue {
+		return ""
+	}
+
+	action, _, err := parseRepositoryEvent(body)
+	if err != nil || action != "transferred" {
+		return ""
+	}
+
+	login, _ := previousOwner(body)["login"].(string)
+	return login
+}
+
 // verifySignature checks the HMAC-SHA256 signature of the body.
 // The signature header value is expected in the format "sha256=<hex>".
 func verifySignature(body []byte, signatureHeader, secret string) bool {
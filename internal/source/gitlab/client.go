@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/mia-platform/ibdm/internal/info"
+	"github.com/mia-platform/ibdm/internal/ratelimit"
 )
 
 var (
@@ -33,9 +34,12 @@ type gitLabClient struct {
 	http   *http.Client
 }
 
-// newHTTPClient returns a default HTTP client with the configured timeout.
+// newHTTPClient returns a default HTTP client keeping the requests within the rate limits of
+// the GitLab API, with the configured timeout applied to every attempt of a request.
 func newHTTPClient() *http.Client {
-	return &http.Client{Timeout: defaultTimeout}
+	return &http.Client{
+		Transport: ratelimit.NewTransport("gitlab", ratelimit.GitLab, nil, ratelimit.WithAttemptTimeout(defaultTimeout)),
+	}
 }
 
 // userAgent returns the User-Agent header value used for all GitLab API requests.
@@ -83,10 +87,14 @@ func (c *gitLabClient) makeRequestList(ctx context.Context, path, query string)
 	return items, nil
 }
 
-// makePageableRequest issues a single paginated GET request to the GitLab API and returns
-// the decoded items together with the total number of pages from the response headers.
-func (c *gitLabClient) makePageableRequest(ctx context.Context, path string, page int) ([]map[string]any, int, error) {
+// makePageableRequest issues a single paginated GET request to the GitLab API, with the
+// optional query parameters, and returns the decoded items together with the total number
+// of pages from the response headers.
+func (c *gitLabClient) makePageableRequest(ctx context.Context, path string, query url.Values, page int) ([]map[string]any, int, error) {
 	q := url.Values{}
+	for key, values := range query {
+		q[key] = values
+	}
 	q.Set("per_page", "100")
 	q.Set("page", strconv.Itoa(page))
 
@@ -169,6 +177,31 @@ func (c *gitLabClient) getProject(ctx context.Context, projectID int) (map[strin
 	return project, nil
 }
 
+// getGroup fetches the group identified by groupID, without the list of its projects.
+func (c *gitLabClient) getGroup(ctx context.Context, groupID string) (map[string]any, error) {
+	return c.makeRequest(ctx, "/api/v4/groups/"+groupID, "with_projects=false")
+}
+
+// getProjectMember fetches the direct member identified by userID of the given project.
+func (c *gitLabClient) getProjectMember(ctx context.Context, projectID, userID string) (map[string]any, error) {
+	return c.makeRequest(ctx, "/api/v4/projects/"+projectID+"/members/"+userID, "")
+}
+
+// getGroupMember fetches the direct member identified by userID of the given group.
+func (c *gitLabClient) getGroupMember(ctx context.Context, groupID, userID string) (map[string]any, error) {
+	return c.makeRequest(ctx, "/api/v4/groups/"+groupID+"/members/"+userID, "")
+}
+
+// getProjectAccessToken fetches the access token identified by tokenID of the given project.
+func (c *gitLabClient) getProjectAccessToken(ctx context.Context, projectID, tokenID string) (map[string]any, error) {
+	return c.makeRequest(ctx, "/api/v4/projects/"+projectID+"/access_tokens/"+tokenID, "")
+}
+
+// getGroupAccessToken fetches the access token identified by tokenID of the given group.
+func (c *gitLabClient) getGroupAccessToken(ctx context.Context, groupID, tokenID string) (map[string]any, error) {
+	return c.makeRequest(ctx, "/api/v4/groups/"+groupID+"/access_tokens/"+tokenID, "")
+}
+
 func (c *gitLabClient) getPipeline(ctx context.Context, projectID string, pipelineID string) (map[string]any, error) {
 	pipeline, err := c.makeRequest(ctx, "/api/v4/projects/"+projectID+"/pipelines/"+pipelineID, "")
 	if err != nil {
@@ -193,6 +226,28 @@ func (c *gitLabClient) listGroups() iterator {
 	return c.newPageIterator("/api/v4/groups")
 }
 
+// listDescendantGroups returns an iterator that pages through all the subgroups of the
+// given group, at any depth.
+func (c *gitLabClient) listDescendantGroups(groupID string) iterator {
+	return c.newPageIterator("/api/v4/groups/" + groupID + "/descendant_groups")
+}
+
+// listGroupProjects returns an iterator that pages through all the projects of the given
+// group and of its subgroups.
+func (c *gitLabClient) listGroupProjects(groupID string) iterator {
+	return &pageIterator{c: c, path: "/api/v4/groups/" + groupID + "/projects", query: url.Values{"include_subgroups": {"true"}}}
+}
+
+// listGroupMembers returns an iterator that pages through the direct members of the given group.
+func (c *gitLabClient) listGroupMembers(groupID string) iterator {
+	return c.newPageIterator("/api/v4/groups/" + groupID + "/members")
+}
+
+// listProjectMembers returns an iterator that pages through the direct members of the given project.
+func (c *gitLabClient) listProjectMembers(projectID string) iterator {
+	return c.newPageIterator("/api/v4/projects/" + projectID + "/members")
+}
+
 // listProjectPipelines returns an iterator that pages through pipelines for the given project.
 func (c *gitLabClient) listProjectPipelines(projectID string) iterator {
 	return c.newPageIterator("/api/v4/projects/" + projectID + "/pipelines")
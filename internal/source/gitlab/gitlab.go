@@ -17,8 +17,9 @@ import (
 )
 
 // Source implements [source.WebhookSource] and [source.SyncableSource] for GitLab.
-// It can both poll resources via the GitLab REST API and receive real-time pipeline
-// events through a token-authenticated webhook.
+// It can both poll resources via the GitLab REST API and receive real-time events
+// from project webhooks, group webhooks and system hooks through a token-authenticated
+// webhook.
 type Source struct {
 	c             *gitLabClient
 	webhookConfig webhookConfig
@@ -31,6 +32,8 @@ const (
 	projectResource     = "project"
 	pipelineResource    = "pipeline"
 	accessTokenResource = "accesstoken"
+	groupResource       = "group"
+	memberResource      = "member"
 )
 
 var (
@@ -72,7 +75,8 @@ func NewSource() (*Source, error) {
 
 // StartSyncProcess performs a full synchronisation of the requested resource types
 // by listing assets from the GitLab API and sending them to results. Supported
-// types are "project" and "pipeline". Concurrent calls are a no-op.
+// types are "project", "pipeline", "accesstoken", "group" and "member". Concurrent
+// calls are a no-op.
 func (s *Source) StartSyncProcess(ctx context.Context, typesToSync map[string]source.Extra, results chan<- source.Data) error {
 	log := logger.FromContext(ctx).WithName(loggerName)
 
@@ -88,8 +92,11 @@ func (s *Source) StartSyncProcess(ctx context.Context, typesToSync map[string]so
 		}
 	}
 
-	if _, ok := typesToSync[accessTokenResource]; ok {
-		if err := s.syncAccessTokenResources(ctx, results); err != nil {
+	_, syncGroups := typesToSync[groupResource]
+	_, syncAccessTokens := typesToSync[accessTokenResource]
+	_, syncMembers := typesToSync[memberResource]
+	if syncGroups || syncAccessTokens || syncMembers {
+		if err := s.syncGroupAssets(ctx, typesToSync, results); err != nil {
 			return err
 		}
 	}
@@ -148,6 +155,13 @@ func (s *Source) syncProjectAssets(ctx context.Context, typesToSync map[string]s
 					return fmt.Errorf("%w: %w", ErrRetrievingAssets, err)
 				}
 			}
+
+			if _, ok := typesToSync[memberResource]; ok {
+				if err := s.syncMembers(ctx, s.c.listProjectMembers(projectID), projectResource, project, results); err != nil {
+					log.Error("error syncing project members", "project_id", projectID, "error", err.Error())
+					return fmt.Errorf("%w: %w", ErrRetrievingAssets, err)
+				}
+			}
 		}
 	}
 
@@ -225,9 +239,10 @@ func (s *Source) syncProjectPipelines(ctx context.Context, project map[string]an
 	return nil
 }
 
-// syncAccessTokenResources iterates all GitLab groups and, for each group, iterates all access tokens
-// page by page, sending upsert events to results.
-func (s *Source) syncAccessTokenResources(ctx context.Context, results chan<- source.Data) error {
+// syncGroupAssets iterates all GitLab groups page by page and, depending on which types are
+// present in typesToSync, sends upsert events to results for the groups, their access tokens
+// and their members.
+func (s *Source) syncGroupAssets(ctx context.Context, typesToSync map[string]source.Extra, results chan<- source.Data) error {
 	log := logger.FromContext(ctx).WithName(loggerName)
 
 	groupIt := s.c.listGroups()
@@ -246,42 +261,106 @@ func (s *Source) syncAccessTokenResources(ctx context.Context, results chan<- so
 				continue
 			}
 
-			tokenIt := s.c.listGroupAccessTokens(groupID)
-			for {
-				tokens, err := tokenIt.next(ctx)
-				if errors.Is(err, ErrIteratorDone) {
-					break
+			if _, ok := typesToSync[groupResource]; ok {
+				results <- source.Data{
+					Type:      groupResource,
+					Operation: source.DataOperationUpsert,
+					Values:    map[string]any{groupResource: group},
+					Time:      time.Now(),
 				}
+			}
 
-				if err != nil && errors.Is(err, ErrNotAccessible) {
-					log.Error("skipping group access tokens: insufficient permissions", "group_id", groupID)
-					continue
+			if _, ok := typesToSync[accessTokenResource]; ok {
+				if err := s.syncGroupAccessTokens(ctx, group, results); err != nil {
+					return err
 				}
+			}
 
-				if err != nil {
+			if _, ok := typesToSync[memberResource]; ok {
+				if err := s.syncMembers(ctx, s.c.listGroupMembers(groupID), groupResource, group, results); err != nil {
+					log.Error("error syncing group members", "group_id", groupID, "error", err.Error())
 					return fmt.Errorf("%w: %w", ErrRetrievingAssets, err)
 				}
+			}
+		}
+	}
 
-				for _, token := range tokens {
-					results <- source.Data{
-						Type:      accessTokenResource,
-						Operation: source.DataOperationUpsert,
-						Values: map[string]any{
-							"group": group,
-							"token": token,
-						},
-						Time: accessTokenTimeOrNow(token),
-					}
-				}
+	return nil
+}
+
+// syncGroupAccessTokens iterates all access tokens for a given group and sends upsert events to results.
+func (s *Source) syncGroupAccessTokens(ctx context.Context, group map[string]any, results chan<- source.Data) error {
+	log := logger.FromContext(ctx).WithName(loggerName)
+
+	groupID, _ := getIDFromItem(group)
+	tokenIt := s.c.listGroupAccessTokens(groupID)
+	for {
+		tokens, err := tokenIt.next(ctx)
+		if errors.Is(err, ErrIteratorDone) {
+			break
+		}
+
+		if err != nil && errors.Is(err, ErrNotAccessible) {
+			log.Error("skipping group access tokens: insufficient permissions", "group_id", groupID)
+			continue
+		}
+
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrRetrievingAssets, err)
+		}
+
+		for _, token := range tokens {
+			results <- source.Data{
+				Type:      accessTokenResource,
+				Operation: source.DataOperationUpsert,
+				Values: map[string]any{
+					"group": group,
+					"token": token,
+				},
+				Time: accessTokenTimeOrNow(token),
 			}
 		}
 	}
+	return nil
+}
+
+// syncMembers iterates all members returned by memberIt and sends upsert events to results,
+// carrying the group or project they are members of under parentKey.
+func (s *Source) syncMembers(ctx context.Context, memberIt iterator, parentKey string, parent map[string]any, results chan<- source.Data) error {
+	log := logger.FromContext(ctx).WithName(loggerName)
+
+	for {
+		members, err := memberIt.next(ctx)
+		if errors.Is(err, ErrIteratorDone) {
+			break
+		}
 
+		if err != nil && errors.Is(err, ErrNotAccessible) {
+			log.Error("skipping members: insufficient permissions", parentKey+"_id", parent["id"])
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		for _, member := range members {
+			results <- source.Data{
+				Type:      memberResource,
+				Operation: source.DataOperationUpsert,
+				Values: map[string]any{
+					memberResource: member,
+					parentKey:      parent,
+				},
+				Time: time.Now(),
+			}
+		}
+	}
 	return nil
 }
 
-// GetWebhook returns a [source.Webhook] that validates incoming GitLab pipeline
-// webhook requests using a plain-text token comparison and dispatches matching
+// GetWebhook returns a [source.Webhook] that validates incoming GitLab webhook
+// requests using a plain-text token comparison and dispatches matching
 // events to results asynchronously. It returns [ErrWebhookTokenMissing] when no
 // token is configured.
 func (s *Source) GetWebhook(ctx context.Context, typesToStream map[string]source.Extra, results chan<- source.Data) (source.Webhook, error) {
@@ -87,6 +87,18 @@ func TestWebhookHandler(t *testing.T) {
 				assert.NotNil(t, d.Values)
 			},
 		},
+		"system hook project destroy dispatched": {
+			token:         validToken,
+			body:          []byte(`{"event_name":"project_destroy","project_id":5,"path_with_namespace":"group/test-project"}`),
+			headers:       validHeaders(validToken, systemHookHeaderValue),
+			typesToStream: map[string]source.Extra{projectResource: nil},
+			expectData:    true,
+			checkData: func(t *testing.T, d source.Data) {
+				t.Helper()
+				assert.Equal(t, projectResource, d.Type)
+				assert.Equal(t, source.DataOperationDelete, d.Operation)
+			},
+		},
 		"invalid token": {
 			token:         validToken,
 			body:          validBody(),
@@ -92,6 +92,36 @@ func TestStartSyncProcess(t *testing.T) {
 				}
 			},
 		},
+		"sync groups and members": {
+			handler: paginatedHandler(t, map[string]any{
+				"/api/v4/projects":                singleProject,
+				"/api/v4/projects/1/languages":    map[string]any{"Go": 100.0},
+				"/api/v4/projects/1/members":      []map[string]any{{"id": float64(41), "username": "johnsmith"}},
+				"/api/v4/groups":                  []map[string]any{{"id": float64(10), "name": "my-group"}},
+				"/api/v4/groups/10/members":       []map[string]any{{"id": float64(41), "username": "johnsmith"}, {"id": float64(42), "username": "janedoe"}},
+				"/api/v4/groups/10/access_tokens": []map[string]any{},
+			}),
+			typesToSync: map[string]source.Extra{
+				projectResource: nil,
+				groupResource:   nil,
+				memberResource:  nil,
+			},
+			expectedDataCount: 5, // 1 project + 1 project member + 1 group + 2 group members
+			checkData: func(t *testing.T, data []source.Data) {
+				t.Helper()
+				types := make(map[string]int)
+				for _, d := range data {
+					types[d.Type]++
+					if d.Type == memberResource {
+						assert.Contains(t, d.Values, memberResource)
+						_, hasProject := d.Values[projectResource]
+						_, hasGroup := d.Values[groupResource]
+						assert.True(t, hasProject != hasGroup)
+					}
+				}
+				assert.Equal(t, map[string]int{projectResource: 1, groupResource: 1, memberResource: 3}, types)
+			},
+		},
 		"sync projects emits project access tokens": {
 			handler: paginatedHandler(t, map[string]any{
 				"/api/v4/projects":                 singleProject,
@@ -6,6 +6,7 @@ package gitlab
 import (
 	"context"
 	"errors"
+	"net/url"
 )
 
 var (
@@ -20,11 +21,12 @@ type iterator interface {
 
 var _ iterator = &pageIterator{}
 
-// pageIterator pages through a GitLab list endpoint. The path is fixed at
-// construction time by the client layer.
+// pageIterator pages through a GitLab list endpoint. The path and the optional
+// query are fixed at construction time by the client layer.
 type pageIterator struct {
 	c          *gitLabClient
 	path       string
+	query      url.Values
 	currPage   int
 	totalPages int
 	done       bool
@@ -39,7 +41,7 @@ func (it *pageIterator) next(ctx context.Context) ([]map[string]any, error) {
 
 	it.currPage++
 
-	items, totalPages, err := it.c.makePageableRequest(ctx, it.path, it.currPage)
+	items, totalPages, err := it.c.makePageableRequest(ctx, it.path, it.query, it.currPage)
 	if err != nil {
 		if it.currPage >= it.totalPages {
 			it.done = true
@@ -19,6 +19,11 @@ type eventProcessor interface {
 // Register new event types by adding an entry here and creating the
 // corresponding processor_*.go file.
 var eventProcessors = map[string]eventProcessor{
-	pipelineHookHeaderValue: &pipelineEventProcessor{},
-	pushHookHeaderValue:     &pushEventProcessor{},
+	pipelineHookHeaderValue:    &pipelineEventProcessor{},
+	pushHookHeaderValue:        &pushEventProcessor{},
+	systemHookHeaderValue:      &systemHookProcessor{},
+	projectHookHeaderValue:     &systemHookProcessor{},
+	subgroupHookHeaderValue:    &systemHookProcessor{},
+	memberHookHeaderValue:      &systemHookProcessor{},
+	accessTokenHookHeaderValue: &accessTokenEventProcessor{},
 }
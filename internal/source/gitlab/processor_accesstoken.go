@@ -0,0 +1,111 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+const (
+	// accessTokenEventKind is the object_kind value for access token webhook events.
+	accessTokenEventKind = "access_token"
+
+	// accessTokenHookHeaderValue is the expected value of X-Gitlab-Event for access token events.
+	accessTokenHookHeaderValue = "Resource Access Token Hook"
+)
+
+// accessTokenEvent represents a GitLab access token webhook payload, sent for the
+// project and group access tokens about to expire.
+type accessTokenEvent struct {
+	ObjectKind       string         `json:"object_kind"`       //nolint:tagliatelle // GitLab API uses snake_case
+	ObjectAttributes map[string]any `json:"object_attributes"` //nolint:tagliatelle // GitLab API uses snake_case
+	Project          map[string]any `json:"project"`
+	Group            map[string]any `json:"group"`
+}
+
+// accessTokenEventProcessor handles "Resource Access Token Hook" webhook events.
+type accessTokenEventProcessor struct{}
+
+func (p *accessTokenEventProcessor) process(ctx context.Context, c *gitLabClient, typesToStream map[string]source.Extra, body []byte) ([]source.Data, error) {
+	var ev accessTokenEvent
+	if err := json.Unmarshal(body, &ev); err != nil {
+		return nil, err
+	}
+	if ev.ObjectKind != accessTokenEventKind {
+		return nil, nil
+	}
+
+	if _, ok := typesToStream[accessTokenResource]; !ok {
+		return nil, nil
+	}
+
+	tokenID, err := eventID(ev.ObjectAttributes, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	var values map[string]any
+	switch {
+	case ev.Project != nil:
+		values, err = projectAccessTokenValues(ctx, c, ev.Project, tokenID)
+	case ev.Group != nil:
+		values, err = groupAccessTokenValues(ctx, c, ev.Group, tokenID)
+	default:
+		return nil, errors.New("event payload missing project or group field")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return []source.Data{upsertData(accessTokenResource, values, accessTokenTimeOrNow(ev.ObjectAttributes))}, nil
+}
+
+// projectAccessTokenValues fetches the project of an access token event and its token
+// identified by tokenID, returning them with the same values of the synced tokens.
+func projectAccessTokenValues(ctx context.Context, c *gitLabClient, eventProject map[string]any, tokenID string) (map[string]any, error) {
+	projectID, err := eventID(eventProject, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := strconv.Atoi(projectID)
+	if err != nil {
+		return nil, err
+	}
+	project, err := c.getProject(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := c.getProjectAccessToken(ctx, projectID, tokenID)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{projectResource: project, "token": token}, nil
+}
+
+// groupAccessTokenValues fetches the group of an access token event and its token
+// identified by tokenID, returning them with the same values of the synced tokens.
+func groupAccessTokenValues(ctx context.Context, c *gitLabClient, eventGroup map[string]any, tokenID string) (map[string]any, error) {
+	groupID, err := eventID(eventGroup, "group_id")
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := c.getGroup(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := c.getGroupAccessToken(ctx, groupID, tokenID)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{groupResource: group, "token": token}, nil
+}
@@ -0,0 +1,114 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package gitlab
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+func TestAccessTokenEventProcessor(t *testing.T) {
+	t.Parallel()
+
+	project := map[string]any{"id": float64(7), "name": "my-project"}
+	group := map[string]any{"id": float64(35), "full_path": "my-group"}
+	token := map[string]any{"id": float64(25), "name": "acd", "created_at": "2024-01-24T16:27:40Z", "revoked": false}
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v4/projects/7":
+			jsonResponse(t, w, project)
+		case "/api/v4/projects/7/access_tokens/25", "/api/v4/groups/35/access_tokens/25":
+			jsonResponse(t, w, token)
+		case "/api/v4/groups/35":
+			jsonResponse(t, w, group)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+
+	attributes := map[string]any{"id": 25, "name": "acd", "created_at": "2024-01-24T16:27:40Z"}
+	testCases := map[string]struct {
+		typesToStream map[string]source.Extra
+		body          map[string]any
+		expected      []source.Data
+		expectErr     bool
+	}{
+		"project access token": {
+			typesToStream: map[string]source.Extra{accessTokenResource: nil},
+			body: map[string]any{
+				"object_kind": "access_token", "event_name": "expiring_access_token",
+				"project": map[string]any{"id": 7}, "object_attributes": attributes,
+			},
+			expected: []source.Data{{
+				Type:      accessTokenResource,
+				Operation: source.DataOperationUpsert,
+				Values:    map[string]any{projectResource: project, "token": token},
+				Time:      time.Date(2024, time.January, 24, 16, 27, 40, 0, time.UTC),
+			}},
+		},
+		"group access token": {
+			typesToStream: map[string]source.Extra{accessTokenResource: nil},
+			body: map[string]any{
+				"object_kind": "access_token", "event_name": "expiring_access_token",
+				"group": map[string]any{"group_id": 35, "group_name": "My Group"}, "object_attributes": attributes,
+			},
+			expected: []source.Data{{
+				Type:      accessTokenResource,
+				Operation: source.DataOperationUpsert,
+				Values:    map[string]any{groupResource: group, "token": token},
+				Time:      time.Date(2024, time.January, 24, 16, 27, 40, 0, time.UTC),
+			}},
+		},
+		"type not streamed": {
+			typesToStream: map[string]source.Extra{projectResource: nil},
+			body:          map[string]any{"object_kind": "access_token", "project": map[string]any{"id": 7}, "object_attributes": attributes},
+		},
+		"other object kind": {
+			typesToStream: map[string]source.Extra{accessTokenResource: nil},
+			body:          map[string]any{"object_kind": "push"},
+		},
+		"missing token id": {
+			typesToStream: map[string]source.Extra{accessTokenResource: nil},
+			body:          map[string]any{"object_kind": "access_token", "project": map[string]any{"id": 7}, "object_attributes": map[string]any{}},
+			expectErr:     true,
+		},
+		"missing project and group": {
+			typesToStream: map[string]source.Extra{accessTokenResource: nil},
+			body:          map[string]any{"object_kind": "access_token", "object_attributes": attributes},
+			expectErr:     true,
+		},
+		"fetch failure": {
+			typesToStream: map[string]source.Extra{accessTokenResource: nil},
+			body:          map[string]any{"object_kind": "access_token", "project": map[string]any{"id": 8}, "object_attributes": attributes},
+			expectErr:     true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(handler))
+			t.Cleanup(srv.Close)
+
+			processor := &accessTokenEventProcessor{}
+			data, err := processor.process(t.Context(), newTestGitLabClient(t, srv), tc.typesToStream, mustMarshal(t, tc.body))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, data)
+		})
+	}
+}
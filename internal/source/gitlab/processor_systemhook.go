@@ -0,0 +1,382 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+const (
+	// systemHookHeaderValue is the expected value of X-Gitlab-Event for system hook events.
+	systemHookHeaderValue = "System Hook"
+	// projectHookHeaderValue is the expected value of X-Gitlab-Event for the project events of group webhooks.
+	projectHookHeaderValue = "Project Hook"
+	// subgroupHookHeaderValue is the expected value of X-Gitlab-Event for the subgroup events of group webhooks.
+	subgroupHookHeaderValue = "Subgroup Hook"
+	// memberHookHeaderValue is the expected value of X-Gitlab-Event for the member events of group webhooks.
+	memberHookHeaderValue = "Member Hook"
+)
+
+// projectEventOperations maps the project event names to data operations.
+var projectEventOperations = map[string]source.DataOperation{
+	"project_create":   source.DataOperationUpsert,
+	"project_update":   source.DataOperationUpsert,
+	"project_rename":   source.DataOperationUpsert,
+	"project_transfer": source.DataOperationUpsert,
+	"project_destroy":  source.DataOperationDelete,
+}
+
+// groupEventOperations maps the group and subgroup event names to data operations.
+var groupEventOperations = map[string]source.DataOperation{
+	"group_create":     source.DataOperationUpsert,
+	"group_rename":     source.DataOperationUpsert,
+	"subgroup_create":  source.DataOperationUpsert,
+	"group_destroy":    source.DataOperationDelete,
+	"subgroup_destroy": source.DataOperationDelete,
+}
+
+// projectMemberEventOperations maps the project member event names to data operations.
+var projectMemberEventOperations = map[string]source.DataOperation{
+	"user_add_to_team":      source.DataOperationUpsert,
+	"user_update_for_team":  source.DataOperationUpsert,
+	"user_remove_from_team": source.DataOperationDelete,
+}
+
+// groupMemberEventOperations maps the group member event names to data operations.
+var groupMemberEventOperations = map[string]source.DataOperation{
+	"user_add_to_group":      source.DataOperationUpsert,
+	"user_update_for_group":  source.DataOperationUpsert,
+	"user_remove_from_group": source.DataOperationDelete,
+}
+
+// systemHookProcessor handles the project, group and member lifecycle events sent by
+// system hooks and by group webhooks, identified by their event_name. Renames and
+// transfers change the paths of the items, so they produce a delete of the previous
+// identity before the upsert of the current one.
+type systemHookProcessor struct{}
+
+func (p *systemHookProcessor) process(ctx context.Context, c *gitLabClient, typesToStream map[string]source.Extra, body []byte) ([]source.Data, error) {
+	var event map[string]any
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, err
+	}
+
+	// system hooks deliver push events too, with the same payload of project webhooks
+	if objectKind, _ := event["object_kind"].(string); objectKind == pushEventKind {
+		return (&pushEventProcessor{}).process(ctx, c, typesToStream, body)
+	}
+
+	eventName, _ := event["event_name"].(string)
+	if operation, ok := projectEventOperations[eventName]; ok {
+		return projectLifecycleData(ctx, c, typesToStream, event, operation)
+	}
+	if operation, ok := groupEventOperations[eventName]; ok {
+		return groupLifecycleData(ctx, c, typesToStream, event, operation)
+	}
+	if operation, ok := projectMemberEventOperations[eventName]; ok {
+		return memberData(ctx, c, typesToStream, event, projectResource, operation)
+	}
+	if operation, ok := groupMemberEventOperations[eventName]; ok {
+		return memberData(ctx, c, typesToStream, event, groupResource, operation)
+	}
+
+	return nil, nil
+}
+
+// projectLifecycleData returns the data of a project event: a delete of the project for
+// project_destroy, or an upsert of the project fetched from the API preceded, for renames
+// and transfers, by a delete of its previous path.
+func projectLifecycleData(ctx context.Context, c *gitLabClient, typesToStream map[string]source.Extra, event map[string]any, operation source.DataOperation) ([]source.Data, error) {
+	if _, ok := typesToStream[projectResource]; !ok {
+		return nil, nil
+	}
+
+	projectID, err := eventID(event, "project_id")
+	if err != nil {
+		return nil, err
+	}
+
+	project := map[string]any{
+		"id":                  event["project_id"],
+		"name":                event["name"],
+		"path":                event["path"],
+		"path_with_namespace": event["path_with_namespace"],
+		"visibility":          event["project_visibility"],
+	}
+	if operation == source.DataOperationDelete {
+		return []source.Data{deleteData(projectResource, projectWrapper(project, nil))}, nil
+	}
+
+	data := make([]source.Data, 0, 2)
+	if oldPath, _ := event["old_path_with_namespace"].(string); oldPath != "" {
+		previous := maps.Clone(project)
+		previous["path"] = path.Base(oldPath)
+		previous["path_with_namespace"] = oldPath
+		data = append(data, deleteData(projectResource, projectWrapper(previous, nil)))
+	}
+
+	current, err := fetchProjectData(ctx, c, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return append(data, current), nil
+}
+
+// groupLifecycleData returns the data of a group event: a delete of the group for the
+// destroy events, or an upsert of the group fetched from the API. Renames are preceded by
+// a delete of the previous path and followed by the deletes and upserts of the subgroups
+// and projects whose paths changed with the group one.
+func groupLifecycleData(ctx context.Context, c *gitLabClient, typesToStream map[string]source.Extra, event map[string]any, operation source.DataOperation) ([]source.Data, error) {
+	_, streamGroups := typesToStream[groupResource]
+	_, streamProjects := typesToStream[projectResource]
+	oldFullPath, _ := event["old_full_path"].(string)
+	if !streamGroups && (!streamProjects || oldFullPath == "") {
+		return nil, nil
+	}
+
+	groupID, err := eventID(event, "group_id")
+	if err != nil {
+		return nil, err
+	}
+
+	group := map[string]any{
+		"id":        event["group_id"],
+		"name":      event["name"],
+		"path":      event["path"],
+		"full_path": event["full_path"],
+	}
+	if operation == source.DataOperationDelete {
+		if !streamGroups {
+			return nil, nil
+		}
+		return []source.Data{deleteData(groupResource, map[string]any{groupResource: group})}, nil
+	}
+
+	var data []source.Data
+	if streamGroups {
+		if oldFullPath != "" {
+			previous := maps.Clone(group)
+			previous["path"] = event["old_path"]
+			previous["full_path"] = oldFullPath
+			data = append(data, deleteData(groupResource, map[string]any{groupResource: previous}))
+		}
+
+		current, err := c.getGroup(ctx, groupID)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, upsertData(groupResource, map[string]any{groupResource: current}, time.Now()))
+	}
+
+	if oldFullPath == "" {
+		return data, nil
+	}
+
+	fullPath, _ := event["full_path"].(string)
+	descendants, err := movedDescendantsData(ctx, c, typesToStream, groupID, oldFullPath, fullPath)
+	if err != nil {
+		return nil, err
+	}
+	return append(data, descendants...), nil
+}
+
+// movedDescendantsData returns, for every subgroup and project of the group identified by
+// groupID whose path changed from oldFullPath to fullPath, a delete of its previous path
+// followed by an upsert of its current state.
+func movedDescendantsData(ctx context.Context, c *gitLabClient, typesToStream map[string]source.Extra, groupID, oldFullPath, fullPath string) ([]source.Data, error) {
+	var data []source.Data
+	previousPath := func(currentPath string) string {
+		return oldFullPath + strings.TrimPrefix(currentPath, fullPath)
+	}
+
+	if _, ok := typesToStream[groupResource]; ok {
+		err := forEachItem(ctx, c.listDescendantGroups(groupID), func(subgroup map[string]any) error {
+			currentPath, _ := subgroup["full_path"].(string)
+			previous := maps.Clone(subgroup)
+			previous["full_path"] = previousPath(currentPath)
+			data = append(data,
+				deleteData(groupResource, map[string]any{groupResource: previous}),
+				upsertData(groupResource, map[string]any{groupResource: subgroup}, time.Now()),
+			)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if _, ok := typesToStream[projectResource]; ok {
+		err := forEachItem(ctx, c.listGroupProjects(groupID), func(project map[string]any) error {
+			projectID, err := getIDFromItem(project)
+			if err != nil {
+				return err
+			}
+
+			currentPath, _ := project["path_with_namespace"].(string)
+			previous := maps.Clone(project)
+			previous["path_with_namespace"] = previousPath(currentPath)
+
+			langs, err := c.getProjectLanguages(ctx, projectID)
+			if err != nil {
+				return err
+			}
+			data = append(data,
+				deleteData(projectResource, projectWrapper(previous, nil)),
+				upsertData(projectResource, projectWrapper(project, langs), updatedAtOrNow(project)),
+			)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return data, nil
+}
+
+// memberData returns the data of a member event of the group or project identified by
+// parentKey: a delete of the membership for removals, or an upsert of the member and of
+// its group or project fetched from the API.
+func memberData(ctx context.Context, c *gitLabClient, typesToStream map[string]source.Extra, event map[string]any, parentKey string, operation source.DataOperation) ([]source.Data, error) {
+	if _, ok := typesToStream[memberResource]; !ok {
+		return nil, nil
+	}
+
+	parentID, err := eventID(event, parentKey+"_id")
+	if err != nil {
+		return nil, err
+	}
+	userID, err := eventID(event, "user_id")
+	if err != nil {
+		return nil, err
+	}
+
+	if operation == source.DataOperationDelete {
+		parent := map[string]any{
+			"id":   event[parentKey+"_id"],
+			"name": event[parentKey+"_name"],
+			"path": event[parentKey+"_path"],
+		}
+		if parentKey == projectResource {
+			parent["path_with_namespace"] = event["project_path_with_namespace"]
+		}
+		member := map[string]any{
+			"id":       event["user_id"],
+			"username": event["user_username"],
+			"name":     event["user_name"],
+		}
+		return []source.Data{deleteData(memberResource, map[string]any{memberResource: member, parentKey: parent})}, nil
+	}
+
+	parent, member, err := fetchMembership(ctx, c, parentKey, parentID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return []source.Data{upsertData(memberResource, map[string]any{memberResource: member, parentKey: parent}, time.Now())}, nil
+}
+
+// fetchMembership fetches the group or project identified by parentKey and parentID, and
+// its member identified by userID.
+func fetchMembership(ctx context.Context, c *gitLabClient, parentKey, parentID, userID string) (map[string]any, map[string]any, error) {
+	if parentKey == groupResource {
+		group, err := c.getGroup(ctx, parentID)
+		if err != nil {
+			return nil, nil, err
+		}
+		member, err := c.getGroupMember(ctx, parentID, userID)
+		return group, member, err
+	}
+
+	id, err := strconv.Atoi(parentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	project, err := c.getProject(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	member, err := c.getProjectMember(ctx, parentID, userID)
+	return project, member, err
+}
+
+// fetchProjectData returns an upsert of the project identified by projectID and of its
+// languages, fetched from the API.
+func fetchProjectData(ctx context.Context, c *gitLabClient, projectID string) (source.Data, error) {
+	id, err := strconv.Atoi(projectID)
+	if err != nil {
+		return source.Data{}, err
+	}
+
+	project, err := c.getProject(ctx, id)
+	if err != nil {
+		return source.Data{}, err
+	}
+
+	langs, err := c.getProjectLanguages(ctx, projectID)
+	if err != nil {
+		return source.Data{}, err
+	}
+
+	return upsertData(projectResource, projectWrapper(project, langs), updatedAtOrNow(project)), nil
+}
+
+// eventID returns the numeric ID stored under key in a webhook event.
+func eventID(event map[string]any, key string) (string, error) {
+	id, ok := event[key].(float64)
+	if !ok {
+		return "", fmt.Errorf("event payload missing %s field", key)
+	}
+	return strconv.FormatInt(int64(id), 10), nil
+}
+
+// forEachItem consumes every page of it, calling fn for each item, and stops at the
+// first error returned by fn or by the iterator.
+func forEachItem(ctx context.Context, it iterator, fn func(item map[string]any) error) error {
+	for {
+		items, err := it.next(ctx)
+		if errors.Is(err, ErrIteratorDone) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// upsertData returns an upsert of dataType carrying values at eventTime.
+func upsertData(dataType string, values map[string]any, eventTime time.Time) source.Data {
+	return source.Data{
+		Type:      dataType,
+		Operation: source.DataOperationUpsert,
+		Values:    values,
+		Time:      eventTime,
+	}
+}
+
+// deleteData returns a delete of dataType carrying values, timestamped now.
+func deleteData(dataType string, values map[string]any) source.Data {
+	return source.Data{
+		Type:      dataType,
+		Operation: source.DataOperationDelete,
+		Values:    values,
+		Time:      time.Now(),
+	}
+}
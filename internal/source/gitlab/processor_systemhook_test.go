@@ -0,0 +1,248 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package gitlab
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+// operationData is the type, operation and values of a source.Data, to compare data
+// regardless of their time.
+type operationData struct {
+	Type      string
+	Operation source.DataOperation
+	Values    map[string]any
+}
+
+func withoutTime(data []source.Data) []operationData {
+	result := make([]operationData, 0, len(data))
+	for _, d := range data {
+		result = append(result, operationData{Type: d.Type, Operation: d.Operation, Values: d.Values})
+	}
+	return result
+}
+
+func TestSystemHookProcessor(t *testing.T) {
+	t.Parallel()
+
+	project := map[string]any{"id": float64(74), "path_with_namespace": "new-group/storecloud", "updated_at": "2026-10-01T10:00:00Z"}
+	languages := map[string]any{"Go": 100.0}
+	group := map[string]any{"id": float64(78), "full_path": "new-group"}
+	subgroup := map[string]any{"id": float64(79), "full_path": "new-group/sub"}
+	member := map[string]any{"id": float64(41), "username": "johnsmith", "access_level": float64(40)}
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v4/projects/74":
+			jsonResponse(t, w, project)
+		case "/api/v4/projects/74/languages":
+			jsonResponse(t, w, languages)
+		case "/api/v4/projects/74/members/41", "/api/v4/groups/78/members/41":
+			jsonResponse(t, w, member)
+		case "/api/v4/groups/78":
+			assert.Equal(t, "false", r.URL.Query().Get("with_projects"))
+			jsonResponse(t, w, group)
+		case "/api/v4/groups/78/descendant_groups":
+			w.Header().Set("x-total-pages", "1")
+			jsonResponse(t, w, []map[string]any{subgroup})
+		case "/api/v4/groups/78/projects":
+			assert.Equal(t, "true", r.URL.Query().Get("include_subgroups"))
+			w.Header().Set("x-total-pages", "1")
+			jsonResponse(t, w, []map[string]any{project})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+
+	allTypes := map[string]source.Extra{projectResource: nil, groupResource: nil, memberResource: nil}
+	testCases := map[string]struct {
+		typesToStream map[string]source.Extra
+		body          map[string]any
+		expected      []operationData
+		expectErr     bool
+	}{
+		"project create upserts the fetched project": {
+			typesToStream: allTypes,
+			body:          map[string]any{"event_name": "project_create", "project_id": 74, "path_with_namespace": "new-group/storecloud"},
+			expected: []operationData{
+				{Type: projectResource, Operation: source.DataOperationUpsert, Values: projectWrapper(project, languages)},
+			},
+		},
+		"project destroy deletes the project": {
+			typesToStream: allTypes,
+			body: map[string]any{
+				"event_name": "project_destroy", "project_id": 74, "name": "StoreCloud", "path": "storecloud",
+				"path_with_namespace": "new-group/storecloud", "project_visibility": "private",
+			},
+			expected: []operationData{
+				{Type: projectResource, Operation: source.DataOperationDelete, Values: projectWrapper(map[string]any{
+					"id": float64(74), "name": "StoreCloud", "path": "storecloud", "path_with_namespace": "new-group/storecloud", "visibility": "private",
+				}, nil)},
+			},
+		},
+		"project transfer deletes the previous path": {
+			typesToStream: allTypes,
+			body: map[string]any{
+				"event_name": "project_transfer", "project_id": 74, "name": "StoreCloud", "path": "storecloud",
+				"path_with_namespace": "new-group/storecloud", "old_path_with_namespace": "old-group/storecloud",
+			},
+			expected: []operationData{
+				{Type: projectResource, Operation: source.DataOperationDelete, Values: projectWrapper(map[string]any{
+					"id": float64(74), "name": "StoreCloud", "path": "storecloud", "path_with_namespace": "old-group/storecloud", "visibility": nil,
+				}, nil)},
+				{Type: projectResource, Operation: source.DataOperationUpsert, Values: projectWrapper(project, languages)},
+			},
+		},
+		"project rename deletes the previous path": {
+			typesToStream: map[string]source.Extra{projectResource: nil},
+			body: map[string]any{
+				"event_name": "project_rename", "project_id": 74, "path": "storecloud",
+				"path_with_namespace": "new-group/storecloud", "old_path_with_namespace": "new-group/overscore",
+			},
+			expected: []operationData{
+				{Type: projectResource, Operation: source.DataOperationDelete, Values: projectWrapper(map[string]any{
+					"id": float64(74), "name": nil, "path": "overscore", "path_with_namespace": "new-group/overscore", "visibility": nil,
+				}, nil)},
+				{Type: projectResource, Operation: source.DataOperationUpsert, Values: projectWrapper(project, languages)},
+			},
+		},
+		"subgroup create upserts the fetched group": {
+			typesToStream: allTypes,
+			body:          map[string]any{"event_name": "subgroup_create", "group_id": 78, "full_path": "new-group"},
+			expected: []operationData{
+				{Type: groupResource, Operation: source.DataOperationUpsert, Values: map[string]any{groupResource: group}},
+			},
+		},
+		"group destroy deletes the group": {
+			typesToStream: allTypes,
+			body:          map[string]any{"event_name": "group_destroy", "group_id": 78, "name": "Group", "path": "group", "full_path": "group"},
+			expected: []operationData{
+				{Type: groupResource, Operation: source.DataOperationDelete, Values: map[string]any{groupResource: map[string]any{
+					"id": float64(78), "name": "Group", "path": "group", "full_path": "group",
+				}}},
+			},
+		},
+		"group rename moves the group, its subgroups and its projects": {
+			typesToStream: allTypes,
+			body: map[string]any{
+				"event_name": "group_rename", "group_id": 78, "name": "Group", "path": "new-group", "full_path": "new-group",
+				"old_path": "old-group", "old_full_path": "old-group",
+			},
+			expected: []operationData{
+				{Type: groupResource, Operation: source.DataOperationDelete, Values: map[string]any{groupResource: map[string]any{
+					"id": float64(78), "name": "Group", "path": "old-group", "full_path": "old-group",
+				}}},
+				{Type: groupResource, Operation: source.DataOperationUpsert, Values: map[string]any{groupResource: group}},
+				{Type: groupResource, Operation: source.DataOperationDelete, Values: map[string]any{groupResource: map[string]any{
+					"id": float64(79), "full_path": "old-group/sub",
+				}}},
+				{Type: groupResource, Operation: source.DataOperationUpsert, Values: map[string]any{groupResource: subgroup}},
+				{Type: projectResource, Operation: source.DataOperationDelete, Values: projectWrapper(map[string]any{
+					"id": float64(74), "path_with_namespace": "old-group/storecloud", "updated_at": "2026-10-01T10:00:00Z",
+				}, nil)},
+				{Type: projectResource, Operation: source.DataOperationUpsert, Values: projectWrapper(project, languages)},
+			},
+		},
+		"group rename with only projects streamed moves the projects": {
+			typesToStream: map[string]source.Extra{projectResource: nil},
+			body: map[string]any{
+				"event_name": "group_rename", "group_id": 78, "full_path": "new-group", "old_full_path": "old-group",
+			},
+			expected: []operationData{
+				{Type: projectResource, Operation: source.DataOperationDelete, Values: projectWrapper(map[string]any{
+					"id": float64(74), "path_with_namespace": "old-group/storecloud", "updated_at": "2026-10-01T10:00:00Z",
+				}, nil)},
+				{Type: projectResource, Operation: source.DataOperationUpsert, Values: projectWrapper(project, languages)},
+			},
+		},
+		"group member added upserts the fetched member": {
+			typesToStream: allTypes,
+			body:          map[string]any{"event_name": "user_add_to_group", "group_id": 78, "user_id": 41, "group_access": "Maintainer"},
+			expected: []operationData{
+				{Type: memberResource, Operation: source.DataOperationUpsert, Values: map[string]any{memberResource: member, groupResource: group}},
+			},
+		},
+		"project member updated upserts the fetched member": {
+			typesToStream: allTypes,
+			body:          map[string]any{"event_name": "user_update_for_team", "project_id": 74, "user_id": 41},
+			expected: []operationData{
+				{Type: memberResource, Operation: source.DataOperationUpsert, Values: map[string]any{memberResource: member, projectResource: project}},
+			},
+		},
+		"project member removed deletes the membership": {
+			typesToStream: allTypes,
+			body: map[string]any{
+				"event_name": "user_remove_from_team", "project_id": 74, "project_name": "StoreCloud", "project_path": "storecloud",
+				"project_path_with_namespace": "new-group/storecloud", "user_id": 41, "user_username": "johnsmith", "user_name": "John Smith",
+			},
+			expected: []operationData{
+				{Type: memberResource, Operation: source.DataOperationDelete, Values: map[string]any{
+					memberResource:  map[string]any{"id": float64(41), "username": "johnsmith", "name": "John Smith"},
+					projectResource: map[string]any{"id": float64(74), "name": "StoreCloud", "path": "storecloud", "path_with_namespace": "new-group/storecloud"},
+				}},
+			},
+		},
+		"push event delivered by system hook": {
+			typesToStream: allTypes,
+			body:          map[string]any{"object_kind": "push", "event_name": "push", "project_id": 74},
+			expected: []operationData{
+				{Type: projectResource, Operation: source.DataOperationUpsert, Values: projectWrapper(project, languages)},
+			},
+		},
+		"type not streamed is ignored": {
+			typesToStream: map[string]source.Extra{pipelineResource: nil},
+			body:          map[string]any{"event_name": "project_destroy", "project_id": 74},
+			expected:      []operationData{},
+		},
+		"unknown event is ignored": {
+			typesToStream: allTypes,
+			body:          map[string]any{"event_name": "key_create", "id": 4},
+			expected:      []operationData{},
+		},
+		"missing project id": {
+			typesToStream: allTypes,
+			body:          map[string]any{"event_name": "project_create"},
+			expectErr:     true,
+		},
+		"fetch failure": {
+			typesToStream: allTypes,
+			body:          map[string]any{"event_name": "user_add_to_group", "group_id": 1, "user_id": 41},
+			expectErr:     true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(handler))
+			t.Cleanup(srv.Close)
+
+			processor := &systemHookProcessor{}
+			data, err := processor.process(t.Context(), newTestGitLabClient(t, srv), tc.typesToStream, mustMarshal(t, tc.body))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, withoutTime(data))
+		})
+	}
+}
+
+func TestSystemHookProcessor_InvalidJSON(t *testing.T) {
+	t.Parallel()
+
+	processor := &systemHookProcessor{}
+	_, err := processor.process(t.Context(), &gitLabClient{}, map[string]source.Extra{projectResource: nil}, []byte("{bad json"))
+	require.Error(t, err)
+}
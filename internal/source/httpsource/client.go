@@ -0,0 +1,333 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package httpsource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/mia-platform/ibdm/internal/jsonpath"
+	"github.com/mia-platform/ibdm/internal/tokensource/clientcredentialssource"
+)
+
+const (
+	// maxErrorBodyBytes caps how much of a non-2xx response body is read into memory.
+	maxErrorBodyBytes = 1024
+)
+
+var (
+	errUnexpectedStatus = errors.New("unexpected status code")
+	errInvalidItems     = errors.New("items must be objects")
+	errRepeatedPage     = errors.New("pagination returned a page already read")
+
+	// nextLinkPattern extracts the URL of the next page from a Link header, see RFC 8288.
+	nextLinkPattern = regexp.MustCompile(`<([^>]+)>\s*;\s*rel="?next"?`)
+)
+
+// client reads the items of a single data type.
+type client struct {
+	config     *typeConfig
+	httpClient *http.Client
+	// authorize sets the authentication headers of a request.
+	authorize func(req *http.Request)
+}
+
+// newClient builds the client of the data type described by cfg, reading its secrets from the
+// environment.
+func newClient(ctx context.Context, cfg *typeConfig, timeout time.Duration) (*client, error) {
+	c := &client{
+		config:     cfg,
+		httpClient: &http.Client{Timeout: timeout},
+		authorize:  func(*http.Request) {},
+	}
+
+	switch cfg.Auth.Type {
+	case authTypeBearer:
+		token, err := requiredEnv(cfg.Auth.TokenEnv)
+		if err != nil {
+			return nil, err
+		}
+		c.authorize = func(req *http.Request) {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	case authTypeBasic:
+		username, err := requiredEnv(cfg.Auth.UsernameEnv)
+		if err != nil {
+			return nil, err
+		}
+		password, err := requiredEnv(cfg.Auth.PasswordEnv)
+		if err != nil {
+			return nil, err
+		}
+		c.authorize = func(req *http.Request) {
+			req.SetBasicAuth(username, password)
+		}
+	case authTypeOAuth2:
+		clientID, err := requiredEnv(cfg.Auth.ClientIDEnv)
+		if err != nil {
+			return nil, err
+		}
+		clientSecret, err := requiredEnv(cfg.Auth.ClientSecretEnv)
+		if err != nil {
+			return nil, err
+		}
+		tokenSource, err := clientcredentialssource.NewSource(ctx, clientID, clientSecret, cfg.Auth.TokenURL, cfg.Auth.Scopes)
+		if err != nil {
+			return nil, err
+		}
+		c.httpClient.Transport = &oauth2.Transport{Source: tokenSource, Base: http.DefaultTransport}
+	}
+
+	return c, nil
+}
+
+// requiredEnv returns the value of the environment variable name, failing when it is empty.
+func requiredEnv(name string) (string, error) {
+	value := os.Getenv(name)
+	if len(value) == 0 {
+		return "", fmt.Errorf("%w: %s", ErrMissingEnvVariable, name)
+	}
+	return value, nil
+}
+
+// listItems calls handler with the items of every page, following the configured pagination
+// until a page has no following one. It stops with an error when the pagination points to a page
+// already read, since an API returning the same cursor or next link again would never end.
+func (c *client) listItems(ctx context.Context, handler func(items []map[string]any) error) error {
+	pages, err := newPager(c.config)
+	if err != nil {
+		return err
+	}
+
+	visited := make(map[string]struct{})
+	for {
+		pageURL := pages.url()
+		if _, found := visited[pageURL]; found {
+			return fmt.Errorf("%w: after %d pages", errRepeatedPage, len(visited))
+		}
+		visited[pageURL] = struct{}{}
+
+		body, header, err := c.do(ctx, c.config.Request.Method, pageURL, c.config.Request.Body)
+		if err != nil {
+			return err
+		}
+
+		items, err := selectItems(body, c.config.Items)
+		if err != nil {
+			return err
+		}
+
+		if err := handler(items); err != nil {
+			return err
+		}
+
+		if more, err := pages.next(body, header, len(items)); err != nil || !more {
+			return err
+		}
+	}
+}
+
+// pager tracks the URL of the page to request.
+type pager struct {
+	pagination  paginationConfig
+	requestURL  *url.URL
+	query       url.Values
+	currentPage int
+}
+
+// newPager returns the pager of the first page of the request declared in cfg.
+func newPager(cfg *typeConfig) (*pager, error) {
+	requestURL, err := url.Parse(cfg.Request.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &pager{
+		pagination: cfg.Pagination,
+		requestURL: requestURL,
+		query:      requestURL.Query(),
+	}
+	for name, value := range cfg.Request.Query {
+		p.query.Set(name, value)
+	}
+	if p.pagination.Type == paginationTypePage {
+		p.currentPage = *p.pagination.FirstPage
+		if len(p.pagination.SizeParam) > 0 && p.pagination.Size > 0 {
+			p.query.Set(p.pagination.SizeParam, strconv.Itoa(p.pagination.Size))
+		}
+	}
+	return p, nil
+}
+
+// url returns the URL of the current page.
+func (p *pager) url() string {
+	if p.pagination.Type == paginationTypePage {
+		p.query.Set(p.pagination.PageParam, strconv.Itoa(p.currentPage))
+	}
+	p.requestURL.RawQuery = p.query.Encode()
+	return p.requestURL.String()
+}
+
+// next moves to the page following the one read, returning false when there is none.
+func (p *pager) next(body any, header http.Header, itemsCount int) (bool, error) {
+	switch p.pagination.Type {
+	case paginationTypePage:
+		if itemsCount == 0 || (p.pagination.Size > 0 && itemsCount < p.pagination.Size) {
+			return false, nil
+		}
+		p.currentPage++
+		return true, nil
+	case paginationTypeLink:
+		match := nextLinkPattern.FindStringSubmatch(header.Get("Link"))
+		if match == nil {
+			return false, nil
+		}
+		nextURL, err := p.requestURL.Parse(match[1])
+		if err != nil {
+			return false, err
+		}
+		// the next link already carries every query parameter
+		p.requestURL = nextURL
+		p.query = nextURL.Query()
+		return true, nil
+	case paginationTypeCursor:
+		cursor, err := nextCursor(body, header, p.pagination)
+		if err != nil || len(cursor) == 0 {
+			return false, err
+		}
+		p.query.Set(p.pagination.CursorParam, cursor)
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// detail fetches the details of item, replacing it with the configured part of the response.
+func (c *client) detail(ctx context.Context, item map[string]any) (map[string]any, error) {
+	detailURL := new(strings.Builder)
+	if err := c.config.Detail.url.Execute(detailURL, item); err != nil {
+		return nil, err
+	}
+
+	body, _, err := c.do(ctx, http.MethodGet, detailURL.String(), "")
+	if err != nil {
+		return nil, err
+	}
+
+	var selected any = body
+	if len(c.config.Detail.Item) > 0 {
+		if selected, err = jsonpath.Get(c.config.Detail.Item, body); err != nil {
+			return nil, err
+		}
+	}
+
+	detail, ok := selected.(map[string]any)
+	if !ok {
+		return nil, errInvalidItems
+	}
+	return detail, nil
+}
+
+// do sends a request and decodes its JSON response body.
+func (c *client) do(ctx context.Context, method, requestURL, body string) (any, http.Header, error) {
+	var bodyReader io.Reader
+	if len(body) > 0 {
+		bodyReader = strings.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, bodyReader)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if bodyReader != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, value := range c.config.Request.Headers {
+		req.Header.Set(name, value)
+	}
+	c.authorize(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		errorBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+		return nil, nil, fmt.Errorf("%w: %s %s: %d: %s", errUnexpectedStatus, method, req.URL.Redacted(), resp.StatusCode, errorBody)
+	}
+
+	var decoded any
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return nil, nil, fmt.Errorf("decoding response of %s %s: %w", method, req.URL.Redacted(), err)
+	}
+
+	return decoded, resp.Header, nil
+}
+
+// selectItems returns the items selected by path in body, or body itself when path is empty.
+func selectItems(body any, path string) ([]map[string]any, error) {
+	selected := body
+	if len(path) > 0 {
+		var err error
+		if selected, err = jsonpath.Get(path, body); err != nil {
+			return nil, err
+		}
+	}
+
+	switch typed := selected.(type) {
+	case nil:
+		return []map[string]any{}, nil
+	case map[string]any:
+		return []map[string]any{typed}, nil
+	case []any:
+		items := make([]map[string]any, 0, len(typed))
+		for _, value := range typed {
+			item, ok := value.(map[string]any)
+			if !ok {
+				return nil, errInvalidItems
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	default:
+		return nil, errInvalidItems
+	}
+}
+
+// nextCursor returns the cursor of the following page, an empty string when there is none.
+func nextCursor(body any, header http.Header, pagination paginationConfig) (string, error) {
+	if len(pagination.CursorHeader) > 0 {
+		return header.Get(pagination.CursorHeader), nil
+	}
+
+	cursor, err := jsonpath.Get(pagination.CursorPath, body)
+	if err != nil {
+		// a missing cursor means there are no more pages
+		return "", nil //nolint:nilerr
+	}
+
+	switch typed := cursor.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return typed, nil
+	default:
+		return fmt.Sprint(typed), nil
+	}
+}
@@ -0,0 +1,357 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package httpsource
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testClient validates cfg and builds its client.
+func testClient(t *testing.T, cfg *typeConfig) *client {
+	t.Helper()
+
+	require.NoError(t, cfg.validate())
+	c, err := newClient(t.Context(), cfg, 5*time.Second)
+	require.NoError(t, err)
+	return c
+}
+
+// writeJSON encodes body as the response of w.
+func writeJSON(t *testing.T, w http.ResponseWriter, body any) {
+	t.Helper()
+
+	w.Header().Set("Content-Type", "application/json")
+	assert.NoError(t, json.NewEncoder(w).Encode(body))
+}
+
+// listAll returns every item read by c.
+func listAll(t *testing.T, c *client) ([]map[string]any, error) {
+	t.Helper()
+
+	items := make([]map[string]any, 0)
+	err := c.listItems(t.Context(), func(page []map[string]any) error {
+		items = append(items, page...)
+		return nil
+	})
+	return items, err
+}
+
+func TestListItemsPagination(t *testing.T) {
+	t.Parallel()
+
+	pages := [][]any{
+		{map[string]any{"id": "1"}, map[string]any{"id": "2"}},
+		{map[string]any{"id": "3"}, map[string]any{"id": "4"}},
+		{map[string]any{"id": "5"}},
+	}
+	expected := []map[string]any{{"id": "1"}, {"id": "2"}, {"id": "3"}, {"id": "4"}, {"id": "5"}}
+
+	testCases := map[string]struct {
+		pagination paginationConfig
+		items      string
+		handler    func(t *testing.T, w http.ResponseWriter, r *http.Request)
+		expected   []map[string]any
+	}{
+		"no pagination reads a single page": {
+			handler: func(t *testing.T, w http.ResponseWriter, _ *http.Request) {
+				t.Helper()
+				writeJSON(t, w, pages[0])
+			},
+			expected: []map[string]any{{"id": "1"}, {"id": "2"}},
+		},
+		"page pagination stops at the short page": {
+			pagination: paginationConfig{Type: paginationTypePage, SizeParam: "per_page", Size: 2},
+			items:      "$.data",
+			handler: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+				t.Helper()
+				assert.Equal(t, "2", r.URL.Query().Get("per_page"))
+				assert.Equal(t, "fixed", r.URL.Query().Get("filter"))
+				var page int
+				_, err := fmt.Sscan(r.URL.Query().Get("page"), &page)
+				require.NoError(t, err)
+				writeJSON(t, w, map[string]any{"data": pages[page-1]})
+			},
+			expected: expected,
+		},
+		"page pagination stops at the empty page": {
+			pagination: paginationConfig{Type: paginationTypePage, PageParam: "p", FirstPage: new(int)},
+			handler: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+				t.Helper()
+				var page int
+				_, err := fmt.Sscan(r.URL.Query().Get("p"), &page)
+				require.NoError(t, err)
+				if page >= len(pages) {
+					writeJSON(t, w, []any{})
+					return
+				}
+				writeJSON(t, w, pages[page])
+			},
+			expected: expected,
+		},
+		"link pagination follows the next link": {
+			pagination: paginationConfig{Type: paginationTypeLink},
+			handler: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+				t.Helper()
+				var page int
+				if cursor := r.URL.Query().Get("cursor"); len(cursor) > 0 {
+					_, err := fmt.Sscan(cursor, &page)
+					require.NoError(t, err)
+				}
+				if page < len(pages)-1 {
+					w.Header().Set("Link", fmt.Sprintf(`</items?cursor=%d>; rel="next", </items>; rel="first"`, page+1))
+				}
+				writeJSON(t, w, pages[page])
+			},
+			expected: expected,
+		},
+		"cursor pagination reads the cursor from the body": {
+			pagination: paginationConfig{Type: paginationTypeCursor, CursorParam: "after", CursorPath: "$.next"},
+			items:      "$.data",
+			handler: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+				t.Helper()
+				assert.Equal(t, "fixed", r.URL.Query().Get("filter"))
+				switch r.URL.Query().Get("after") {
+				case "":
+					writeJSON(t, w, map[string]any{"data": pages[0], "next": "a"})
+				case "a":
+					writeJSON(t, w, map[string]any{"data": pages[1], "next": "b"})
+				default:
+					writeJSON(t, w, map[string]any{"data": pages[2], "next": nil})
+				}
+			},
+			expected: expected,
+		},
+		"cursor pagination reads the cursor from a header": {
+			pagination: paginationConfig{Type: paginationTypeCursor, CursorParam: "after", CursorHeader: "X-Next"},
+			handler: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+				t.Helper()
+				switch r.URL.Query().Get("after") {
+				case "":
+					w.Header().Set("X-Next", "a")
+					writeJSON(t, w, pages[0])
+				default:
+					writeJSON(t, w, pages[2])
+				}
+			},
+			expected: []map[string]any{{"id": "1"}, {"id": "2"}, {"id": "5"}},
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				test.handler(t, w, r)
+			}))
+			defer server.Close()
+
+			c := testClient(t, &typeConfig{
+				Type:       "items",
+				Request:    requestConfig{URL: server.URL + "/items", Query: map[string]string{"filter": "fixed"}},
+				Pagination: test.pagination,
+				Items:      test.items,
+			})
+
+			items, err := listAll(t, c)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, items)
+		})
+	}
+}
+
+func TestListItemsErrors(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		handler     http.HandlerFunc
+		items       string
+		pagination  paginationConfig
+		expectedErr error
+	}{
+		"unexpected status": {
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				http.Error(w, "boom", http.StatusInternalServerError)
+			},
+			expectedErr: errUnexpectedStatus,
+		},
+		"items are not objects": {
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte(`{"data": [1, 2]}`))
+			},
+			items:       "$.data",
+			expectedErr: errInvalidItems,
+		},
+		"cursor pagination returning the same cursor": {
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte(`{"data": [{"id": "1"}], "next": "same"}`))
+			},
+			items:       "$.data",
+			pagination:  paginationConfig{Type: paginationTypeCursor, CursorParam: "after", CursorPath: "$.next"},
+			expectedErr: errRepeatedPage,
+		},
+		"link pagination returning a page already read": {
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				next := "/?page=2"
+				if r.URL.Query().Get("page") == "2" {
+					next = "/?page=1"
+				}
+				w.Header().Set("Link", "<"+next+`>; rel="next"`)
+				_, _ = w.Write([]byte(`[{"id": "1"}]`))
+			},
+			pagination:  paginationConfig{Type: paginationTypeLink},
+			expectedErr: errRepeatedPage,
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(test.handler)
+			defer server.Close()
+
+			c := testClient(t, &typeConfig{
+				Type:       "items",
+				Request:    requestConfig{URL: server.URL + "/?page=1"},
+				Pagination: test.pagination,
+				Items:      test.items,
+			})
+			_, err := listAll(t, c)
+			assert.ErrorIs(t, err, test.expectedErr)
+		})
+	}
+}
+
+func TestRequest(t *testing.T) {
+	t.Setenv("TEST_HTTP_TOKEN", "secret-token")
+	t.Setenv("TEST_HTTP_USERNAME", "user")
+	t.Setenv("TEST_HTTP_PASSWORD", "pass")
+
+	t.Run("bearer auth, method, headers and body", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "Bearer secret-token", r.Header.Get("Authorization"))
+			assert.Equal(t, "value", r.Header.Get("X-Custom"))
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			var body map[string]any
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, map[string]any{"query": "all"}, body)
+			writeJSON(t, w, []any{map[string]any{"id": "1"}})
+		}))
+		defer server.Close()
+
+		c := testClient(t, &typeConfig{
+			Type: "items",
+			Request: requestConfig{
+				URL:     server.URL,
+				Method:  "post",
+				Headers: map[string]string{"X-Custom": "value"},
+				Body:    `{"query": "all"}`,
+			},
+			Auth: authConfig{Type: authTypeBearer, TokenEnv: "TEST_HTTP_TOKEN"},
+		})
+
+		items, err := listAll(t, c)
+		require.NoError(t, err)
+		assert.Equal(t, []map[string]any{{"id": "1"}}, items)
+	})
+
+	t.Run("basic auth", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			username, password, ok := r.BasicAuth()
+			assert.True(t, ok)
+			assert.Equal(t, "user", username)
+			assert.Equal(t, "pass", password)
+			writeJSON(t, w, map[string]any{"id": "1"})
+		}))
+		defer server.Close()
+
+		c := testClient(t, &typeConfig{
+			Type:    "items",
+			Request: requestConfig{URL: server.URL},
+			Auth:    authConfig{Type: authTypeBasic, UsernameEnv: "TEST_HTTP_USERNAME", PasswordEnv: "TEST_HTTP_PASSWORD"},
+		})
+
+		items, err := listAll(t, c)
+		require.NoError(t, err)
+		assert.Equal(t, []map[string]any{{"id": "1"}}, items)
+	})
+
+	t.Run("oauth2 auth", func(t *testing.T) {
+		t.Setenv("TEST_HTTP_CLIENT_ID", "client")
+		t.Setenv("TEST_HTTP_CLIENT_SECRET", "secret")
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+			assert.NoError(t, r.ParseForm())
+			assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+			writeJSON(t, w, map[string]any{"access_token": "oauth-token", "token_type": "bearer", "expires_in": 3600})
+		})
+		mux.HandleFunc("/items", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "Bearer oauth-token", r.Header.Get("Authorization"))
+			writeJSON(t, w, []any{map[string]any{"id": "1"}})
+		})
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		c := testClient(t, &typeConfig{
+			Type:    "items",
+			Request: requestConfig{URL: server.URL + "/items"},
+			Auth: authConfig{
+				Type:            authTypeOAuth2,
+				ClientIDEnv:     "TEST_HTTP_CLIENT_ID",
+				ClientSecretEnv: "TEST_HTTP_CLIENT_SECRET",
+				TokenURL:        server.URL + "/token",
+			},
+		})
+
+		items, err := listAll(t, c)
+		require.NoError(t, err)
+		assert.Equal(t, []map[string]any{{"id": "1"}}, items)
+	})
+
+	t.Run("missing secret", func(t *testing.T) {
+		cfg := &typeConfig{
+			Type:    "items",
+			Request: requestConfig{URL: "https://example.com"},
+			Auth:    authConfig{Type: authTypeBearer, TokenEnv: "TEST_HTTP_MISSING_TOKEN"},
+		}
+		require.NoError(t, cfg.validate())
+
+		_, err := newClient(t.Context(), cfg, time.Second)
+		assert.ErrorIs(t, err, ErrMissingEnvVariable)
+	})
+}
+
+func TestDetail(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/items/42", r.URL.Path)
+		writeJSON(t, w, map[string]any{"item": map[string]any{"id": "42", "owner": "team"}})
+	}))
+	defer server.Close()
+
+	c := testClient(t, &typeConfig{
+		Type:    "items",
+		Request: requestConfig{URL: server.URL},
+		Detail:  &detailConfig{URL: server.URL + "/items/{{ .id }}", Item: "$.item"},
+	})
+
+	detail, err := c.detail(t.Context(), map[string]any{"id": "42"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"id": "42", "owner": "team"}, detail)
+
+	_, err = c.detail(t.Context(), map[string]any{"name": "no id"})
+	assert.ErrorContains(t, err, `map has no entry for key "id"`)
+}
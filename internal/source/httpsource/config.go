@@ -0,0 +1,259 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package httpsource
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/caarlos0/env/v11"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	authTypeNone   = ""
+	authTypeBearer = "bearer"
+	authTypeBasic  = "basic"
+	authTypeOAuth2 = "oauth2"
+
+	paginationTypeNone   = ""
+	paginationTypePage   = "page"
+	paginationTypeLink   = "link"
+	paginationTypeCursor = "cursor"
+
+	defaultPageParam = "page"
+	defaultFirstPage = 1
+)
+
+var (
+	// ErrMissingEnvVariable reports missing mandatory environment variables.
+	ErrMissingEnvVariable = errors.New("missing environment variable")
+	// ErrInvalidEnvVariable reports malformed environment variable values.
+	ErrInvalidEnvVariable = errors.New("invalid environment value")
+	// ErrInvalidTypesConfig reports an invalid types configuration file.
+	ErrInvalidTypesConfig = errors.New("invalid types configuration")
+)
+
+// config holds the environment-driven HTTP source settings.
+type config struct {
+	TypesConfigPath string        `env:"HTTP_SOURCE_CONFIG"`
+	HTTPTimeout     time.Duration `env:"HTTP_SOURCE_HTTP_TIMEOUT" envDefault:"30s"`
+	PollInterval    time.Duration `env:"HTTP_SOURCE_POLL_INTERVAL" envDefault:"5m"`
+}
+
+// typesConfig is the content of the types configuration file.
+type typesConfig struct {
+	Types []typeConfig `yaml:"types"`
+}
+
+// typeConfig declares how the items of a data type are read.
+type typeConfig struct {
+	Type       string           `yaml:"type"`
+	Request    requestConfig    `yaml:"request"`
+	Auth       authConfig       `yaml:"auth"`
+	Pagination paginationConfig `yaml:"pagination"`
+	// Items is the JSONPath selecting the items in the response body, when empty the body itself
+	// is the item list or a single item.
+	Items  string        `yaml:"items"`
+	Detail *detailConfig `yaml:"detail"`
+	// Key is a template rendered with an item to identify it between two polls, when empty an
+	// item is identified by its whole content.
+	Key string `yaml:"key"`
+
+	key *template.Template
+}
+
+// requestConfig declares the request returning the items of a data type.
+type requestConfig struct {
+	URL     string            `yaml:"url"`
+	Method  string            `yaml:"method"`
+	Headers map[string]string `yaml:"headers"`
+	Query   map[string]string `yaml:"query"`
+	Body    string            `yaml:"body"`
+}
+
+// authConfig declares the authentication of the requests, secrets are read from the environment
+// variables named by the *Env fields so they are never written in the configuration file.
+type authConfig struct {
+	Type            string   `yaml:"type"`
+	TokenEnv        string   `yaml:"tokenEnv"`
+	UsernameEnv     string   `yaml:"usernameEnv"`
+	PasswordEnv     string   `yaml:"passwordEnv"`
+	ClientIDEnv     string   `yaml:"clientIdEnv"`
+	ClientSecretEnv string   `yaml:"clientSecretEnv"`
+	TokenURL        string   `yaml:"tokenUrl"`
+	Scopes          []string `yaml:"scopes"`
+}
+
+// paginationConfig declares how the following pages of a response are requested.
+type paginationConfig struct {
+	Type string `yaml:"type"`
+	// PageParam, SizeParam, Size and FirstPage configure the page number pagination.
+	PageParam string `yaml:"pageParam"`
+	SizeParam string `yaml:"sizeParam"`
+	Size      int    `yaml:"size"`
+	FirstPage *int   `yaml:"firstPage"`
+	// CursorParam, CursorPath and CursorHeader configure the cursor pagination, the cursor is
+	// read from the body with CursorPath or from the CursorHeader response header.
+	CursorParam  string `yaml:"cursorParam"`
+	CursorPath   string `yaml:"cursorPath"`
+	CursorHeader string `yaml:"cursorHeader"`
+}
+
+// detailConfig declares the request fetching the details of every item.
+type detailConfig struct {
+	// URL is a template rendered with the item.
+	URL string `yaml:"url"`
+	// Item is the JSONPath selecting the item in the detail response, when empty the body itself
+	// replaces the item.
+	Item string `yaml:"item"`
+
+	url *template.Template
+}
+
+// loadConfigFromEnv parses configuration from environment variables and
+// validates the result.
+func loadConfigFromEnv() (*config, error) {
+	cfg, err := env.ParseAs[config]()
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// validate checks that all required fields are present and that durations are positive.
+func (c config) validate() error {
+	if len(c.TypesConfigPath) == 0 {
+		return fmt.Errorf("%w: %s", ErrMissingEnvVariable, "HTTP_SOURCE_CONFIG")
+	}
+	if c.PollInterval <= 0 {
+		return fmt.Errorf("%w: HTTP_SOURCE_POLL_INTERVAL must be positive, got %s", ErrInvalidEnvVariable, c.PollInterval)
+	}
+	return nil
+}
+
+// loadTypesConfig reads and validates the types configuration file at path.
+func loadTypesConfig(path string) (map[string]*typeConfig, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg typesConfig
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTypesConfig, err)
+	}
+
+	types := make(map[string]*typeConfig, len(cfg.Types))
+	for index := range cfg.Types {
+		typeCfg := &cfg.Types[index]
+		if err := typeCfg.validate(); err != nil {
+			return nil, fmt.Errorf("%w: type %q: %w", ErrInvalidTypesConfig, typeCfg.Type, err)
+		}
+		if _, found := types[typeCfg.Type]; found {
+			return nil, fmt.Errorf("%w: type %q is declared more than once", ErrInvalidTypesConfig, typeCfg.Type)
+		}
+		types[typeCfg.Type] = typeCfg
+	}
+
+	return types, nil
+}
+
+// validate checks the type configuration, applies the defaults and parses its templates.
+func (c *typeConfig) validate() error {
+	if len(c.Type) == 0 {
+		return errors.New("missing type")
+	}
+	if len(c.Request.URL) == 0 {
+		return errors.New("missing request url")
+	}
+
+	c.Request.Method = strings.ToUpper(c.Request.Method)
+	if len(c.Request.Method) == 0 {
+		c.Request.Method = http.MethodGet
+	}
+
+	if err := c.Auth.validate(); err != nil {
+		return err
+	}
+	if err := c.Pagination.validate(); err != nil {
+		return err
+	}
+
+	if len(c.Key) > 0 {
+		key, err := template.New("key").Option("missingkey=error").Parse(c.Key)
+		if err != nil {
+			return err
+		}
+		c.key = key
+	}
+
+	if c.Detail != nil {
+		if len(c.Detail.URL) == 0 {
+			return errors.New("missing detail url")
+		}
+		detailURL, err := template.New("detail").Option("missingkey=error").Parse(c.Detail.URL)
+		if err != nil {
+			return err
+		}
+		c.Detail.url = detailURL
+	}
+
+	return nil
+}
+
+// validate checks that the secrets required by the authentication type are configured.
+func (c authConfig) validate() error {
+	switch c.Type {
+	case authTypeNone:
+	case authTypeBearer:
+		if len(c.TokenEnv) == 0 {
+			return errors.New("bearer auth requires tokenEnv")
+		}
+	case authTypeBasic:
+		if len(c.UsernameEnv) == 0 || len(c.PasswordEnv) == 0 {
+			return errors.New("basic auth requires usernameEnv and passwordEnv")
+		}
+	case authTypeOAuth2:
+		if len(c.ClientIDEnv) == 0 || len(c.ClientSecretEnv) == 0 || len(c.TokenURL) == 0 {
+			return errors.New("oauth2 auth requires clientIdEnv, clientSecretEnv and tokenUrl")
+		}
+	default:
+		return fmt.Errorf("unknown auth type %q", c.Type)
+	}
+	return nil
+}
+
+// validate checks the pagination configuration and applies its defaults.
+func (c *paginationConfig) validate() error {
+	switch c.Type {
+	case paginationTypeNone, paginationTypeLink:
+	case paginationTypePage:
+		if len(c.PageParam) == 0 {
+			c.PageParam = defaultPageParam
+		}
+		if c.FirstPage == nil {
+			firstPage := defaultFirstPage
+			c.FirstPage = &firstPage
+		}
+	case paginationTypeCursor:
+		if len(c.CursorParam) == 0 {
+			return errors.New("cursor pagination requires cursorParam")
+		}
+		if (len(c.CursorPath) == 0) == (len(c.CursorHeader) == 0) {
+			return errors.New("cursor pagination requires one of cursorPath or cursorHeader")
+		}
+	default:
+		return fmt.Errorf("unknown pagination type %q", c.Type)
+	}
+	return nil
+}
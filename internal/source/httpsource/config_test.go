@@ -0,0 +1,153 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package httpsource
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfigFromEnv(t *testing.T) {
+	t.Run("valid full configuration", func(t *testing.T) {
+		t.Setenv("HTTP_SOURCE_CONFIG", "types.yaml")
+		t.Setenv("HTTP_SOURCE_HTTP_TIMEOUT", "10s")
+		t.Setenv("HTTP_SOURCE_POLL_INTERVAL", "1m")
+
+		cfg, err := loadConfigFromEnv()
+		require.NoError(t, err)
+		assert.Equal(t, "types.yaml", cfg.TypesConfigPath)
+		assert.Equal(t, 10*time.Second, cfg.HTTPTimeout)
+		assert.Equal(t, time.Minute, cfg.PollInterval)
+	})
+
+	t.Run("valid minimal configuration with defaults", func(t *testing.T) {
+		t.Setenv("HTTP_SOURCE_CONFIG", "types.yaml")
+
+		cfg, err := loadConfigFromEnv()
+		require.NoError(t, err)
+		assert.Equal(t, 30*time.Second, cfg.HTTPTimeout)
+		assert.Equal(t, 5*time.Minute, cfg.PollInterval)
+	})
+
+	t.Run("missing configuration file", func(t *testing.T) {
+		_, err := loadConfigFromEnv()
+		assert.ErrorIs(t, err, ErrMissingEnvVariable)
+	})
+
+	t.Run("invalid poll interval", func(t *testing.T) {
+		t.Setenv("HTTP_SOURCE_CONFIG", "types.yaml")
+		t.Setenv("HTTP_SOURCE_POLL_INTERVAL", "0s")
+
+		_, err := loadConfigFromEnv()
+		assert.ErrorIs(t, err, ErrInvalidEnvVariable)
+	})
+}
+
+func TestLoadTypesConfig(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		content     string
+		expectedErr string
+		assertTypes func(t *testing.T, types map[string]*typeConfig)
+	}{
+		"defaults are applied": {
+			content: `
+types:
+  - type: services
+    request:
+      url: https://example.com/services
+    pagination:
+      type: page
+    key: "{{ .id }}"
+    detail:
+      url: "https://example.com/services/{{ .id }}"
+`,
+			assertTypes: func(t *testing.T, types map[string]*typeConfig) {
+				t.Helper()
+
+				require.Contains(t, types, "services")
+				services := types["services"]
+				assert.Equal(t, http.MethodGet, services.Request.Method)
+				assert.Equal(t, defaultPageParam, services.Pagination.PageParam)
+				require.NotNil(t, services.Pagination.FirstPage)
+				assert.Equal(t, defaultFirstPage, *services.Pagination.FirstPage)
+				assert.NotNil(t, services.key)
+				assert.NotNil(t, services.Detail.url)
+			},
+		},
+		"method is normalized": {
+			content: "types:\n  - type: services\n    request: {url: https://example.com, method: post}\n",
+			assertTypes: func(t *testing.T, types map[string]*typeConfig) {
+				t.Helper()
+				assert.Equal(t, http.MethodPost, types["services"].Request.Method)
+			},
+		},
+		"duplicated type": {
+			content:     "types:\n  - type: services\n    request: {url: https://example.com}\n  - type: services\n    request: {url: https://example.com}\n",
+			expectedErr: `invalid types configuration: type "services" is declared more than once`,
+		},
+		"missing type": {
+			content:     "types:\n  - request: {url: https://example.com}\n",
+			expectedErr: `invalid types configuration: type "": missing type`,
+		},
+		"missing url": {
+			content:     "types:\n  - type: services\n",
+			expectedErr: `invalid types configuration: type "services": missing request url`,
+		},
+		"unknown auth": {
+			content:     "types:\n  - type: services\n    request: {url: https://example.com}\n    auth: {type: digest}\n",
+			expectedErr: `invalid types configuration: type "services": unknown auth type "digest"`,
+		},
+		"incomplete basic auth": {
+			content:     "types:\n  - type: services\n    request: {url: https://example.com}\n    auth: {type: basic, usernameEnv: USER}\n",
+			expectedErr: `invalid types configuration: type "services": basic auth requires usernameEnv and passwordEnv`,
+		},
+		"incomplete oauth2 auth": {
+			content:     "types:\n  - type: services\n    request: {url: https://example.com}\n    auth: {type: oauth2, clientIdEnv: ID, clientSecretEnv: SECRET}\n",
+			expectedErr: `invalid types configuration: type "services": oauth2 auth requires clientIdEnv, clientSecretEnv and tokenUrl`,
+		},
+		"unknown pagination": {
+			content:     "types:\n  - type: services\n    request: {url: https://example.com}\n    pagination: {type: offset}\n",
+			expectedErr: `invalid types configuration: type "services": unknown pagination type "offset"`,
+		},
+		"cursor pagination with both sources": {
+			content:     "types:\n  - type: services\n    request: {url: https://example.com}\n    pagination: {type: cursor, cursorParam: after, cursorPath: $.next, cursorHeader: X-Next}\n",
+			expectedErr: `invalid types configuration: type "services": cursor pagination requires one of cursorPath or cursorHeader`,
+		},
+		"invalid key template": {
+			content:     "types:\n  - type: services\n    request: {url: https://example.com}\n    key: \"{{ .id \"\n",
+			expectedErr: `invalid types configuration: type "services": template: key:1: unclosed action`,
+		},
+		"invalid yaml": {
+			content:     "types: [",
+			expectedErr: "invalid types configuration: yaml: line 1: did not find expected node content",
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "types.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(test.content), 0o600))
+
+			types, err := loadTypesConfig(path)
+			if len(test.expectedErr) > 0 {
+				require.ErrorIs(t, err, ErrInvalidTypesConfig)
+				assert.EqualError(t, err, test.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			test.assertTypes(t, types)
+		})
+	}
+}
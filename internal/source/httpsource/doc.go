@@ -0,0 +1,7 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+// Package httpsource provides a source implementation for generic REST APIs. Every data type is
+// declared in a YAML file with its endpoint, authentication, pagination style and the JSONPath
+// of its items, so internal APIs can be read without writing a dedicated source.
+package httpsource
@@ -0,0 +1,262 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package httpsource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mia-platform/ibdm/internal/logger"
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+const (
+	loggerName = "ibdm:source:http"
+)
+
+var (
+	// ErrHTTPSource wraps all errors originating from the HTTP source.
+	ErrHTTPSource = errors.New("http source")
+
+	// timeSource is a package-level function for the current time, replaceable in tests.
+	timeSource = time.Now
+)
+
+var _ source.SyncableSource = &Source{}
+var _ source.EventSource = &Source{}
+
+// Source implements [source.SyncableSource] and [source.EventSource] for the REST APIs declared
+// in the types configuration file. The event stream polls the APIs and sends the items added,
+// changed or removed since the previous poll.
+type Source struct {
+	config  config
+	clients map[string]*client
+
+	syncLock   sync.Mutex
+	streamLock sync.Mutex
+}
+
+// NewSource constructs a [Source] by reading its configuration from environment variables and
+// the types configuration file they point to. It returns [ErrHTTPSource] if the configuration is
+// invalid.
+func NewSource() (*Source, error) {
+	cfg, err := loadConfigFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrHTTPSource, err)
+	}
+
+	types, err := loadTypesConfig(cfg.TypesConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrHTTPSource, err)
+	}
+
+	clients := make(map[string]*client, len(types))
+	for dataType, typeCfg := range types {
+		// tokens are requested with a context that outlives a single sync or poll
+		c, err := newClient(context.Background(), typeCfg, cfg.HTTPTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("%w: type %q: %w", ErrHTTPSource, dataType, err)
+		}
+		clients[dataType] = c
+	}
+
+	return &Source{
+		config:  *cfg,
+		clients: clients,
+	}, nil
+}
+
+// StartSyncProcess sends an upsert for every item of the requested types. Types that are not
+// declared in the configuration file are skipped, and a failing type does not stop the others.
+func (s *Source) StartSyncProcess(ctx context.Context, typesToSync map[string]source.Extra, results chan<- source.Data) error {
+	log := logger.FromContext(ctx).WithName(loggerName)
+	if !s.syncLock.TryLock() {
+		log.Debug("sync process already running")
+		return nil
+	}
+	defer s.syncLock.Unlock()
+
+	for _, dataType := range slices.Sorted(maps.Keys(typesToSync)) {
+		c, found := s.clients[dataType]
+		if !found {
+			log.Debug("skipping data type not declared in the configuration", "type", dataType)
+			continue
+		}
+
+		log.Trace("starting sync for data type", "type", dataType)
+		err := s.items(ctx, c, func(item map[string]any) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case results <- source.Data{
+				Type:      dataType,
+				Operation: source.DataOperationUpsert,
+				Values:    item,
+				Time:      timeSource(),
+			}:
+				return nil
+			}
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			log.Error("error syncing data type", "type", dataType, "error", err.Error())
+			continue
+		}
+
+		log.Trace("completed sync for data type", "type", dataType)
+	}
+
+	return nil
+}
+
+// StartEventStream polls the APIs of the requested types until ctx is cancelled. The first poll
+// sends every item, the following ones an upsert for the new and changed items and a delete for
+// the items no longer returned. A failing poll keeps the items of the previous one.
+func (s *Source) StartEventStream(ctx context.Context, typesToStream map[string]source.Extra, results chan<- source.Data) error {
+	log := logger.FromContext(ctx).WithName(loggerName)
+	if !s.streamLock.TryLock() {
+		log.Debug("event stream already running")
+		return nil
+	}
+	defer s.streamLock.Unlock()
+
+	state := make(map[string][]polledItem)
+	ticker := time.NewTicker(s.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for _, dataType := range slices.Sorted(maps.Keys(typesToStream)) {
+			c, found := s.clients[dataType]
+			if !found {
+				log.Debug("skipping data type not declared in the configuration", "type", dataType)
+				continue
+			}
+
+			if err := s.poll(ctx, dataType, c, state, results); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				log.Error("error polling data type, keeping its previous items", "type", dataType, "error", err.Error())
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// polledItem is an item returned by a poll together with its key.
+type polledItem struct {
+	key    string
+	values map[string]any
+}
+
+// poll reads the items of dataType, sends the differences with the ones in state to results, and
+// updates state.
+func (s *Source) poll(ctx context.Context, dataType string, c *client, state map[string][]polledItem, results chan<- source.Data) error {
+	log := logger.FromContext(ctx).WithName(loggerName)
+
+	items := make([]polledItem, 0)
+	err := s.items(ctx, c, func(item map[string]any) error {
+		key, err := itemKey(c.config, item)
+		if err != nil {
+			log.Error("error rendering item key, skipping", "type", dataType, "error", err.Error())
+			return nil
+		}
+		items = append(items, polledItem{key: key, values: item})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	oldItems := make(map[string]map[string]any, len(state[dataType]))
+	for _, item := range state[dataType] {
+		oldItems[item.key] = item.values
+	}
+	newKeys := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		newKeys[item.key] = struct{}{}
+	}
+
+	now := timeSource()
+	changes := make([]source.Data, 0)
+	// deletes go first, so an item whose key is its whole content is replaced and not removed
+	for _, item := range state[dataType] {
+		if _, found := newKeys[item.key]; !found {
+			changes = append(changes, source.Data{Type: dataType, Operation: source.DataOperationDelete, Values: item.values, Time: now})
+		}
+	}
+	for _, item := range items {
+		if old, found := oldItems[item.key]; !found || !reflect.DeepEqual(old, item.values) {
+			changes = append(changes, source.Data{Type: dataType, Operation: source.DataOperationUpsert, Values: item.values, Time: now})
+		}
+	}
+
+	for _, data := range changes {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case results <- data:
+		}
+	}
+
+	state[dataType] = items
+	return nil
+}
+
+// items calls handler with every item read by c, replaced by its details when configured. Items
+// whose details cannot be fetched are reported and skipped.
+func (s *Source) items(ctx context.Context, c *client, handler func(item map[string]any) error) error {
+	log := logger.FromContext(ctx).WithName(loggerName)
+	return c.listItems(ctx, func(items []map[string]any) error {
+		for _, item := range items {
+			if c.config.Detail != nil {
+				detail, err := c.detail(ctx, item)
+				if err != nil {
+					if ctx.Err() != nil {
+						return ctx.Err()
+					}
+					log.Error("error fetching item detail, skipping", "type", c.config.Type, "error", err.Error())
+					continue
+				}
+				item = detail
+			}
+
+			if err := handler(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// itemKey identifies item between two polls: the rendered key template, or its whole content when
+// no key is configured.
+func itemKey(cfg *typeConfig, item map[string]any) (string, error) {
+	if cfg.key == nil {
+		// the encoding of a map is stable because keys are sorted
+		encoded, err := json.Marshal(item)
+		return string(encoded), err
+	}
+
+	key := new(strings.Builder)
+	if err := cfg.key.Execute(key, item); err != nil {
+		return "", err
+	}
+	return key.String(), nil
+}
@@ -0,0 +1,214 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package httpsource
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+// testSource builds a source reading the given types.
+func testSource(t *testing.T, pollInterval time.Duration, types ...*typeConfig) *Source {
+	t.Helper()
+
+	s := &Source{
+		config:  config{PollInterval: pollInterval},
+		clients: make(map[string]*client, len(types)),
+	}
+	for _, cfg := range types {
+		s.clients[cfg.Type] = testClient(t, cfg)
+	}
+	return s
+}
+
+func TestStartSyncProcess(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/services", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(t, w, []any{map[string]any{"id": "1"}, map[string]any{"id": "2"}, map[string]any{"id": "3"}})
+	})
+	mux.HandleFunc("/services/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") == "2" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		writeJSON(t, w, map[string]any{"id": r.PathValue("id"), "detailed": true})
+	})
+	mux.HandleFunc("/teams", func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	})
+	mux.HandleFunc("/users", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(t, w, map[string]any{"users": []any{map[string]any{"name": "alice"}}})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	s := testSource(t, time.Minute,
+		&typeConfig{
+			Type:    "services",
+			Request: requestConfig{URL: server.URL + "/services"},
+			Detail:  &detailConfig{URL: server.URL + "/services/{{ .id }}"},
+		},
+		&typeConfig{Type: "teams", Request: requestConfig{URL: server.URL + "/teams"}},
+		&typeConfig{Type: "users", Request: requestConfig{URL: server.URL + "/users"}, Items: "$.users"},
+	)
+
+	results := make(chan source.Data, 100)
+	types := map[string]source.Extra{"services": nil, "teams": nil, "users": nil, "unknown": nil}
+	require.NoError(t, s.StartSyncProcess(t.Context(), types, results))
+	close(results)
+
+	received := make([]source.Data, 0)
+	for data := range results {
+		assert.False(t, data.Time.IsZero())
+		data.Time = time.Time{}
+		received = append(received, data)
+	}
+
+	assert.Equal(t, []source.Data{
+		{Type: "services", Operation: source.DataOperationUpsert, Values: map[string]any{"id": "1", "detailed": true}},
+		{Type: "services", Operation: source.DataOperationUpsert, Values: map[string]any{"id": "3", "detailed": true}},
+		{Type: "users", Operation: source.DataOperationUpsert, Values: map[string]any{"name": "alice"}},
+	}, received)
+}
+
+func TestStartEventStream(t *testing.T) {
+	t.Parallel()
+
+	polls := [][]any{
+		{map[string]any{"id": "1", "cpu": 4.0}, map[string]any{"id": "2", "cpu": 2.0}},
+		nil,
+		{map[string]any{"id": "1", "cpu": 8.0}, map[string]any{"id": "3", "cpu": 2.0}},
+	}
+
+	testCases := map[string]struct {
+		key      string
+		expected []source.Data
+	}{
+		"items are identified by the key": {
+			key: "{{ .id }}",
+			expected: []source.Data{
+				{Type: "servers", Operation: source.DataOperationDelete, Values: map[string]any{"id": "2", "cpu": 2.0}},
+				{Type: "servers", Operation: source.DataOperationUpsert, Values: map[string]any{"id": "1", "cpu": 8.0}},
+				{Type: "servers", Operation: source.DataOperationUpsert, Values: map[string]any{"id": "3", "cpu": 2.0}},
+			},
+		},
+		"items are identified by their content": {
+			expected: []source.Data{
+				{Type: "servers", Operation: source.DataOperationDelete, Values: map[string]any{"id": "1", "cpu": 4.0}},
+				{Type: "servers", Operation: source.DataOperationDelete, Values: map[string]any{"id": "2", "cpu": 2.0}},
+				{Type: "servers", Operation: source.DataOperationUpsert, Values: map[string]any{"id": "1", "cpu": 8.0}},
+				{Type: "servers", Operation: source.DataOperationUpsert, Values: map[string]any{"id": "3", "cpu": 2.0}},
+			},
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var poll atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				current := int(poll.Add(1)) - 1
+				switch {
+				case current >= len(polls):
+					writeJSON(t, w, polls[len(polls)-1])
+				case polls[current] == nil:
+					// a failing poll keeps the previous items
+					http.Error(w, "unavailable", http.StatusServiceUnavailable)
+				default:
+					writeJSON(t, w, polls[current])
+				}
+			}))
+			defer server.Close()
+
+			s := testSource(t, 20*time.Millisecond, &typeConfig{
+				Type:    "servers",
+				Request: requestConfig{URL: server.URL},
+				Key:     test.key,
+			})
+			results := make(chan source.Data, 100)
+
+			ctx, cancel := context.WithCancel(t.Context())
+			var wg sync.WaitGroup
+			wg.Go(func() {
+				assert.NoError(t, s.StartEventStream(ctx, map[string]source.Extra{"servers": nil}, results))
+			})
+			defer func() {
+				cancel()
+				wg.Wait()
+			}()
+
+			receive := func(t *testing.T, count int) []source.Data {
+				t.Helper()
+
+				received := make([]source.Data, 0, count)
+				for range count {
+					select {
+					case data := <-results:
+						assert.False(t, data.Time.IsZero())
+						data.Time = time.Time{}
+						received = append(received, data)
+					case <-time.After(5 * time.Second):
+						require.FailNow(t, "timeout waiting for data")
+					}
+				}
+				return received
+			}
+
+			assert.Equal(t, []source.Data{
+				{Type: "servers", Operation: source.DataOperationUpsert, Values: map[string]any{"id": "1", "cpu": 4.0}},
+				{Type: "servers", Operation: source.DataOperationUpsert, Values: map[string]any{"id": "2", "cpu": 2.0}},
+			}, receive(t, 2))
+			assert.Equal(t, test.expected, receive(t, len(test.expected)))
+
+			// unchanged polls send nothing
+			for poll.Load() < int32(len(polls)+2) {
+				time.Sleep(10 * time.Millisecond)
+			}
+			assert.Empty(t, results)
+		})
+	}
+}
+
+func TestNewSource(t *testing.T) {
+	t.Run("valid configuration", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "types.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("types:\n  - type: services\n    request: {url: https://example.com}\n"), 0o600))
+		t.Setenv("HTTP_SOURCE_CONFIG", path)
+
+		s, err := NewSource()
+		require.NoError(t, err)
+		assert.Contains(t, s.clients, "services")
+	})
+
+	t.Run("missing configuration", func(t *testing.T) {
+		_, err := NewSource()
+		require.ErrorIs(t, err, ErrHTTPSource)
+		assert.ErrorIs(t, err, ErrMissingEnvVariable)
+	})
+
+	t.Run("missing secret", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "types.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("types:\n  - type: services\n    request: {url: https://example.com}\n    auth: {type: bearer, tokenEnv: TEST_HTTP_SOURCE_TOKEN}\n"), 0o600))
+		t.Setenv("HTTP_SOURCE_CONFIG", path)
+
+		_, err := NewSource()
+		require.ErrorIs(t, err, ErrHTTPSource)
+		assert.ErrorIs(t, err, ErrMissingEnvVariable)
+	})
+}
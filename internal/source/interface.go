@@ -31,6 +31,14 @@ type ClosableSource interface {
 	Close(ctx context.Context, timeout time.Duration) (err error)
 }
 
+// TypesUpdatableSource can change the data types it handles while running.
+type TypesUpdatableSource interface {
+	// UpdateTypes replaces the data types handled by a running sync process or event stream.
+	// It can be called before the event stream has started, in which case types take precedence
+	// over the ones the stream is started with.
+	UpdateTypes(ctx context.Context, types map[string]Extra) (err error)
+}
+
 type WebhookSource interface {
 	// GetWebhook sets up webhooks for the specified data types, sending updates to results or returning an error.
 	// typesToStream lists the expected data types.
@@ -0,0 +1,47 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package kubernetes
+
+import (
+	"errors"
+
+	"github.com/caarlos0/env/v11"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// config holds the environment-driven Kubernetes settings.
+type config struct {
+	Kubeconfig string `env:"KUBERNETES_KUBECONFIG"`
+	Context    string `env:"KUBERNETES_CONTEXT"`
+}
+
+// loadConfigFromEnv parses configuration from environment variables.
+func loadConfigFromEnv() (*config, error) {
+	cfg, err := env.ParseAs[config]()
+	if err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// restConfig returns the configuration of the Kubernetes client. When neither a kubeconfig nor a
+// context is set, the in-cluster configuration is used if available, otherwise the kubeconfig is
+// loaded from the KUBECONFIG variable or from the default location.
+func (c config) restConfig() (*rest.Config, error) {
+	if len(c.Kubeconfig) == 0 && len(c.Context) == 0 {
+		restConfig, err := rest.InClusterConfig()
+		if err == nil {
+			return restConfig, nil
+		}
+		if !errors.Is(err, rest.ErrNotInCluster) {
+			return nil, err
+		}
+	}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = c.Kubeconfig
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: c.Context}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).ClientConfig()
+}
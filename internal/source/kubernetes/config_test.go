@@ -0,0 +1,86 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: dev
+  cluster:
+    server: https://dev.example.com
+- name: prod
+  cluster:
+    server: https://prod.example.com
+contexts:
+- name: dev
+  context:
+    cluster: dev
+    user: user
+- name: prod
+  context:
+    cluster: prod
+    user: user
+current-context: dev
+users:
+- name: user
+  user:
+    token: secret
+`
+
+func TestRestConfig(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	require.NoError(t, os.WriteFile(kubeconfig, []byte(testKubeconfig), 0o600))
+
+	t.Run("kubeconfig current context", func(t *testing.T) {
+		t.Setenv("KUBERNETES_KUBECONFIG", kubeconfig)
+
+		cfg, err := loadConfigFromEnv()
+		require.NoError(t, err)
+		restConfig, err := cfg.restConfig()
+		require.NoError(t, err)
+		assert.Equal(t, "https://dev.example.com", restConfig.Host)
+		assert.Equal(t, "secret", restConfig.BearerToken)
+	})
+
+	t.Run("kubeconfig context", func(t *testing.T) {
+		t.Setenv("KUBERNETES_KUBECONFIG", kubeconfig)
+		t.Setenv("KUBERNETES_CONTEXT", "prod")
+
+		cfg, err := loadConfigFromEnv()
+		require.NoError(t, err)
+		restConfig, err := cfg.restConfig()
+		require.NoError(t, err)
+		assert.Equal(t, "https://prod.example.com", restConfig.Host)
+	})
+
+	t.Run("default kubeconfig outside of a cluster", func(t *testing.T) {
+		t.Setenv("KUBERNETES_SERVICE_HOST", "")
+		t.Setenv("KUBECONFIG", kubeconfig)
+
+		cfg, err := loadConfigFromEnv()
+		require.NoError(t, err)
+		restConfig, err := cfg.restConfig()
+		require.NoError(t, err)
+		assert.Equal(t, "https://dev.example.com", restConfig.Host)
+	})
+
+	t.Run("unknown context", func(t *testing.T) {
+		t.Setenv("KUBERNETES_KUBECONFIG", kubeconfig)
+		t.Setenv("KUBERNETES_CONTEXT", "staging")
+
+		cfg, err := loadConfigFromEnv()
+		require.NoError(t, err)
+		_, err = cfg.restConfig()
+		assert.Error(t, err)
+	})
+}
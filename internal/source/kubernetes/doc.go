@@ -0,0 +1,6 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+// Package kubernetes provides a source implementation that lists and watches the Kubernetes
+// resources whose group, version and resource are used as data types, like apps/v1/deployments.
+package kubernetes
@@ -0,0 +1,218 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/mia-platform/ibdm/internal/logger"
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+const (
+	loggerName = "ibdm:source:kubernetes"
+
+	// listPageSize is the number of resources requested by every list call of a sync.
+	listPageSize = 500
+)
+
+var (
+	// ErrKubernetesSource wraps all errors originating from the Kubernetes source.
+	ErrKubernetesSource = errors.New("kubernetes source")
+
+	// timeSource is a package-level function for the current time, replaceable in tests.
+	timeSource = time.Now
+)
+
+var _ source.SyncableSource = &Source{}
+var _ source.EventSource = &Source{}
+
+// Source implements [source.SyncableSource] and [source.EventSource] for the Kubernetes resources
+// of any group, version and resource. The sync lists the resources, while the event stream watches
+// them with an informer per data type.
+type Source struct {
+	client dynamic.Interface
+
+	syncLock   sync.Mutex
+	streamLock sync.Mutex
+}
+
+// NewSource constructs a [Source] by reading its configuration from environment variables, using
+// the in-cluster configuration or a kubeconfig to authenticate. It returns [ErrKubernetesSource] if
+// the configuration is invalid.
+func NewSource() (*Source, error) {
+	cfg, err := loadConfigFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrKubernetesSource, err)
+	}
+
+	restConfig, err := cfg.restConfig()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrKubernetesSource, err)
+	}
+
+	client, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrKubernetesSource, err)
+	}
+
+	return &Source{client: client}, nil
+}
+
+// StartSyncProcess sends an upsert for every resource of the requested types. Types that cannot be
+// resolved to a resource are reported and skipped, and a failing type does not stop the others.
+func (s *Source) StartSyncProcess(ctx context.Context, typesToSync map[string]source.Extra, results chan<- source.Data) error {
+	log := logger.FromContext(ctx).WithName(loggerName)
+	if !s.syncLock.TryLock() {
+		log.Debug("sync process already running")
+		return nil
+	}
+	defer s.syncLock.Unlock()
+
+	for _, dataType := range slices.Sorted(maps.Keys(typesToSync)) {
+		res, err := newResource(dataType, typesToSync[dataType])
+		if err != nil {
+			log.Error("skipping data type", "type", dataType, "error", err.Error())
+			continue
+		}
+
+		log.Trace("starting sync for data type", "type", dataType)
+		if err := s.syncResource(ctx, res, results); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			log.Error("error syncing data type", "type", dataType, "error", err.Error())
+			continue
+		}
+		log.Trace("completed sync for data type", "type", dataType)
+	}
+
+	return nil
+}
+
+// syncResource lists the resources of res page by page, sending an upsert for each of them.
+func (s *Source) syncResource(ctx context.Context, res *resource, results chan<- source.Data) error {
+	options := metav1.ListOptions{Limit: listPageSize}
+	res.applySelectors(&options)
+
+	for {
+		list, err := s.client.Resource(res.gvr).Namespace(res.namespace).List(ctx, options)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range list.Items {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case results <- resourceData(res.dataType, source.DataOperationUpsert, &item):
+			}
+		}
+
+		options.Continue = list.GetContinue()
+		if len(options.Continue) == 0 {
+			return nil
+		}
+	}
+}
+
+// StartEventStream watches the resources of the requested types until ctx is cancelled. An upsert
+// is sent for every resource found when the stream starts and for every one added or changed
+// afterwards, and a delete for every one removed.
+func (s *Source) StartEventStream(ctx context.Context, typesToStream map[string]source.Extra, results chan<- source.Data) error {
+	log := logger.FromContext(ctx).WithName(loggerName)
+	if !s.streamLock.TryLock() {
+		log.Debug("event stream already running")
+		return nil
+	}
+	defer s.streamLock.Unlock()
+
+	var wg sync.WaitGroup
+	for _, dataType := range slices.Sorted(maps.Keys(typesToStream)) {
+		res, err := newResource(dataType, typesToStream[dataType])
+		if err != nil {
+			log.Error("skipping data type", "type", dataType, "error", err.Error())
+			continue
+		}
+
+		informer := dynamicinformer.NewFilteredDynamicInformer(s.client, res.gvr, res.namespace, 0, cache.Indexers{}, res.applySelectors).Informer()
+		if _, err := informer.AddEventHandler(eventHandler(ctx, res.dataType, results)); err != nil {
+			return fmt.Errorf("%w: %w", ErrKubernetesSource, err)
+		}
+		if err := informer.SetWatchErrorHandlerWithContext(func(ctx context.Context, _ *cache.Reflector, err error) {
+			logger.FromContext(ctx).WithName(loggerName).Error("error watching data type", "type", dataType, "error", err.Error())
+		}); err != nil {
+			return fmt.Errorf("%w: %w", ErrKubernetesSource, err)
+		}
+
+		log.Trace("starting watch for data type", "type", dataType)
+		wg.Go(func() {
+			informer.RunWithContext(ctx)
+		})
+	}
+
+	wg.Wait()
+	return nil
+}
+
+// eventHandler returns the informer handler sending the changes of the resources of dataType.
+func eventHandler(ctx context.Context, dataType string, results chan<- source.Data) cache.ResourceEventHandler {
+	send := func(operation source.DataOperation, obj any) {
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		item, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+		case results <- resourceData(dataType, operation, item):
+		}
+	}
+
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj any) {
+			send(source.DataOperationUpsert, obj)
+		},
+		UpdateFunc: func(oldObj, newObj any) {
+			oldItem, oldOk := oldObj.(*unstructured.Unstructured)
+			newItem, newOk := newObj.(*unstructured.Unstructured)
+			if oldOk && newOk && oldItem.GetResourceVersion() == newItem.GetResourceVersion() {
+				return
+			}
+			send(source.DataOperationUpsert, newObj)
+		},
+		DeleteFunc: func(obj any) {
+			send(source.DataOperationDelete, obj)
+		},
+	}
+}
+
+// resourceData returns the data of item, without its managed fields that only track which
+// manager set every field.
+func resourceData(dataType string, operation source.DataOperation, item *unstructured.Unstructured) source.Data {
+	values := item.DeepCopy().Object
+	unstructured.RemoveNestedField(values, "metadata", "managedFields")
+
+	return source.Data{
+		Type:      dataType,
+		Operation: operation,
+		Values:    values,
+		Time:      timeSource(),
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package kubernetes
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+var (
+	deploymentsGVR = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	podsGVR        = schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+)
+
+// newObject returns a resource of kind in namespace with the given labels.
+func newObject(apiVersion, kind, namespace, name string, labels map[string]any) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata": map[string]any{
+			"namespace":     namespace,
+			"name":          name,
+			"labels":        labels,
+			"managedFields": []any{map[string]any{"manager": "kubectl"}},
+		},
+	}}
+}
+
+// expectedValues returns the values sent for a resource built by newObject.
+func expectedValues(apiVersion, kind, namespace, name string, labels map[string]any) map[string]any {
+	return map[string]any{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata": map[string]any{
+			"namespace": namespace,
+			"name":      name,
+			"labels":    labels,
+		},
+	}
+}
+
+// testSource returns a source reading objects from a fake cluster.
+func testSource(objects ...runtime.Object) (*Source, *dynamicfake.FakeDynamicClient) {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		deploymentsGVR: "DeploymentList",
+		podsGVR:        "PodList",
+	}, objects...)
+	return &Source{client: client}, client
+}
+
+// withoutTime returns data with a zero time, after checking that it was set.
+func withoutTime(t *testing.T, data source.Data) source.Data {
+	t.Helper()
+
+	assert.False(t, data.Time.IsZero())
+	data.Time = time.Time{}
+	return data
+}
+
+func TestStartSyncProcess(t *testing.T) {
+	t.Parallel()
+
+	catalogLabels := map[string]any{"app": "catalog"}
+	s, _ := testSource(
+		newObject("apps/v1", "Deployment", "prod", "catalog", catalogLabels),
+		newObject("apps/v1", "Deployment", "prod", "billing", map[string]any{"app": "billing"}),
+		newObject("apps/v1", "Deployment", "dev", "catalog", catalogLabels),
+		newObject("v1", "Pod", "prod", "catalog-1", catalogLabels),
+	)
+
+	results := make(chan source.Data, 100)
+	types := map[string]source.Extra{
+		"apps/v1/deployments": {"namespace": "prod", "labelSelector": "app=catalog"},
+		"v1/pods":             nil,
+		"deployments":         nil,
+	}
+	require.NoError(t, s.StartSyncProcess(t.Context(), types, results))
+	close(results)
+
+	received := make([]source.Data, 0)
+	for data := range results {
+		received = append(received, withoutTime(t, data))
+	}
+
+	assert.Equal(t, []source.Data{
+		{Type: "apps/v1/deployments", Operation: source.DataOperationUpsert, Values: expectedValues("apps/v1", "Deployment", "prod", "catalog", catalogLabels)},
+		{Type: "v1/pods", Operation: source.DataOperationUpsert, Values: expectedValues("v1", "Pod", "prod", "catalog-1", catalogLabels)},
+	}, received)
+}
+
+func TestStartEventStream(t *testing.T) {
+	t.Parallel()
+
+	labels := map[string]any{"app": "catalog"}
+	s, client := testSource(newObject("apps/v1", "Deployment", "prod", "catalog", labels))
+	deployments := client.Resource(deploymentsGVR).Namespace("prod")
+
+	results := make(chan source.Data, 100)
+	ctx, cancel := context.WithCancel(t.Context())
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		assert.NoError(t, s.StartEventStream(ctx, map[string]source.Extra{"apps/v1/deployments": {"namespace": "prod"}}, results))
+	})
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	receive := func(t *testing.T) source.Data {
+		t.Helper()
+
+		select {
+		case data := <-results:
+			return withoutTime(t, data)
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "timeout waiting for data")
+			return source.Data{}
+		}
+	}
+
+	assert.Equal(t, source.Data{
+		Type:      "apps/v1/deployments",
+		Operation: source.DataOperationUpsert,
+		Values:    expectedValues("apps/v1", "Deployment", "prod", "catalog", labels),
+	}, receive(t))
+
+	_, err := deployments.Create(t.Context(), newObject("apps/v1", "Deployment", "prod", "billing", labels), metav1.CreateOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, source.Data{
+		Type:      "apps/v1/deployments",
+		Operation: source.DataOperationUpsert,
+		Values:    expectedValues("apps/v1", "Deployment", "prod", "billing", labels),
+	}, receive(t))
+
+	updatedLabels := map[string]any{"app": "catalog", "tier": "backend"}
+	updated := newObject("apps/v1", "Deployment", "prod", "catalog", updatedLabels)
+	updated.SetResourceVersion("2")
+	_, err = deployments.Update(t.Context(), updated, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	updatedData := receive(t)
+	assert.Equal(t, source.DataOperationUpsert, updatedData.Operation)
+	assert.Equal(t, updatedLabels, updatedData.Values["metadata"].(map[string]any)["labels"])
+
+	require.NoError(t, deployments.Delete(t.Context(), "billing", metav1.DeleteOptions{}))
+	deleted := receive(t)
+	assert.Equal(t, source.DataOperationDelete, deleted.Operation)
+	assert.Equal(t, "billing", deleted.Values["metadata"].(map[string]any)["name"])
+
+	// resources of other namespaces are not watched
+	_, err = client.Resource(deploymentsGVR).Namespace("dev").Create(t.Context(), newObject("apps/v1", "Deployment", "dev", "catalog", labels), metav1.CreateOptions{})
+	require.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+	assert.Empty(t, results)
+}
@@ -0,0 +1,95 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package kubernetes
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+const (
+	extraNamespaceKey     = "namespace"
+	extraLabelSelectorKey = "labelSelector"
+	extraFieldSelectorKey = "fieldSelector"
+
+	// coreTypeParts and groupTypeParts are the parts of the data types of the core resources, like
+	// v1/pods, and of the resources of a named group, like apps/v1/deployments.
+	coreTypeParts  = 2
+	groupTypeParts = 3
+)
+
+var (
+	errInvalidType  = errors.New("data type must be <group>/<version>/<resource> or <version>/<resource>")
+	errInvalidExtra = errors.New("invalid extra")
+)
+
+// resource is a data type resolved to the Kubernetes resources it reads.
+type resource struct {
+	dataType  string
+	gvr       schema.GroupVersionResource
+	namespace string
+	// labelSelector and fieldSelector filter the listed and watched resources.
+	labelSelector string
+	fieldSelector string
+}
+
+// newResource resolves dataType and its mapping extra. The type is the group, version and resource
+// separated by slashes, the group is omitted for the core resources, like v1/pods. The extra can
+// restrict the resources to a namespace and filter them with label and field selectors.
+func newResource(dataType string, extra source.Extra) (*resource, error) {
+	var gvr schema.GroupVersionResource
+	parts := strings.Split(dataType, "/")
+	switch len(parts) {
+	case coreTypeParts:
+		gvr = schema.GroupVersionResource{Version: parts[0], Resource: parts[1]}
+	case groupTypeParts:
+		gvr = schema.GroupVersionResource{Group: parts[0], Version: parts[1], Resource: parts[2]}
+	default:
+		return nil, fmt.Errorf("%w: %q", errInvalidType, dataType)
+	}
+	for _, part := range parts {
+		if len(part) == 0 {
+			return nil, fmt.Errorf("%w: %q", errInvalidType, dataType)
+		}
+	}
+
+	res := &resource{dataType: dataType, gvr: gvr}
+	var err error
+	if res.namespace, err = extraString(extra, extraNamespaceKey); err != nil {
+		return nil, err
+	}
+	if res.labelSelector, err = extraString(extra, extraLabelSelectorKey); err != nil {
+		return nil, err
+	}
+	if res.fieldSelector, err = extraString(extra, extraFieldSelectorKey); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// extraString returns the string value of key in extra, an empty string when it is not set.
+func extraString(extra source.Extra, key string) (string, error) {
+	value, found := extra[key]
+	if !found || value == nil {
+		return "", nil
+	}
+
+	stringValue, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("%w: %s must be a string", errInvalidExtra, key)
+	}
+	return stringValue, nil
+}
+
+// applySelectors sets the selectors of the resource on options.
+func (r *resource) applySelectors(options *metav1.ListOptions) {
+	options.LabelSelector = r.labelSelector
+	options.FieldSelector = r.fieldSelector
+}
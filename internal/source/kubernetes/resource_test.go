@@ -0,0 +1,75 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+func TestNewResource(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		dataType    string
+		extra       source.Extra
+		expected    *resource
+		expectedErr error
+	}{
+		"named group resource": {
+			dataType: "apps/v1/deployments",
+			expected: &resource{
+				dataType: "apps/v1/deployments",
+				gvr:      schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+			},
+		},
+		"core resource with extra": {
+			dataType: "v1/pods",
+			extra:    source.Extra{"namespace": "prod", "labelSelector": "app=catalog", "fieldSelector": "status.phase=Running", "other": 1},
+			expected: &resource{
+				dataType:      "v1/pods",
+				gvr:           schema.GroupVersionResource{Version: "v1", Resource: "pods"},
+				namespace:     "prod",
+				labelSelector: "app=catalog",
+				fieldSelector: "status.phase=Running",
+			},
+		},
+		"type without version": {
+			dataType:    "deployments",
+			expectedErr: errInvalidType,
+		},
+		"type with too many parts": {
+			dataType:    "apps/v1/deployments/scale",
+			expectedErr: errInvalidType,
+		},
+		"type with an empty part": {
+			dataType:    "apps//deployments",
+			expectedErr: errInvalidType,
+		},
+		"extra with a wrong type": {
+			dataType:    "v1/pods",
+			extra:       source.Extra{"namespace": []any{"prod"}},
+			expectedErr: errInvalidExtra,
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			res, err := newResource(test.dataType, test.extra)
+			if test.expectedErr != nil {
+				assert.ErrorIs(t, err, test.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+}
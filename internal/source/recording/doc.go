@@ -0,0 +1,6 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+// Package recording records the raw data emitted by a source and provides a source implementation
+// that reads it back, so that mappings can be exercised offline without the source credentials.
+package recording
@@ -0,0 +1,126 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package recording
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/mia-platform/ibdm/internal/mapper"
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+const (
+	recordExtension = ".ndjson"
+	// recordTimeFormat keeps the names of the recorded files sortable in the order they were created.
+	recordTimeFormat = "20060102T150405.000Z"
+
+	dirPermissions  = 0o750
+	filePermissions = 0o600
+)
+
+// failure describes why a recorded data could not be mapped, with the position of the failing
+// template inside its mapping file when known.
+type failure struct {
+	Message  string `json:"message"`
+	File     string `json:"file,omitempty"`
+	Document int    `json:"document"`
+	Field    string `json:"field,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+}
+
+// Recorder writes the raw data emitted by a source to a new NDJSON file, one source.Data per line.
+type Recorder struct {
+	file *os.File
+
+	lock sync.Mutex
+}
+
+// NewRecorder creates the directory dir if missing and a new file inside it named after the
+// integration name and the current time.
+func NewRecorder(dir, name string) (*Recorder, error) {
+	if err := os.MkdirAll(dir, dirPermissions); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrReplaySource, err)
+	}
+
+	fileName := fmt.Sprintf("%s-%s%s", name, time.Now().UTC().Format(recordTimeFormat), recordExtension)
+	file, err := os.OpenFile(filepath.Join(dir, fileName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, filePermissions)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrReplaySource, err)
+	}
+
+	return &Recorder{file: file}, nil
+}
+
+// Record appends data to the file.
+func (r *Recorder) Record(data source.Data) error {
+	line, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrReplaySource, err)
+	}
+
+	return r.write(line)
+}
+
+// RecordFailure appends data to the file together with the error that prevented its mapping, in
+// an error field ignored when the file is read back by Source.
+func (r *Recorder) RecordFailure(data source.Data, err error) error {
+	encoded, marshalErr := json.Marshal(data)
+	if marshalErr != nil {
+		return fmt.Errorf("%w: %w", ErrReplaySource, marshalErr)
+	}
+
+	fields := make(map[string]json.RawMessage)
+	if unmarshalErr := json.Unmarshal(encoded, &fields); unmarshalErr != nil {
+		return fmt.Errorf("%w: %w", ErrReplaySource, unmarshalErr)
+	}
+
+	dataFailure := failure{Message: err.Error()}
+	var mappingErr *mapper.MappingError
+	if errors.As(err, &mappingErr) {
+		dataFailure = failure{
+			Message:  mappingErr.Err.Error(),
+			File:     mappingErr.Path,
+			Document: mappingErr.Document,
+			Field:    mappingErr.Field,
+			Line:     mappingErr.Line,
+			Column:   mappingErr.Column,
+		}
+	}
+
+	if fields["error"], marshalErr = json.Marshal(dataFailure); marshalErr != nil {
+		return fmt.Errorf("%w: %w", ErrReplaySource, marshalErr)
+	}
+
+	line, marshalErr := json.Marshal(fields)
+	if marshalErr != nil {
+		return fmt.Errorf("%w: %w", ErrReplaySource, marshalErr)
+	}
+
+	return r.write(line)
+}
+
+// write appends line to the file followed by a new line.
+func (r *Recorder) write(line []byte) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, err := r.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("%w: %w", ErrReplaySource, err)
+	}
+
+	return nil
+}
+
+// Close closes the file.
+func (r *Recorder) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.file.Close()
+}
@@ -0,0 +1,131 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package recording
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/mia-platform/ibdm/internal/logger"
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+const (
+	loggerName = "ibdm:source:recording"
+
+	// maxRecordSize bounds the length of a single recorded line.
+	maxRecordSize = 10 * 1024 * 1024
+)
+
+var (
+	// ErrReplaySource wraps errors emitted by the replay source implementation.
+	ErrReplaySource = errors.New("replay source")
+
+	errMissingDir = errors.New("the directory of the recorded data is required")
+)
+
+var _ source.SyncableSource = &Source{}
+var _ source.EventSource = &Source{}
+
+// Source implements source.SyncableSource and source.EventSource by reading back the data
+// recorded by a Recorder.
+type Source struct {
+	dir string
+}
+
+// NewSource creates a Source reading the recorded files found in dir.
+func NewSource(dir string) (*Source, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("%w: %w", ErrReplaySource, errMissingDir)
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrReplaySource, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%w: %q is not a directory", ErrReplaySource, dir)
+	}
+
+	return &Source{dir: dir}, nil
+}
+
+// StartSyncProcess implements source.SyncableSource.
+func (s *Source) StartSyncProcess(ctx context.Context, typesToSync map[string]source.Extra, results chan<- source.Data) error {
+	return s.replay(ctx, typesToSync, results)
+}
+
+// StartEventStream implements source.EventSource. The stream ends once every recorded data has
+// been sent.
+func (s *Source) StartEventStream(ctx context.Context, typesToStream map[string]source.Extra, results chan<- source.Data) error {
+	return s.replay(ctx, typesToStream, results)
+}
+
+// replay sends the recorded data of the requested types to results, reading the files in name
+// order and every file from the top, so the same files always produce the same sequence.
+func (s *Source) replay(ctx context.Context, types map[string]source.Extra, results chan<- source.Data) error {
+	log := logger.FromContext(ctx).WithName(loggerName)
+
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*"+recordExtension))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrReplaySource, err)
+	}
+	slices.Sort(paths)
+
+	for _, path := range paths {
+		log.Debug("replaying recorded data", "path", path)
+		if err := replayFile(ctx, path, types, results); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// replayFile sends the recorded data of the requested types found in path to results.
+func replayFile(ctx context.Context, path string, types map[string]source.Extra, results chan<- source.Data) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrReplaySource, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRecordSize)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var data source.Data
+		if err := json.Unmarshal(line, &data); err != nil {
+			return fmt.Errorf("%w: %s: line %d: %w", ErrReplaySource, filepath.Base(path), lineNumber, err)
+		}
+
+		if _, found := types[data.Type]; !found {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case results <- data:
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrReplaySource, filepath.Base(path), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,210 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package recording
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mia-platform/ibdm/internal/mapper"
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+var (
+	testTime = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	testUpsert = source.Data{
+		Type:      "repository",
+		Operation: source.DataOperationUpsert,
+		Values:    map[string]any{"id": "1", "name": "first"},
+		Time:      testTime,
+	}
+	testDelete = source.Data{
+		Type:      "repository",
+		Operation: source.DataOperationDelete,
+		Values:    map[string]any{"id": "1"},
+		Time:      testTime.Add(time.Second),
+	}
+	testPipeline = source.Data{
+		Type:      "pipeline",
+		Operation: source.DataOperationUpsert,
+		Values:    map[string]any{"id": "2"},
+		Time:      testTime,
+	}
+)
+
+// collect runs start and returns the data it sends.
+func collect(t *testing.T, start func(context.Context, map[string]source.Extra, chan<- source.Data) error, types map[string]source.Extra) ([]source.Data, error) {
+	t.Helper()
+
+	results := make(chan source.Data)
+	collected := make([]source.Data, 0)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for data := range results {
+			collected = append(collected, data)
+		}
+	}()
+
+	err := start(t.Context(), types, results)
+	close(results)
+	<-done
+	return collected, err
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	recorder, err := NewRecorder(filepath.Join(dir, "nested"), "gitlab")
+	require.NoError(t, err)
+	for _, data := range []source.Data{testUpsert, testPipeline, testDelete} {
+		require.NoError(t, recorder.Record(data))
+	}
+	require.NoError(t, recorder.Close())
+
+	recordedSource, err := NewSource(filepath.Join(dir, "nested"))
+	require.NoError(t, err)
+
+	types := map[string]source.Extra{"repository": nil}
+	synced, err := collect(t, recordedSource.StartSyncProcess, types)
+	require.NoError(t, err)
+	assert.Equal(t, []source.Data{testUpsert, testDelete}, synced)
+
+	streamed, err := collect(t, recordedSource.StartEventStream, types)
+	require.NoError(t, err)
+	assert.Equal(t, synced, streamed, "the same files always produce the same data")
+}
+
+func TestRecordFailure(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	recorder, err := NewRecorder(dir, "gitlab")
+	require.NoError(t, err)
+
+	mappingErr := &mapper.MappingError{
+		Path:   "mappings/repository.yaml",
+		Field:  "spec.name",
+		Line:   7,
+		Column: 5,
+		Type:   "repository",
+		Err:    errors.New("map has no entry for key \"name\""),
+	}
+	require.NoError(t, recorder.RecordFailure(testUpsert, mappingErr))
+	require.NoError(t, recorder.RecordFailure(testDelete, errors.New("plain error")))
+	require.NoError(t, recorder.Close())
+
+	files, err := filepath.Glob(filepath.Join(dir, "gitlab-*.ndjson"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	content, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.Equal(t, `{"error":{"message":"map has no entry for key \"name\"","file":"mappings/repository.yaml","document":0,"field":"spec.name","line":7,"column":5},"operation":"upsert","time":"2024-06-01T12:00:00Z","type":"repository","values":{"id":"1","name":"first"}}
+{"error":{"message":"plain error","document":0},"operation":"delete","time":"2024-06-01T12:00:01Z","type":"repository","values":{"id":"1"}}
+`, string(content))
+
+	recordedSource, err := NewSource(dir)
+	require.NoError(t, err)
+	synced, err := collect(t, recordedSource.StartSyncProcess, map[string]source.Extra{"repository": nil})
+	require.NoError(t, err)
+	assert.Equal(t, []source.Data{testUpsert, testDelete}, synced, "failed data can be replayed")
+}
+
+func TestReplayOrder(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"gitlab-20240601T120001.000Z.ndjson": `{"type":"repository","operation":"delete","values":{"id":"1"},"time":"2024-06-01T12:00:01Z"}` + "\n",
+		"gitlab-20240601T120000.000Z.ndjson": `{"type":"repository","values":{"id":"1","name":"first"},"time":"2024-06-01T12:00:00Z"}` + "\n\n",
+		"notes.txt":                          "not recorded data",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	recordedSource, err := NewSource(dir)
+	require.NoError(t, err)
+
+	synced, err := collect(t, recordedSource.StartSyncProcess, map[string]source.Extra{"repository": nil})
+	require.NoError(t, err)
+	assert.Equal(t, []source.Data{testUpsert, testDelete}, synced)
+}
+
+func TestReplayErrors(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		content     string
+		expectedErr string
+	}{
+		"invalid json": {
+			content:     `{"type":"repository"`,
+			expectedErr: "replay source: gitlab.ndjson: line 1: unexpected end of JSON input",
+		},
+		"unknown operation": {
+			content:     `{"type":"repository","operation":"patch","values":{}}`,
+			expectedErr: `replay source: gitlab.ndjson: line 1: unknown operation "patch"`,
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "gitlab.ndjson"), []byte(test.content), 0o600))
+
+			recordedSource, err := NewSource(dir)
+			require.NoError(t, err)
+
+			_, err = collect(t, recordedSource.StartSyncProcess, map[string]source.Extra{"repository": nil})
+			assert.ErrorIs(t, err, ErrReplaySource)
+			assert.EqualError(t, err, test.expectedErr)
+		})
+	}
+}
+
+func TestNewSource(t *testing.T) {
+	t.Parallel()
+
+	file := filepath.Join(t.TempDir(), "file.ndjson")
+	require.NoError(t, os.WriteFile(file, nil, 0o600))
+
+	testCases := map[string]struct {
+		dir         string
+		expectedErr string
+	}{
+		"missing directory": {
+			expectedErr: "replay source: the directory of the recorded data is required",
+		},
+		"not existing directory": {
+			dir:         filepath.Join(t.TempDir(), "missing"),
+			expectedErr: "no such file or directory",
+		},
+		"file instead of directory": {
+			dir:         file,
+			expectedErr: "is not a directory",
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewSource(test.dir)
+			assert.ErrorIs(t, err, ErrReplaySource)
+			assert.ErrorContains(t, err, test.expectedErr)
+		})
+	}
+}
@@ -15,3 +15,19 @@ type Webhook struct {
 	Path    string
 	Handler WebhookHandler
 }
+
+// webhookPathKey is the context key of the path of a webhook request.
+type webhookPathKey struct{}
+
+// ContextWithWebhookPath returns a copy of ctx carrying the path of the webhook request being
+// handled, so handlers registered with a wildcard path can route the requests they receive.
+func ContextWithWebhookPath(ctx context.Context, path string) context.Context {
+	return context.WithValue(ctx, webhookPathKey{}, path)
+}
+
+// WebhookPathFromContext returns the path of the webhook request carried by ctx, or an empty
+// string when ctx does not carry one.
+func WebhookPathFromContext(ctx context.Context) string {
+	path, _ := ctx.Value(webhookPathKey{}).(string)
+	return path
+}
@@ -0,0 +1,215 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/caarlos0/env/v11"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	verificationTypeNone  = "none"
+	verificationTypeHMAC  = "hmac"
+	verificationTypeToken = "token"
+	verificationTypeBasic = "basic"
+
+	hmacAlgorithmSHA1   = "sha1"
+	hmacAlgorithmSHA256 = "sha256"
+	hmacAlgorithmSHA512 = "sha512"
+
+	hmacEncodingHex    = "hex"
+	hmacEncodingBase64 = "base64"
+)
+
+var (
+	// ErrMissingEnvVariable reports missing mandatory environment variables.
+	ErrMissingEnvVariable = errors.New("missing environment variable")
+	// ErrInvalidEnvVariable reports malformed environment variable values.
+	ErrInvalidEnvVariable = errors.New("invalid environment value")
+	// ErrInvalidRoutesConfig reports an invalid routes configuration file.
+	ErrInvalidRoutesConfig = errors.New("invalid routes configuration")
+)
+
+// config holds the environment-driven webhook source settings.
+type config struct {
+	RoutesConfigPath string `env:"WEBHOOK_SOURCE_CONFIG"`
+	WebhookPath      string `env:"WEBHOOK_SOURCE_PATH" envDefault:"/webhook"`
+}
+
+// routesConfig is the content of the routes configuration file.
+type routesConfig struct {
+	Routes []routeConfig `yaml:"routes"`
+}
+
+// routeConfig declares how the events received on a path are verified and read.
+type routeConfig struct {
+	// Path is relative to the webhook path, an empty path routes the webhook path itself.
+	Path         string             `yaml:"path"`
+	Verification verificationConfig `yaml:"verification"`
+	// Type, Operation and Time are templates rendered with every item of the event payload.
+	Type      string `yaml:"type"`
+	Operation string `yaml:"operation"`
+	Time      string `yaml:"time"`
+
+	dataType  *template.Template
+	operation *template.Template
+	time      *template.Template
+}
+
+// verificationConfig declares how the requests of a route are authenticated, secrets are read from
+// the environment variables named by the *Env fields so they are never written in the file.
+type verificationConfig struct {
+	Type string `yaml:"type"`
+	// Header and Prefix locate the signature or the token in the request headers.
+	Header string `yaml:"header"`
+	Prefix string `yaml:"prefix"`
+	// Algorithm and Encoding configure the HMAC signature.
+	Algorithm   string `yaml:"algorithm"`
+	Encoding    string `yaml:"encoding"`
+	SecretEnv   string `yaml:"secretEnv"`
+	UsernameEnv string `yaml:"usernameEnv"`
+	PasswordEnv string `yaml:"passwordEnv"`
+}
+
+// loadConfigFromEnv parses configuration from environment variables and
+// validates the result.
+func loadConfigFromEnv() (*config, error) {
+	cfg, err := env.ParseAs[config]()
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// validate checks that all required fields are present and that the webhook path is absolute.
+func (c *config) validate() error {
+	if len(c.RoutesConfigPath) == 0 {
+		return fmt.Errorf("%w: %s", ErrMissingEnvVariable, "WEBHOOK_SOURCE_CONFIG")
+	}
+	if !strings.HasPrefix(c.WebhookPath, "/") {
+		return fmt.Errorf("%w: WEBHOOK_SOURCE_PATH must start with /, got %q", ErrInvalidEnvVariable, c.WebhookPath)
+	}
+	c.WebhookPath = strings.TrimRight(c.WebhookPath, "/")
+	return nil
+}
+
+// loadRoutesConfig reads and validates the routes configuration file at path, returning the
+// routes by their path.
+func loadRoutesConfig(path string) (map[string]*routeConfig, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg routesConfig
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRoutesConfig, err)
+	}
+	if len(cfg.Routes) == 0 {
+		return nil, fmt.Errorf("%w: no route declared", ErrInvalidRoutesConfig)
+	}
+
+	routes := make(map[string]*routeConfig, len(cfg.Routes))
+	for index := range cfg.Routes {
+		route := &cfg.Routes[index]
+		route.Path = strings.Trim(route.Path, "/")
+		if err := route.validate(); err != nil {
+			return nil, fmt.Errorf("%w: route %q: %w", ErrInvalidRoutesConfig, route.Path, err)
+		}
+		if _, found := routes[route.Path]; found {
+			return nil, fmt.Errorf("%w: route %q is declared more than once", ErrInvalidRoutesConfig, route.Path)
+		}
+		routes[route.Path] = route
+	}
+
+	return routes, nil
+}
+
+// validate checks the route configuration, applies the defaults and parses its templates.
+func (c *routeConfig) validate() error {
+	if err := c.Verification.validate(); err != nil {
+		return err
+	}
+
+	if len(c.Type) == 0 {
+		return errors.New("missing type")
+	}
+
+	var err error
+	if c.dataType, err = parseTemplate("type", c.Type); err != nil {
+		return err
+	}
+	if c.operation, err = parseTemplate("operation", c.Operation); err != nil {
+		return err
+	}
+	if c.time, err = parseTemplate("time", c.Time); err != nil {
+		return err
+	}
+	return nil
+}
+
+// validate checks the verification configuration and applies the defaults.
+func (c *verificationConfig) validate() error {
+	switch c.Type {
+	case "":
+		return fmt.Errorf("missing verification type, use %q to accept unsigned requests", verificationTypeNone)
+	case verificationTypeNone:
+	case verificationTypeHMAC:
+		return c.validateHMAC()
+	case verificationTypeToken:
+		if len(c.Header) == 0 || len(c.SecretEnv) == 0 {
+			return errors.New("token verification requires header and secretEnv")
+		}
+	case verificationTypeBasic:
+		if len(c.UsernameEnv) == 0 || len(c.PasswordEnv) == 0 {
+			return errors.New("basic verification requires usernameEnv and passwordEnv")
+		}
+	default:
+		return fmt.Errorf("unknown verification type %q", c.Type)
+	}
+	return nil
+}
+
+// validateHMAC checks the HMAC verification configuration and applies its defaults.
+func (c *verificationConfig) validateHMAC() error {
+	if len(c.Header) == 0 || len(c.SecretEnv) == 0 {
+		return errors.New("hmac verification requires header and secretEnv")
+	}
+	if len(c.Algorithm) == 0 {
+		c.Algorithm = hmacAlgorithmSHA256
+	}
+	if _, found := hmacAlgorithms[c.Algorithm]; !found {
+		return fmt.Errorf("unknown hmac algorithm %q", c.Algorithm)
+	}
+	switch c.Encoding {
+	case "":
+		c.Encoding = hmacEncodingHex
+	case hmacEncodingHex, hmacEncodingBase64:
+	default:
+		return fmt.Errorf("unknown hmac encoding %q", c.Encoding)
+	}
+	return nil
+}
+
+// parseTemplate parses text as the template name, returning nil for an empty text. The header
+// function is a placeholder replaced with the headers of every request.
+func parseTemplate(name, text string) (*template.Template, error) {
+	if len(text) == 0 {
+		return nil, nil
+	}
+
+	return template.New(name).
+		Option("missingkey=error").
+		Funcs(template.FuncMap{"header": func(string) string { return "" }}).
+		Parse(text)
+}
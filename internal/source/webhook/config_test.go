@@ -0,0 +1,151 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package webhook
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfigFromEnv(t *testing.T) {
+	t.Run("valid full configuration", func(t *testing.T) {
+		t.Setenv("WEBHOOK_SOURCE_CONFIG", "routes.yaml")
+		t.Setenv("WEBHOOK_SOURCE_PATH", "/hooks/")
+
+		cfg, err := loadConfigFromEnv()
+		require.NoError(t, err)
+		assert.Equal(t, "routes.yaml", cfg.RoutesConfigPath)
+		assert.Equal(t, "/hooks", cfg.WebhookPath)
+	})
+
+	t.Run("valid minimal configuration with defaults", func(t *testing.T) {
+		t.Setenv("WEBHOOK_SOURCE_CONFIG", "routes.yaml")
+
+		cfg, err := loadConfigFromEnv()
+		require.NoError(t, err)
+		assert.Equal(t, "/webhook", cfg.WebhookPath)
+	})
+
+	t.Run("missing configuration file", func(t *testing.T) {
+		_, err := loadConfigFromEnv()
+		assert.ErrorIs(t, err, ErrMissingEnvVariable)
+	})
+
+	t.Run("relative webhook path", func(t *testing.T) {
+		t.Setenv("WEBHOOK_SOURCE_CONFIG", "routes.yaml")
+		t.Setenv("WEBHOOK_SOURCE_PATH", "hooks")
+
+		_, err := loadConfigFromEnv()
+		assert.ErrorIs(t, err, ErrInvalidEnvVariable)
+	})
+}
+
+func TestLoadRoutesConfig(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		content      string
+		expectedErr  string
+		assertRoutes func(t *testing.T, routes map[string]*routeConfig)
+	}{
+		"paths are normalized and defaults applied": {
+			content: `
+routes:
+  - path: /deployments/
+    verification:
+      type: hmac
+      header: X-Signature
+      secretEnv: SECRET
+    type: deployments
+  - verification:
+      type: none
+    type: "{{ .kind }}"
+    operation: "{{ .action }}"
+    time: "{{ .timestamp }}"
+`,
+			assertRoutes: func(t *testing.T, routes map[string]*routeConfig) {
+				t.Helper()
+
+				require.Contains(t, routes, "deployments")
+				deployments := routes["deployments"]
+				assert.Equal(t, hmacAlgorithmSHA256, deployments.Verification.Algorithm)
+				assert.Equal(t, hmacEncodingHex, deployments.Verification.Encoding)
+				assert.NotNil(t, deployments.dataType)
+				assert.Nil(t, deployments.operation)
+				assert.Nil(t, deployments.time)
+
+				require.Contains(t, routes, "")
+				assert.NotNil(t, routes[""].operation)
+				assert.NotNil(t, routes[""].time)
+			},
+		},
+		"no routes": {
+			content:     "routes: []\n",
+			expectedErr: "invalid routes configuration: no route declared",
+		},
+		"duplicated route": {
+			content:     "routes:\n  - {path: a, type: t, verification: {type: none}}\n  - {path: /a, type: t, verification: {type: none}}\n",
+			expectedErr: `invalid routes configuration: route "a" is declared more than once`,
+		},
+		"missing verification": {
+			content:     "routes:\n  - {path: a, type: t}\n",
+			expectedErr: `invalid routes configuration: route "a": missing verification type, use "none" to accept unsigned requests`,
+		},
+		"unknown verification": {
+			content:     "routes:\n  - {path: a, type: t, verification: {type: jwt}}\n",
+			expectedErr: `invalid routes configuration: route "a": unknown verification type "jwt"`,
+		},
+		"incomplete hmac verification": {
+			content:     "routes:\n  - {path: a, type: t, verification: {type: hmac, header: X-Signature}}\n",
+			expectedErr: `invalid routes configuration: route "a": hmac verification requires header and secretEnv`,
+		},
+		"unknown hmac algorithm": {
+			content:     "routes:\n  - {path: a, type: t, verification: {type: hmac, header: X-Signature, secretEnv: S, algorithm: md5}}\n",
+			expectedErr: `invalid routes configuration: route "a": unknown hmac algorithm "md5"`,
+		},
+		"unknown hmac encoding": {
+			content:     "routes:\n  - {path: a, type: t, verification: {type: hmac, header: X-Signature, secretEnv: S, encoding: base32}}\n",
+			expectedErr: `invalid routes configuration: route "a": unknown hmac encoding "base32"`,
+		},
+		"incomplete token verification": {
+			content:     "routes:\n  - {path: a, type: t, verification: {type: token, secretEnv: S}}\n",
+			expectedErr: `invalid routes configuration: route "a": token verification requires header and secretEnv`,
+		},
+		"incomplete basic verification": {
+			content:     "routes:\n  - {path: a, type: t, verification: {type: basic, usernameEnv: U}}\n",
+			expectedErr: `invalid routes configuration: route "a": basic verification requires usernameEnv and passwordEnv`,
+		},
+		"missing type": {
+			content:     "routes:\n  - {path: a, verification: {type: none}}\n",
+			expectedErr: `invalid routes configuration: route "a": missing type`,
+		},
+		"invalid template": {
+			content:     "routes:\n  - {path: a, type: t, operation: \"{{ .action\", verification: {type: none}}\n",
+			expectedErr: `invalid routes configuration: route "a": template: operation:1: unclosed action`,
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "routes.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(test.content), 0o600))
+
+			routes, err := loadRoutesConfig(path)
+			if len(test.expectedErr) > 0 {
+				require.ErrorIs(t, err, ErrInvalidRoutesConfig)
+				assert.EqualError(t, err, test.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			test.assertRoutes(t, routes)
+		})
+	}
+}
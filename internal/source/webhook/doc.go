@@ -0,0 +1,7 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+// Package webhook provides a source implementation that receives the JSON events pushed by any
+// producer, verifying them with a configurable scheme and extracting their data type, operation
+// and time with templates declared per route.
+package webhook
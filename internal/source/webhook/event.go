@@ -0,0 +1,153 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+const (
+	operationUpsert = "upsert"
+	operationDelete = "delete"
+)
+
+var (
+	errInvalidPayload = errors.New("payload must be an object or a list of objects")
+)
+
+// route verifies and reads the events received on a path.
+type route struct {
+	config *routeConfig
+	verify verifier
+}
+
+// data returns the data of the items in the JSON body whose type is in types. Items whose type
+// renders to an empty string are ignored, so a route can skip events like pings.
+func (r *route) data(headers http.Header, body []byte, types map[string]source.Extra) ([]source.Data, error) {
+	items, err := payloadItems(body)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]source.Data, 0, len(items))
+	for _, item := range items {
+		dataType, err := render(r.config.dataType, headers, item)
+		if err != nil {
+			return nil, fmt.Errorf("rendering type: %w", err)
+		}
+		if _, found := types[dataType]; !found {
+			continue
+		}
+
+		operation, err := r.operation(headers, item)
+		if err != nil {
+			return nil, err
+		}
+
+		eventTime, err := r.time(headers, item)
+		if err != nil {
+			return nil, err
+		}
+
+		data = append(data, source.Data{
+			Type:      dataType,
+			Operation: operation,
+			Values:    item,
+			Time:      eventTime,
+		})
+	}
+
+	return data, nil
+}
+
+// operation renders the operation of item, an upsert when the route does not declare one.
+func (r *route) operation(headers http.Header, item map[string]any) (source.DataOperation, error) {
+	operation, err := render(r.config.operation, headers, item)
+	if err != nil {
+		return source.DataOperationUpsert, fmt.Errorf("rendering operation: %w", err)
+	}
+
+	switch operation {
+	case "", operationUpsert:
+		return source.DataOperationUpsert, nil
+	case operationDelete:
+		return source.DataOperationDelete, nil
+	default:
+		return source.DataOperationUpsert, fmt.Errorf("unknown operation %q", operation)
+	}
+}
+
+// time renders the time of item as an RFC 3339 date or as Unix seconds, the current time when the
+// route does not declare one or it renders to an empty string.
+func (r *route) time(headers http.Header, item map[string]any) (time.Time, error) {
+	value, err := render(r.config.time, headers, item)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("rendering time: %w", err)
+	}
+	if len(value) == 0 {
+		return timeSource(), nil
+	}
+
+	if eventTime, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return eventTime, nil
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: must be an RFC 3339 date or Unix seconds", value)
+}
+
+// payloadItems decodes body as a single item or a list of items.
+func payloadItems(body []byte) ([]map[string]any, error) {
+	var payload any
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidPayload, err)
+	}
+
+	switch typed := payload.(type) {
+	case map[string]any:
+		return []map[string]any{typed}, nil
+	case []any:
+		items := make([]map[string]any, 0, len(typed))
+		for _, value := range typed {
+			item, ok := value.(map[string]any)
+			if !ok {
+				return nil, errInvalidPayload
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	default:
+		return nil, errInvalidPayload
+	}
+}
+
+// render executes tmpl with item, with the header function returning the request headers. It
+// returns an empty string when tmpl is nil.
+func render(tmpl *template.Template, headers http.Header, item map[string]any) (string, error) {
+	if tmpl == nil {
+		return "", nil
+	}
+
+	requestTemplate, err := tmpl.Clone()
+	if err != nil {
+		return "", err
+	}
+	requestTemplate.Funcs(template.FuncMap{"header": headers.Get})
+
+	output := new(strings.Builder)
+	if err := requestTemplate.Execute(output, item); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output.String()), nil
+}
@@ -0,0 +1,99 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package webhook
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+func TestRouteOperation(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		template    string
+		expected    source.DataOperation
+		expectedErr string
+	}{
+		"no template is an upsert": {
+			expected: source.DataOperationUpsert,
+		},
+		"upsert": {
+			template: "{{ .action }}",
+			expected: source.DataOperationUpsert,
+		},
+		"delete with spaces": {
+			template: "  {{ if .deleted }}delete{{ end }}\n",
+			expected: source.DataOperationDelete,
+		},
+		"unknown operation": {
+			template:    "{{ .action }}d",
+			expectedErr: `unknown operation "upsertd"`,
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &routeConfig{Type: "items", Operation: test.template, Verification: verificationConfig{Type: verificationTypeNone}}
+			require.NoError(t, cfg.validate())
+			r := &route{config: cfg}
+
+			operation, err := r.operation(http.Header{}, map[string]any{"action": "upsert", "deleted": true})
+			if len(test.expectedErr) > 0 {
+				assert.EqualError(t, err, test.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, operation)
+		})
+	}
+}
+
+func TestPayloadItems(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		body        string
+		expected    []map[string]any
+		expectedErr error
+	}{
+		"single object": {
+			body:     `{"id":"1"}`,
+			expected: []map[string]any{{"id": "1"}},
+		},
+		"list of objects": {
+			body:     `[{"id":"1"},{"id":"2"}]`,
+			expected: []map[string]any{{"id": "1"}, {"id": "2"}},
+		},
+		"scalar": {
+			body:        `"id"`,
+			expectedErr: errInvalidPayload,
+		},
+		"not json": {
+			body:        `id=1`,
+			expectedErr: errInvalidPayload,
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			items, err := payloadItems([]byte(test.body))
+			if test.expectedErr != nil {
+				assert.ErrorIs(t, err, test.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, items)
+		})
+	}
+}
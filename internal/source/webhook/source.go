@@ -0,0 +1,112 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mia-platform/ibdm/internal/logger"
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+const (
+	loggerName = "ibdm:source:webhook"
+)
+
+var (
+	// ErrWebhookSource wraps all errors originating from the webhook source.
+	ErrWebhookSource = errors.New("webhook source")
+
+	errUnknownRoute = errors.New("no route declared for path")
+
+	// timeSource is a package-level function for the current time, replaceable in tests.
+	timeSource = time.Now
+)
+
+var _ source.WebhookSource = &Source{}
+
+// Source implements [source.WebhookSource] for the producers declared in the routes configuration
+// file. Every route is served under the webhook path and has its own verification scheme and
+// templates extracting the data type, operation and time of the received events.
+type Source struct {
+	config config
+	routes map[string]*route
+}
+
+// NewSource constructs a [Source] by reading its configuration from environment variables and
+// the routes configuration file they point to. It returns [ErrWebhookSource] if the configuration
+// is invalid.
+func NewSource() (*Source, error) {
+	cfg, err := loadConfigFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrWebhookSource, err)
+	}
+
+	routesConfig, err := loadRoutesConfig(cfg.RoutesConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrWebhookSource, err)
+	}
+
+	routes := make(map[string]*route, len(routesConfig))
+	for path, routeCfg := range routesConfig {
+		verify, err := newVerifier(routeCfg.Verification)
+		if err != nil {
+			return nil, fmt.Errorf("%w: route %q: %w", ErrWebhookSource, path, err)
+		}
+		routes[path] = &route{config: routeCfg, verify: verify}
+	}
+
+	return &Source{
+		config: *cfg,
+		routes: routes,
+	}, nil
+}
+
+// GetWebhook implements source.WebhookSource. It returns a Webhook serving every path under the
+// webhook path: a request is routed by its path relative to the webhook path, verified with the
+// scheme of its route, and its payload items are sent to results when their type is in
+// typesToStream.
+func (s *Source) GetWebhook(_ context.Context, typesToStream map[string]source.Extra, results chan<- source.Data) (source.Webhook, error) {
+	return source.Webhook{
+		Method: http.MethodPost,
+		Path:   s.config.WebhookPath + "/*",
+		Handler: func(ctx context.Context, headers http.Header, body []byte) error {
+			log := logger.FromContext(ctx).WithName(loggerName)
+
+			routePath := strings.Trim(strings.TrimPrefix(source.WebhookPathFromContext(ctx), s.config.WebhookPath), "/")
+			route, found := s.routes[routePath]
+			if !found {
+				err := fmt.Errorf("%w: %w %q", ErrWebhookSource, errUnknownRoute, routePath)
+				log.Error("webhook request for an unknown route", "error", err.Error())
+				return err
+			}
+
+			if err := route.verify(headers, body); err != nil {
+				err = fmt.Errorf("%w: %w", ErrWebhookSource, err)
+				log.Error("webhook request rejected", "route", routePath, "error", err.Error())
+				return err
+			}
+
+			data, err := route.data(headers, body, typesToStream)
+			if err != nil {
+				err = fmt.Errorf("%w: %w", ErrWebhookSource, err)
+				log.Error("error reading webhook event", "route", routePath, "error", err.Error())
+				return err
+			}
+
+			go func() {
+				for _, d := range data {
+					results <- d
+				}
+			}()
+
+			return nil
+		},
+	}, nil
+}
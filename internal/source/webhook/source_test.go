@@ -0,0 +1,175 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package webhook
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mia-platform/ibdm/internal/source"
+)
+
+const testRoutes = `
+routes:
+  - path: deployments
+    verification:
+      type: token
+      header: X-Token
+      secretEnv: TEST_WEBHOOK_TOKEN
+    type: "{{ .kind }}"
+    operation: '{{ if eq .action "removed" }}delete{{ end }}'
+    time: "{{ .timestamp }}"
+  - path: services
+    verification:
+      type: none
+    type: '{{ if ne (header "X-Event") "ping" }}services{{ end }}'
+`
+
+// testSource builds a source from testRoutes served under /webhook.
+func testSource(t *testing.T) *Source {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "routes.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testRoutes), 0o600))
+	t.Setenv("WEBHOOK_SOURCE_CONFIG", path)
+	t.Setenv("TEST_WEBHOOK_TOKEN", "secret")
+
+	s, err := NewSource()
+	require.NoError(t, err)
+	return s
+}
+
+// receive returns count data from results.
+func receive(t *testing.T, results <-chan source.Data, count int) []source.Data {
+	t.Helper()
+
+	received := make([]source.Data, 0, count)
+	for range count {
+		select {
+		case data := <-results:
+			received = append(received, data)
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "timeout waiting for data")
+		}
+	}
+	return received
+}
+
+func TestGetWebhook(t *testing.T) {
+	s := testSource(t)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	timeSource = func() time.Time { return now }
+	t.Cleanup(func() { timeSource = time.Now })
+
+	types := map[string]source.Extra{"deployments": nil, "services": nil}
+
+	testCases := map[string]struct {
+		path        string
+		headers     http.Header
+		body        string
+		expected    []source.Data
+		expectedErr string
+	}{
+		"event items are routed and extracted": {
+			path:    "/webhook/deployments",
+			headers: http.Header{"X-Token": {"secret"}},
+			body:    `[{"kind":"deployments","action":"created","timestamp":"2024-05-01T10:00:00Z"},{"kind":"deployments","action":"removed","timestamp":"1714557600"},{"kind":"builds","action":"created","timestamp":""}]`,
+			expected: []source.Data{
+				{
+					Type:      "deployments",
+					Operation: source.DataOperationUpsert,
+					Values:    map[string]any{"kind": "deployments", "action": "created", "timestamp": "2024-05-01T10:00:00Z"},
+					Time:      time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+				},
+				{
+					Type:      "deployments",
+					Operation: source.DataOperationDelete,
+					Values:    map[string]any{"kind": "deployments", "action": "removed", "timestamp": "1714557600"},
+					Time:      time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+				},
+			},
+		},
+		"headers are available to templates": {
+			path:    "/webhook/services/",
+			headers: http.Header{"X-Event": {"updated"}},
+			body:    `{"name":"catalog"}`,
+			expected: []source.Data{
+				{Type: "services", Operation: source.DataOperationUpsert, Values: map[string]any{"name": "catalog"}, Time: now},
+			},
+		},
+		"items with an empty type are ignored": {
+			path:     "/webhook/services",
+			headers:  http.Header{"X-Event": {"ping"}},
+			body:     `{"zen":"keep it simple"}`,
+			expected: []source.Data{},
+		},
+		"unknown route": {
+			path:        "/webhook/unknown",
+			body:        `{}`,
+			expectedErr: `webhook source: no route declared for path "unknown"`,
+		},
+		"rejected request": {
+			path:        "/webhook/deployments",
+			headers:     http.Header{"X-Token": {"guess"}},
+			body:        `{}`,
+			expectedErr: "webhook source: request verification failed: invalid token",
+		},
+		"invalid payload": {
+			path:        "/webhook/services",
+			headers:     http.Header{},
+			body:        `["catalog"]`,
+			expectedErr: "webhook source: payload must be an object or a list of objects",
+		},
+		"invalid time": {
+			path:        "/webhook/deployments",
+			headers:     http.Header{"X-Token": {"secret"}},
+			body:        `{"kind":"deployments","action":"removed","timestamp":"yesterday"}`,
+			expectedErr: `webhook source: invalid time "yesterday": must be an RFC 3339 date or Unix seconds`,
+		},
+		"missing template field": {
+			path:        "/webhook/deployments",
+			headers:     http.Header{"X-Token": {"secret"}},
+			body:        `{"action":"created"}`,
+			expectedErr: `webhook source: rendering type: template: type:1:3: executing "type" at <.kind>: map has no entry for key "kind"`,
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			results := make(chan source.Data, 10)
+			webhook, err := s.GetWebhook(t.Context(), types, results)
+			require.NoError(t, err)
+			assert.Equal(t, http.MethodPost, webhook.Method)
+			assert.Equal(t, "/webhook/*", webhook.Path)
+
+			ctx := source.ContextWithWebhookPath(t.Context(), test.path)
+			err = webhook.Handler(ctx, test.headers, []byte(test.body))
+			if len(test.expectedErr) > 0 {
+				require.ErrorIs(t, err, ErrWebhookSource)
+				assert.EqualError(t, err, test.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, receive(t, results, len(test.expected)))
+			assert.Empty(t, results)
+		})
+	}
+}
+
+func TestNewSourceMissingSecret(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "routes.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testRoutes), 0o600))
+	t.Setenv("WEBHOOK_SOURCE_CONFIG", path)
+
+	_, err := NewSource()
+	require.ErrorIs(t, err, ErrWebhookSource)
+	assert.ErrorIs(t, err, ErrMissingEnvVariable)
+}
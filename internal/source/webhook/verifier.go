@@ -0,0 +1,148 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha1" //nolint:gosec // some producers still sign their webhooks with HMAC-SHA1
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"net/http"
+	"os"
+	"strings"
+)
+
+var (
+	errVerification = errors.New("request verification failed")
+
+	// hmacAlgorithms maps the supported HMAC algorithms to their hash constructors.
+	hmacAlgorithms = map[string]func() hash.Hash{
+		hmacAlgorithmSHA1:   sha1.New,
+		hmacAlgorithmSHA256: sha256.New,
+		hmacAlgorithmSHA512: sha512.New,
+	}
+)
+
+// verifier authenticates the requests of a route.
+type verifier func(headers http.Header, body []byte) error
+
+// newVerifier returns the verifier described by cfg, reading its secrets from the environment.
+func newVerifier(cfg verificationConfig) (verifier, error) {
+	switch cfg.Type {
+	case verificationTypeHMAC:
+		secret, err := requiredEnv(cfg.SecretEnv)
+		if err != nil {
+			return nil, err
+		}
+		return hmacVerifier(cfg, []byte(secret)), nil
+	case verificationTypeToken:
+		token, err := requiredEnv(cfg.SecretEnv)
+		if err != nil {
+			return nil, err
+		}
+		return tokenVerifier(cfg, token), nil
+	case verificationTypeBasic:
+		username, err := requiredEnv(cfg.UsernameEnv)
+		if err != nil {
+			return nil, err
+		}
+		password, err := requiredEnv(cfg.PasswordEnv)
+		if err != nil {
+			return nil, err
+		}
+		return basicVerifier(username, password), nil
+	default:
+		return func(http.Header, []byte) error { return nil }, nil
+	}
+}
+
+// requiredEnv returns the value of the environment variable name, failing when it is empty.
+func requiredEnv(name string) (string, error) {
+	value := os.Getenv(name)
+	if len(value) == 0 {
+		return "", fmt.Errorf("%w: %s", ErrMissingEnvVariable, name)
+	}
+	return value, nil
+}
+
+// hmacVerifier checks the HMAC of the body carried in the configured header, after its prefix.
+// It uses hmac.Equal for constant-time comparison to prevent timing attacks.
+func hmacVerifier(cfg verificationConfig, secret []byte) verifier {
+	newHash := hmacAlgorithms[cfg.Algorithm]
+	return func(headers http.Header, body []byte) error {
+		value, err := headerValue(headers, cfg.Header, cfg.Prefix)
+		if err != nil {
+			return err
+		}
+
+		var signature []byte
+		if cfg.Encoding == hmacEncodingBase64 {
+			signature, err = base64.StdEncoding.DecodeString(value)
+		} else {
+			signature, err = hex.DecodeString(value)
+		}
+		if err != nil {
+			return fmt.Errorf("%w: malformed %s header", errVerification, cfg.Header)
+		}
+
+		mac := hmac.New(newHash, secret)
+		mac.Write(body)
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return fmt.Errorf("%w: invalid signature", errVerification)
+		}
+		return nil
+	}
+}
+
+// tokenVerifier checks that the configured header carries the token after its prefix.
+func tokenVerifier(cfg verificationConfig, token string) verifier {
+	return func(headers http.Header, _ []byte) error {
+		value, err := headerValue(headers, cfg.Header, cfg.Prefix)
+		if err != nil {
+			return err
+		}
+		if subtle.ConstantTimeCompare([]byte(value), []byte(token)) != 1 {
+			return fmt.Errorf("%w: invalid token", errVerification)
+		}
+		return nil
+	}
+}
+
+// basicVerifier checks the HTTP basic authentication credentials of the request.
+func basicVerifier(username, password string) verifier {
+	return func(headers http.Header, _ []byte) error {
+		request := &http.Request{Header: headers}
+		gotUsername, gotPassword, ok := request.BasicAuth()
+		if !ok {
+			return fmt.Errorf("%w: missing basic authentication", errVerification)
+		}
+
+		usernameMatch := subtle.ConstantTimeCompare([]byte(gotUsername), []byte(username))
+		passwordMatch := subtle.ConstantTimeCompare([]byte(gotPassword), []byte(password))
+		if usernameMatch&passwordMatch != 1 {
+			return fmt.Errorf("%w: invalid credentials", errVerification)
+		}
+		return nil
+	}
+}
+
+// headerValue returns the value of the header name without prefix, failing when the header is
+// missing or does not start with prefix.
+func headerValue(headers http.Header, name, prefix string) (string, error) {
+	value := headers.Get(name)
+	if len(value) == 0 {
+		return "", fmt.Errorf("%w: missing %s header", errVerification, name)
+	}
+	value, found := strings.CutPrefix(value, prefix)
+	if !found {
+		return "", fmt.Errorf("%w: malformed %s header", errVerification, name)
+	}
+	return value, nil
+}
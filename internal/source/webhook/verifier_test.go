@@ -0,0 +1,128 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"hash"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sign returns the HMAC of body computed with newHash and secret.
+func sign(newHash func() hash.Hash, secret string, body []byte) []byte {
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+func TestVerifiers(t *testing.T) {
+	t.Setenv("TEST_WEBHOOK_SECRET", "secret")
+	t.Setenv("TEST_WEBHOOK_USERNAME", "user")
+	t.Setenv("TEST_WEBHOOK_PASSWORD", "pass")
+
+	body := []byte(`{"id":"1"}`)
+	basicRequest := func(username, password string) http.Header {
+		request, _ := http.NewRequest(http.MethodPost, "/", nil)
+		request.SetBasicAuth(username, password)
+		return request.Header
+	}
+
+	testCases := map[string]struct {
+		config      verificationConfig
+		headers     http.Header
+		expectedErr string
+	}{
+		"none accepts every request": {
+			config:  verificationConfig{Type: verificationTypeNone},
+			headers: http.Header{},
+		},
+		"hmac sha256 with prefix": {
+			config:  verificationConfig{Type: verificationTypeHMAC, Header: "X-Hub-Signature-256", Prefix: "sha256=", SecretEnv: "TEST_WEBHOOK_SECRET"},
+			headers: http.Header{"X-Hub-Signature-256": {"sha256=" + hex.EncodeToString(sign(sha256.New, "secret", body))}},
+		},
+		"hmac sha1": {
+			config:  verificationConfig{Type: verificationTypeHMAC, Header: "X-Signature", Algorithm: hmacAlgorithmSHA1, SecretEnv: "TEST_WEBHOOK_SECRET"},
+			headers: http.Header{"X-Signature": {hex.EncodeToString(sign(sha1.New, "secret", body))}},
+		},
+		"hmac sha512 in base64": {
+			config:  verificationConfig{Type: verificationTypeHMAC, Header: "X-Signature", Algorithm: hmacAlgorithmSHA512, Encoding: hmacEncodingBase64, SecretEnv: "TEST_WEBHOOK_SECRET"},
+			headers: http.Header{"X-Signature": {base64.StdEncoding.EncodeToString(sign(sha512.New, "secret", body))}},
+		},
+		"hmac with a wrong secret": {
+			config:      verificationConfig{Type: verificationTypeHMAC, Header: "X-Signature", SecretEnv: "TEST_WEBHOOK_SECRET"},
+			headers:     http.Header{"X-Signature": {hex.EncodeToString(sign(sha256.New, "other", body))}},
+			expectedErr: "request verification failed: invalid signature",
+		},
+		"hmac without the prefix": {
+			config:      verificationConfig{Type: verificationTypeHMAC, Header: "X-Signature", Prefix: "sha256=", SecretEnv: "TEST_WEBHOOK_SECRET"},
+			headers:     http.Header{"X-Signature": {hex.EncodeToString(sign(sha256.New, "secret", body))}},
+			expectedErr: "request verification failed: malformed X-Signature header",
+		},
+		"hmac not encoded": {
+			config:      verificationConfig{Type: verificationTypeHMAC, Header: "X-Signature", SecretEnv: "TEST_WEBHOOK_SECRET"},
+			headers:     http.Header{"X-Signature": {"not-hex"}},
+			expectedErr: "request verification failed: malformed X-Signature header",
+		},
+		"hmac missing header": {
+			config:      verificationConfig{Type: verificationTypeHMAC, Header: "X-Signature", SecretEnv: "TEST_WEBHOOK_SECRET"},
+			headers:     http.Header{},
+			expectedErr: "request verification failed: missing X-Signature header",
+		},
+		"token with prefix": {
+			config:  verificationConfig{Type: verificationTypeToken, Header: "Authorization", Prefix: "Bearer ", SecretEnv: "TEST_WEBHOOK_SECRET"},
+			headers: http.Header{"Authorization": {"Bearer secret"}},
+		},
+		"wrong token": {
+			config:      verificationConfig{Type: verificationTypeToken, Header: "X-Token", SecretEnv: "TEST_WEBHOOK_SECRET"},
+			headers:     http.Header{"X-Token": {"guess"}},
+			expectedErr: "request verification failed: invalid token",
+		},
+		"basic": {
+			config:  verificationConfig{Type: verificationTypeBasic, UsernameEnv: "TEST_WEBHOOK_USERNAME", PasswordEnv: "TEST_WEBHOOK_PASSWORD"},
+			headers: basicRequest("user", "pass"),
+		},
+		"basic with a wrong password": {
+			config:      verificationConfig{Type: verificationTypeBasic, UsernameEnv: "TEST_WEBHOOK_USERNAME", PasswordEnv: "TEST_WEBHOOK_PASSWORD"},
+			headers:     basicRequest("user", "guess"),
+			expectedErr: "request verification failed: invalid credentials",
+		},
+		"basic missing": {
+			config:      verificationConfig{Type: verificationTypeBasic, UsernameEnv: "TEST_WEBHOOK_USERNAME", PasswordEnv: "TEST_WEBHOOK_PASSWORD"},
+			headers:     http.Header{},
+			expectedErr: "request verification failed: missing basic authentication",
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, test.config.validate())
+			verify, err := newVerifier(test.config)
+			require.NoError(t, err)
+
+			err = verify(test.headers, body)
+			if len(test.expectedErr) > 0 {
+				require.ErrorIs(t, err, errVerification)
+				assert.EqualError(t, err, test.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestNewVerifierMissingSecret(t *testing.T) {
+	t.Parallel()
+
+	_, err := newVerifier(verificationConfig{Type: verificationTypeToken, Header: "X-Token", SecretEnv: "TEST_WEBHOOK_MISSING_SECRET"})
+	assert.ErrorIs(t, err, ErrMissingEnvVariable)
+}
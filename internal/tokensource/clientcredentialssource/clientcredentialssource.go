@@ -0,0 +1,54 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package clientcredentialssource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"github.com/mia-platform/ibdm/internal/tokensource"
+)
+
+const (
+	// tokenRequestTimeout bounds how long a single token exchange request is allowed to take.
+	tokenRequestTimeout = 30 * time.Second
+)
+
+var (
+	// ErrConfig wraps invalid client credentials configurations.
+	ErrConfig = errors.New("clientcredentialssource config")
+)
+
+// NewSource returns a tokensource.Source that exchanges clientID and clientSecret for an access
+// token at tokenURL, requesting scopes when not empty. Configuration is validated at construction
+// time, while the first token is requested lazily.
+//
+// The oauth2.TokenSource interface does not accept a context on Token(), so ctx is used for every
+// outgoing token request, like golang.org/x/oauth2/clientcredentials.Config.TokenSource.
+func NewSource(ctx context.Context, clientID, clientSecret, tokenURL string, scopes []string) (tokensource.Source, error) {
+	switch {
+	case clientID == "":
+		return nil, fmt.Errorf("%w: missing client id", ErrConfig)
+	case clientSecret == "":
+		return nil, fmt.Errorf("%w: missing client secret", ErrConfig)
+	case tokenURL == "":
+		return nil, fmt.Errorf("%w: missing token url", ErrConfig)
+	}
+
+	config := &clientcredentials.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		TokenURL:     tokenURL,
+		Scopes:       scopes,
+	}
+
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Timeout: tokenRequestTimeout})
+	return config.TokenSource(ctx), nil
+}
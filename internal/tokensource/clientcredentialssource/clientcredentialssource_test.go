@@ -0,0 +1,82 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package clientcredentialssource
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientCredentialsFlow(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "inventory:read", r.PostForm.Get("scope"))
+
+		clientID, clientSecret, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "client", clientID)
+		assert.Equal(t, "secret", clientSecret)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"token","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer server.Close()
+
+	source, err := NewSource(t.Context(), "client", "secret", server.URL, []string{"inventory:read"})
+	require.NoError(t, err)
+
+	for range 2 {
+		token, err := source.Token()
+		require.NoError(t, err)
+		assert.Equal(t, "token", token.AccessToken)
+	}
+	assert.Equal(t, int32(1), requests.Load(), "tokens are reused until expiry")
+}
+
+func TestNewSourceValidation(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		clientID     string
+		clientSecret string
+		tokenURL     string
+		expectedErr  string
+	}{
+		"missing client id": {
+			clientSecret: "secret",
+			tokenURL:     "https://auth.example.com/token",
+			expectedErr:  "clientcredentialssource config: missing client id",
+		},
+		"missing client secret": {
+			clientID:    "client",
+			tokenURL:    "https://auth.example.com/token",
+			expectedErr: "clientcredentialssource config: missing client secret",
+		},
+		"missing token url": {
+			clientID:     "client",
+			clientSecret: "secret",
+			expectedErr:  "clientcredentialssource config: missing token url",
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewSource(t.Context(), test.clientID, test.clientSecret, test.tokenURL, nil)
+			assert.ErrorIs(t, err, ErrConfig)
+			assert.EqualError(t, err, test.expectedErr)
+		})
+	}
+}
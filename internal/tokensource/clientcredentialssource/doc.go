@@ -0,0 +1,10 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+// Package clientcredentialssource implements tokensource.Source using the OAuth2
+// client_credentials grant defined in RFC 6749 section 4.4, authenticating the client with its
+// id and secret.
+//
+// The oauth2.TokenSource returned by NewSource automatically reuses tokens until they are near
+// expiry.
+package clientcredentialssource
@@ -0,0 +1,14 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+// Package githubappsource implements tokensource.Source for GitHub Apps: a JWT identifying the
+// app is signed with its private key and exchanged for an installation access token, as described
+// in the GitHub documentation about authenticating as a GitHub App installation.
+//
+// When the installation id is not known, it is resolved once from the organization the app is
+// installed on and cached for the lifetime of the source. App also lists the installations of the
+// app, to discover the accounts it can access.
+//
+// The oauth2.TokenSource returned by NewSource automatically reuses installation tokens until they
+// are near expiry, via oauth2.ReuseTokenSource.
+package githubappsource
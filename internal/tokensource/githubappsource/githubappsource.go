@@ -0,0 +1,302 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package githubappsource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v3/jwa"
+	"github.com/lestrrat-go/jwx/v3/jwk"
+	"github.com/lestrrat-go/jwx/v3/jwt"
+	"golang.org/x/oauth2"
+
+	"github.com/mia-platform/ibdm/internal/tokensource"
+)
+
+const (
+	// appJWTLifetime is the validity window of the JWT identifying the app. GitHub rejects JWTs
+	// expiring more than 10 minutes in the future.
+	appJWTLifetime = 9 * time.Minute
+	// appJWTClockDrift backdates the issue time of the JWT to tolerate clock drift with GitHub.
+	appJWTClockDrift = time.Minute
+	// tokenRequestTimeout bounds how long a single installation or token request is allowed to take
+	// when no HTTP client is provided.
+	tokenRequestTimeout = 30 * time.Second
+	// maxTokenErrorBodyBytes caps how much of a non-2xx response body is read into memory.
+	maxTokenErrorBodyBytes = 1024
+	// installationsPageSize is the number of installations requested for every page.
+	installationsPageSize = 100
+)
+
+var (
+	// ErrConfig wraps invalid GitHub App configurations.
+	ErrConfig = errors.New("githubappsource config")
+
+	// ErrTokenExchange wraps failures encountered while exchanging the app JWT for an installation
+	// access token.
+	ErrTokenExchange = errors.New("githubappsource token exchange")
+)
+
+// API describes the GitHub REST API the requests of an App are sent to, so that they share the
+// version, the user agent and the HTTP client of the other requests of the caller.
+type API struct {
+	// URL is the base URL of the GitHub REST API.
+	URL string
+	// Version is sent in the X-GitHub-Api-Version header of every request.
+	Version string
+	// UserAgent is sent in the User-Agent header of every request, required by the GitHub API.
+	UserAgent string
+	// HTTPClient sends the requests. When nil, a client with a default timeout is used.
+	HTTPClient *http.Client
+}
+
+// App authenticates as a GitHub App, signing JWTs with its private key to list its installations
+// and to obtain their access tokens.
+type App struct {
+	api        API
+	appID      string
+	privateKey jwk.Key
+}
+
+// Installation is an installation of a GitHub App on a user or organization account.
+type Installation struct {
+	// ID identifies the installation.
+	ID int64
+	// Account is the login of the account the app is installed on.
+	Account string
+	// AccountType is the type of the account the app is installed on, User or Organization.
+	AccountType string
+}
+
+// source implements tokensource.Source by exchanging a JWT signed with the private key of a
+// GitHub App for an access token of one of its installations.
+//
+// The oauth2.TokenSource interface does not accept a context on Token(), so the context used for
+// outgoing requests is captured once at construction time, mirroring the behaviour of
+// golang.org/x/oauth2/clientcredentials.Config.TokenSource.
+type source struct {
+	ctx context.Context //nolint:containedctx // Token() has no context parameter, see doc comment above.
+	app *App
+	org string
+
+	mu sync.Mutex
+	// installationID is the installation the tokens are requested for. When not supplied at
+	// construction time, it is resolved from org on first use and cached.
+	installationID int64
+}
+
+var _ tokensource.Source = &source{}
+
+// NewApp returns an App authenticating as the GitHub App appID with a JWT signed with privateKey
+// against api.
+func NewApp(api API, appID string, privateKey jwk.Key) (*App, error) {
+	switch {
+	case api.URL == "":
+		return nil, fmt.Errorf("%w: missing api url", ErrConfig)
+	case api.Version == "":
+		return nil, fmt.Errorf("%w: missing api version", ErrConfig)
+	case api.UserAgent == "":
+		return nil, fmt.Errorf("%w: missing user agent", ErrConfig)
+	case appID == "":
+		return nil, fmt.Errorf("%w: missing app id", ErrConfig)
+	case privateKey == nil:
+		return nil, fmt.Errorf("%w: missing private key", ErrConfig)
+	}
+
+	api.URL = strings.TrimSuffix(api.URL, "/")
+	if api.HTTPClient == nil {
+		api.HTTPClient = &http.Client{Timeout: tokenRequestTimeout}
+	}
+
+	return &App{
+		api:        api,
+		appID:      appID,
+		privateKey: privateKey,
+	}, nil
+}
+
+// NewSource returns a tokensource.Source producing installation access tokens for the GitHub App
+// appID, authenticating with a JWT signed with privateKey against api. When installationID is
+// zero, the installation of the app on org is used. See App.TokenSource.
+func NewSource(ctx context.Context, api API, appID string, installationID int64, org string, privateKey jwk.Key) (tokensource.Source, error) {
+	app, err := NewApp(api, appID, privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return app.TokenSource(ctx, installationID, org)
+}
+
+// TokenSource returns a tokensource.Source producing access tokens of the installation
+// installationID, or of the installation of the app on org when installationID is zero. The
+// returned source automatically reuses tokens until near expiry, see oauth2.ReuseTokenSource.
+// Configuration is validated at construction time, while the first token is requested lazily.
+func (a *App) TokenSource(ctx context.Context, installationID int64, org string) (tokensource.Source, error) {
+	switch {
+	case installationID < 0:
+		return nil, fmt.Errorf("%w: invalid installation id %d", ErrConfig, installationID)
+	case installationID == 0 && org == "":
+		return nil, fmt.Errorf("%w: one of installation id or organization is required", ErrConfig)
+	}
+
+	inner := &source{
+		ctx:            ctx,
+		app:            a,
+		org:            org,
+		installationID: installationID,
+	}
+
+	return oauth2.ReuseTokenSource(nil, inner), nil
+}
+
+// Installations returns all the installations of the app.
+func (a *App) Installations(ctx context.Context) ([]Installation, error) {
+	appJWT, err := a.signedJWT(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	installations := make([]Installation, 0)
+	for page := 1; ; page++ {
+		var pageItems []struct {
+			ID      int64 `json:"id"`
+			Account struct {
+				Login string `json:"login"`
+				Type  string `json:"type"`
+			} `json:"account"`
+		}
+
+		path := "/app/installations?per_page=" + strconv.Itoa(installationsPageSize) + "&page=" + strconv.Itoa(page)
+		if err := a.do(ctx, http.MethodGet, path, appJWT, &pageItems); err != nil {
+			return nil, err
+		}
+
+		for _, item := range pageItems {
+			installations = append(installations, Installation{
+				ID:          item.ID,
+				Account:     item.Account.Login,
+				AccountType: item.Account.Type,
+			})
+		}
+
+		if len(pageItems) < installationsPageSize {
+			return installations, nil
+		}
+	}
+}
+
+// Token implements oauth2.TokenSource by signing a JWT identifying the app and exchanging it for
+// an access token of the installation, whose id is resolved on first use when not configured.
+func (s *source) Token() (*oauth2.Token, error) {
+	appJWT, err := s.app.signedJWT(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	installationID, err := s.resolveInstallationID(appJWT)
+	if err != nil {
+		return nil, err
+	}
+
+	path := "/app/installations/" + strconv.FormatInt(installationID, 10) + "/access_tokens"
+	var tokenResp struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"` //nolint:tagliatelle // GitHub API uses snake_case
+	}
+	if err := s.app.do(s.ctx, http.MethodPost, path, appJWT, &tokenResp); err != nil {
+		return nil, err
+	}
+
+	return &oauth2.Token{
+		AccessToken: tokenResp.Token,
+		TokenType:   "Bearer",
+		Expiry:      tokenResp.ExpiresAt,
+	}, nil
+}
+
+// signedJWT builds the JWT identifying the app, signed with RS256 as required by GitHub.
+func (a *App) signedJWT(now time.Time) (string, error) {
+	tok, err := jwt.NewBuilder().
+		Issuer(a.appID).
+		IssuedAt(now.Add(-appJWTClockDrift)).
+		Expiration(now.Add(appJWTLifetime)).
+		Build()
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to build app jwt: %w", ErrTokenExchange, err)
+	}
+
+	signed, err := jwt.Sign(tok, jwt.WithKey(jwa.RS256(), a.privateKey))
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to sign app jwt: %w", ErrTokenExchange, err)
+	}
+
+	return string(signed), nil
+}
+
+// resolveInstallationID returns the configured installation id, or looks up the installation of
+// the app on the organization on first use, caching the result for subsequent calls. A failed
+// lookup is never cached, so a later call retries it from scratch.
+func (s *source) resolveInstallationID(appJWT string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.installationID != 0 {
+		return s.installationID, nil
+	}
+
+	var installation struct {
+		ID int64 `json:"id"`
+	}
+	if err := s.app.do(s.ctx, http.MethodGet, "/orgs/"+url.PathEscape(s.org)+"/installation", appJWT, &installation); err != nil {
+		return 0, err
+	}
+
+	if installation.ID == 0 {
+		return 0, fmt.Errorf("%w: installation response for organization %q is missing the id", ErrTokenExchange, s.org)
+	}
+
+	s.installationID = installation.ID
+	return s.installationID, nil
+}
+
+// do sends a request to the GitHub API authenticated as the app and decodes the JSON response
+// body into output.
+func (a *App) do(ctx context.Context, method, path, appJWT string, output any) error {
+	req, err := http.NewRequestWithContext(ctx, method, a.api.URL+path, nil)
+	if err != nil {
+		return fmt.Errorf("%w: failed to build request: %w", ErrTokenExchange, err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+appJWT)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", a.api.Version)
+	req.Header.Set("User-Agent", a.api.UserAgent)
+
+	resp, err := a.api.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: request to %s failed: %w", ErrTokenExchange, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxTokenErrorBodyBytes))
+		return fmt.Errorf("%w: request to %s failed: status %s: %s", ErrTokenExchange, path, resp.Status, body)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(output); err != nil {
+		return fmt.Errorf("%w: failed to decode response of %s: %w", ErrTokenExchange, path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,254 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: AGPL-3.0-only or Commercial
+
+package githubappsource
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v3/jwa"
+	"github.com/lestrrat-go/jwx/v3/jwk"
+	"github.com/lestrrat-go/jwx/v3/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	rsaKeyBits = 2048
+	// appID is the fictional GitHub App identifier used across the tests in this package.
+	appID = "123456"
+	// testAPIVersion and testUserAgent are the headers expected on every request of the app.
+	testAPIVersion = "2026-03-10"
+	testUserAgent  = "ibdm-test"
+)
+
+// testAPI returns the API at url sending the test version and user agent.
+func testAPI(url string) API {
+	return API{URL: url, Version: testAPIVersion, UserAgent: testUserAgent}
+}
+
+// generateTestKey creates a fresh RSA key pair and wraps it into a jwk.Key, to be used as
+// fictional test material. It is never used outside of this test file.
+func generateTestKey(t *testing.T) (*rsa.PrivateKey, jwk.Key) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	require.NoError(t, err)
+
+	jwkKey, err := jwk.Import(key)
+	require.NoError(t, err)
+
+	return key, jwkKey
+}
+
+// newGitHubServer starts a test server serving the list of the installations of the app, the
+// installation lookup of the "my-org" organization and the installation token exchange of installation 42, returning tokens valid for
+// expiresIn. It returns the server together with atomic counters tracking how many times the
+// lookup and exchange endpoints were hit.
+func newGitHubServer(t *testing.T, key *rsa.PrivateKey, expiresIn time.Duration) (*httptest.Server, *atomic.Int32, *atomic.Int32) {
+	t.Helper()
+
+	var lookupHits, tokenHits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bearer, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		require.True(t, found)
+		parsed, err := jwt.Parse([]byte(bearer), jwt.WithKey(jwa.RS256(), key.Public()))
+		require.NoError(t, err)
+
+		issuer, _ := parsed.Issuer()
+		assert.Equal(t, appID, issuer)
+		issuedAt, _ := parsed.IssuedAt()
+		expiration, _ := parsed.Expiration()
+		assert.LessOrEqual(t, expiration.Sub(issuedAt), 10*time.Minute)
+		assert.Equal(t, "application/vnd.github+json", r.Header.Get("Accept"))
+		assert.Equal(t, testAPIVersion, r.Header.Get("X-GitHub-Api-Version"))
+		assert.Equal(t, testUserAgent, r.Header.Get("User-Agent"))
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/app/installations":
+			assert.Equal(t, "100", r.URL.Query().Get("per_page"))
+			assert.Equal(t, "1", r.URL.Query().Get("page"))
+			_, _ = w.Write([]byte(`[{"id":42,"account":{"login":"my-org","type":"Organization"}},{"id":43,"account":{"login":"octocat","type":"User"}}]`))
+		case r.Method == http.MethodGet && r.URL.Path == "/orgs/my-org/installation":
+			lookupHits.Add(1)
+			_, _ = w.Write([]byte(`{"id":42,"account":{"login":"my-org"}}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/app/installations/42/access_tokens":
+			hit := tokenHits.Add(1)
+			err := json.NewEncoder(w).Encode(map[string]any{
+				"token":      "ghs_token" + string(rune('0'+hit)),
+				"expires_at": time.Now().Add(expiresIn).UTC().Format(time.RFC3339),
+			})
+			require.NoError(t, err)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Not Found"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &lookupHits, &tokenHits
+}
+
+func TestNewSourceValidation(t *testing.T) {
+	t.Parallel()
+
+	_, key := generateTestKey(t)
+	testCases := map[string]struct {
+		api            API
+		appID          string
+		installationID int64
+		org            string
+		privateKey     jwk.Key
+	}{
+		"missing api url": {
+			appID:          appID,
+			installationID: 42,
+			privateKey:     key,
+		},
+		"missing api version": {
+			api:            API{URL: "https://api.github.com", UserAgent: testUserAgent},
+			appID:          appID,
+			installationID: 42,
+			privateKey:     key,
+		},
+		"missing user agent": {
+			api:            API{URL: "https://api.github.com", Version: testAPIVersion},
+			appID:          appID,
+			installationID: 42,
+			privateKey:     key,
+		},
+		"missing app id": {
+			api:            testAPI("https://api.github.com"),
+			installationID: 42,
+			privateKey:     key,
+		},
+		"missing private key": {
+			api:            testAPI("https://api.github.com"),
+			appID:          appID,
+			installationID: 42,
+		},
+		"negative installation id": {
+			api:            testAPI("https://api.github.com"),
+			appID:          appID,
+			installationID: -1,
+			privateKey:     key,
+		},
+		"missing installation id and organization": {
+			api:        testAPI("https://api.github.com"),
+			appID:      appID,
+			privateKey: key,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			source, err := NewSource(t.Context(), tc.api, tc.appID, tc.installationID, tc.org, tc.privateKey)
+			require.ErrorIs(t, err, ErrConfig)
+			assert.Nil(t, source)
+		})
+	}
+}
+
+func TestTokenIsReusedUntilExpiry(t *testing.T) {
+	t.Parallel()
+
+	rsaKey, key := generateTestKey(t)
+	server, lookupHits, tokenHits := newGitHubServer(t, rsaKey, time.Hour)
+
+	source, err := NewSource(t.Context(), testAPI(server.URL+"/"), appID, 42, "", key)
+	require.NoError(t, err)
+
+	for range 3 {
+		token, err := source.Token()
+		require.NoError(t, err)
+		assert.Equal(t, "ghs_token1", token.AccessToken)
+		assert.Equal(t, "Bearer", token.TokenType)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), token.Expiry, time.Minute)
+	}
+
+	assert.Equal(t, int32(0), lookupHits.Load())
+	assert.Equal(t, int32(1), tokenHits.Load())
+}
+
+func TestTokenResolvesInstallationOnce(t *testing.T) {
+	t.Parallel()
+
+	rsaKey, key := generateTestKey(t)
+	// tokens already expired force a new exchange at every call
+	server, lookupHits, tokenHits := newGitHubServer(t, rsaKey, -time.Minute)
+
+	source, err := NewSource(t.Context(), testAPI(server.URL), appID, 0, "my-org", key)
+	require.NoError(t, err)
+
+	token, err := source.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "ghs_token1", token.AccessToken)
+
+	token, err = source.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "ghs_token2", token.AccessToken)
+
+	assert.Equal(t, int32(1), lookupHits.Load())
+	assert.Equal(t, int32(2), tokenHits.Load())
+}
+
+func TestTokenErrors(t *testing.T) {
+	t.Parallel()
+
+	rsaKey, key := generateTestKey(t)
+	server, _, _ := newGitHubServer(t, rsaKey, time.Hour)
+
+	testCases := map[string]struct {
+		installationID int64
+		org            string
+	}{
+		"app not installed on organization": {
+			org: "other-org",
+		},
+		"unknown installation": {
+			installationID: 7,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			source, err := NewSource(t.Context(), testAPI(server.URL), appID, tc.installationID, tc.org, key)
+			require.NoError(t, err)
+
+			token, err := source.Token()
+			require.ErrorIs(t, err, ErrTokenExchange)
+			assert.ErrorContains(t, err, "404")
+			assert.Nil(t, token)
+		})
+	}
+}
+
+func TestInstallations(t *testing.T) {
+	t.Parallel()
+
+	rsaKey, key := generateTestKey(t)
+	server, _, _ := newGitHubServer(t, rsaKey, time.Hour)
+
+	app, err := NewApp(testAPI(server.URL), appID, key)
+	require.NoError(t, err)
+
+	installations, err := app.Installations(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, []Installation{
+		{ID: 42, Account: "my-org", AccountType: "Organization"},
+		{ID: 43, Account: "octocat", AccountType: "User"},
+	}, installations)
+}
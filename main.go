@@ -104,6 +104,8 @@ func rootCmd() *cobra.Command {
 	cmd.AddCommand(
 		internalcmd.RunCmd(),
 		internalcmd.SyncCmd(),
+		internalcmd.MappingCmd(),
+		internalcmd.ReplayCmd(),
 		versionCmd(),
 	)
 